@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/app/debug"
+	"http-services/api/app/v1/admin/diagnostics"
+	"http-services/api/app/v1/admin/loglevel"
+	"http-services/api/app/v1/admin/settings"
+	"http-services/api/middleware"
+	"http-services/application"
+)
+
+// NewAdminHandler 构建独立管理端口（admin.listen）的路由。管理端口只绑定回环地址，
+// 不经过限流、跨域与请求体限制：
+//   - /debug/pprof/*、/metrics：运行时诊断，不要求 token，便于直接使用 go tool pprof
+//   - /api/v1/admin/log/*、/api/v1/admin/config：与主端口相同的 admin token 认证
+//   - /api/v1/admin/diagnostics/*：与主端口相同，使用 admin.diagnostics_token 认证
+func NewAdminHandler(a *application.App) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	// gin.DefaultWriter 已在 newEngine 中重定向到 zap
+	router := gin.Default()
+	router.Use(middleware.TraceID())
+
+	debug.RegisterRoutes(router)
+
+	admin := router.Group("/api/v1/admin")
+	admin.Use(
+		middleware.NewAudit(a.Config, middleware.AdminAccessAction, middleware.AdminResource),
+		middleware.NewAdminTokenVerify(a.Config),
+	)
+	loglevel.RegisterAdminRoutes(admin, a)
+	settings.RegisterAdminRoutes(admin)
+
+	diagnostics.RegisterRoutes(router.Group("/api/v1/admin/diagnostics"), a)
+	return router
+}
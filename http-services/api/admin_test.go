@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"http-services/api/response"
+	"http-services/config"
+)
+
+func TestAdminHandlerServesDiagnosticsAndGuardsAdminRoutes(t *testing.T) {
+	oldConfig := config.Update(func(c *config.Config) {
+		c.Admin.Token = "admin-handler-test-token"
+	})
+	t.Cleanup(func() { config.Replace(oldConfig) })
+	handler := NewAdminHandler(newTestApp(t))
+
+	serve := func(target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// 诊断路由不要求 token
+	if w := serve("/debug/pprof/", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("GET /debug/pprof/ = %d, want pprof index", w.Code)
+	}
+	if w := serve("/debug/pprof/goroutine?debug=1", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("GET /debug/pprof/goroutine = %d, want goroutine profile", w.Code)
+	}
+	w := serve("/metrics", "")
+	var metrics map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &metrics); err != nil {
+		t.Fatalf("GET /metrics returned invalid json: %v", err)
+	}
+	for _, name := range []string{"memstats", "goroutines", "uptime_seconds", "rate_limiters", "log_sinks"} {
+		if _, ok := metrics[name]; !ok {
+			t.Errorf("metrics missing %s", name)
+		}
+	}
+
+	// 日志级别与配置接口与主端口一样要求 admin token
+	for _, target := range []string{"/api/v1/admin/log/levels", "/api/v1/admin/config"} {
+		var body struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal(serve(target, "").Body.Bytes(), &body); err != nil || body.Code != response.UNAUTHENTICATED.Code {
+			t.Errorf("GET %s without token code = %d, want UNAUTHENTICATED", target, body.Code)
+		}
+		if err := json.Unmarshal(serve(target, "admin-handler-test-token").Body.Bytes(), &body); err != nil || body.Code != response.OK.Code {
+			t.Errorf("GET %s with token code = %d, want OK", target, body.Code)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package debug
+
+import (
+	"expvar"
+	"runtime"
+	"sync"
+
+	"http-services/api/middleware"
+	"http-services/utils/buildinfo"
+	"http-services/utils/log"
+)
+
+var publishOnce sync.Once
+
+// publishMetrics 向 expvar 注册服务自身的指标；expvar 默认已包含 memstats 与 cmdline。
+// expvar 的变量名全局唯一，engine 重建时不能重复注册
+func publishMetrics() {
+	publishOnce.Do(func() {
+		expvar.Publish("uptime_seconds", expvar.Func(func() any {
+			return int64(buildinfo.Uptime().Seconds())
+		}))
+		expvar.Publish("goroutines", expvar.Func(func() any {
+			return runtime.NumGoroutine()
+		}))
+		expvar.Publish("rate_limiters", expvar.Func(func() any {
+			return middleware.GetAllStats()
+		}))
+		expvar.Publish("log_sinks", expvar.Func(func() any {
+			return log.SinkStats()
+		}))
+	})
+}
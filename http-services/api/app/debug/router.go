@@ -0,0 +1,51 @@
+// Package debug 提供只挂载在独立管理端口上的运行时诊断路由：pprof 与 expvar 指标。
+package debug
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册诊断路由
+// 路径：/debug/pprof/*、/metrics
+func RegisterRoutes(router gin.IRouter) {
+	if router == nil {
+		return
+	}
+	publishMetrics()
+
+	RegisterPprofRoutes(router.Group("/debug/pprof"))
+	router.GET("/metrics", gin.WrapH(expvar.Handler()))
+}
+
+// RegisterPprofRoutes 在 group 下注册 net/http/pprof 的全部 handler，
+// 诊断接口（/api/v1/admin/diagnostics/pprof）与管理端口共用
+func RegisterPprofRoutes(group gin.IRouter) {
+	if group == nil {
+		return
+	}
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(withoutWriteDeadline(pprof.Profile)))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(withoutWriteDeadline(pprof.Trace)))
+	// heap、goroutine、allocs、block、mutex、threadcreate 等按名称分发；pprof.Index 只识别
+	// /debug/pprof/ 前缀，挂在其他路径下时需要直接使用 pprof.Handler
+	group.GET("/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// withoutWriteDeadline 取消本次请求的写超时：profile、trace 按 seconds 参数持续采样，
+// 挂在主端口上时会超过 server.write_timeout
+func withoutWriteDeadline(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		handler(w, r)
+	}
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"http-services/api/middleware"
+	"http-services/api/response"
+	"http-services/application"
+	"http-services/db/msqldb/auditlog"
+	"http-services/utils/log"
+)
+
+// Handler 是审计查询接口，配置与数据库连接由 App 提供
+type Handler struct {
+	app *application.App
+}
+
+// NewHandler 创建审计查询接口
+func NewHandler(a *application.App) *Handler {
+	return &Handler{app: a}
+}
+
+// List 按条件分页查询 audit_logs 表，按时间倒序返回；只写入 hash 链文件时该接口不可用
+func (h *Handler) List(c *gin.Context) {
+	if auditConfig := h.app.Config().Audit; !auditConfig.Enabled || !slices.Contains(auditConfig.Sinks, "mysql") {
+		response.ReturnError(c, response.FAILED_PRECONDITION, "audit mysql sink disabled.")
+		return
+	}
+	var req ListRequest
+	if !middleware.CheckQueryParam(&req, c) {
+		return
+	}
+	database, err := h.app.DB()
+	if err != nil {
+		log.FromContext(c).Error("open audit database failed", zap.Error(err))
+		response.ReturnError(c, response.INTERNAL, "服务内部错误")
+		return
+	}
+
+	page := middleware.ParsePageQuery(c)
+	logs, total, err := auditlog.List(c.Request.Context(), database, auditlog.Filter{
+		Actor:      req.Actor,
+		Action:     req.Action,
+		Resource:   req.Resource,
+		ResourceID: req.ResourceID,
+		Outcome:    req.Outcome,
+		TraceID:    req.TraceID,
+		Since:      req.Since,
+		Until:      req.Until,
+	}, page.Offset, page.Limit)
+	if err != nil {
+		log.FromContext(c).Error("list audit logs failed", zap.Error(err))
+		response.ReturnError(c, response.INTERNAL, "服务内部错误")
+		return
+	}
+	response.ReturnOkWithTotal(c, int(total), toLogDTOs(logs))
+}
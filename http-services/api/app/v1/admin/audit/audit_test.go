@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/application/apptest"
+	"http-services/config"
+)
+
+type listResponse struct {
+	Code   int      `json:"code"`
+	Total  *int     `json:"total"`
+	Detail []LogDTO `json:"detail"`
+}
+
+func setupTestRouter(t *testing.T, sinks []string) (*gin.Engine, *apptest.Harness) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := apptest.New(t, func(c *config.Config) {
+		c.Audit.Enabled = true
+		c.Audit.Sinks = sinks
+	})
+
+	r := gin.New()
+	RegisterAdminRoutes(r.Group("/admin"), h.App)
+	return r, h
+}
+
+func doRequest(t *testing.T, router *gin.Engine, target string) listResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	var resp listResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return resp
+}
+
+func TestList_FiltersAndPages(t *testing.T) {
+	router, h := setupTestRouter(t, []string{"file", "mysql"})
+	mock := h.SQL
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `audit_logs` WHERE action = ? AND created_at >= ?")).
+		WithArgs("user.login", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `audit_logs` WHERE action = ? AND created_at >= ? ORDER BY id DESC LIMIT ? OFFSET ?",
+	)).
+		WithArgs("user.login", since, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "outcome", "created_at"}).
+			AddRow(1, "u1", "user.login", "failure", since))
+
+	resp := doRequest(t, router, "/admin/audit/logs?action=user.login&since=2026-10-01T00:00:00Z&page=2&page_size=2")
+	if resp.Code != response.OK.Code || resp.Total == nil || *resp.Total != 3 {
+		t.Fatalf("List() code = %d total = %v", resp.Code, resp.Total)
+	}
+	if len(resp.Detail) != 1 || resp.Detail[0].ID != 1 || resp.Detail[0].Actor != "u1" || resp.Detail[0].Outcome != "failure" {
+		t.Fatalf("List() detail = %+v", resp.Detail)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList_RejectsInvalidInputAndDisabledSink(t *testing.T) {
+	router, h := setupTestRouter(t, []string{"mysql"})
+	if resp := doRequest(t, router, "/admin/audit/logs?outcome=maybe"); resp.Code != response.INVALID_ARGUMENT.Code {
+		t.Fatalf("invalid outcome code = %d", resp.Code)
+	}
+
+	h.Config.Audit.Sinks = []string{"file"}
+	if resp := doRequest(t, router, "/admin/audit/logs"); resp.Code != response.FAILED_PRECONDITION.Code {
+		t.Fatalf("file-only audit code = %d, want FAILED_PRECONDITION", resp.Code)
+	}
+}
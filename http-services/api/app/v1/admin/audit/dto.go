@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"time"
+
+	"http-services/db/msqldb/auditlog"
+	"http-services/utils/audit"
+)
+
+// ListRequest 审计记录查询条件，均为精确匹配；分页使用 page / page_size
+type ListRequest struct {
+	Actor      string    `form:"actor"`
+	Action     string    `form:"action"`
+	Resource   string    `form:"resource"`
+	ResourceID string    `form:"resource_id"`
+	Outcome    string    `form:"outcome" binding:"omitempty,oneof=success failure denied"`
+	TraceID    string    `form:"trace_id"`
+	Since      time.Time `form:"since"` // RFC3339，包含
+	Until      time.Time `form:"until"` // RFC3339，不包含
+}
+
+// LogDTO 单条审计记录
+type LogDTO struct {
+	ID uint64 `json:"id"`
+	audit.Entry
+}
+
+func toLogDTOs(logs []auditlog.Log) []LogDTO {
+	result := make([]LogDTO, 0, len(logs))
+	for _, item := range logs {
+		result = append(result, LogDTO{ID: item.ID, Entry: item.Entry()})
+	}
+	return result
+}
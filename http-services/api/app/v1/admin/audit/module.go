@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+	"http-services/db"
+	"http-services/db/msqldb/auditlog"
+	domainhealth "http-services/domain/health"
+)
+
+func init() {
+	application.RegisterModule(NewModule)
+}
+
+// Module 把审计接入 App：提供 audit_logs 表迁移与 /api/v1/admin 下的查询接口，启用 MySQL sink 时检查数据库连通性
+type Module struct {
+	application.BaseModule
+	app *application.App
+}
+
+// NewModule 创建审计模块
+func NewModule(a *application.App) application.Module {
+	return &Module{app: a}
+}
+
+// Name 模块名称
+func (m *Module) Name() string { return "audit" }
+
+// AdminRoutes 注册审计记录查询接口
+func (m *Module) AdminRoutes(admin *gin.RouterGroup) {
+	RegisterAdminRoutes(admin, m.app)
+}
+
+// Migrations 返回 audit_logs 表迁移
+func (m *Module) Migrations() []db.Migrator {
+	return []db.Migrator{{Name: "auditlog", Migrate: auditlog.Migrate}}
+}
+
+// HealthChecks 在启用 MySQL sink 时检查数据库，未启用时检查项直接通过
+func (m *Module) HealthChecks() []domainhealth.Check {
+	return []domainhealth.Check{{Name: "audit-mysql", Check: func(ctx context.Context) error {
+		if auditConfig := m.app.Config().Audit; !auditConfig.Enabled || !slices.Contains(auditConfig.Sinks, "mysql") {
+			return nil
+		}
+		return m.app.PingDB(ctx)
+	}}}
+}
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+)
+
+// RegisterAdminRoutes 注册审计记录查询路由
+// 路径：/api/v1/admin/audit/logs
+func RegisterAdminRoutes(admin *gin.RouterGroup, a *application.App) {
+	if admin == nil {
+		return
+	}
+	h := NewHandler(a)
+	admin.GET("/audit/logs", h.List)
+}
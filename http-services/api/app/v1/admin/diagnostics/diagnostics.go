@@ -0,0 +1,108 @@
+package diagnostics
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/middleware"
+	"http-services/api/response"
+	"http-services/db/msqldb"
+	"http-services/db/rdb"
+	"http-services/utils/buildinfo"
+)
+
+// Runtime 返回 goroutine、内存与 GC 状态；ReadMemStats 会短暂 stop the world，不适合高频轮询
+func Runtime(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	gc := GCDTO{
+		NumGC:        mem.NumGC,
+		NumForcedGC:  mem.NumForcedGC,
+		PauseTotalMs: milliseconds(time.Duration(mem.PauseTotalNs)),
+		NextGC:       mem.NextGC,
+		CPUFraction:  mem.GCCPUFraction,
+		// 传入负数只读取当前值，不修改限制
+		MemoryLimit: debug.SetMemoryLimit(-1),
+	}
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC))
+		gc.LastGC = &lastGC
+		gc.LastPauseMs = milliseconds(time.Duration(mem.PauseNs[(mem.NumGC+255)%256]))
+	}
+
+	response.ReturnOk(c, RuntimeDTO{
+		GoVersion:     runtime.Version(),
+		GOOS:          runtime.GOOS,
+		GOARCH:        runtime.GOARCH,
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		Goroutines:    runtime.NumGoroutine(),
+		CgoCalls:      runtime.NumCgoCall(),
+		UptimeSeconds: int64(buildinfo.Uptime().Seconds()),
+		Memory: MemoryDTO{
+			Alloc:        mem.Alloc,
+			TotalAlloc:   mem.TotalAlloc,
+			Sys:          mem.Sys,
+			HeapAlloc:    mem.HeapAlloc,
+			HeapSys:      mem.HeapSys,
+			HeapIdle:     mem.HeapIdle,
+			HeapInuse:    mem.HeapInuse,
+			HeapReleased: mem.HeapReleased,
+			HeapObjects:  mem.HeapObjects,
+			StackInuse:   mem.StackInuse,
+			Mallocs:      mem.Mallocs,
+			Frees:        mem.Frees,
+		},
+		GC: gc,
+	})
+}
+
+// Build 返回 main.Version / BuildTime / GitCommit 与 go 工具链记录的构建参数
+func Build(c *gin.Context) {
+	response.ReturnOk(c, buildinfo.Get())
+}
+
+// RateLimits 返回按速率与突发量分组的限流器统计
+func RateLimits(c *gin.Context) {
+	response.ReturnOk(c, RateLimitsDTO{Limiters: middleware.GetAllStats()})
+}
+
+// Pools 返回 MySQL 主库、副本与 Redis 的连接池统计
+func Pools(c *gin.Context) {
+	dto := PoolsDTO{MySQL: []MySQLPoolDTO{}}
+	for _, pool := range msqldb.Stats() {
+		dto.MySQL = append(dto.MySQL, MySQLPoolDTO{
+			Name:              pool.Name,
+			MaxOpen:           pool.MaxOpenConnections,
+			Open:              pool.OpenConnections,
+			InUse:             pool.InUse,
+			Idle:              pool.Idle,
+			WaitCount:         pool.WaitCount,
+			WaitDurationMs:    milliseconds(pool.WaitDuration),
+			MaxIdleClosed:     pool.MaxIdleClosed,
+			MaxIdleTimeClosed: pool.MaxIdleTimeClosed,
+			MaxLifetimeClosed: pool.MaxLifetimeClosed,
+		})
+	}
+	if stats := rdb.Stats(); stats != nil {
+		dto.Redis = &RedisPoolDTO{
+			Hits:           stats.Hits,
+			Misses:         stats.Misses,
+			Timeouts:       stats.Timeouts,
+			WaitCount:      stats.WaitCount,
+			WaitDurationMs: milliseconds(time.Duration(stats.WaitDurationNs)),
+			TotalConns:     stats.TotalConns,
+			IdleConns:      stats.IdleConns,
+			StaleConns:     stats.StaleConns,
+		}
+	}
+	response.ReturnOk(c, dto)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/middleware"
+	"http-services/api/response"
+	"http-services/application/apptest"
+	"http-services/config"
+)
+
+// newRouter 按 v1 的方式挂载：admin 分组要求 admin token，诊断分组与它并列
+func newRouter(t *testing.T, adminToken, diagnosticsToken string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := apptest.New(t, func(c *config.Config) {
+		c.Admin.Token = adminToken
+		c.Admin.DiagnosticsToken = diagnosticsToken
+	})
+
+	r := gin.New()
+	admin := r.Group("/admin")
+	admin.Use(middleware.NewAdminTokenVerify(h.App.Config))
+	RegisterRoutes(r.Group("/admin/diagnostics"), h.App)
+	return r
+}
+
+func get(r *gin.Engine, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	if token != "" {
+		req.Header.Set(middleware.AuthorizationHeader, "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, detail any) int {
+	t.Helper()
+	resp := struct {
+		Code   int `json:"code"`
+		Detail any `json:"detail"`
+	}{Detail: detail}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", w.Body.String(), err)
+	}
+	return resp.Code
+}
+
+func TestDiagnosticsDisabledByDefault(t *testing.T) {
+	r := newRouter(t, "admin-secret", "")
+	for _, target := range []string{"/admin/diagnostics/runtime", "/admin/diagnostics/pprof/heap"} {
+		if code := decode(t, get(r, target, "admin-secret"), nil); code != response.PERMISSION_DENIED.Code {
+			t.Fatalf("GET %s code = %d, want PERMISSION_DENIED", target, code)
+		}
+	}
+}
+
+func TestDiagnosticsEndpoints(t *testing.T) {
+	r := newRouter(t, "admin-secret", "diag-secret")
+
+	if code := decode(t, get(r, "/admin/diagnostics/runtime", "admin-secret"), nil); code != response.UNAUTHENTICATED.Code {
+		t.Fatalf("runtime with admin token code = %d, want UNAUTHENTICATED", code)
+	}
+
+	var runtimeDTO RuntimeDTO
+	if code := decode(t, get(r, "/admin/diagnostics/runtime", "diag-secret"), &runtimeDTO); code != response.OK.Code {
+		t.Fatalf("runtime code = %d", code)
+	}
+	if runtimeDTO.Goroutines == 0 || runtimeDTO.GOMAXPROCS == 0 || runtimeDTO.Memory.HeapAlloc == 0 {
+		t.Fatalf("runtime = %+v, want non-zero goroutines, gomaxprocs and heap", runtimeDTO)
+	}
+
+	var build struct {
+		Version   string `json:"version"`
+		GoVersion string `json:"go_version"`
+	}
+	if code := decode(t, get(r, "/admin/diagnostics/build", "diag-secret"), &build); code != response.OK.Code || build.Version == "" || build.GoVersion == "" {
+		t.Fatalf("build code = %d, detail = %+v", code, build)
+	}
+
+	// 未初始化的客户端只返回空统计，不会尝试连接
+	var pools PoolsDTO
+	if code := decode(t, get(r, "/admin/diagnostics/pools", "diag-secret"), &pools); code != response.OK.Code || len(pools.MySQL) != 0 || pools.Redis != nil {
+		t.Fatalf("pools code = %d, detail = %+v", code, pools)
+	}
+
+	// 配置取值与脱敏由 settings 包测试，这里只确认路由已挂载
+	if code := decode(t, get(r, "/admin/diagnostics/config", "diag-secret"), nil); code != response.OK.Code {
+		t.Fatalf("config code = %d", code)
+	}
+
+	w := get(r, "/admin/diagnostics/pprof/goroutine?debug=1", "diag-secret")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("pprof goroutine status = %d, body = %.200s", w.Code, w.Body.String())
+	}
+}
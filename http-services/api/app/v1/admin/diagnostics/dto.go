@@ -0,0 +1,86 @@
+package diagnostics
+
+import (
+	"time"
+
+	"http-services/api/middleware"
+)
+
+// RuntimeDTO Go 运行时状态
+type RuntimeDTO struct {
+	GoVersion     string    `json:"go_version"`
+	GOOS          string    `json:"goos"`
+	GOARCH        string    `json:"goarch"`
+	NumCPU        int       `json:"num_cpu"`
+	GOMAXPROCS    int       `json:"gomaxprocs"`
+	Goroutines    int       `json:"goroutines"`
+	CgoCalls      int64     `json:"cgo_calls"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Memory        MemoryDTO `json:"memory"`
+	GC            GCDTO     `json:"gc"`
+}
+
+// MemoryDTO runtime.MemStats 中常用的内存指标，单位字节
+type MemoryDTO struct {
+	Alloc        uint64 `json:"alloc"`
+	TotalAlloc   uint64 `json:"total_alloc"`
+	Sys          uint64 `json:"sys"`
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapSys      uint64 `json:"heap_sys"`
+	HeapIdle     uint64 `json:"heap_idle"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapReleased uint64 `json:"heap_released"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Mallocs      uint64 `json:"mallocs"`
+	Frees        uint64 `json:"frees"`
+}
+
+// GCDTO 垃圾回收统计
+type GCDTO struct {
+	NumGC        uint32     `json:"num_gc"`
+	NumForcedGC  uint32     `json:"num_forced_gc"`
+	LastGC       *time.Time `json:"last_gc"` // 尚未发生 GC 时为 null
+	LastPauseMs  float64    `json:"last_pause_ms"`
+	PauseTotalMs float64    `json:"pause_total_ms"`
+	NextGC       uint64     `json:"next_gc"`      // 下一次 GC 的堆目标，单位字节
+	CPUFraction  float64    `json:"cpu_fraction"` // 启动以来 GC 占用的 CPU 比例
+	MemoryLimit  int64      `json:"memory_limit"` // GOMEMLIMIT，未设置时为 math.MaxInt64
+}
+
+// RateLimitsDTO 各限流器的统计，key 为 "<rate>-<burst>"
+type RateLimitsDTO struct {
+	Limiters map[string]middleware.Stats `json:"limiters"`
+}
+
+// PoolsDTO 数据库与 Redis 连接池统计；未初始化的客户端不会被连接
+type PoolsDTO struct {
+	MySQL []MySQLPoolDTO `json:"mysql"`
+	Redis *RedisPoolDTO  `json:"redis"` // 未初始化时为 null
+}
+
+// MySQLPoolDTO 单个 MySQL 连接池（主库或副本）的 database/sql 统计
+type MySQLPoolDTO struct {
+	Name              string  `json:"name"`
+	MaxOpen           int     `json:"max_open"`
+	Open              int     `json:"open"`
+	InUse             int     `json:"in_use"`
+	Idle              int     `json:"idle"`
+	WaitCount         int64   `json:"wait_count"`
+	WaitDurationMs    float64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64   `json:"max_lifetime_closed"`
+}
+
+// RedisPoolDTO Redis 连接池统计
+type RedisPoolDTO struct {
+	Hits           uint32  `json:"hits"`
+	Misses         uint32  `json:"misses"`
+	Timeouts       uint32  `json:"timeouts"`
+	WaitCount      uint32  `json:"wait_count"`
+	WaitDurationMs float64 `json:"wait_duration_ms"`
+	TotalConns     uint32  `json:"total_conns"`
+	IdleConns      uint32  `json:"idle_conns"`
+	StaleConns     uint32  `json:"stale_conns"`
+}
@@ -0,0 +1,28 @@
+package diagnostics
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/app/debug"
+	"http-services/api/app/v1/admin/settings"
+	"http-services/api/middleware"
+	"http-services/application"
+)
+
+// RegisterRoutes 注册运行时诊断路由，使用独立的 admin.diagnostics_token 认证，未配置时全部关闭。
+// group 不能是已经挂了 AdminTokenVerify 的 admin 分组，否则两个 token 都要求携带
+// 路径：/api/v1/admin/diagnostics/{runtime,build,config,rate-limits,pools}、/api/v1/admin/diagnostics/pprof/*
+func RegisterRoutes(group *gin.RouterGroup, a *application.App) {
+	if group == nil {
+		return
+	}
+	group.Use(middleware.NewDiagnosticsTokenVerify(a.Config))
+
+	group.GET("/runtime", Runtime)
+	group.GET("/build", Build)
+	// 与管理端口的 /api/v1/admin/config 相同，敏感项已脱敏
+	group.GET("/config", settings.List)
+	group.GET("/rate-limits", RateLimits)
+	group.GET("/pools", Pools)
+	debug.RegisterPprofRoutes(group.Group("/pprof"))
+}
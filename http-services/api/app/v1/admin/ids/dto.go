@@ -0,0 +1,21 @@
+package ids
+
+import (
+	"time"
+
+	"http-services/utils/id"
+)
+
+// StatusDTO 当前 ID 生成器的状态
+type StatusDTO struct {
+	MachineID     uint16    `json:"machine_id"`
+	Epoch         time.Time `json:"epoch"`
+	FallbackCount uint64    `json:"fallback_count"` // Sonyflake 不可用时降级生成的 ID 数量
+}
+
+// PartsDTO Sonyflake ID 拆解结果
+type PartsDTO struct {
+	id.Parts
+	// ID 以字符串返回，避免 JavaScript 客户端丢失精度
+	ID string `json:"id"`
+}
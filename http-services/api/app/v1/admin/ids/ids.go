@@ -0,0 +1,40 @@
+package ids
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/application"
+	"http-services/utils/id"
+)
+
+// Handler 是 ID 生成器排查接口，配置由 App 提供
+type Handler struct {
+	app *application.App
+}
+
+// NewHandler 创建 ID 生成器排查接口
+func NewHandler(a *application.App) *Handler {
+	return &Handler{app: a}
+}
+
+// Status 返回本实例的 machine id、epoch 与降级计数
+func (h *Handler) Status(c *gin.Context) {
+	response.ReturnOk(c, StatusDTO{
+		MachineID:     id.MachineID(),
+		Epoch:         h.app.Config().ID.Epoch,
+		FallbackCount: id.FallbackCount(),
+	})
+}
+
+// Decode 拆解 IssueID 生成的 ID，返回生成时间、machine id 与序号
+func (h *Handler) Decode(c *gin.Context) {
+	parts, err := id.DecodeString(c.Param("id"))
+	if err != nil {
+		response.ReturnError(c, response.INVALID_ARGUMENT, "not a sonyflake id.")
+		return
+	}
+	response.ReturnOk(c, PartsDTO{Parts: parts, ID: strconv.FormatUint(parts.ID, 10)})
+}
@@ -0,0 +1,66 @@
+package ids
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/application/apptest"
+	"http-services/utils/id"
+)
+
+func doRequest(t *testing.T, target string, detail any) int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterAdminRoutes(r.Group("/admin"), apptest.New(t).App)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+	resp := struct {
+		Code   int `json:"code"`
+		Detail any `json:"detail"`
+	}{Detail: detail}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return resp.Code
+}
+
+func TestDecode(t *testing.T) {
+	value, err := id.NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+
+	var parts struct {
+		ID        string    `json:"id"`
+		Time      time.Time `json:"time"`
+		MachineID uint16    `json:"machine_id"`
+	}
+	if code := doRequest(t, "/admin/ids/"+strconv.FormatUint(value, 10), &parts); code != response.OK.Code {
+		t.Fatalf("Decode() code = %d", code)
+	}
+	if parts.ID != strconv.FormatUint(value, 10) || parts.MachineID != id.MachineID() || time.Since(parts.Time) > time.Minute {
+		t.Fatalf("Decode() detail = %+v", parts)
+	}
+
+	if code := doRequest(t, "/admin/ids/not-an-id", nil); code != response.INVALID_ARGUMENT.Code {
+		t.Fatalf("Decode(invalid) code = %d", code)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	var status StatusDTO
+	if code := doRequest(t, "/admin/ids", &status); code != response.OK.Code {
+		t.Fatalf("Status() code = %d", code)
+	}
+	if status.MachineID != id.MachineID() {
+		t.Fatalf("Status() = %+v", status)
+	}
+}
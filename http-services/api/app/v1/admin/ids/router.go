@@ -0,0 +1,18 @@
+package ids
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+)
+
+// RegisterAdminRoutes 注册 ID 生成器排查路由
+// 路径：/api/v1/admin/ids、/api/v1/admin/ids/:id
+func RegisterAdminRoutes(admin *gin.RouterGroup, a *application.App) {
+	if admin == nil {
+		return
+	}
+	h := NewHandler(a)
+	admin.GET("/ids", h.Status)
+	admin.GET("/ids/:id", h.Decode)
+}
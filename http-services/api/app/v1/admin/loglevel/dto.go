@@ -0,0 +1,25 @@
+package loglevel
+
+import "http-services/utils/log"
+
+// SetRequest 调整日志级别的请求参数
+type SetRequest struct {
+	Module   string `json:"module" binding:"required"` // app / gin / gorm / redis / cron
+	Level    string `json:"level" binding:"required"`  // debug / info / warn / error
+	Duration string `json:"duration"`                  // 自动恢复时长，如 "15m"；为空使用 log.level_revert_after，"0" 表示不自动恢复
+}
+
+// ResetRequest 恢复配置级别的请求参数
+type ResetRequest struct {
+	Module string `form:"module"` // 为空时恢复全部模块
+}
+
+// LevelsDTO 各模块当前的日志级别
+type LevelsDTO struct {
+	Modules []log.ModuleLevel `json:"modules"`
+}
+
+// SinksDTO 异步日志输出的丢弃与失败统计
+type SinksDTO struct {
+	Sinks []log.SinkStat `json:"sinks"`
+}
@@ -0,0 +1,106 @@
+package loglevel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"http-services/api/middleware"
+	"http-services/api/response"
+	"http-services/application"
+	"http-services/utils/log"
+)
+
+// Handler 是日志级别管理接口，默认的临时覆盖时长从 App 的配置读取
+type Handler struct {
+	app *application.App
+}
+
+// NewHandler 创建日志级别管理接口
+func NewHandler(a *application.App) *Handler {
+	return &Handler{app: a}
+}
+
+// List 返回各模块当前级别、配置级别以及临时覆盖的恢复时间
+func (h *Handler) List(c *gin.Context) {
+	response.ReturnOk(c, LevelsDTO{Modules: log.Levels()})
+}
+
+// Set 临时调整某个模块的日志级别，到期后自动恢复为配置级别
+func (h *Handler) Set(c *gin.Context) {
+	var req SetRequest
+	if !middleware.CheckJSONParam(&req, c) {
+		return
+	}
+	level, err := log.ParseLevel(req.Level)
+	if err != nil {
+		response.ReturnError(c, response.INVALID_ARGUMENT, err.Error())
+		return
+	}
+	revertAfter := h.app.Config().Log.LevelRevertAfter
+	if req.Duration != "" {
+		revertAfter, err = time.ParseDuration(req.Duration)
+		if err != nil || revertAfter < 0 {
+			response.ReturnError(c, response.INVALID_ARGUMENT, "invalid duration.")
+			return
+		}
+	}
+	middleware.AuditResource(c, req.Module)
+	before := currentLevels()
+	if err := log.SetLevel(req.Module, level, revertAfter); err != nil {
+		returnLevelError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, currentLevels())
+
+	log.FromContext(c).Info("log level changed",
+		zap.String("module", req.Module),
+		zap.Stringer("level", level),
+		zap.Duration("revert_after", revertAfter),
+	)
+	response.ReturnOk(c, LevelsDTO{Modules: log.Levels()})
+}
+
+// Reset 撤销临时覆盖，恢复配置级别；未指定 module 时恢复全部模块
+func (h *Handler) Reset(c *gin.Context) {
+	var req ResetRequest
+	if !middleware.CheckQueryParam(&req, c) {
+		return
+	}
+	middleware.AuditResource(c, req.Module)
+	before := currentLevels()
+	if req.Module == "" {
+		log.ResetLevels()
+	} else if err := log.ResetLevel(req.Module); err != nil {
+		returnLevelError(c, err)
+		return
+	}
+	middleware.AuditChange(c, before, currentLevels())
+
+	log.FromContext(c).Info("log level reset", zap.String("module", req.Module))
+	response.ReturnOk(c, LevelsDTO{Modules: log.Levels()})
+}
+
+// Sinks 返回异步日志输出的缓冲、丢弃与失败计数，用于判断日志是否因背压丢失
+func (h *Handler) Sinks(c *gin.Context) {
+	response.ReturnOk(c, SinksDTO{Sinks: log.SinkStats()})
+}
+
+// currentLevels 返回 module -> 当前级别，审计记录据此只保留发生变化的模块
+func currentLevels() map[string]string {
+	levels := make(map[string]string)
+	for _, item := range log.Levels() {
+		levels[item.Module] = item.Level
+	}
+	return levels
+}
+
+func returnLevelError(c *gin.Context, err error) {
+	if errors.Is(err, log.ErrUnknownModule) {
+		response.ReturnError(c, response.NOT_FOUND, "unknown log module.")
+		return
+	}
+	response.ReturnError(c, response.INTERNAL, "服务内部错误")
+}
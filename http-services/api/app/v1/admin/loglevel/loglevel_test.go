@@ -0,0 +1,174 @@
+package loglevel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/application/apptest"
+	"http-services/config"
+	"http-services/utils/audit"
+	"http-services/utils/log"
+)
+
+type levelsResponse struct {
+	Code   int       `json:"code"`
+	Detail LevelsDTO `json:"detail"`
+}
+
+func setupTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := apptest.New(t, func(c *config.Config) { c.Log.LevelRevertAfter = time.Hour })
+	t.Cleanup(log.ResetLevels)
+
+	r := gin.New()
+	RegisterAdminRoutes(r.Group("/admin"), h.App)
+	return r
+}
+
+func doRequest(t *testing.T, router *gin.Engine, method, target, body string) levelsResponse {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s status = %d", method, target, w.Code)
+	}
+	var resp levelsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return resp
+}
+
+func findModule(resp levelsResponse, module string) log.ModuleLevel {
+	for _, item := range resp.Detail.Modules {
+		if item.Module == module {
+			return item
+		}
+	}
+	return log.ModuleLevel{}
+}
+
+func TestSet_UsesDefaultRevertAndReset(t *testing.T) {
+	router := setupTestRouter(t)
+
+	resp := doRequest(t, router, "PUT", "/admin/log/levels", `{"module":"gorm","level":"debug"}`)
+	if resp.Code != response.OK.Code {
+		t.Fatalf("Set() code = %d, want %d", resp.Code, response.OK.Code)
+	}
+	gorm := findModule(resp, log.ModuleGorm)
+	if gorm.Level != "debug" || !gorm.Override || gorm.RevertAt == nil {
+		t.Fatalf("gorm level = %+v, want debug override with revert_at", gorm)
+	}
+	if until := time.Until(*gorm.RevertAt); until < 59*time.Minute {
+		t.Fatalf("revert_at in %v, want log.level_revert_after (1h)", until)
+	}
+
+	resp = doRequest(t, router, "DELETE", "/admin/log/levels?module=gorm", "")
+	if gorm := findModule(resp, log.ModuleGorm); gorm.Override || gorm.Level != gorm.Configured {
+		t.Fatalf("gorm level after reset = %+v", gorm)
+	}
+}
+
+func TestSet_PermanentOverride(t *testing.T) {
+	router := setupTestRouter(t)
+
+	resp := doRequest(t, router, "PUT", "/admin/log/levels", `{"module":"redis","level":"warn","duration":"0"}`)
+	if redis := findModule(resp, log.ModuleRedis); redis.Level != "warn" || redis.RevertAt != nil {
+		t.Fatalf("redis level = %+v, want warn without revert_at", redis)
+	}
+
+	resp = doRequest(t, router, "GET", "/admin/log/levels", "")
+	if len(resp.Detail.Modules) != len(log.Modules()) {
+		t.Fatalf("List() returned %d modules, want %d", len(resp.Detail.Modules), len(log.Modules()))
+	}
+}
+
+func TestSet_RejectsInvalidInput(t *testing.T) {
+	router := setupTestRouter(t)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"missing level", `{"module":"gorm"}`, response.INVALID_ARGUMENT.Code},
+		{"unknown level", `{"module":"gorm","level":"fatal"}`, response.INVALID_ARGUMENT.Code},
+		{"bad duration", `{"module":"gorm","level":"debug","duration":"soon"}`, response.INVALID_ARGUMENT.Code},
+		{"unknown module", `{"module":"kafka","level":"debug"}`, response.NOT_FOUND.Code},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := doRequest(t, router, "PUT", "/admin/log/levels", tt.body); resp.Code != tt.wantCode {
+				t.Fatalf("Set() code = %d, want %d", resp.Code, tt.wantCode)
+			}
+		})
+	}
+	if resp := doRequest(t, router, "DELETE", "/admin/log/levels?module=kafka", ""); resp.Code != response.NOT_FOUND.Code {
+		t.Fatalf("Reset() code = %d, want %d", resp.Code, response.NOT_FOUND.Code)
+	}
+}
+
+func TestSinks_ReturnsStats(t *testing.T) {
+	router := setupTestRouter(t)
+
+	req := httptest.NewRequest("GET", "/admin/log/sinks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Code   int      `json:"code"`
+		Detail SinksDTO `json:"detail"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Code != response.OK.Code {
+		t.Fatalf("Sinks() code = %d, want %d", resp.Code, response.OK.Code)
+	}
+}
+
+type memoryAuditSink struct {
+	entries []audit.Entry
+}
+
+func (s *memoryAuditSink) Name() string { return "memory" }
+
+func (s *memoryAuditSink) Write(_ context.Context, entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryAuditSink) Close() error { return nil }
+
+func TestSet_RecordsAuditChange(t *testing.T) {
+	router := setupTestRouter(t)
+	sink := &memoryAuditSink{}
+	old := audit.SetDefault(audit.New(sink))
+	t.Cleanup(func() { audit.SetDefault(old) })
+	log.ResetLevels()
+
+	doRequest(t, router, "PUT", "/admin/log/levels", `{"module":"cron","level":"error"}`)
+	if len(sink.entries) != 1 {
+		t.Fatalf("recorded %d audit entries, want 1", len(sink.entries))
+	}
+	entry := sink.entries[0]
+	if entry.Action != "log.level.set" || entry.ResourceID != log.ModuleCron || entry.Outcome != audit.OutcomeSuccess {
+		t.Fatalf("audit entry = %+v", entry)
+	}
+	if len(entry.After) != 1 || entry.After[log.ModuleCron] != "error" {
+		t.Fatalf("audit change = %v -> %v, want only cron", entry.Before, entry.After)
+	}
+}
@@ -0,0 +1,21 @@
+package loglevel
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/middleware"
+	"http-services/application"
+)
+
+// RegisterAdminRoutes 注册日志级别管理路由
+// 路径：/api/v1/admin/log/levels、/api/v1/admin/log/sinks
+func RegisterAdminRoutes(admin *gin.RouterGroup, a *application.App) {
+	if admin == nil {
+		return
+	}
+	h := NewHandler(a)
+	admin.GET("/log/levels", h.List)
+	admin.PUT("/log/levels", middleware.NewAudit(a.Config, "log.level.set", "log_level"), h.Set)
+	admin.DELETE("/log/levels", middleware.NewAudit(a.Config, "log.level.reset", "log_level"), h.Reset)
+	admin.GET("/log/sinks", h.Sinks)
+}
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/app/v1/admin/ids"
+	"http-services/api/app/v1/admin/loglevel"
+	"http-services/api/middleware"
+	"http-services/application"
+)
+
+// RegisterRoutes 统一在 /api/v1/admin 下注册运维管理路由，全部要求 admin token
+// Audit 挂在 token 校验之前，token 错误被拒绝的请求同样留下 denied 记录。
+func RegisterRoutes(admin *gin.RouterGroup, a *application.App) {
+	if admin == nil {
+		return
+	}
+	admin.Use(
+		middleware.NewAudit(a.Config, middleware.AdminAccessAction, middleware.AdminResource),
+		middleware.NewAdminTokenVerify(a.Config),
+	)
+
+	// 运行期日志级别
+	loglevel.RegisterAdminRoutes(admin, a)
+	// ID 生成器状态与 ID 拆解
+	ids.RegisterAdminRoutes(admin, a)
+	// 模块提供的运维路由，如审计记录查询
+	a.RegisterModuleAdminRoutes(admin)
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/middleware"
+	"http-services/application/apptest"
+	"http-services/config"
+	"http-services/utils/audit"
+	"http-services/utils/log"
+)
+
+type memoryAuditSink struct {
+	entries []audit.Entry
+}
+
+func (s *memoryAuditSink) Name() string { return "memory" }
+
+func (s *memoryAuditSink) Write(_ context.Context, entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryAuditSink) Close() error { return nil }
+
+func TestRegisterRoutesAuditsDeniedAndAllowedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &memoryAuditSink{}
+	old := audit.SetDefault(audit.New(sink))
+	t.Cleanup(func() { audit.SetDefault(old) })
+	t.Cleanup(log.ResetLevels)
+
+	h := apptest.New(t, func(c *config.Config) { c.Admin.Token = "admin-secret" })
+	router := gin.New()
+	RegisterRoutes(router.Group("/api/v1/admin"), h.App)
+
+	for _, token := range []string{"Bearer wrong", "Bearer admin-secret"} {
+		req := httptest.NewRequest("PUT", "/api/v1/admin/log/levels", strings.NewReader(`{"module":"cron","level":"debug"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.AuthorizationHeader, token)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("recorded %d audit entries, want 2: %+v", len(sink.entries), sink.entries)
+	}
+	denied := sink.entries[0]
+	if denied.Action != middleware.AdminAccessAction || denied.Actor != "anonymous" || denied.Outcome != audit.OutcomeDenied {
+		t.Fatalf("denied entry = %+v", denied)
+	}
+	allowed := sink.entries[1]
+	if allowed.Action != "log.level.set" || allowed.Actor != middleware.AdminActor || allowed.Outcome != audit.OutcomeSuccess {
+		t.Fatalf("allowed entry = %+v", allowed)
+	}
+}
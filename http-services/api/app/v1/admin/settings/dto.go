@@ -0,0 +1,13 @@
+package settings
+
+// SettingDTO 单个配置项的取值与来源
+type SettingDTO struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"` // 文件路径、"env <变量名>" 或 "default"
+}
+
+// SettingsDTO 当前生效的全部配置项，按 key 排序
+type SettingsDTO struct {
+	Settings []SettingDTO `json:"settings"`
+}
@@ -0,0 +1,12 @@
+package settings
+
+import "github.com/gin-gonic/gin"
+
+// RegisterAdminRoutes 注册生效配置查看路由，只挂载在独立管理端口上
+// 路径：/api/v1/admin/config
+func RegisterAdminRoutes(admin *gin.RouterGroup) {
+	if admin == nil {
+		return
+	}
+	admin.GET("/config", List)
+}
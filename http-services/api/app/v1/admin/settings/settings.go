@@ -0,0 +1,18 @@
+package settings
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/config"
+)
+
+// List 返回合并后的全部配置项及其来源，与 config print 子命令一致，敏感项已脱敏
+func List(c *gin.Context) {
+	settings := config.Settings()
+	dto := SettingsDTO{Settings: make([]SettingDTO, 0, len(settings))}
+	for _, setting := range settings {
+		dto.Settings = append(dto.Settings, SettingDTO{Key: setting.Key, Value: setting.Value, Source: setting.Source})
+	}
+	response.ReturnOk(c, dto)
+}
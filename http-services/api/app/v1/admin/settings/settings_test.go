@@ -0,0 +1,47 @@
+package settings
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/config"
+)
+
+func TestListRedactsSecrets(t *testing.T) {
+	t.Setenv("HTTP_SERVICES_JWT_KEY", "settings-test-secret-0123456789abcdef")
+	t.Setenv("HTTP_SERVICES_SERVER_PORT", "9123")
+	if err := config.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterAdminRoutes(r.Group("/admin"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/config", nil))
+
+	var resp struct {
+		Code   int         `json:"code"`
+		Detail SettingsDTO `json:"detail"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Code != response.OK.Code {
+		t.Fatalf("List() code = %d", resp.Code)
+	}
+	settings := make(map[string]SettingDTO)
+	for _, setting := range resp.Detail.Settings {
+		settings[setting.Key] = setting
+	}
+	if got := settings["jwt.key"]; got.Value != "******" || got.Source != "env HTTP_SERVICES_JWT_KEY" {
+		t.Errorf("jwt.key = %+v, want redacted value from env", got)
+	}
+	if got := settings["server.port"]; got.Value != "9123" || got.Source != "env HTTP_SERVICES_SERVER_PORT" {
+		t.Errorf("server.port = %+v, want 9123 from env", got)
+	}
+}
@@ -4,18 +4,29 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"http-services/api/response"
+	"http-services/application"
 	domain "http-services/domain/health"
 	"http-services/utils/log"
 )
 
+// Handler 健康检查接口，检查项来自 App 中全部模块的 HealthChecks
+type Handler struct {
+	app *application.App
+}
+
+// NewHandler 创建健康检查接口
+func NewHandler(a *application.App) *Handler {
+	return &Handler{app: a}
+}
+
 // Status 合并后的健康检查接口
-// 返回服务健康与就绪状态，以及运行时长
-func Status(c *gin.Context) {
+// 返回服务健康与就绪状态、运行时长以及各模块检查项的结果
+func (h *Handler) Status(c *gin.Context) {
 	// 默认使用带 trace_id 等上下文信息的 logger
 	l := log.FromContext(c)
 	l.Debug("健康检查开始")
 
-	status, err := domain.GetStatus()
+	status, err := domain.CheckStatus(c.Request.Context(), h.app.HealthChecks())
 	if err != nil {
 		// 仅在出错时记录请求参数，便于排查问题
 		log.WithRequest(c).Error("健康检查失败", zap.Error(err))
@@ -30,5 +41,16 @@ func Status(c *gin.Context) {
 		Uptime:    status.Uptime.String(),
 		Timestamp: status.Timestamp,
 	}
+	for _, check := range status.Checks {
+		if !check.Healthy {
+			l.Warn("健康检查项失败", zap.String("check", check.Name), zap.String("error", check.Error))
+		}
+		dto.Checks = append(dto.Checks, CheckDTO{
+			Name:    check.Name,
+			Healthy: check.Healthy,
+			Error:   check.Error,
+			Elapsed: check.Elapsed.String(),
+		})
+	}
 	response.ReturnOk(c, dto)
 }
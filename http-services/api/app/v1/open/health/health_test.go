@@ -1,12 +1,18 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+	"http-services/application/apptest"
+	domain "http-services/domain/health"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -25,7 +31,7 @@ type statusResponse struct {
 // 合并后健康检查接口的测试
 func TestStatus(t *testing.T) {
 	router := setupTestRouter()
-	router.GET("/health", Status)
+	router.GET("/health", NewHandler(apptest.New(t).App).Status)
 
 	req, _ := http.NewRequest("GET", "/health", nil)
 	w := httptest.NewRecorder()
@@ -64,3 +70,42 @@ func TestStatus(t *testing.T) {
 		t.Errorf("Status() missing timestamp field")
 	}
 }
+
+// checkModule 是只提供检查项的测试模块
+type checkModule struct {
+	application.BaseModule
+	checks []domain.Check
+}
+
+func (m checkModule) Name() string                 { return "checks" }
+func (m checkModule) HealthChecks() []domain.Check { return m.checks }
+
+// 模块检查项失败时整体状态降级，并返回每一项的结果
+func TestStatusReportsModuleChecks(t *testing.T) {
+	module := checkModule{checks: []domain.Check{
+		{Name: "mysql", Check: func(context.Context) error { return nil }},
+		{Name: "redis", Check: func(context.Context) error { return errors.New("connection refused") }},
+	}}
+	a, err := application.New(application.Options{Modules: []application.ModuleFactory{
+		NewModule,
+		func(*application.App) application.Module { return module },
+	}})
+	if err != nil {
+		t.Fatalf("application.New() error = %v", err)
+	}
+	router := setupTestRouter()
+	a.RegisterModuleRoutes(router.Group("/open"), router.Group("/private"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/open/health", nil))
+	var resp statusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Detail.Status != "degraded" || len(resp.Detail.Checks) != 2 {
+		t.Fatalf("detail = %+v, want degraded with 2 checks", resp.Detail)
+	}
+	if got := resp.Detail.Checks[1]; got.Name != "redis" || got.Healthy || got.Error != "connection refused" {
+		t.Errorf("checks[1] = %+v, want failed redis check", got)
+	}
+}
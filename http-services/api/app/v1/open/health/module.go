@@ -0,0 +1,30 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+)
+
+func init() {
+	application.RegisterModule(NewModule)
+}
+
+// Module 把 health 模块接入 App：注册 /api/v1/open/health，本身不提供检查项
+type Module struct {
+	application.BaseModule
+	app *application.App
+}
+
+// NewModule 创建 health 模块
+func NewModule(a *application.App) application.Module {
+	return &Module{app: a}
+}
+
+// Name 模块名称
+func (m *Module) Name() string { return "health" }
+
+// Routes 注册健康检查的开放路由
+func (m *Module) Routes(open, _ *gin.RouterGroup) {
+	RegisterOpenRoutes(open, m.app)
+}
@@ -1,14 +1,19 @@
 package health
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	"http-services/application"
+)
 
 // RegisterOpenRoutes 注册 health 模块的开放路由
 // 路径：/api/v1/open/health
-func RegisterOpenRoutes(open *gin.RouterGroup) {
+func RegisterOpenRoutes(open *gin.RouterGroup, a *application.App) {
 	if open == nil {
 		return
 	}
-	open.GET("/health", Status)
+	h := NewHandler(a)
+	open.GET("/health", h.Status)
 }
 
 // RegisterPrivateRoutes 预留（health 模块通常无私有接口）
@@ -2,14 +2,14 @@ package open
 
 import (
 	"github.com/gin-gonic/gin"
-	health "http-services/api/app/v1/open/health"
+	"http-services/application"
 )
 
-// RegisterRoutes 统一在 /api/v1/open 下注册各模块公开路由
-func RegisterRoutes(open *gin.RouterGroup) {
+// RegisterRoutes 统一在 /api/v1/open 下注册不属于任何模块的公开路由
+// 模块的公开路由（如 /api/v1/open/health）由 application.Module.Routes 注册，无需在此添加
+func RegisterRoutes(open *gin.RouterGroup, _ *application.App) {
 	if open == nil {
 		return
 	}
-	// 健康检查
-	health.RegisterOpenRoutes(open)
+	// 预留：按需在此注册跨模块的公开接口
 }
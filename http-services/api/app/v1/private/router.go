@@ -1,11 +1,16 @@
 package private
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
 
-// RegisterRoutes 统一在 /api/v1/private 下注册各模块私有路由
-func RegisterRoutes(private *gin.RouterGroup) {
+	"http-services/application"
+)
+
+// RegisterRoutes 统一在 /api/v1/private 下注册不属于任何模块的私有路由
+// 模块的私有路由由 application.Module.Routes 注册；需要认证时使用 middleware.NewTokenVerify(a.JWT())
+func RegisterRoutes(private *gin.RouterGroup, _ *application.App) {
 	if private == nil {
 		return
 	}
-	// 预留：按需在此注册各模块私有接口
+	// 预留：按需在此注册跨模块的私有接口
 }
@@ -2,21 +2,35 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"http-services/api/app/v1/admin"
+	"http-services/api/app/v1/admin/diagnostics"
 	"http-services/api/app/v1/open"
 	"http-services/api/app/v1/private"
+	"http-services/application"
 )
 
-// RegisterRoutes 负责在 /api/v1 下挂载各子分组（open/private等）
-func RegisterRoutes(v1 *gin.RouterGroup) {
+// RegisterRoutes 负责在 /api/v1 下挂载各子分组（open/private/admin 等）
+func RegisterRoutes(v1 *gin.RouterGroup, a *application.App) {
 	if v1 == nil {
 		return
 	}
 
 	// /api/v1/open
 	openGroup := v1.Group("/open")
-	open.RegisterRoutes(openGroup)
+	open.RegisterRoutes(openGroup, a)
 
 	// /api/v1/private
 	privateGroup := v1.Group("/private")
-	private.RegisterRoutes(privateGroup)
+	private.RegisterRoutes(privateGroup, a)
+
+	// 各模块按依赖顺序在 open / private 分组下注册自己的路由
+	a.RegisterModuleRoutes(openGroup, privateGroup)
+
+	// /api/v1/admin
+	adminGroup := v1.Group("/admin")
+	admin.RegisterRoutes(adminGroup, a)
+
+	// /api/v1/admin/diagnostics：从 v1 单独分组，不继承 admin 分组的 AdminTokenVerify，
+	// 只使用 admin.diagnostics_token 认证
+	diagnostics.RegisterRoutes(v1.Group("/admin/diagnostics"), a)
 }
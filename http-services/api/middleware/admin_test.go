@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"http-services/api/response"
+	"http-services/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminTokenVerify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		wantCode   int
+	}{
+		{"disabled without configured token", "", "Bearer anything", response.PERMISSION_DENIED.Code},
+		{"missing header", "secret", "", response.UNAUTHENTICATED.Code},
+		{"wrong token", "secret", "Bearer wrong", response.UNAUTHENTICATED.Code},
+		{"bearer token", "secret", "Bearer secret", response.OK.Code},
+		{"raw token without scheme", "secret", "secret", response.UNAUTHENTICATED.Code},
+		{"lowercase scheme", "secret", "bearer secret", response.OK.Code},
+		{"scheme without token", "secret", "Bearer ", response.UNAUTHENTICATED.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Admin: config.AdminConfig{Token: tt.configured}}
+			router := gin.New()
+			router.Use(NewAdminTokenVerify(func() *config.Config { return cfg }))
+			router.GET("/admin", func(c *gin.Context) { response.ReturnOk(c, nil) })
+
+			req := httptest.NewRequest("GET", "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set(AuthorizationHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp struct {
+				Code int `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("response code = %d, want %d", resp.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsTokenIsSeparateFromAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		adminToken  string
+		diagnostics string
+		header      string
+		wantCode    int
+	}{
+		{"disabled even with admin token", "admin-secret", "", "Bearer admin-secret", response.PERMISSION_DENIED.Code},
+		{"admin token rejected", "admin-secret", "diag-secret", "Bearer admin-secret", response.UNAUTHENTICATED.Code},
+		{"diagnostics token", "admin-secret", "diag-secret", "Bearer diag-secret", response.OK.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Admin: config.AdminConfig{Token: tt.adminToken, DiagnosticsToken: tt.diagnostics}}
+			router := gin.New()
+			router.Use(NewDiagnosticsTokenVerify(func() *config.Config { return cfg }))
+			router.GET("/diagnostics", func(c *gin.Context) { response.ReturnOk(c, nil) })
+
+			req := httptest.NewRequest("GET", "/diagnostics", nil)
+			req.Header.Set(AuthorizationHeader, tt.header)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp struct {
+				Code int `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("response code = %d, want %d", resp.Code, tt.wantCode)
+			}
+		})
+	}
+}
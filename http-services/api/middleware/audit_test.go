@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"http-services/api/response"
+	"http-services/config"
+	"http-services/utils/audit"
+	"http-services/utils/contextkey"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryAuditSink struct {
+	entries []audit.Entry
+}
+
+func (s *memoryAuditSink) Name() string { return "memory" }
+
+func (s *memoryAuditSink) Write(_ context.Context, entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryAuditSink) Close() error { return nil }
+
+func useAuditSink(t *testing.T) *memoryAuditSink {
+	t.Helper()
+	sink := &memoryAuditSink{}
+	old := audit.SetDefault(audit.New(sink))
+	t.Cleanup(func() { audit.SetDefault(old) })
+	return sink
+}
+
+// testConfig 返回 Audit 与 token 校验读取的配置
+func testConfig() *config.Config {
+	return &config.Config{
+		Admin: config.AdminConfig{Token: "secret"},
+		Audit: config.AuditConfig{ActorClaim: "user_id"},
+	}
+}
+
+func TestAudit_RecordsOutcomeActorAndChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := useAuditSink(t)
+
+	router := gin.New()
+	router.Use(TraceID())
+	router.PUT("/roles/:id", NewAudit(testConfig, "role.update", "role"), func(c *gin.Context) {
+		if c.GetHeader(AuthorizationHeader) == "" {
+			response.ReturnError(c, response.UNAUTHENTICATED, "without token.")
+			return
+		}
+		c.Set(contextkey.JWTData, map[string]interface{}{"user_id": float64(7)})
+		c.Next()
+	}, func(c *gin.Context) {
+		AuditChange(c, map[string]any{"name": "ops", "perms": []string{"read"}},
+			map[string]any{"name": "ops", "perms": []string{"read", "write"}})
+		response.ReturnOk(c, nil)
+	})
+
+	req := httptest.NewRequest("PUT", "/roles/42", nil)
+	req.Header.Set(AuthorizationHeader, "token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/roles/42", nil))
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(sink.entries))
+	}
+	ok := sink.entries[0]
+	if ok.Actor != "7" || ok.Action != "role.update" || ok.Resource != "role" || ok.ResourceID != "42" ||
+		ok.Outcome != audit.OutcomeSuccess || ok.TraceID == "" || ok.ClientIP == "" {
+		t.Fatalf("success entry = %+v", ok)
+	}
+	if _, kept := ok.After["name"]; kept || ok.After["perms"] == nil {
+		t.Fatalf("success change = %v -> %v, want only perms", ok.Before, ok.After)
+	}
+	denied := sink.entries[1]
+	if denied.Actor != anonymousActor || denied.Outcome != audit.OutcomeDenied || denied.Reason != "response code 401" {
+		t.Fatalf("denied entry = %+v", denied)
+	}
+}
+
+func TestAudit_AdminActorAndDisabledRecorder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := useAuditSink(t)
+	router := gin.New()
+	router.DELETE("/log/levels", NewAudit(testConfig, "log.level.reset", "log_level"), NewAdminTokenVerify(testConfig), func(c *gin.Context) {
+		response.ReturnError(c, response.NOT_FOUND, "unknown log module.")
+	})
+	req := httptest.NewRequest("DELETE", "/log/levels", nil)
+	req.Header.Set(AuthorizationHeader, "Bearer secret")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(sink.entries) != 1 || sink.entries[0].Actor != AdminActor || sink.entries[0].Outcome != audit.OutcomeFailure {
+		t.Fatalf("entries = %+v, want one failed admin entry", sink.entries)
+	}
+
+	audit.SetDefault(nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if len(sink.entries) != 1 {
+		t.Fatalf("disabled recorder still wrote entries: %+v", sink.entries)
+	}
+}
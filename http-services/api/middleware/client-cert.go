@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/api/response"
+	"http-services/utils/contextkey"
+)
+
+// ClientPrincipal 是通过 mTLS 校验的客户端证书身份，只有证书链已由 server.tls.client_ca_file 验证时才会设置
+type ClientPrincipal struct {
+	CommonName     string    `json:"common_name"`
+	Organization   []string  `json:"organization,omitempty"`
+	DNSNames       []string  `json:"dns_names,omitempty"`
+	URIs           []string  `json:"uris,omitempty"` // 例如 SPIFFE ID spiffe://example.org/service
+	EmailAddresses []string  `json:"email_addresses,omitempty"`
+	SerialNumber   string    `json:"serial_number"`
+	Issuer         string    `json:"issuer"`
+	NotAfter       time.Time `json:"not_after"`
+}
+
+// Name 返回用于日志与授权判断的身份名：依次取 URI SAN、CommonName、第一个 DNS SAN
+func (p *ClientPrincipal) Name() string {
+	switch {
+	case len(p.URIs) > 0:
+		return p.URIs[0]
+	case p.CommonName != "":
+		return p.CommonName
+	case len(p.DNSNames) > 0:
+		return p.DNSNames[0]
+	}
+	return p.SerialNumber
+}
+
+// ClientCertificate 把已校验的客户端证书身份写入 context；没有 TLS 或客户端未出示可信证书时不做任何事
+func ClientCertificate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := c.Request.TLS
+		if state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+			leaf := state.VerifiedChains[0][0]
+			principal := &ClientPrincipal{
+				CommonName:     leaf.Subject.CommonName,
+				Organization:   leaf.Subject.Organization,
+				DNSNames:       leaf.DNSNames,
+				EmailAddresses: leaf.EmailAddresses,
+				SerialNumber:   leaf.SerialNumber.Text(16),
+				Issuer:         leaf.Issuer.String(),
+				NotAfter:       leaf.NotAfter,
+			}
+			for _, uri := range leaf.URIs {
+				principal.URIs = append(principal.URIs, uri.String())
+			}
+			c.Set(contextkey.ClientPrincipal, principal)
+		}
+		c.Next()
+	}
+}
+
+// GetClientPrincipal 返回 ClientCertificate 设置的客户端证书身份
+func GetClientPrincipal(c *gin.Context) (*ClientPrincipal, bool) {
+	value, ok := c.Get(contextkey.ClientPrincipal)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*ClientPrincipal)
+	return principal, ok
+}
+
+// RequireClientCertificate 要求请求携带已校验的客户端证书，用于 server.tls.client_auth 为
+// verify_if_given 时只对部分路由强制 mTLS
+func RequireClientCertificate(c *gin.Context) {
+	if _, ok := GetClientPrincipal(c); !ok {
+		response.ReturnError(c, response.UNAUTHENTICATED, "client certificate required.")
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"http-services/api/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientCertificate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spiffe, _ := url.Parse("spiffe://example.org/billing")
+	leaf := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "billing", Organization: []string{"example"}},
+		Issuer:       pkix.Name{CommonName: "internal-ca"},
+		URIs:         []*url.URL{spiffe},
+		SerialNumber: big.NewInt(255),
+	}
+
+	tests := []struct {
+		name     string
+		state    *tls.ConnectionState
+		wantCode int
+		wantName string
+	}{
+		{"plain http", nil, response.UNAUTHENTICATED.Code, ""},
+		{"tls without client certificate", &tls.ConnectionState{}, response.UNAUTHENTICATED.Code, ""},
+		// 出示了证书但未通过 CA 校验（client_auth: request）时不算作身份
+		{"unverified certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, response.UNAUTHENTICATED.Code, ""},
+		{"verified certificate", &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}, response.OK.Code, "spiffe://example.org/billing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotName string
+			router := gin.New()
+			router.Use(ClientCertificate(), RequireClientCertificate)
+			router.GET("/internal", func(c *gin.Context) {
+				principal, _ := GetClientPrincipal(c)
+				gotName = principal.Name()
+				response.ReturnOk(c, nil)
+			})
+
+			req := httptest.NewRequest("GET", "/internal", nil)
+			req.TLS = tt.state
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var body struct {
+				Code int `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Code != tt.wantCode || gotName != tt.wantName {
+				t.Fatalf("code = %d, principal = %q, want %d, %q", body.Code, gotName, tt.wantCode, tt.wantName)
+			}
+		})
+	}
+}
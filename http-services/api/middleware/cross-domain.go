@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 
+	"http-services/config"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,6 +13,18 @@ const (
 	corsAllowedHeaders = "Authorization, Content-Type, X-Trace-ID"
 )
 
+// ConfiguredCORS 按 server.enable_cors 决定是否处理跨域，开关在每个请求时从 cfg 读取，热重载后立即生效
+func ConfiguredCORS(cfg func() *config.Config) gin.HandlerFunc {
+	cors := CorsDomainHandler()
+	return func(c *gin.Context) {
+		if !cfg().Server.EnableCORS {
+			c.Next()
+			return
+		}
+		cors(c)
+	}
+}
+
 // CorsDomainHandler 创建默认跨域处理中间件。
 // 脚手架默认纯放开跨域，业务项目需要收紧时可在项目内自行替换。
 func CorsDomainHandler() gin.HandlerFunc {
@@ -10,14 +10,25 @@ import (
 
 const AuthorizationHeader = "Authorization"
 
-// TokenVerify 获取 token 并验证其有效性
+// TokenVerify 获取 token 并使用 config.Current() 中的 jwt.key 验证其有效性
 func TokenVerify(c *gin.Context) {
+	verifyToken(c, authentication.JWTDecrypt)
+}
+
+// NewTokenVerify 返回使用指定 JWT 校验的 TokenVerify，路由通过 App.JWT() 传入
+func NewTokenVerify(jwt *authentication.JWT) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verifyToken(c, jwt.Decrypt)
+	}
+}
+
+func verifyToken(c *gin.Context, decrypt func(string) (map[string]interface{}, error)) {
 	token := c.Request.Header.Get(AuthorizationHeader)
 	if token == "" {
 		response.ReturnError(c, response.UNAUTHENTICATED, "without token.")
 		return
 	}
-	jwtData, err := authentication.JWTDecrypt(token)
+	jwtData, err := decrypt(token)
 	if err != nil {
 		response.ReturnError(c, response.UNAUTHENTICATED, "token verify failed.")
 		return
@@ -5,8 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"http-services/application/apptest"
 	"http-services/config"
-	"http-services/utils/authentication"
 	"http-services/utils/contextkey"
 
 	"github.com/gin-gonic/gin"
@@ -15,20 +15,19 @@ import (
 func TestTokenVerify_ValidToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	// 设置测试配置
-	config.JWTKey = "test-secret-key-for-testing-at-least-32-chars"
-	config.JWTExpiration = 1 * time.Hour
+	// 测试配置只属于这个 App，不修改 config.Current()
+	h := apptest.New(t, func(c *config.Config) { c.JWT.Expiration = 1 * time.Hour })
 
 	// 创建有效的 token
 	userData := map[string]interface{}{"user_id": "user123"}
-	token, err := authentication.JWTIssue(userData)
+	token, err := h.App.JWT().Issue(userData)
 	if err != nil {
 		t.Fatalf("Failed to issue token: %v", err)
 	}
 
 	// 创建测试路由
 	router := gin.New()
-	router.Use(TokenVerify)
+	router.Use(NewTokenVerify(h.App.JWT()))
 	router.GET("/test", func(c *gin.Context) {
 		jwtData, exists := c.Get(contextkey.JWTData)
 		if !exists {
@@ -103,20 +102,19 @@ func TestTokenVerify_DifferentKey(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// 使用一个密钥创建 token
-	config.JWTKey = "test-secret-key-for-testing-at-least-32-chars"
-	config.JWTExpiration = 1 * time.Hour
+	h := apptest.New(t, func(c *config.Config) { c.JWT.Expiration = 1 * time.Hour })
 	userData := map[string]interface{}{"user_id": "user123"}
-	token, err := authentication.JWTIssue(userData)
+	token, err := h.App.JWT().Issue(userData)
 	if err != nil {
 		t.Fatalf("Failed to issue token: %v", err)
 	}
 
-	// 更换密钥后验证（模拟密钥轮换场景）
-	config.JWTKey = "different-secret-key-for-testing-32chars"
+	// 更换密钥后验证（模拟密钥轮换场景），校验时读取 App 的最新配置
+	h.Config.JWT.Key = "different-secret-key-for-testing-32chars"
 
 	// 创建测试路由
 	router := gin.New()
-	router.Use(TokenVerify)
+	router.Use(NewTokenVerify(h.App.JWT()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "ok"})
 	})
@@ -135,9 +133,6 @@ func TestTokenVerify_DifferentKey(t *testing.T) {
 	if !contains(w.Body.String(), "401") || !contains(w.Body.String(), "UNAUTHENTICATED") {
 		t.Errorf("Expected UNAUTHENTICATED error in body, got: %s", w.Body.String())
 	}
-
-	// 恢复密钥
-	config.JWTKey = "test-secret-key-for-testing-at-least-32-chars"
 }
 
 // contains 检查字符串是否包含子串
@@ -3,12 +3,14 @@ package middleware
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 
 	"http-services/api/response"
+	"http-services/config"
 	"http-services/utils/contextkey"
 )
 
@@ -257,6 +259,45 @@ func IPRateLimit(r, b int) gin.HandlerFunc {
 	}
 }
 
+// globalLimiter 记录 GlobalRateLimit 最近一次使用的速率组合，配置不变时免去查缓存
+type globalLimiter struct {
+	rate    int
+	burst   int
+	limiter *RateLimiter
+}
+
+// GlobalRateLimit 全局 IP 限流中间件，开关与速率在每个请求时从 cfg().Server 读取，
+// 热重载修改 enable_rate_limit、global_rate_limit、global_rate_burst 后下一个请求即生效。
+// 速率变化后按新的 rate-burst 组合取限流器，各 IP 的令牌桶从满桶重新开始。
+func GlobalRateLimit(cfg func() *config.Config) gin.HandlerFunc {
+	var last atomic.Pointer[globalLimiter]
+
+	return func(c *gin.Context) {
+		serverConfig := cfg().Server
+		if !serverConfig.EnableRateLimit {
+			c.Next()
+			return
+		}
+
+		current := last.Load()
+		if current == nil || current.rate != serverConfig.GlobalRateLimit || current.burst != serverConfig.GlobalRateBurst {
+			current = &globalLimiter{
+				rate:    serverConfig.GlobalRateLimit,
+				burst:   serverConfig.GlobalRateBurst,
+				limiter: getLimiterFromCache(serverConfig.GlobalRateLimit, serverConfig.GlobalRateBurst),
+			}
+			last.Store(current)
+		}
+
+		if !current.limiter.allow(c.ClientIP()) {
+			response.ReturnError(c, response.RESOURCE_EXHAUSTED, "IP rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // TokenRateLimit Token 限流中间件（可指定速率）
 // 参数：
 //   - r: 每秒请求数（rate）
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"http-services/config"
 	"http-services/utils/contextkey"
 
 	"github.com/gin-gonic/gin"
@@ -145,6 +146,43 @@ func TestRateLimitWithOptions(t *testing.T) {
 	}
 }
 
+func TestGlobalRateLimitFollowsConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Server: config.ServerConfig{EnableRateLimit: true, GlobalRateLimit: 0, GlobalRateBurst: 1}}
+	t.Cleanup(CleanupAllLimiters)
+
+	router := gin.New()
+	router.Use(GlobalRateLimit(func() *config.Config { return cfg }))
+	router.GET("/test", func(c *gin.Context) { c.Status(204) })
+	limited := func() bool {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "192.168.1.3:12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return contains(w.Body.String(), "RESOURCE_EXHAUSTED")
+	}
+
+	if limited() || !limited() {
+		t.Fatal("burst=1: want first request allowed and second limited")
+	}
+
+	// 调大突发量后使用新的令牌桶，无需重建路由
+	cfg = &config.Config{Server: config.ServerConfig{EnableRateLimit: true, GlobalRateLimit: 0, GlobalRateBurst: 3}}
+	for i := range 3 {
+		if limited() {
+			t.Fatalf("burst=3: request %d limited", i+1)
+		}
+	}
+	if !limited() {
+		t.Fatal("burst=3: want 4th request limited")
+	}
+
+	cfg = &config.Config{Server: config.ServerConfig{EnableRateLimit: false}}
+	if limited() {
+		t.Fatal("rate limit disabled: request still limited")
+	}
+}
+
 func TestRateLimiterCleanup(t *testing.T) {
 	// 创建一个限流器
 	rl := NewRateLimiter(10, 20)
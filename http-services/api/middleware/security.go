@@ -6,22 +6,34 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"http-services/api/response"
+	"http-services/config"
 )
 
 // BodySizeLimit 请求体大小限制中间件
 // 默认限制为 10MB，防止过大的请求导致服务器资源耗尽
 func BodySizeLimit(maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 设置请求体最大大小
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+		limitBodySize(c, maxSize)
+	}
+}
 
-		c.Next()
+// ConfiguredBodySizeLimit 与 BodySizeLimit 相同，但上限在每个请求时从 cfg 读取 server.max_body_size，热重载后立即生效
+func ConfiguredBodySizeLimit(cfg func() *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limitBodySize(c, cfg().Server.MaxBodySize)
+	}
+}
+
+func limitBodySize(c *gin.Context, maxSize int64) {
+	// 设置请求体最大大小
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
+	c.Next()
 
-		// 检查是否因为请求体过大而出错
-		if c.Writer.Status() == http.StatusRequestEntityTooLarge {
-			response.ReturnError(c, response.INVALID_ARGUMENT, "request body too large")
-			c.Abort()
-		}
+	// 检查是否因为请求体过大而出错
+	if c.Writer.Status() == http.StatusRequestEntityTooLarge {
+		response.ReturnError(c, response.INVALID_ARGUMENT, "request body too large")
+		c.Abort()
 	}
 }
 
@@ -16,6 +16,7 @@ func ReturnErrorWithData(c *gin.Context, data responseData, result interface{})
 	data.Timestamp = time.Now().Unix()
 	data.TraceID = requestTraceID(c)
 	data.Detail = result
+	c.Set(contextkey.ResponseCode, data.Code)
 	c.JSON(http.StatusOK, data)
 	logErrorResponse(l, "Returning error response with data", data)
 	// Return directly
@@ -29,6 +30,7 @@ func ReturnOk(c *gin.Context, result interface{}) {
 	data.Timestamp = time.Now().Unix()
 	data.TraceID = requestTraceID(c)
 	data.Detail = result
+	c.Set(contextkey.ResponseCode, data.Code)
 	c.JSON(http.StatusOK, data)
 	l.Debug("Returning OK response", zap.Any("response", data))
 	// Return directly
@@ -43,6 +45,7 @@ func ReturnOkWithTotal(c *gin.Context, total int, result interface{}) {
 	data.TraceID = requestTraceID(c)
 	data.Detail = result
 	data.Total = &total
+	c.Set(contextkey.ResponseCode, data.Code)
 	c.JSON(http.StatusOK, data)
 	l.Debug("Returning OK response with total", zap.Any("response", data))
 	// Return directly
@@ -57,6 +60,7 @@ func ReturnError(c *gin.Context, data responseData, message string) {
 	if message != "" {
 		data.Message = message
 	}
+	c.Set(contextkey.ResponseCode, data.Code)
 	c.JSON(http.StatusOK, data)
 	logErrorResponse(l, "Returning error response", data)
 	// Return directly
@@ -84,6 +88,7 @@ func ReturnSuccess(c *gin.Context) {
 	data := OK
 	data.Timestamp = time.Now().Unix()
 	data.TraceID = requestTraceID(c)
+	c.Set(contextkey.ResponseCode, data.Code)
 	c.JSON(http.StatusOK, data)
 	l.Debug("Returning success response", zap.Any("response", data))
 	// Return directly
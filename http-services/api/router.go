@@ -1,10 +1,15 @@
 package api
 
 import (
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"http-services/api/app"
 	"http-services/api/middleware"
+	"http-services/application"
 	"http-services/config"
 	httplog "http-services/utils/log"
 
@@ -15,8 +20,60 @@ import (
 
 // InitApi 初始化 API 路由
 // 顶层仅负责：gin 初始化、全局中间件、挂载 /api 分组
-// 具体业务路由由 app 层逐级（app -> v1 -> open/private -> module）注册
-func InitApi() *gin.Engine {
+// 具体业务路由由 app 层逐级（app -> v1 -> open/private -> module）注册，模块依赖由 a 提供
+func InitApi(a *application.App) *gin.Engine {
+	return newEngine(a, a.Config().Server)
+}
+
+// Handler 是对外提供服务的 http.Handler，内部持有当前生效的 gin.Engine。
+// 限流、跨域、请求体上限等中间件每个请求读取最新配置；trusted_proxies 与 static_dir
+// 只能在构建 engine 时设置，且 gin 不允许在处理请求时修改，因此由 Reload 重建 engine 后整体替换，
+// 已经进入旧 engine 的请求继续由旧 engine 处理完。
+type Handler struct {
+	app    *application.App
+	engine atomic.Pointer[gin.Engine]
+
+	mu        sync.Mutex
+	proxies   []string
+	staticDir string
+}
+
+// NewHandler 按 a 的当前配置构建路由，Reload 重建 engine 时沿用同一个 App
+func NewHandler(a *application.App) *Handler {
+	serverConfig := a.Config().Server
+	h := &Handler{
+		app:       a,
+		proxies:   slices.Clone(serverConfig.TrustedProxies),
+		staticDir: serverConfig.StaticDir,
+	}
+	h.engine.Store(newEngine(h.app, serverConfig))
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.engine.Load().ServeHTTP(w, r)
+}
+
+// Engine 返回当前生效的 gin.Engine
+func (h *Handler) Engine() *gin.Engine {
+	return h.engine.Load()
+}
+
+// Reload 在 trusted_proxies 或 static_dir 变化时重建 engine，返回是否发生了替换
+func (h *Handler) Reload(serverConfig config.ServerConfig) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if slices.Equal(h.proxies, serverConfig.TrustedProxies) && h.staticDir == serverConfig.StaticDir {
+		return false
+	}
+	h.engine.Store(newEngine(h.app, serverConfig))
+	h.proxies = slices.Clone(serverConfig.TrustedProxies)
+	h.staticDir = serverConfig.StaticDir
+	return true
+}
+
+func newEngine(a *application.App, serverConfig config.ServerConfig) *gin.Engine {
 	// 将 gin 的默认日志输出重定向到 zap（Gin 独立日志文件），避免与业务日志混在同一个文件
 	// 注意：main 中会在 InitApi 之前完成 zap 初始化
 	ginLogWriter := httplog.NewZapWriterFunc(httplog.GetGinLogger, zapcore.InfoLevel)
@@ -27,42 +84,40 @@ func InitApi() *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
 	// Trust local reverse proxies such as Caddy/Nginx so ClientIP can use forwarded headers.
-	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+	if err := router.SetTrustedProxies(serverConfig.TrustedProxies); err != nil {
 		zap.L().Error("set trusted proxies failed", zap.Error(err))
 	}
 
 	// gin.Default 已安装框架自带的 Logger 和 Recovery。
 	router.Use(middleware.TraceID())
+	// 启用 mTLS 时把已校验的客户端证书身份提供给后续中间件与 handler
+	router.Use(middleware.ClientCertificate())
 
-	// 1. 全局限流（如果启用）
-	if config.EnableRateLimit {
-		router.Use(middleware.IPRateLimit(config.GlobalRateLimit, config.GlobalRateBurst))
-	}
+	// 1. 全局限流，是否启用与速率在每个请求时读取，支持热重载
+	router.Use(middleware.GlobalRateLimit(a.Config))
 
 	// 2. 安全响应头
 	router.Use(middleware.SecurityHeaders())
 
 	// 4. 取消 Prometheus 监控中间件（不需要 metrics）
 
-	// 5. 请求体大小限制 - 使用配置值
-	router.Use(middleware.BodySizeLimit(config.MaxBodySize))
+	// 5. 请求体大小限制 - 使用配置值，支持热重载
+	router.Use(middleware.ConfiguredBodySizeLimit(a.Config))
 
-	// 6. 跨域处理 - 在业务逻辑前处理
-	if config.EnableCORS {
-		router.Use(middleware.CorsDomainHandler())
-	}
+	// 6. 跨域处理 - 在业务逻辑前处理，server.enable_cors 支持热重载
+	router.Use(middleware.ConfiguredCORS(a.Config))
 
 	// 健康检查端点已移动到 openRouter（/api/v1/open/health）
 
 	// 移除 Prometheus metrics 端点（不需要 metrics）
 
 	// static
-	if staticDir := strings.TrimSpace(config.StaticDir); staticDir != "" {
+	if staticDir := strings.TrimSpace(serverConfig.StaticDir); staticDir != "" {
 		router.Static("/static", staticDir)
 	}
 
 	// /api 分组，业务路由由 app 层递归注册
 	apiGroup := router.Group("/api")
-	app.RegisterRoutes(apiGroup)
+	app.RegisterRoutes(apiGroup, a)
 	return router
 }
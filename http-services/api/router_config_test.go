@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"http-services/api/middleware"
@@ -15,28 +16,20 @@ import (
 )
 
 func TestRateLimitedResponseKeepsTraceID(t *testing.T) {
-	previousMaxBodySize := config.MaxBodySize
-	previousRateLimit := config.EnableRateLimit
-	previousRate := config.GlobalRateLimit
-	previousBurst := config.GlobalRateBurst
-	previousProxies := config.TrustedProxies
-	previousStaticDir := config.StaticDir
+	oldConfig := config.Current()
 	t.Cleanup(func() {
 		middleware.CleanupAllLimiters()
-		config.MaxBodySize = previousMaxBodySize
-		config.EnableRateLimit = previousRateLimit
-		config.GlobalRateLimit = previousRate
-		config.GlobalRateBurst = previousBurst
-		config.TrustedProxies = previousProxies
-		config.StaticDir = previousStaticDir
+		config.Replace(oldConfig)
 	})
-	config.MaxBodySize = 1 << 20
-	config.EnableRateLimit = true
-	config.GlobalRateLimit = 0
-	config.GlobalRateBurst = 1
-	config.TrustedProxies = []string{"127.0.0.1", "::1"}
-	config.StaticDir = ""
-	router := InitApi()
+	config.Update(func(c *config.Config) {
+		c.Server.MaxBodySize = 1 << 20
+		c.Server.EnableRateLimit = true
+		c.Server.GlobalRateLimit = 0
+		c.Server.GlobalRateBurst = 1
+		c.Server.TrustedProxies = []string{"127.0.0.1", "::1"}
+		c.Server.StaticDir = ""
+	})
+	router := InitApi(newTestApp(t))
 	router.GET("/probe", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 	request := func() *http.Request {
 		result := httptest.NewRequest(http.MethodGet, "/probe", nil)
@@ -63,26 +56,22 @@ func TestRateLimitedResponseKeepsTraceID(t *testing.T) {
 }
 
 func TestRouterUsesConfiguredCORSProxiesAndStaticDirectory(t *testing.T) {
-	previousMaxBodySize := config.MaxBodySize
-	previousCORS := config.EnableCORS
-	previousProxies := config.TrustedProxies
-	previousStaticDir := config.StaticDir
+	oldConfig := config.Current()
 	t.Cleanup(func() {
-		config.MaxBodySize = previousMaxBodySize
-		config.EnableCORS = previousCORS
-		config.TrustedProxies = previousProxies
-		config.StaticDir = previousStaticDir
+		config.Replace(oldConfig)
 	})
 
 	staticDir := t.TempDir()
 	if err := os.WriteFile(filepath.Join(staticDir, "probe.txt"), []byte("static-ok"), 0o600); err != nil {
 		t.Fatal(err)
 	}
-	config.MaxBodySize = 1 << 20
-	config.EnableCORS = true
-	config.TrustedProxies = []string{"127.0.0.1", "::1"}
-	config.StaticDir = staticDir
-	router := InitApi()
+	config.Update(func(c *config.Config) {
+		c.Server.MaxBodySize = 1 << 20
+		c.Server.EnableCORS = true
+		c.Server.TrustedProxies = []string{"127.0.0.1", "::1"}
+		c.Server.StaticDir = staticDir
+	})
+	router := InitApi(newTestApp(t))
 	router.GET("/client-ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
 
 	preflight := httptest.NewRequest(http.MethodOptions, "/api/v1/open/health", nil)
@@ -115,21 +104,17 @@ func TestRouterUsesConfiguredCORSProxiesAndStaticDirectory(t *testing.T) {
 const corsAllowedHeadersForTest = "Authorization, Content-Type, X-Trace-ID"
 
 func TestRouterCanDisableCORSAndStaticFiles(t *testing.T) {
-	previousMaxBodySize := config.MaxBodySize
-	previousCORS := config.EnableCORS
-	previousProxies := config.TrustedProxies
-	previousStaticDir := config.StaticDir
+	oldConfig := config.Current()
 	t.Cleanup(func() {
-		config.MaxBodySize = previousMaxBodySize
-		config.EnableCORS = previousCORS
-		config.TrustedProxies = previousProxies
-		config.StaticDir = previousStaticDir
+		config.Replace(oldConfig)
+	})
+	config.Update(func(c *config.Config) {
+		c.Server.MaxBodySize = 1 << 20
+		c.Server.EnableCORS = false
+		c.Server.TrustedProxies = []string{"127.0.0.1", "::1"}
+		c.Server.StaticDir = ""
 	})
-	config.MaxBodySize = 1 << 20
-	config.EnableCORS = false
-	config.TrustedProxies = []string{"127.0.0.1", "::1"}
-	config.StaticDir = ""
-	router := InitApi()
+	router := InitApi(newTestApp(t))
 
 	request := httptest.NewRequest(http.MethodOptions, "/api/v1/open/health", nil)
 	request.Header.Set("Origin", "https://client.example")
@@ -145,3 +130,70 @@ func TestRouterCanDisableCORSAndStaticFiles(t *testing.T) {
 		t.Fatalf("disabled static status = %d", staticRecorder.Code)
 	}
 }
+
+func TestHandlerAppliesReloadedServerConfig(t *testing.T) {
+	oldConfig := config.Current()
+	t.Cleanup(func() {
+		middleware.CleanupAllLimiters()
+		config.Replace(oldConfig)
+	})
+	config.Update(func(c *config.Config) {
+		c.Server.MaxBodySize = 1 << 20
+		c.Server.EnableRateLimit = false
+		c.Server.EnableCORS = false
+		c.Server.TrustedProxies = nil
+		c.Server.StaticDir = ""
+	})
+	handler := NewHandler(newTestApp(t))
+	if handler.Reload(config.Current().Server) {
+		t.Fatal("Reload() with unchanged proxies and static dir rebuilt the engine")
+	}
+
+	preflight := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodOptions, "/api/v1/open/health", nil)
+		request.Header.Set("Origin", "https://client.example")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	if recorder := preflight(); recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("CORS headers present while disabled: %#v", recorder.Header())
+	}
+
+	// 限流与跨域开关在已构建的 engine 上直接生效，trusted_proxies 需要 Reload 重建
+	config.Update(func(c *config.Config) {
+		c.Server.EnableRateLimit = true
+		c.Server.GlobalRateLimit = 0
+		c.Server.GlobalRateBurst = 1
+		c.Server.EnableCORS = true
+		c.Server.TrustedProxies = []string{"127.0.0.1"}
+	})
+	if recorder := preflight(); recorder.Code != http.StatusNoContent || recorder.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("preflight after reload = %d headers %#v", recorder.Code, recorder.Header())
+	}
+	if !handler.Reload(config.Current().Server) {
+		t.Fatal("Reload() with new trusted proxies did not rebuild the engine")
+	}
+	handler.Engine().GET("/client-ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	request := func() *httptest.ResponseRecorder {
+		result := httptest.NewRequest(http.MethodGet, "/client-ip", nil)
+		result.RemoteAddr = "127.0.0.1:12345"
+		result.Header.Set("X-Forwarded-For", "203.0.113.20")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, result)
+		return recorder
+	}
+	if recorder := request(); recorder.Body.String() != "203.0.113.20" {
+		t.Fatalf("client IP after reload = %q, want forwarded address", recorder.Body.String())
+	}
+	// burst=1 且 rate=0，同一 IP 的第二个请求被限流
+	if recorder := request(); !strings.Contains(recorder.Body.String(), "RESOURCE_EXHAUSTED") {
+		t.Fatalf("second request after enabling rate limit = %q, want rate limited", recorder.Body.String())
+	}
+
+	config.Update(func(c *config.Config) { c.Server.EnableRateLimit = false })
+	if recorder := request(); recorder.Body.String() != "203.0.113.20" {
+		t.Fatalf("request after disabling rate limit = %q", recorder.Body.String())
+	}
+}
@@ -6,11 +6,23 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"http-services/application"
 	"http-services/config"
+	_ "http-services/modules"
 
 	"github.com/gin-gonic/gin"
 )
 
+// newTestApp 与 main 一样使用进程级配置与 modules 导入的全部模块构建 App
+func newTestApp(t *testing.T) *application.App {
+	t.Helper()
+	a, err := application.New(application.Options{})
+	if err != nil {
+		t.Fatalf("application.New() error = %v", err)
+	}
+	return a
+}
+
 // openHealthResponse 用于解析通过路由访问健康检查接口的统一响应
 type openHealthResponse struct {
 	Code   int                    `json:"code"`
@@ -22,9 +34,11 @@ type openHealthResponse struct {
 func TestOpenHealth(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	// 避免未加载配置导致请求体限制为0
-	config.MaxBodySize = 10 << 20 // 10MB
+	config.Update(func(c *config.Config) {
+		c.Server.MaxBodySize = 10 << 20 // 10MB
+	})
 
-	r := InitApi()
+	r := InitApi(newTestApp(t))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/open/health", nil)
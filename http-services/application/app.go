@@ -0,0 +1,143 @@
+// Package application 是进程的组合根：App 持有配置、logger、MySQL、Redis、事件总线与生命周期管理器，
+// main 构建一次后通过构造函数交给路由与各模块，模块不再直接读取 db、config 等包级单例。
+// 各业务模块实现 Module 并通过 RegisterModule 登记，App 构建时按依赖顺序创建全部模块。
+// 测试使用 application/apptest 构建由替身依赖组成的 App。
+package application
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"http-services/config"
+	"http-services/db/msqldb"
+	"http-services/db/rdb"
+	"http-services/utils/authentication"
+	"http-services/utils/eventbus"
+	"http-services/utils/lifecycle"
+)
+
+// Options 是构建 App 的依赖，未设置的字段使用进程级默认实现
+type Options struct {
+	Config    func() *config.Config         // 默认 config.Current，每次调用返回最新快照
+	Logger    *zap.Logger                   // 默认 zap.L()
+	DB        func() (*gorm.DB, error)      // 默认 msqldb.Client，首次调用时连接
+	Redis     func() (*redis.Client, error) // 默认 rdb.Client，首次调用时连接
+	Bus       *eventbus.Bus                 // 默认 eventbus.Default()
+	Lifecycle *lifecycle.Manager            // 默认新建
+	Modules   []ModuleFactory               // 默认 RegisteredModules()，即 init 中登记的全部模块
+}
+
+// App 持有模块共享的依赖。配置会热重载，因此以函数形式保存，模块在使用时读取；
+// MySQL 与 Redis 在首次使用时连接，没有用到存储的进程不会因缺少 DSN 而启动失败
+type App struct {
+	config    func() *config.Config
+	logger    *zap.Logger
+	db        func() (*gorm.DB, error)
+	redis     func() (*redis.Client, error)
+	bus       *eventbus.Bus
+	lifecycle *lifecycle.Manager
+	jwt       *authentication.JWT
+	modules   []Module
+}
+
+// New 按 opts 构建 App 与全部模块；模块名称重复、依赖不存在或循环依赖时返回错误
+func New(opts Options) (*App, error) {
+	a := &App{
+		config:    opts.Config,
+		logger:    opts.Logger,
+		db:        opts.DB,
+		redis:     opts.Redis,
+		bus:       opts.Bus,
+		lifecycle: opts.Lifecycle,
+	}
+	if a.config == nil {
+		a.config = config.Current
+	}
+	if a.logger == nil {
+		a.logger = zap.L()
+	}
+	if a.db == nil {
+		a.db = msqldb.Client
+	}
+	if a.redis == nil {
+		a.redis = rdb.Client
+	}
+	if a.bus == nil {
+		a.bus = eventbus.Default()
+	}
+	if a.lifecycle == nil {
+		a.lifecycle = lifecycle.New()
+	}
+	a.jwt = authentication.NewJWT(func() config.JWTConfig { return a.config().JWT })
+
+	factories := opts.Modules
+	if factories == nil {
+		factories = RegisteredModules()
+	}
+	modules, err := buildModules(a, factories)
+	if err != nil {
+		return nil, err
+	}
+	a.modules = modules
+	return a, nil
+}
+
+// Config 返回当前生效的配置快照；一次请求内需要读取多个字段时只调用一次
+func (a *App) Config() *config.Config {
+	return a.config()
+}
+
+// Logger 返回进程 logger；请求内优先使用 log.FromContext 携带 trace_id
+func (a *App) Logger() *zap.Logger {
+	return a.logger
+}
+
+// DB 返回 MySQL 连接，未配置 database.mysql_dsn 或连接失败时返回错误
+func (a *App) DB() (*gorm.DB, error) {
+	return a.db()
+}
+
+// Redis 返回 Redis 连接，未配置 redis.host 或连接失败时返回错误
+func (a *App) Redis() (*redis.Client, error) {
+	return a.redis()
+}
+
+// PingDB 检查 MySQL 主库连通性，供模块的健康检查使用
+func (a *App) PingDB(ctx context.Context) error {
+	database, err := a.db()
+	if err != nil {
+		return err
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// PingRedis 检查 Redis 连通性，供模块的健康检查使用
+func (a *App) PingRedis(ctx context.Context) error {
+	client, err := a.redis()
+	if err != nil {
+		return err
+	}
+	return client.Ping(ctx).Err()
+}
+
+// Bus 返回进程内事件总线
+func (a *App) Bus() *eventbus.Bus {
+	return a.bus
+}
+
+// Lifecycle 返回组件生命周期管理器，长驻组件初始化后在此注册停止 hook
+func (a *App) Lifecycle() *lifecycle.Manager {
+	return a.lifecycle
+}
+
+// JWT 返回使用本 App 配置中 jwt.key / jwt.expiration 的签发与校验器
+func (a *App) JWT() *authentication.JWT {
+	return a.jwt
+}
@@ -0,0 +1,51 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"http-services/config"
+)
+
+func TestJWTReadsAppConfig(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{Key: "first-app-jwt-key-0123456789abcdef"}}
+	a, err := New(Options{Config: func() *config.Config { return cfg }, Modules: []ModuleFactory{}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	token, err := a.JWT().Issue(map[string]interface{}{"user_id": "u1"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	data, err := a.JWT().Decrypt(token)
+	if err != nil || data["user_id"] != "u1" {
+		t.Fatalf("Decrypt() = %v, %v, want user_id u1", data, err)
+	}
+
+	// 配置替换后立即按新密钥校验
+	cfg = &config.Config{JWT: config.JWTConfig{Key: "second-app-jwt-key-0123456789abcdef"}}
+	if _, err := a.JWT().Decrypt(token); err == nil {
+		t.Fatal("Decrypt() with rotated key succeeded")
+	}
+}
+
+func TestOptionsOverrideDefaults(t *testing.T) {
+	errNoDB := errors.New("no database")
+	a, err := New(Options{DB: func() (*gorm.DB, error) { return nil, errNoDB }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := a.DB(); !errors.Is(err, errNoDB) {
+		t.Fatalf("DB() error = %v, want %v", err, errNoDB)
+	}
+	if a.Config() != config.Current() {
+		t.Fatal("Config() should default to config.Current()")
+	}
+	if a.Bus() == nil || a.Lifecycle() == nil || a.Logger() == nil {
+		t.Fatal("unset options should fall back to process defaults")
+	}
+}
@@ -0,0 +1,99 @@
+// Package apptest 构建由替身依赖组成的 application.App，供 handler 与模块测试使用：
+// 独立的配置副本、可断言的内存 logger、sqlmock 驱动的 gorm、miniredis 与独立的事件总线。
+// 通过 App 构建的 handler、模块与中间件（token 校验、审计、全局限流、跨域、请求体上限）读取 Harness.Config，
+// 这些测试不需要修改 config.Current() 或替换包级的数据库连接函数；utils/log 等仍直接读取
+// config.Current() 的包级功能不受 Harness.Config 控制，依赖它们的测试仍需自行设置全局配置。
+package apptest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"http-services/application"
+	"http-services/config"
+	"http-services/utils/eventbus"
+	"http-services/utils/lifecycle"
+)
+
+// JWTKey 是 Harness 默认使用的 jwt.key
+const JWTKey = "apptest-jwt-key-0123456789abcdef"
+
+// Harness 是一个测试用的 App 及其替身依赖
+type Harness struct {
+	App    *application.App
+	Config *config.Config         // App.Config() 返回的就是它，测试可以直接修改字段
+	SQL    sqlmock.Sqlmock        // App.DB() 背后的 mock，用于设置期望的 SQL
+	Redis  *miniredis.Miniredis   // App.Redis() 连接的内存 Redis
+	Logs   *observer.ObservedLogs // App.Logger() 写入的日志
+}
+
+// New 构建 Harness，configure 在 App 构建前依次修改配置；测试二进制中已登记的模块同样会被构建。
+// 测试结束时关闭连接、停止生命周期 hook 并校验 sqlmock 的期望都已满足
+func New(t testing.TB, configure ...func(*config.Config)) *Harness {
+	t.Helper()
+	cfg, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("config.Defaults() error = %v", err)
+	}
+	cfg.JWT.Key = JWTKey
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	database, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	core, logs := observer.New(zap.DebugLevel)
+	bus := eventbus.New()
+	lc := lifecycle.New()
+
+	h := &Harness{Config: cfg, SQL: mock, Redis: server, Logs: logs}
+	app, err := application.New(application.Options{
+		Config:    func() *config.Config { return h.Config },
+		Logger:    zap.New(core),
+		DB:        func() (*gorm.DB, error) { return database, nil },
+		Redis:     func() (*redis.Client, error) { return client, nil },
+		Bus:       bus,
+		Lifecycle: lc,
+	})
+	if err != nil {
+		t.Fatalf("application.New() error = %v", err)
+	}
+	h.App = app
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := lc.Stop(ctx); err != nil {
+			t.Errorf("lifecycle Stop() error = %v", err)
+		}
+		_ = bus.Close(ctx)
+		_ = client.Close()
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("sqlmock expectations: %v", err)
+		}
+		_ = sqlDB.Close()
+	})
+	return h
+}
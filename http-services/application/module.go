@@ -0,0 +1,213 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"http-services/db"
+	"http-services/db/msqldb"
+	domainhealth "http-services/domain/health"
+	"http-services/services/cron"
+)
+
+// Module 是一个业务模块对进程的全部接入点。模块在 init 中通过 RegisterModule 登记构造函数，
+// 由 http-services/modules 统一导入；路由、迁移、加密列、定时任务、健康检查与停止都从 App 发现，
+// 新增模块不再需要分别修改路由聚合、db.MigrateAll、db.ReencryptAll 与 main。嵌入 BaseModule 后只需实现用到的方法
+type Module interface {
+	// Name 是模块的唯一名称，用于依赖声明与日志
+	Name() string
+	// DependsOn 返回依赖的模块名称；依赖先迁移、先注册路由，停止时后停止
+	DependsOn() []string
+	// Routes 在 /api/v1/open 与 /api/v1/private 下注册模块路由
+	Routes(open, private *gin.RouterGroup)
+	// AdminRoutes 在 /api/v1/admin 下注册运维路由，分组已挂载审计与 admin token 校验
+	AdminRoutes(admin *gin.RouterGroup)
+	// Migrations 返回模块的表迁移，--migrate 按模块依赖顺序执行
+	Migrations() []db.Migrator
+	// EncryptedColumns 返回模块中保存 encryption.EncryptedString 的列，--reencrypt 轮换密钥时依次处理
+	EncryptedColumns() []msqldb.EncryptedColumns
+	// Jobs 返回模块的定时任务，在服务运行期间按间隔执行
+	Jobs() []cron.Job
+	// HealthChecks 返回模块依赖的检查项，结果出现在 /api/v1/open/health 中
+	HealthChecks() []domainhealth.Check
+	// Shutdown 在后台任务停止后调用，释放模块自己持有的资源
+	Shutdown(ctx context.Context) error
+}
+
+// ModuleFactory 使用 App 中的依赖构建模块
+type ModuleFactory func(a *App) Module
+
+// BaseModule 为 Module 的可选方法提供空实现
+type BaseModule struct{}
+
+// DependsOn 默认没有依赖
+func (BaseModule) DependsOn() []string { return nil }
+
+// Routes 默认不注册路由
+func (BaseModule) Routes(_, _ *gin.RouterGroup) {}
+
+// AdminRoutes 默认不注册运维路由
+func (BaseModule) AdminRoutes(*gin.RouterGroup) {}
+
+// Migrations 默认没有迁移
+func (BaseModule) Migrations() []db.Migrator { return nil }
+
+// EncryptedColumns 默认没有加密列
+func (BaseModule) EncryptedColumns() []msqldb.EncryptedColumns { return nil }
+
+// Jobs 默认没有定时任务
+func (BaseModule) Jobs() []cron.Job { return nil }
+
+// HealthChecks 默认没有检查项
+func (BaseModule) HealthChecks() []domainhealth.Check { return nil }
+
+// Shutdown 默认无需清理
+func (BaseModule) Shutdown(context.Context) error { return nil }
+
+var (
+	registryMu sync.Mutex
+	registry   []ModuleFactory
+)
+
+// RegisterModule 登记模块构造函数，通常在模块包的 init 中调用
+func RegisterModule(factory ModuleFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, factory)
+}
+
+// RegisteredModules 返回已登记的模块构造函数
+func RegisteredModules() []ModuleFactory {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Clone(registry)
+}
+
+// buildModules 构建全部模块并按依赖排序；没有依赖关系的模块按名称排序，保证每次启动顺序一致
+// （Go 按 import 路径初始化包，init 中的登记顺序不能表达依赖）
+func buildModules(a *App, factories []ModuleFactory) ([]Module, error) {
+	byName := make(map[string]Module, len(factories))
+	names := make([]string, 0, len(factories))
+	for _, factory := range factories {
+		m := factory(a)
+		if _, ok := byName[m.Name()]; ok {
+			return nil, fmt.Errorf("module %q registered twice", m.Name())
+		}
+		byName[m.Name()] = m
+		names = append(names, m.Name())
+	}
+	slices.Sort(names)
+
+	pending := make(map[string]int, len(names))
+	dependents := make(map[string][]string)
+	for _, name := range names {
+		for _, dep := range byName[name].DependsOn() {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("module %q depends on unknown module %q", name, dep)
+			}
+			pending[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	ordered := make([]Module, 0, len(names))
+	var ready []string
+	for _, name := range names {
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		name := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, byName[name])
+		for _, dependent := range dependents[name] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(ordered) != len(names) {
+		var cyclic []string
+		for _, name := range names {
+			if pending[name] > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		return nil, fmt.Errorf("module dependency cycle among %v", cyclic)
+	}
+	return ordered, nil
+}
+
+// Modules 返回按依赖排序的模块
+func (a *App) Modules() []Module {
+	return a.modules
+}
+
+// RegisterModuleRoutes 按依赖顺序注册全部模块的路由
+func (a *App) RegisterModuleRoutes(open, private *gin.RouterGroup) {
+	for _, m := range a.modules {
+		m.Routes(open, private)
+	}
+}
+
+// RegisterModuleAdminRoutes 按依赖顺序注册全部模块的运维路由
+func (a *App) RegisterModuleAdminRoutes(admin *gin.RouterGroup) {
+	for _, m := range a.modules {
+		m.AdminRoutes(admin)
+	}
+}
+
+// Migrations 按依赖顺序汇总全部模块的迁移
+func (a *App) Migrations() []db.Migrator {
+	var migrators []db.Migrator
+	for _, m := range a.modules {
+		migrators = append(migrators, m.Migrations()...)
+	}
+	return migrators
+}
+
+// EncryptedColumns 按依赖顺序汇总全部模块的加密列
+func (a *App) EncryptedColumns() []msqldb.EncryptedColumns {
+	var columns []msqldb.EncryptedColumns
+	for _, m := range a.modules {
+		columns = append(columns, m.EncryptedColumns()...)
+	}
+	return columns
+}
+
+// Jobs 汇总全部模块的定时任务
+func (a *App) Jobs() []cron.Job {
+	var jobs []cron.Job
+	for _, m := range a.modules {
+		jobs = append(jobs, m.Jobs()...)
+	}
+	return jobs
+}
+
+// HealthChecks 汇总全部模块的检查项
+func (a *App) HealthChecks() []domainhealth.Check {
+	var checks []domainhealth.Check
+	for _, m := range a.modules {
+		checks = append(checks, m.HealthChecks()...)
+	}
+	return checks
+}
+
+// ShutdownModules 按依赖的逆序停止模块：依赖方先停止，被依赖的模块最后停止。
+// 某个模块失败时继续停止其余模块，返回合并的错误
+func (a *App) ShutdownModules(ctx context.Context) error {
+	var errs []error
+	for _, m := range slices.Backward(a.modules) {
+		if err := m.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown module %s: %w", m.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"http-services/db"
+	"http-services/db/msqldb"
+)
+
+// testModule 记录停止顺序，并按名称提供一条迁移、一张加密表与一条运维路由
+type testModule struct {
+	BaseModule
+	name    string
+	deps    []string
+	stopped *[]string
+	stopErr error
+}
+
+func (m testModule) Name() string        { return m.name }
+func (m testModule) DependsOn() []string { return m.deps }
+
+func (m testModule) Migrations() []db.Migrator {
+	return []db.Migrator{{Name: m.name, Migrate: func(*gorm.DB) error { return nil }}}
+}
+
+func (m testModule) EncryptedColumns() []msqldb.EncryptedColumns {
+	return []msqldb.EncryptedColumns{{Table: m.name, Columns: []string{"phone"}}}
+}
+
+func (m testModule) AdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/"+m.name, func(*gin.Context) {})
+}
+
+func (m testModule) Shutdown(context.Context) error {
+	*m.stopped = append(*m.stopped, m.name)
+	return m.stopErr
+}
+
+func factories(modules ...testModule) []ModuleFactory {
+	result := make([]ModuleFactory, 0, len(modules))
+	for _, m := range modules {
+		result = append(result, func(*App) Module { return m })
+	}
+	return result
+}
+
+func TestModulesFollowDependencyOrder(t *testing.T) {
+	var stopped []string
+	a, err := New(Options{Modules: factories(
+		testModule{name: "orders", deps: []string{"users", "audit"}, stopped: &stopped},
+		testModule{name: "users", deps: []string{"audit"}, stopped: &stopped, stopErr: errors.New("flush failed")},
+		testModule{name: "billing", stopped: &stopped},
+		testModule{name: "audit", stopped: &stopped},
+	)})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var migrated []string
+	for _, m := range a.Migrations() {
+		migrated = append(migrated, m.Name)
+	}
+	// 依赖先迁移，没有依赖关系的模块按名称排序
+	if want := []string{"audit", "billing", "users", "orders"}; !reflect.DeepEqual(migrated, want) {
+		t.Fatalf("migration order = %v, want %v", migrated, want)
+	}
+	var encrypted []string
+	for _, c := range a.EncryptedColumns() {
+		encrypted = append(encrypted, c.Table)
+	}
+	if want := []string{"audit", "billing", "users", "orders"}; !reflect.DeepEqual(encrypted, want) {
+		t.Fatalf("encrypted tables = %v, want %v", encrypted, want)
+	}
+	router := gin.New()
+	a.RegisterModuleAdminRoutes(router.Group("/admin"))
+	var routes []string
+	for _, r := range router.Routes() {
+		routes = append(routes, r.Path)
+	}
+	if want := []string{"/admin/audit", "/admin/billing", "/admin/users", "/admin/orders"}; !reflect.DeepEqual(routes, want) {
+		t.Fatalf("admin routes = %v, want %v", routes, want)
+	}
+
+	err = a.ShutdownModules(context.Background())
+	if want := []string{"orders", "users", "billing", "audit"}; !reflect.DeepEqual(stopped, want) {
+		t.Fatalf("shutdown order = %v, want %v", stopped, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "shutdown module users") {
+		t.Fatalf("ShutdownModules() error = %v, want users failure", err)
+	}
+}
+
+func TestModulesRejectInvalidGraph(t *testing.T) {
+	var stopped []string
+	tests := []struct {
+		name    string
+		modules []testModule
+		wantErr string
+	}{
+		{
+			name:    "duplicate name",
+			modules: []testModule{{name: "users"}, {name: "users"}},
+			wantErr: "registered twice",
+		},
+		{
+			name:    "unknown dependency",
+			modules: []testModule{{name: "orders", deps: []string{"users"}}},
+			wantErr: `unknown module "users"`,
+		},
+		{
+			name: "cycle",
+			modules: []testModule{
+				{name: "a", deps: []string{"b"}},
+				{name: "b", deps: []string{"a"}},
+				{name: "c"},
+			},
+			wantErr: "cycle among [a b]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.modules {
+				tt.modules[i].stopped = &stopped
+			}
+			_, err := New(Options{Modules: factories(tt.modules...)})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
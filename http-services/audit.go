@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"http-services/config"
+	"http-services/db/msqldb"
+	"http-services/db/msqldb/auditlog"
+	"http-services/utils/audit"
+)
+
+// openAuditRecorder 按 audit.sinks 打开审计输出并替换默认 Recorder；未启用审计时保持空 Recorder
+// 审计是合规要求，任一输出打开失败都直接返回错误，由调用方终止启动。
+func openAuditRecorder() (*audit.Recorder, error) {
+	auditConfig := config.Current().Audit
+	if !auditConfig.Enabled {
+		return audit.Default(), nil
+	}
+	if len(auditConfig.Sinks) == 0 {
+		return nil, errors.New("audit.enabled is true but audit.sinks is empty")
+	}
+
+	var sinks []audit.Sink
+	for _, name := range auditConfig.Sinks {
+		switch name {
+		case "file":
+			sink, err := audit.OpenFile(auditConfig.File)
+			if err != nil {
+				_ = audit.New(sinks...).Close()
+				return nil, fmt.Errorf("open audit file %s: %w", auditConfig.File, err)
+			}
+			sinks = append(sinks, sink)
+		case "mysql":
+			database, err := msqldb.Client()
+			if err != nil {
+				_ = audit.New(sinks...).Close()
+				return nil, fmt.Errorf("init mysql client for audit: %w", err)
+			}
+			sinks = append(sinks, auditlog.Sink{DB: database})
+		}
+	}
+	recorder := audit.New(sinks...)
+	audit.SetDefault(recorder)
+	return recorder, nil
+}
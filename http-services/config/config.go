@@ -4,15 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Here are some basic configurations
 // These configurations are usually generic
 var (
-	// listen
-	ListenHost = "0.0.0.0"
-	ListenPort = 8080 // api listen port
 	// run model
 	RunModelKey      = "model"
 	RunModel         = ""
@@ -26,42 +28,306 @@ var (
 	LogPath = filepath.Join(LogDir, fmt.Sprintf("%s.log", SelfName)) // self log path
 )
 
-// 从配置文件加载的配置变量
+// Config 是从配置文件与环境变量加载出的完整配置。加载或热重载时先构建并校验一份新的 Config，
+// 通过后整体发布，读取方通过 Current() 拿到的快照在发布后不会再被修改，不要原地改写其中的字段。
+//
+// mapstructure 标签是配置文件中的 key，validate 标签是校验规则（github.com/go-playground/validator），
+// 无法用标签表达的跨字段约束在 Config.validate 中检查。
+type Config struct {
+	Server     ServerConfig     `mapstructure:"server"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	Log        LogConfig        `mapstructure:"log"`
+	Admin      AdminConfig      `mapstructure:"admin"`
+	Audit      AuditConfig      `mapstructure:"audit"`
+	ID         IDConfig         `mapstructure:"id"`
+	Password   PasswordConfig   `mapstructure:"password"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Queue      QueueConfig      `mapstructure:"queue"`
+	Outbox     OutboxConfig     `mapstructure:"outbox"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Host            string           `mapstructure:"host"`
+	Port            int              `mapstructure:"port" validate:"min=1,max=65535"`         // api listen port
+	MaxBodySize     int64            `mapstructure:"max_body_size" validate:"gt=0"`           // 请求体大小限制（字节），配置中写作 10MB 等带单位的字符串
+	MaxHeaderBytes  int              `mapstructure:"max_header_bytes" validate:"gt=0"`        // 最大请求头大小
+	ShutdownTimeout time.Duration    `mapstructure:"shutdown_timeout" validate:"gt=0"`        // 优雅关闭超时时间
+	PreStopDelay    time.Duration    `mapstructure:"pre_stop_delay" validate:"gte=0"`         // 撤销就绪后、开始排空前的等待时间，供负载均衡摘除实例
+	ReadTimeout     time.Duration    `mapstructure:"read_timeout" validate:"gte=0"`           // 读取超时
+	WriteTimeout    time.Duration    `mapstructure:"write_timeout" validate:"gte=0"`          // 写入超时
+	IdleTimeout     time.Duration    `mapstructure:"idle_timeout" validate:"gte=0"`           // 空闲超时
+	EnableRateLimit bool             `mapstructure:"enable_rate_limit"`                       // 是否启用全局限流
+	GlobalRateLimit int              `mapstructure:"global_rate_limit" validate:"gte=0"`      // 全局限流速率（每秒请求数）
+	GlobalRateBurst int              `mapstructure:"global_rate_burst" validate:"gte=0"`      // 全局限流突发容量
+	PidFile         string           `mapstructure:"pid_file"`                                // pid 文件路径（支持相对路径，相对 AbsPath）
+	StaticDir       string           `mapstructure:"static_dir"`                              // 静态文件目录；为空时不挂载 /static
+	TrustedProxies  []string         `mapstructure:"trusted_proxies" validate:"dive,ip|cidr"` // Gin 可信反向代理
+	EnableCORS      bool             `mapstructure:"enable_cors"`                             // 是否启用跨域中间件
+	H2C             bool             `mapstructure:"h2c"`                                     // 主监听与 unix socket 同时接受 HTTP/2 cleartext（prior knowledge）
+	UnixSocket      UnixSocketConfig `mapstructure:"unix_socket"`                             // 附加的 unix domain socket 监听
+	TLS             TLSConfig        `mapstructure:"tls"`                                     // 主监听的 TLS / mTLS
+}
+
+// TLSConfig 主监听的 TLS 配置；证书、私钥与客户端 CA 文件变化时自动重新加载，其余字段修改需要重启
+type TLSConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	CertFile     string   `mapstructure:"cert_file" validate:"required_if=Enabled true"`                     // 证书链 PEM（支持相对路径，相对 AbsPath）
+	KeyFile      string   `mapstructure:"key_file" validate:"required_if=Enabled true"`                      // 私钥 PEM（支持相对路径，相对 AbsPath）
+	MinVersion   string   `mapstructure:"min_version" validate:"oneof=1.2 1.3"`                              // 最低 TLS 版本
+	CipherSuites []string `mapstructure:"cipher_suites"`                                                     // TLS 1.2 密码套件（Go 名称），为空时使用 Go 默认值
+	ClientAuth   string   `mapstructure:"client_auth" validate:"oneof=none request verify_if_given require"` // 客户端证书校验方式
+	ClientCAFile string   `mapstructure:"client_ca_file"`                                                    // 校验客户端证书的 CA bundle
+}
+
+// UnixSocketConfig unix domain socket 监听配置，供同机反向代理使用
+type UnixSocketConfig struct {
+	Path  string      `mapstructure:"path"`  // socket 路径（支持相对路径，相对 AbsPath）；为空时不监听
+	Mode  os.FileMode `mapstructure:"mode"`  // socket 文件权限，配置中写作 "0660"
+	Owner string      `mapstructure:"owner"` // socket 文件属主，user 或 user:group；为空时不修改
+}
+
+// JWTConfig JWT 配置，key 的强度由 CheckConfig 在启动时检查
+type JWTConfig struct {
+	Key        string        `mapstructure:"key"`
+	Expiration time.Duration `mapstructure:"expiration" validate:"gte=0"`
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	MaxSize          int               `mapstructure:"max_size" validate:"gte=0"`
+	MaxAge           int               `mapstructure:"max_age" validate:"gte=0"`
+	Level            string            `mapstructure:"level"`
+	GinLevel         string            `mapstructure:"gin_level"`
+	GormLevel        string            `mapstructure:"gorm_level"`                                  // gorm 日志级别；为空时跟随 Level
+	RedisLevel       string            `mapstructure:"redis_level"`                                 // redis 日志级别；为空时跟随 Level
+	CronLevel        string            `mapstructure:"cron_level"`                                  // 定时任务日志级别；为空时跟随 Level
+	LevelRevertAfter time.Duration     `mapstructure:"level_revert_after" validate:"gte=0"`         // 运行期临时调整日志级别后自动恢复的时长
+	Sinks            []LogSink         `mapstructure:"sinks"`                                       // 日志输出列表；为空时使用默认输出
+	Compress         bool              `mapstructure:"compress"`                                    // 轮转后的旧日志是否 gzip 压缩
+	MaxTotalSize     int64             `mapstructure:"max_total_size" validate:"gte=0"`             // LogDir 下日志总大小上限（字节），超出时从最旧的文件开始删除；0 表示不限制
+	Rotation         string            `mapstructure:"rotation" validate:"oneof=daily hourly none"` // 定时轮转：daily / hourly / none
+	Sampling         LogSamplingConfig `mapstructure:"sampling"`
+}
+
+// LogSamplingConfig 重复日志采样配置
+type LogSamplingConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`                                  // 是否对重复日志采样
+	Tick         time.Duration `mapstructure:"tick" validate:"required_if=Enabled true"` // 采样统计窗口
+	First        int           `mapstructure:"first" validate:"gte=0"`                   // 每个窗口内同级别同消息先完整记录的条数
+	Thereafter   int           `mapstructure:"thereafter" validate:"gte=0"`              // 超出后每 N 条记录 1 条
+	ExemptLevels []string      `mapstructure:"exempt_levels"`                            // 不参与采样的级别；error 及以上始终不采样
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Token            string `mapstructure:"token"`                                     // 管理接口 Bearer token；为空时管理接口一律拒绝
+	DiagnosticsToken string `mapstructure:"diagnostics_token"`                         // 诊断接口（/api/v1/admin/diagnostics）独立的 Bearer token；为空时诊断接口关闭
+	Listen           string `mapstructure:"listen" validate:"omitempty,hostname_port"` // 独立管理端口（pprof、metrics、日志级别、配置），只允许回环地址；为空时不监听
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`                                // 是否记录审计日志
+	Sinks      []string `mapstructure:"sinks" validate:"dive,oneof=file mysql"` // 审计输出：file（hash 链文件）/ mysql（audit_logs 表，需先执行迁移）
+	File       string   `mapstructure:"file" validate:"required"`               // hash 链审计文件路径（支持相对路径，相对 AbsPath）
+	ActorClaim string   `mapstructure:"actor_claim" validate:"required"`        // 从 JWT 数据中取操作者的字段名
+}
+
+// IDConfig Sonyflake ID 配置
+type IDConfig struct {
+	MachineID        int           `mapstructure:"machine_id" validate:"min=-1,max=65535"` // Sonyflake machine id（0～65535）；-1 表示自动推导（私有 IPv4、MAC、主机名 hash）
+	RedisLease       bool          `mapstructure:"redis_lease"`                            // 是否通过 Redis 租约分配 machine id，与显式 MachineID 互斥
+	LeaseTTL         time.Duration `mapstructure:"lease_ttl" validate:"gte=0"`             // machine id 租约 TTL，每 TTL/3 续约一次
+	Epoch            time.Time     `mapstructure:"epoch"`                                  // Sonyflake 时间起点；已有数据后不可修改
+	MaxClockBackward time.Duration `mapstructure:"max_clock_backward" validate:"gte=0"`    // 可容忍的时钟回拨幅度，超出时拒绝生成 Sonyflake ID
+}
+
+// PasswordConfig 密码哈希配置；参数过小会明显削弱哈希强度，校验不通过时直接拒绝加载
+type PasswordConfig struct {
+	Algorithm         string `mapstructure:"algorithm" validate:"oneof=argon2id scrypt bcrypt"` // 新密码哈希算法，存量哈希无论算法都能校验
+	Argon2Memory      int    `mapstructure:"argon2_memory" validate:"min=8192,max=4194304"`     // Argon2id 内存（KiB）
+	Argon2Time        int    `mapstructure:"argon2_time" validate:"min=1"`                      // Argon2id 迭代轮数
+	Argon2Parallelism int    `mapstructure:"argon2_parallelism" validate:"min=1,max=255"`       // Argon2id 并行度
+	ScryptLogN        int    `mapstructure:"scrypt_log_n" validate:"min=10,max=30"`             // scrypt N 的以 2 为底的对数
+	ScryptR           int    `mapstructure:"scrypt_r" validate:"min=1"`                         // scrypt 块大小
+	ScryptP           int    `mapstructure:"scrypt_p" validate:"min=1"`                         // scrypt 并行度
+	BcryptCost        int    `mapstructure:"bcrypt_cost" validate:"min=4,max=31"`               // bcrypt cost
+	Pepper            string `mapstructure:"pepper"`                                            // 密码 pepper（HMAC 密钥），为空表示不启用；设置后不可随意更换
+	AllowUnpeppered   bool   `mapstructure:"allow_unpeppered"`                                  // 启用 pepper 后仍允许校验旧的无 pepper 哈希，登录成功后应重新哈希
+}
+
+// EncryptionConfig 字段加密配置
+type EncryptionConfig struct {
+	Keys          map[string][]byte `mapstructure:"keys"`            // 字段加密主密钥（key id -> 32 字节 AES-256 密钥），为空表示不启用
+	PrimaryKey    string            `mapstructure:"primary_key"`     // 加密新数据使用的 key id
+	BlindIndexKey []byte            `mapstructure:"blind_index_key"` // 盲索引 HMAC 密钥；更换后需要重建所有索引列
+}
+
+// DatabaseConfig MySQL 配置
+type DatabaseConfig struct {
+	MysqlDSN        string        `mapstructure:"mysql_dsn"`                           // MySQL 数据库连接字符串（主库，承担写入与事务）
+	ReplicaDSNs     []string      `mapstructure:"replica_dsns"`                        // 只读副本连接字符串，为空时读写都走主库
+	ReplicaPolicy   string        `mapstructure:"replica_policy"`                      // 副本负载均衡策略：random 或 round_robin，未知值按 random 处理
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" validate:"gte=0"`     // 每个连接池的最大空闲连接数
+	MaxOpenConns    int           `mapstructure:"max_open_conns" validate:"gte=0"`     // 每个连接池的最大打开连接数
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" validate:"gte=0"`  // 连接最大存活时间
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" validate:"gte=0"` // 连接最大空闲时间
+	SlowThreshold   time.Duration `mapstructure:"slow_threshold" validate:"gte=0"`     // 慢查询日志阈值
+}
+
+// RedisConfig Redis 配置
+type RedisConfig struct {
+	Host      string `mapstructure:"host" validate:"required"`                   // Redis 连接地址
+	Password  string `mapstructure:"password"`                                   // Redis 密码
+	KeyPrefix string `mapstructure:"key_prefix" validate:"omitempty,endswith=:"` // 所有 key 的前缀，非空时必须以 ':' 结尾
+}
+
+// QueueConfig 后台任务队列配置
+type QueueConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`                        // 是否启动后台任务 worker
+	Name         string        `mapstructure:"name" validate:"required"`       // 队列名称，用于隔离 Redis key
+	Workers      int           `mapstructure:"workers" validate:"gte=0"`       // worker 数量
+	PollInterval time.Duration `mapstructure:"poll_interval" validate:"gte=0"` // 队列为空时的轮询间隔
+	JobTimeout   time.Duration `mapstructure:"job_timeout" validate:"gte=0"`   // 单个任务执行超时
+	MaxAttempts  int           `mapstructure:"max_attempts" validate:"gte=0"`  // 默认最大执行次数（含首次）
+	RetryBase    time.Duration `mapstructure:"retry_base" validate:"gte=0"`    // 首次重试等待时间，之后指数增长
+	RetryMax     time.Duration `mapstructure:"retry_max" validate:"gt=0"`      // 重试等待时间上限
+}
+
+// OutboxConfig outbox relay 配置
+type OutboxConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`                           // 是否启动 outbox relay
+	PollInterval    time.Duration `mapstructure:"poll_interval" validate:"gte=0"`    // 无待投递事件时的轮询间隔
+	BatchSize       int           `mapstructure:"batch_size" validate:"gte=0"`       // 单次事务领取的事件数
+	MaxAttempts     int           `mapstructure:"max_attempts" validate:"gt=0"`      // 最大投递次数（含首次），用尽后事件标记为失败
+	RetryBase       time.Duration `mapstructure:"retry_base" validate:"gte=0"`       // 首次重试等待时间，之后指数增长
+	RetryMax        time.Duration `mapstructure:"retry_max" validate:"gt=0"`         // 重试等待时间上限
+	Retention       time.Duration `mapstructure:"retention" validate:"gte=0"`        // 已投递事件保留时长，0 表示不清理
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" validate:"gte=0"` // 已投递事件清理间隔
+	StreamPrefix    string        `mapstructure:"stream_prefix"`                     // Redis Stream key 前缀，stream 名为前缀 + topic
+	StreamMaxLen    int64         `mapstructure:"stream_max_len" validate:"gte=0"`   // 单个 stream 近似最大长度，0 表示不裁剪
+}
+
 var (
-	// JWT
-	JWTKey        string
-	JWTExpiration time.Duration
-
-	// Server
-	MaxBodySize     int64         // 请求体大小限制（字节）
-	ShutdownTimeout time.Duration // 优雅关闭超时时间
-	ReadTimeout     time.Duration // 读取超时
-	WriteTimeout    time.Duration // 写入超时
-	IdleTimeout     time.Duration // 空闲超时
-	MaxHeaderBytes  int           // 最大请求头大小
-	EnableRateLimit bool          // 是否启用全局限流
-	GlobalRateLimit int           // 全局限流速率（每秒请求数）
-	GlobalRateBurst int           // 全局限流突发容量
-	PidFile         string        // pid 文件路径（支持相对路径，相对 AbsPath）
-	StaticDir       string        // 静态文件目录；为空时不挂载 /static
-	TrustedProxies  []string      // Gin 可信反向代理
-	EnableCORS      bool          // 是否启用跨域中间件
-
-	// Log
-	LogMaxSize  int
-	LogMaxAge   int
-	LogLevel    string
-	GinLogLevel string
-
-	// Database
-	MysqlDSN string // MySQL 数据库连接字符串
-
-	// Redis
-	RedisHost      string // Redis 连接地址
-	RedisPassword  string // Redis 密码
-	RedisKeyPrefix string
+	current     atomic.Pointer[Config]
+	publishMu   sync.Mutex // 串行化加载、重载与 Replace，保证订阅者按发布顺序收到通知
+	subscribers = make(map[int]func(old, new *Config))
+	nextSubID   int
 )
 
+func init() {
+	current.Store(&Config{Server: ServerConfig{Host: "0.0.0.0", Port: 8080}})
+}
+
+// Current 返回当前生效的配置快照，可以在任意 goroutine 中并发调用。
+// 一次请求内需要读取多个字段时应只调用一次，保证读到的字段来自同一版本配置
+func Current() *Config {
+	return current.Load()
+}
+
+// Subscribe 注册配置变更回调，每次有新配置发布（LoadConfig、热重载、Replace）后以新旧两份快照调用；
+// 校验失败的重载不会触发回调。回调在发布方 goroutine 中串行执行，不能在回调里再发布配置。
+// 返回的函数用于取消订阅
+func Subscribe(fn func(old, new *Config)) (unsubscribe func()) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	id := nextSubID
+	nextSubID++
+	subscribers[id] = fn
+	return func() {
+		publishMu.Lock()
+		defer publishMu.Unlock()
+		delete(subscribers, id)
+	}
+}
+
+// Replace 跳过加载与校验直接发布 cfg，并返回被替换的旧配置，主要用于测试与嵌入式场景
+func Replace(cfg *Config) (old *Config) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	return publish(cfg)
+}
+
+// Update 复制当前配置，交给 fn 修改后发布，返回被替换的旧配置。fn 拿到的是浅拷贝，
+// 修改切片或 map 字段时应整体赋值而不是原地修改
+func Update(fn func(cfg *Config)) (old *Config) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	next := *current.Load()
+	fn(&next)
+	return publish(&next)
+}
+
+// ChangedKeys 返回 old 与 new 之间取值不同的配置 key（如 server.port），按结构体字段顺序排列；
+// 只展开本包定义的配置段结构体，time.Time、切片、map 等其余字段整体比较。任一方为 nil 时返回 nil
+func ChangedKeys(old, new *Config) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+	var keys []string
+	collectChangedKeys("", reflect.ValueOf(*old), reflect.ValueOf(*new), &keys)
+	return keys
+}
+
+func collectChangedKeys(prefix string, old, new reflect.Value, keys *[]string) {
+	for i := range old.NumField() {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+		oldField, newField := old.Field(i), new.Field(i)
+		if isConfigSection(field.Type) {
+			collectChangedKeys(key+".", oldField, newField, keys)
+			continue
+		}
+		if !leafEqual(oldField.Interface(), newField.Interface()) {
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+// configPkgPath 是本包的导入路径，用于区分配置段与 time.Time 等外部结构体
+var configPkgPath = reflect.TypeFor[Config]().PkgPath()
+
+// isConfigSection 判断字段是否为需要逐项比较的配置段（本包定义的结构体）
+func isConfigSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == configPkgPath
+}
+
+// leafEqual 比较叶子字段；time.Time 按时刻比较，忽略时区指针与单调时钟读数
+func leafEqual(old, new any) bool {
+	if oldTime, ok := old.(time.Time); ok {
+		newTime, ok := new.(time.Time)
+		return ok && oldTime.Equal(newTime)
+	}
+	return reflect.DeepEqual(old, new)
+}
+
+// publish 调用方需持有 publishMu
+func publish(cfg *Config) *Config {
+	old := current.Swap(cfg)
+	ids := make([]int, 0, len(subscribers))
+	for id := range subscribers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		subscribers[id](old, cfg)
+	}
+	return old
+}
+
 // 分页配置
 var (
 	DefaultPageSize = 20 // 默认分页大小
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribe_ReceivesOldAndNewConfig(t *testing.T) {
+	originalConfig := Current()
+	t.Cleanup(func() { Replace(originalConfig) })
+
+	var calls int
+	var gotOld, gotNew *Config
+	unsubscribe := Subscribe(func(old, new *Config) {
+		calls++
+		gotOld, gotNew = old, new
+	})
+
+	before := Current()
+	returned := Update(func(c *Config) { c.Server.Port = 9191 })
+	if calls != 1 || gotOld != before || gotNew != Current() || returned != before {
+		t.Fatalf("subscriber calls = %d, old %p new %p, want 1 call with %p -> %p", calls, gotOld, gotNew, before, Current())
+	}
+	if before.Server.Port == 9191 {
+		t.Fatal("Update mutated the previous snapshot")
+	}
+	if Current().Server.Port != 9191 {
+		t.Fatalf("Current().Server.Port = %d, want 9191", Current().Server.Port)
+	}
+
+	unsubscribe()
+	Replace(before)
+	if calls != 1 {
+		t.Fatalf("subscriber called %d times after unsubscribe, want 1", calls)
+	}
+}
+
+func TestReload_RejectsInvalidConfigWhole(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+
+	configDir := t.TempDir()
+	t.Chdir(configDir)
+	configFile := filepath.Join(configDir, "config.yaml")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("write config file: %v", err)
+		}
+	}
+	writeConfig("server:\n  port: 8081\nredis:\n  host: redis-a:6379\n")
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	loadedConfig := Current()
+
+	var notified bool
+	unsubscribe := Subscribe(func(_, _ *Config) { notified = true })
+	t.Cleanup(unsubscribe)
+
+	// redis.host 本身合法，但同一次重载中的端口与 key 前缀不合法，整份配置都不应生效
+	writeConfig("server:\n  port: 70000\nredis:\n  host: redis-b:6379\n  key_prefix: app\n")
+	loadedViper := v
+	err := reloadConfig()
+	if err == nil {
+		t.Fatal("reloadConfig() with invalid values succeeded, want error")
+	}
+	for _, want := range []string{"server.port", "redis.key_prefix"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("reloadConfig() error = %v, want mention of %s", err, want)
+		}
+	}
+	if v != loadedViper {
+		t.Error("reloadConfig() kept the rejected viper instance")
+	}
+	if Current() != loadedConfig || Current().Redis.Host != "redis-a:6379" {
+		t.Fatalf("Current() = %+v, want previous config to stay published", Current().Redis)
+	}
+	if notified {
+		t.Fatal("subscriber notified for rejected reload")
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	old := &Config{
+		Server: ServerConfig{Port: 8080, TrustedProxies: []string{"127.0.0.1"}},
+		Log:    LogConfig{Sampling: LogSamplingConfig{First: 100}},
+	}
+	next := *old
+	next.Server.Port = 9090
+	next.Server.TrustedProxies = []string{"127.0.0.1", "::1"}
+	next.Log.Sampling.First = 10
+	next.Redis.KeyPrefix = "app:"
+	next.ID.Epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	got := ChangedKeys(old, &next)
+	want := []string{"server.port", "server.trusted_proxies", "log.sampling.first", "id.epoch", "redis.key_prefix"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ChangedKeys() = %v, want %v", got, want)
+	}
+	if got := ChangedKeys(old, old); len(got) != 0 {
+		t.Fatalf("ChangedKeys(same) = %v, want none", got)
+	}
+	// 同一时刻在不同时区表示时不算变化
+	sameEpoch := next
+	sameEpoch.ID.Epoch = next.ID.Epoch.In(time.FixedZone("UTC+8", 8*3600))
+	if got := ChangedKeys(&next, &sameEpoch); len(got) != 0 {
+		t.Fatalf("ChangedKeys(same epoch in another zone) = %v, want none", got)
+	}
+}
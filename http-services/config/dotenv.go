@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// envFileEnv 指定 .env 文件路径；未设置时读取工作目录下的 .env（不存在则跳过）
+const envFileEnv = "HTTP_SERVICES_ENV_FILE"
+
+// loadEnvFile 把 .env 中的变量写入进程环境，已存在的环境变量优先，不会被覆盖。
+// 支持 KEY=VALUE、export KEY=VALUE、# 注释，以及单/双引号包裹的值（双引号内支持 \n 等转义）。
+func loadEnvFile() error {
+	path, explicit := os.LookupEnv(envFileEnv)
+	if !explicit || strings.TrimSpace(path) == "" {
+		path = filepath.Join(currentDirectory(), ".env")
+		explicit = false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("open env file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		key, value, ok, err := parseEnvLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseEnvLine 解析一行 .env；空行和注释返回 ok=false
+func parseEnvLine(line string) (key, value string, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, value, found := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false, errors.New("want KEY=VALUE")
+	}
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", "", false, errors.New("unterminated double quote")
+		}
+		if value, err = strconv.Unquote(value[:end+1]); err != nil {
+			return "", "", false, fmt.Errorf("invalid quoted value: %w", err)
+		}
+	case strings.HasPrefix(value, "'"):
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", "", false, errors.New("unterminated single quote")
+		}
+		value = value[1 : end+1]
+	default:
+		// 未加引号时 " #" 之后为行尾注释
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return key, value, true, nil
+}
+
+// closingQuote 返回与开头双引号配对的双引号下标，跳过转义
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEnvLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		key, want string
+		ok        bool
+		wantErr   bool
+	}{
+		{"", "", "", false, false},
+		{"# comment", "", "", false, false},
+		{"PLAIN=value", "PLAIN", "value", true, false},
+		{"export EXPORTED = spaced ", "EXPORTED", "spaced", true, false},
+		{"TRAILING=value # comment", "TRAILING", "value", true, false},
+		{"HASH=abc#def", "HASH", "abc#def", true, false},
+		{`DOUBLE="line1\nline2 # kept" # comment`, "DOUBLE", "line1\nline2 # kept", true, false},
+		{`SINGLE='raw\n $value'`, "SINGLE", `raw\n $value`, true, false},
+		{"EMPTY=", "EMPTY", "", true, false},
+		{"no equals sign", "", "", false, true},
+		{`OPEN="unterminated`, "", "", false, true},
+	}
+	for _, tt := range tests {
+		key, value, ok, err := parseEnvLine(tt.line)
+		if (err != nil) != tt.wantErr || ok != tt.ok || key != tt.key || value != tt.want {
+			t.Errorf("parseEnvLine(%q) = %q, %q, %v, %v; want %q, %q, %v, err %v",
+				tt.line, key, value, ok, err, tt.key, tt.want, tt.ok, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadEnvFile_KeepsExistingEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.env")
+	content := "HTTP_SERVICES_DOTENV_TEST_NEW=from-file\nHTTP_SERVICES_DOTENV_TEST_SET=from-file\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envFileEnv, path)
+	t.Setenv("HTTP_SERVICES_DOTENV_TEST_SET", "from-env")
+	t.Cleanup(func() { _ = os.Unsetenv("HTTP_SERVICES_DOTENV_TEST_NEW") })
+
+	if err := loadEnvFile(); err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+	if got := os.Getenv("HTTP_SERVICES_DOTENV_TEST_NEW"); got != "from-file" {
+		t.Errorf("new variable = %q, want from-file", got)
+	}
+	if got := os.Getenv("HTTP_SERVICES_DOTENV_TEST_SET"); got != "from-env" {
+		t.Errorf("existing variable = %q, want from-env", got)
+	}
+
+	t.Setenv(envFileEnv, filepath.Join(t.TempDir(), "missing.env"))
+	if err := loadEnvFile(); err == nil {
+		t.Error("loadEnvFile() with an explicit missing file succeeded, want error")
+	}
+}
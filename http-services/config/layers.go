@@ -0,0 +1,294 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// 配置按以下顺序分层叠加，后面的覆盖前面的：
+//  1. 内置默认值（setDefaults）
+//  2. 基础配置 config.yaml：--config 指定的文件，未指定时依次在工作目录、/etc/http-services 查找
+//  3. 环境配置 config.<env>.yaml：与基础配置同目录，env 来自 --config-env，未指定时使用运行模式（dev/release）
+//  4. 片段 conf.d/*.yaml：与基础配置同目录，按文件名字典序叠加
+//  5. 环境变量 HTTP_SERVICES_<SECTION>_<KEY>
+//
+// 文件中的字符串值支持 ${VAR} 与 ${VAR:-default} 引用环境变量，$${ 表示字面量 ${。
+// map 逐层深度合并，列表整体覆盖。
+
+const (
+	envPrefix     = "HTTP_SERVICES"
+	systemDir     = "/etc/http-services/"
+	fragmentsDir  = "conf.d"
+	defaultSource = "default"
+)
+
+// LoadOptions 指定 LoadConfigWith 查找的配置文件
+type LoadOptions struct {
+	File string // 基础配置文件路径；为空时在工作目录与 /etc/http-services 查找 config.yaml
+	Env  string // 环境名，叠加同目录下的 config.<Env>.yaml；为空时不叠加
+}
+
+// layers 是一次加载得到的 Viper 实例、参与合并的文件与每个 key 的来源
+type layers struct {
+	viper   *viper.Viper
+	dir     string
+	base    string
+	files   []string
+	sources map[string]string
+}
+
+// readLayers 按 opts 读取全部配置文件并合并到新的 Viper 实例，不修改任何全局状态
+func readLayers(opts LoadOptions) (*layers, error) {
+	l := &layers{
+		viper:   newViper(),
+		sources: make(map[string]string),
+	}
+
+	if opts.File != "" {
+		base, err := filepath.Abs(opts.File)
+		if err != nil {
+			return nil, fmt.Errorf("resolve config file: %w", err)
+		}
+		if _, err := os.Stat(base); err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		l.base = base
+	} else {
+		l.base = findBaseConfig(currentDirectory(), systemDir)
+	}
+	if l.base != "" {
+		l.dir = filepath.Dir(l.base)
+	} else {
+		l.dir = currentDirectory()
+	}
+
+	paths := []string{l.base}
+	if env := strings.TrimSpace(opts.Env); env != "" {
+		paths = append(paths, findConfigFile(l.dir, "config."+env))
+	}
+	fragments, err := listFragments(filepath.Join(l.dir, fragmentsDir))
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, fragments...)
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		settings, err := readLayerFile(path, l.sources)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.viper.MergeConfigMap(settings); err != nil {
+			return nil, fmt.Errorf("merge config file %s: %w", path, err)
+		}
+		l.files = append(l.files, path)
+	}
+	return l, nil
+}
+
+// newViper 创建带默认值与环境变量映射的 Viper 实例
+func newViper() *viper.Viper {
+	nv := viper.New()
+	nv.SetConfigType("yaml")
+	// 支持环境变量（自动转换：HTTP_SERVICES_SERVER_PORT）
+	nv.SetEnvPrefix(envPrefix)
+	nv.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	nv.AutomaticEnv()
+	setDefaults(nv)
+	return nv
+}
+
+// findBaseConfig 在 dirs 中依次查找 config.yaml / config.yml，找不到时返回空
+func findBaseConfig(dirs ...string) string {
+	for _, dir := range dirs {
+		if path := findConfigFile(dir, "config"); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// findConfigFile 查找 dir 下的 <name>.yaml / <name>.yml
+func findConfigFile(dir, name string) string {
+	for _, ext := range []string{".yaml", ".yml"} {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// listFragments 返回 conf.d 下按文件名排序的 yaml 片段，目录不存在时返回空
+func listFragments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isYAMLFile(entry.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// readLayerFile 解析单个配置文件，替换字符串中的 ${VAR}，并把其中每个 key 的来源记为该文件
+func readLayerFile(path string, sources map[string]string) (map[string]any, error) {
+	fv := viper.New()
+	fv.SetConfigFile(path)
+	fv.SetConfigType("yaml")
+	if err := fv.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	settings := fv.AllSettings()
+	for key, value := range settings {
+		interpolated, err := interpolateNode(key, value, path, sources)
+		if err != nil {
+			return nil, err
+		}
+		settings[key] = interpolated
+	}
+	return settings, nil
+}
+
+// interpolateNode 递归替换 node 中的环境变量引用，叶子节点（含整个列表）记录来源
+func interpolateNode(key string, node any, path string, sources map[string]string) (any, error) {
+	switch value := node.(type) {
+	case map[string]any:
+		for child, childValue := range value {
+			interpolated, err := interpolateNode(key+"."+child, childValue, path, sources)
+			if err != nil {
+				return nil, err
+			}
+			value[child] = interpolated
+		}
+		return value, nil
+	case []any:
+		var vars []string
+		for i, item := range value {
+			if s, ok := item.(string); ok {
+				expanded, used, err := interpolate(s)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+				}
+				value[i] = expanded
+				vars = append(vars, used...)
+			}
+		}
+		sources[key] = describeFileSource(path, vars)
+		return value, nil
+	case string:
+		expanded, vars, err := interpolate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		sources[key] = describeFileSource(path, vars)
+		return expanded, nil
+	default:
+		sources[key] = path
+		return node, nil
+	}
+}
+
+func describeFileSource(path string, vars []string) string {
+	if len(vars) == 0 {
+		return path
+	}
+	slices.Sort(vars)
+	vars = slices.Compact(vars)
+	return path + " via ${" + strings.Join(vars, "}, ${") + "}"
+}
+
+// interpolate 展开 ${VAR} 与 ${VAR:-default}，返回展开结果与引用到的变量名。
+// ${VAR:-default} 在变量未设置或为空时使用 default；$${ 输出字面量 ${
+func interpolate(s string) (string, []string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, nil
+	}
+	var b strings.Builder
+	var vars []string
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), vars, nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated ${ in %q", s)
+		}
+		b.WriteString(s[:start])
+		expr := s[start+2 : start+end]
+		name, fallback, hasDefault := strings.Cut(expr, ":-")
+		if !isEnvName(name) {
+			return "", nil, fmt.Errorf("invalid variable reference ${%s}", expr)
+		}
+		value := os.Getenv(name)
+		if value == "" && hasDefault {
+			value = fallback
+		}
+		b.WriteString(value)
+		vars = append(vars, name)
+		s = s[start+end+1:]
+	}
+}
+
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// envName 返回 key 对应的环境变量名，例如 server.port -> HTTP_SERVICES_SERVER_PORT
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// sourceOf 返回 key 当前取值的来源，优先级与读取时一致：<key>_file 先于 <key>（见 secretString），
+// 同一 key 的环境变量先于文件，都没有时为默认值
+func (l *layers) sourceOf(key string) string {
+	for _, candidate := range []string{key + "_file", key} {
+		if value, ok := os.LookupEnv(envName(candidate)); ok && value != "" {
+			return "env " + envName(candidate)
+		}
+		if source, ok := l.sources[candidate]; ok {
+			return source
+		}
+	}
+	return defaultSource
+}
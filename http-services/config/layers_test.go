@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("LAYER_HOST", "db.internal")
+	t.Setenv("LAYER_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		wantVars []string
+		wantErr  bool
+	}{
+		{"no reference", "plain", "plain", nil, false},
+		{"set variable", "${LAYER_HOST}:3306", "db.internal:3306", []string{"LAYER_HOST"}, false},
+		{"unset without default", "a${LAYER_MISSING}b", "ab", []string{"LAYER_MISSING"}, false},
+		{"default when unset", "${LAYER_MISSING:-8080}", "8080", []string{"LAYER_MISSING"}, false},
+		{"default when empty", "${LAYER_EMPTY:-fallback}", "fallback", []string{"LAYER_EMPTY"}, false},
+		{"set variable ignores default", "${LAYER_HOST:-x}", "db.internal", []string{"LAYER_HOST"}, false},
+		{"escaped", "$${LAYER_HOST}", "${LAYER_HOST}", nil, false},
+		{"unterminated", "${LAYER_HOST", "", nil, true},
+		{"invalid name", "${1HOST}", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, vars, err := interpolate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("interpolate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if len(vars) != len(tt.wantVars) || (len(vars) > 0 && vars[0] != tt.wantVars[0]) {
+				t.Errorf("interpolate(%q) vars = %v, want %v", tt.input, vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func writeLayerFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigWith_MergesLayersInOrder(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+
+	configDir := t.TempDir()
+	t.Chdir(t.TempDir()) // 工作目录下没有 config.yaml，只能通过 File 找到
+	writeLayerFile(t, filepath.Join(configDir, "app.yaml"), `server:
+  port: 8081
+  read_timeout: 5s
+  trusted_proxies: ["10.0.0.1"]
+redis:
+  host: ${LAYER_REDIS_HOST:-127.0.0.1:6379}
+jwt:
+  key: base-secret
+`)
+	writeLayerFile(t, filepath.Join(configDir, "config.staging.yaml"), "server:\n  port: 8082\n  write_timeout: 7s\n")
+	writeLayerFile(t, filepath.Join(configDir, "config.prod.yaml"), "server:\n  port: 9999\n")
+	writeLayerFile(t, filepath.Join(configDir, fragmentsDir, "20-proxies.yaml"), "server:\n  trusted_proxies: [\"10.0.0.2\", \"10.0.0.3\"]\n")
+	writeLayerFile(t, filepath.Join(configDir, fragmentsDir, "10-port.yaml"), "server:\n  port: 8083\n")
+	writeLayerFile(t, filepath.Join(configDir, fragmentsDir, "ignored.txt"), "server:\n  port: 1\n")
+	t.Setenv("LAYER_REDIS_HOST", "redis.internal:6379")
+	t.Setenv("HTTP_SERVICES_SERVER_IDLE_TIMEOUT", "9s")
+
+	if err := LoadConfigWith(LoadOptions{File: filepath.Join(configDir, "app.yaml"), Env: "staging"}); err != nil {
+		t.Fatalf("LoadConfigWith() error = %v", err)
+	}
+
+	cfg := Current().Server
+	if cfg.Port != 8083 {
+		t.Errorf("server.port = %d, want 8083 from conf.d/10-port.yaml", cfg.Port)
+	}
+	if cfg.ReadTimeout.String() != "5s" || cfg.WriteTimeout.String() != "7s" || cfg.IdleTimeout.String() != "9s" {
+		t.Errorf("timeouts = %v/%v/%v, want 5s/7s/9s", cfg.ReadTimeout, cfg.WriteTimeout, cfg.IdleTimeout)
+	}
+	if len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[0] != "10.0.0.2" {
+		t.Errorf("server.trusted_proxies = %v, want list replaced by conf.d/20-proxies.yaml", cfg.TrustedProxies)
+	}
+	if Current().Redis.Host != "redis.internal:6379" {
+		t.Errorf("redis.host = %q, want interpolated value", Current().Redis.Host)
+	}
+
+	sources := make(map[string]Setting)
+	for _, setting := range Settings() {
+		sources[setting.Key] = setting
+	}
+	wantSources := map[string]string{
+		"server.port":            filepath.Join(configDir, fragmentsDir, "10-port.yaml"),
+		"server.read_timeout":    filepath.Join(configDir, "app.yaml"),
+		"server.write_timeout":   filepath.Join(configDir, "config.staging.yaml"),
+		"server.idle_timeout":    "env HTTP_SERVICES_SERVER_IDLE_TIMEOUT",
+		"server.trusted_proxies": filepath.Join(configDir, fragmentsDir, "20-proxies.yaml"),
+		"redis.host":             filepath.Join(configDir, "app.yaml") + " via ${LAYER_REDIS_HOST}",
+		"server.host":            defaultSource,
+	}
+	for key, want := range wantSources {
+		if got := sources[key].Source; got != want {
+			t.Errorf("source of %s = %q, want %q", key, got, want)
+		}
+	}
+	if got := sources["jwt.key"].Value; got != redacted {
+		t.Errorf("jwt.key value = %v, want redacted", got)
+	}
+	if got := sources["admin.token"].Value; got != "" {
+		t.Errorf("empty admin.token value = %v, want empty", got)
+	}
+}
+
+func TestLoadConfigWith_MissingExplicitFile(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+
+	err := LoadConfigWith(LoadOptions{File: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Fatal("LoadConfigWith() with missing --config file succeeded, want error")
+	}
+	if Current() != originalConfig {
+		t.Fatal("failed load published a new config")
+	}
+}
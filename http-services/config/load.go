@@ -1,9 +1,17 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -12,50 +20,87 @@ import (
 
 var (
 	v *viper.Viper // Viper 实例
+
+	loadOpts LoadOptions // 最近一次 LoadConfigWith 的参数，热重载时沿用
+	loaded   *layers     // 当前生效配置对应的文件与来源
 )
 
-// LoadConfig 使用 Viper 加载配置
+// LoadConfig 按默认位置加载配置（不叠加环境配置），见 LoadConfigWith
 func LoadConfig() error {
-	v = viper.New()
+	return LoadConfigWith(LoadOptions{})
+}
 
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(currentDirectory())    // 工作目录
-	v.AddConfigPath("/etc/http-services/") // 系统目录
-
-	// 支持环境变量（自动转换：HTTP_SERVICES_SERVER_PORT）
-	v.SetEnvPrefix("HTTP_SERVICES")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	// 设置默认值
-	setDefaults()
-
-	// 读取配置文件
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// 配置文件不存在，使用默认值
-			zap.L().Warn("Config file not found, using defaults", zap.String("path", currentDirectory()))
-		} else {
-			// 配置文件存在但读取失败
-			return fmt.Errorf("failed to read config file: %w", err)
-		}
-	} else {
-		zap.L().Info("Config file loaded", zap.String("file", v.ConfigFileUsed()))
+// LoadConfigWith 读取 .env 与各层配置文件（分层规则见 layers.go），校验通过后发布为 Current()
+func LoadConfigWith(opts LoadOptions) error {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	if err := loadEnvFile(); err != nil {
+		return err
+	}
+	l, err := readLayers(opts)
+	if err != nil {
+		return err
+	}
+	if l.base == "" {
+		// 配置文件不存在，使用默认值
+		zap.L().Warn("Config file not found, using defaults", zap.String("path", currentDirectory()))
+	}
+	if len(l.files) > 0 {
+		zap.L().Info("Config file loaded", zap.Strings("files", l.files))
 	}
 
-	// 应用配置到全局变量
+	v, loadOpts, loaded = l.viper, opts, l
 	return applyConfig()
 }
 
+// Defaults 返回只由默认值构成的配置，不读取配置文件与环境变量，也不发布为 Current()，
+// 用于测试与嵌入式场景构建独立的配置；默认值没有 jwt.key 等必填项，因此不做校验
+func Defaults() (*Config, error) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	// buildConfig 读取包级 Viper 实例，在 publishMu 内临时换成只含默认值的实例
+	previous := v
+	v = viper.New()
+	setDefaults(v)
+	defer func() { v = previous }()
+	return buildConfig()
+}
+
+// reloadConfig 重新读取全部配置文件并发布；任何一步失败都保留当前的 Viper 实例与配置。
+// 基础配置文件沿用首次加载时找到的路径，文件暂时缺失（例如编辑器先删后写）时报错而不是退回默认值
+func reloadConfig() error {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	opts := loadOpts
+	if loaded != nil && loaded.base != "" {
+		opts.File = loaded.base
+	}
+	l, err := readLayers(opts)
+	if err != nil {
+		return err
+	}
+	previousViper := v
+	v = l.viper
+	if err := applyConfig(); err != nil {
+		v = previousViper
+		return err
+	}
+	loaded = l
+	return nil
+}
+
 // setDefaults 设置默认配置值
-func setDefaults() {
+func setDefaults(v *viper.Viper) {
 	// Server 默认配置
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.max_body_size", "10MB")
 	v.SetDefault("server.max_header_bytes", 1<<20) // 1MB
 	v.SetDefault("server.shutdown_timeout", "10s")
+	v.SetDefault("server.pre_stop_delay", "0s")
 	v.SetDefault("server.read_timeout", "30s")
 	v.SetDefault("server.write_timeout", "30s")
 	v.SetDefault("server.idle_timeout", "120s")
@@ -66,8 +111,20 @@ func setDefaults() {
 	v.SetDefault("server.static_dir", "./static")
 	v.SetDefault("server.trusted_proxies", []string{"127.0.0.1", "::1"})
 	v.SetDefault("server.enable_cors", true)
+	v.SetDefault("server.h2c", false)
+	v.SetDefault("server.unix_socket.path", "")
+	v.SetDefault("server.unix_socket.mode", "0660")
+	v.SetDefault("server.unix_socket.owner", "")
+	v.SetDefault("server.tls.enabled", false)
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.min_version", "1.2")
+	v.SetDefault("server.tls.cipher_suites", []string{})
+	v.SetDefault("server.tls.client_auth", "none")
+	v.SetDefault("server.tls.client_ca_file", "")
 
 	// JWT 默认配置
+	v.SetDefault("jwt.key", "")
 	v.SetDefault("jwt.expiration", "12h")
 
 	// Log 默认配置
@@ -75,109 +132,431 @@ func setDefaults() {
 	v.SetDefault("log.max_age", 30)  // 保留 30 天
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.gin_level", "")
+	v.SetDefault("log.gorm_level", "")
+	v.SetDefault("log.redis_level", "")
+	v.SetDefault("log.cron_level", "")
+	v.SetDefault("log.level_revert_after", "10m")
+	v.SetDefault("log.sinks", []any{})
+	v.SetDefault("log.compress", true)
+	v.SetDefault("log.max_total_size", "0") // 0 表示不限制
+	v.SetDefault("log.rotation", "daily")
+	v.SetDefault("log.sampling.enabled", true)
+	v.SetDefault("log.sampling.tick", "1s")
+	v.SetDefault("log.sampling.first", 100)
+	v.SetDefault("log.sampling.thereafter", 100)
+	v.SetDefault("log.sampling.exempt_levels", []string{"warn", "error"})
+
+	// Admin 默认配置
+	v.SetDefault("admin.token", "")
+	v.SetDefault("admin.diagnostics_token", "")
+	v.SetDefault("admin.listen", "")
+
+	// Audit 默认配置
+	v.SetDefault("audit.enabled", false)
+	v.SetDefault("audit.sinks", []string{"file"})
+	v.SetDefault("audit.file", "log/audit.jsonl")
+	v.SetDefault("audit.actor_claim", "user_id")
+
+	// ID 默认配置
+	v.SetDefault("id.machine_id", -1)
+	v.SetDefault("id.redis_lease", false)
+	v.SetDefault("id.lease_ttl", "30s")
+	v.SetDefault("id.epoch", "2014-09-01")
+	v.SetDefault("id.max_clock_backward", "1s")
+
+	// Password 默认配置
+	v.SetDefault("password.algorithm", "argon2id")
+	v.SetDefault("password.argon2_memory", 64*1024)
+	v.SetDefault("password.argon2_time", 3)
+	v.SetDefault("password.argon2_parallelism", 4)
+	v.SetDefault("password.scrypt_log_n", 15)
+	v.SetDefault("password.scrypt_r", 8)
+	v.SetDefault("password.scrypt_p", 1)
+	v.SetDefault("password.bcrypt_cost", 12)
+	v.SetDefault("password.pepper", "")
+	v.SetDefault("password.allow_unpeppered", false)
+
+	// Encryption 默认配置
+	v.SetDefault("encryption.keys", []string{})
+	v.SetDefault("encryption.primary_key", "")
+	v.SetDefault("encryption.blind_index_key", "")
 
 	// Database 默认配置
 	v.SetDefault("database.mysql_dsn", "")
+	v.SetDefault("database.replica_dsns", []string{})
+	v.SetDefault("database.replica_policy", "random")
+	v.SetDefault("database.max_idle_conns", 25)
+	v.SetDefault("database.max_open_conns", 100)
+	v.SetDefault("database.conn_max_lifetime", "1h")
+	v.SetDefault("database.conn_max_idle_time", "30m")
+	v.SetDefault("database.slow_threshold", "200ms")
 
 	// Redis 默认配置
 	v.SetDefault("redis.host", "127.0.0.1:6379")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.key_prefix", "")
+
+	// Queue 默认配置
+	v.SetDefault("queue.enabled", false)
+	v.SetDefault("queue.name", "default")
+	v.SetDefault("queue.workers", 4)
+	v.SetDefault("queue.poll_interval", "1s")
+	v.SetDefault("queue.job_timeout", "5m")
+	v.SetDefault("queue.max_attempts", 5)
+	v.SetDefault("queue.retry_base", "10s")
+	v.SetDefault("queue.retry_max", "30m")
+
+	// Outbox 默认配置
+	v.SetDefault("outbox.enabled", false)
+	v.SetDefault("outbox.poll_interval", "1s")
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 20)
+	v.SetDefault("outbox.retry_base", "1s")
+	v.SetDefault("outbox.retry_max", "5m")
+	v.SetDefault("outbox.retention", "168h")
+	v.SetDefault("outbox.cleanup_interval", "1h")
+	v.SetDefault("outbox.stream_prefix", "outbox:")
+	v.SetDefault("outbox.stream_max_len", 100000)
 }
 
-// applyConfig 将 Viper 配置应用到全局变量
+// applyConfig 从 Viper 构建并校验一份新配置，通过后整体发布；任何一项出错都不会影响当前生效的配置
 func applyConfig() error {
-	// Server 配置
-	ListenHost = strings.TrimSpace(v.GetString("server.host"))
-	ListenPort = v.GetInt("server.port")
-
-	// 解析大小字符串
-	maxBodySizeStr := v.GetString("server.max_body_size")
-	size, err := parseSize(maxBodySizeStr)
+	cfg, err := buildConfig()
 	if err != nil {
-		return fmt.Errorf("invalid max_body_size: %w", err)
+		return err
 	}
-	MaxBodySize = size
-
-	MaxHeaderBytes = v.GetInt("server.max_header_bytes")
-
-	// 解析超时时间
-	ShutdownTimeout = v.GetDuration("server.shutdown_timeout")
-	ReadTimeout = v.GetDuration("server.read_timeout")
-	WriteTimeout = v.GetDuration("server.write_timeout")
-	IdleTimeout = v.GetDuration("server.idle_timeout")
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	publish(cfg)
+	return nil
+}
 
-	// 限流配置
-	EnableRateLimit = v.GetBool("server.enable_rate_limit")
-	GlobalRateLimit = v.GetInt("server.global_rate_limit")
-	GlobalRateBurst = v.GetInt("server.global_rate_burst")
+// buildConfig 读取 Viper 中的值并做规范化（去空白、相对路径、单位换算、解析密钥），不修改任何全局状态
+func buildConfig() (*Config, error) {
+	cfg := &Config{}
+	var err error
 
+	// Server 配置
+	server := &cfg.Server
+	server.Host = strings.TrimSpace(v.GetString("server.host"))
+	server.Port = v.GetInt("server.port")
+	if server.MaxBodySize, err = parseSize(v.GetString("server.max_body_size")); err != nil {
+		return nil, fmt.Errorf("invalid server.max_body_size: %w", err)
+	}
+	server.MaxHeaderBytes = v.GetInt("server.max_header_bytes")
+	server.ShutdownTimeout = v.GetDuration("server.shutdown_timeout")
+	server.PreStopDelay = v.GetDuration("server.pre_stop_delay")
+	server.ReadTimeout = v.GetDuration("server.read_timeout")
+	server.WriteTimeout = v.GetDuration("server.write_timeout")
+	server.IdleTimeout = v.GetDuration("server.idle_timeout")
+	server.EnableRateLimit = v.GetBool("server.enable_rate_limit")
+	server.GlobalRateLimit = v.GetInt("server.global_rate_limit")
+	server.GlobalRateBurst = v.GetInt("server.global_rate_burst")
 	// pid 文件（相对路径基于程序所在目录）
-	PidFile = strings.TrimSpace(v.GetString("server.pid_file"))
-	if PidFile != "" && !filepath.IsAbs(PidFile) {
-		PidFile = filepath.Join(AbsPath, PidFile)
+	server.PidFile = strings.TrimSpace(v.GetString("server.pid_file"))
+	if server.PidFile != "" && !filepath.IsAbs(server.PidFile) {
+		server.PidFile = filepath.Join(AbsPath, server.PidFile)
 	}
-	StaticDir = strings.TrimSpace(v.GetString("server.static_dir"))
-	TrustedProxies = getStringSlice("server.trusted_proxies")
-	EnableCORS = v.GetBool("server.enable_cors")
+	server.StaticDir = strings.TrimSpace(v.GetString("server.static_dir"))
+	server.TrustedProxies = getStringSlice("server.trusted_proxies")
+	server.EnableCORS = v.GetBool("server.enable_cors")
+	server.H2C = v.GetBool("server.h2c")
+	server.UnixSocket.Path = strings.TrimSpace(v.GetString("server.unix_socket.path"))
+	if server.UnixSocket.Path != "" && !filepath.IsAbs(server.UnixSocket.Path) {
+		server.UnixSocket.Path = filepath.Join(AbsPath, server.UnixSocket.Path)
+	}
+	// 权限按八进制解析，"660" 与 "0660" 等价
+	mode, err := strconv.ParseUint(strings.TrimSpace(v.GetString("server.unix_socket.mode")), 8, 32)
+	if err != nil || mode > 0o777 {
+		return nil, fmt.Errorf("invalid server.unix_socket.mode %q: want octal permission bits such as 0660", v.GetString("server.unix_socket.mode"))
+	}
+	server.UnixSocket.Mode = os.FileMode(mode)
+	server.UnixSocket.Owner = strings.TrimSpace(v.GetString("server.unix_socket.owner"))
+	server.TLS.Enabled = v.GetBool("server.tls.enabled")
+	server.TLS.CertFile = resolvePath(v.GetString("server.tls.cert_file"))
+	server.TLS.KeyFile = resolvePath(v.GetString("server.tls.key_file"))
+	server.TLS.MinVersion = strings.TrimSpace(v.GetString("server.tls.min_version"))
+	server.TLS.CipherSuites = getStringSlice("server.tls.cipher_suites")
+	server.TLS.ClientAuth = strings.ToLower(strings.TrimSpace(v.GetString("server.tls.client_auth")))
+	server.TLS.ClientCAFile = resolvePath(v.GetString("server.tls.client_ca_file"))
 
 	// JWT 配置
-	JWTKey = v.GetString("jwt.key")
-	JWTExpiration = v.GetDuration("jwt.expiration")
+	if cfg.JWT.Key, err = secretString("jwt.key"); err != nil {
+		return nil, err
+	}
+	cfg.JWT.Expiration = v.GetDuration("jwt.expiration")
 
 	// Log 配置
-	LogMaxSize = v.GetInt("log.max_size")
-	LogMaxAge = v.GetInt("log.max_age")
-	LogLevel = v.GetString("log.level")
-	GinLogLevel = v.GetString("log.gin_level")
+	logCfg := &cfg.Log
+	logCfg.MaxSize = v.GetInt("log.max_size")
+	logCfg.MaxAge = v.GetInt("log.max_age")
+	logCfg.Level = v.GetString("log.level")
+	logCfg.GinLevel = v.GetString("log.gin_level")
+	logCfg.GormLevel = v.GetString("log.gorm_level")
+	logCfg.RedisLevel = v.GetString("log.redis_level")
+	logCfg.CronLevel = v.GetString("log.cron_level")
+	logCfg.LevelRevertAfter = v.GetDuration("log.level_revert_after")
+	if logCfg.Sinks, err = getLogSinks(); err != nil {
+		return nil, err
+	}
+	logCfg.Compress = v.GetBool("log.compress")
+	if totalSize := strings.TrimSpace(v.GetString("log.max_total_size")); totalSize != "" && totalSize != "0" {
+		if logCfg.MaxTotalSize, err = parseSize(totalSize); err != nil {
+			return nil, fmt.Errorf("invalid log.max_total_size: %w", err)
+		}
+	}
+	logCfg.Rotation = strings.ToLower(strings.TrimSpace(v.GetString("log.rotation")))
+	logCfg.Sampling = LogSamplingConfig{
+		Enabled:      v.GetBool("log.sampling.enabled"),
+		Tick:         v.GetDuration("log.sampling.tick"),
+		First:        v.GetInt("log.sampling.first"),
+		Thereafter:   v.GetInt("log.sampling.thereafter"),
+		ExemptLevels: getStringSlice("log.sampling.exempt_levels"),
+	}
+
+	// Admin 配置
+	adminToken, err := secretString("admin.token")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Admin.Token = strings.TrimSpace(adminToken)
+	diagnosticsToken, err := secretString("admin.diagnostics_token")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Admin.DiagnosticsToken = strings.TrimSpace(diagnosticsToken)
+	cfg.Admin.Listen = strings.TrimSpace(v.GetString("admin.listen"))
+
+	// Audit 配置
+	audit := &cfg.Audit
+	audit.Enabled = v.GetBool("audit.enabled")
+	for _, sink := range getStringSlice("audit.sinks") {
+		audit.Sinks = append(audit.Sinks, strings.ToLower(sink))
+	}
+	audit.File = strings.TrimSpace(v.GetString("audit.file"))
+	if audit.File == "" {
+		audit.File = "log/audit.jsonl"
+	}
+	if !filepath.IsAbs(audit.File) {
+		audit.File = filepath.Join(AbsPath, audit.File)
+	}
+	audit.ActorClaim = strings.TrimSpace(v.GetString("audit.actor_claim"))
+	if audit.ActorClaim == "" {
+		audit.ActorClaim = "user_id"
+	}
+
+	// ID 配置
+	cfg.ID.MachineID = v.GetInt("id.machine_id")
+	cfg.ID.RedisLease = v.GetBool("id.redis_lease")
+	cfg.ID.LeaseTTL = v.GetDuration("id.lease_ttl")
+	if cfg.ID.Epoch, err = parseEpoch(v.GetString("id.epoch")); err != nil {
+		return nil, err
+	}
+	cfg.ID.MaxClockBackward = v.GetDuration("id.max_clock_backward")
+
+	// Password 配置
+	password := &cfg.Password
+	password.Algorithm = strings.ToLower(strings.TrimSpace(v.GetString("password.algorithm")))
+	password.Argon2Memory = v.GetInt("password.argon2_memory")
+	password.Argon2Time = v.GetInt("password.argon2_time")
+	password.Argon2Parallelism = v.GetInt("password.argon2_parallelism")
+	password.ScryptLogN = v.GetInt("password.scrypt_log_n")
+	password.ScryptR = v.GetInt("password.scrypt_r")
+	password.ScryptP = v.GetInt("password.scrypt_p")
+	password.BcryptCost = v.GetInt("password.bcrypt_cost")
+	if password.Pepper, err = secretString("password.pepper"); err != nil {
+		return nil, err
+	}
+	password.AllowUnpeppered = v.GetBool("password.allow_unpeppered")
+
+	// Encryption 配置
+	if cfg.Encryption, err = getEncryptionConfig(); err != nil {
+		return nil, err
+	}
 
 	// Database 配置
-	MysqlDSN = v.GetString("database.mysql_dsn")
+	database := &cfg.Database
+	if database.MysqlDSN, err = secretString("database.mysql_dsn"); err != nil {
+		return nil, err
+	}
+	replicaDSNs, err := secretStringSlice("database.replica_dsns")
+	if err != nil {
+		return nil, err
+	}
+	for _, dsn := range replicaDSNs {
+		if dsn != "" {
+			database.ReplicaDSNs = append(database.ReplicaDSNs, dsn)
+		}
+	}
+	database.ReplicaPolicy = strings.ToLower(strings.TrimSpace(v.GetString("database.replica_policy")))
+	database.MaxIdleConns = v.GetInt("database.max_idle_conns")
+	database.MaxOpenConns = v.GetInt("database.max_open_conns")
+	database.ConnMaxLifetime = v.GetDuration("database.conn_max_lifetime")
+	database.ConnMaxIdleTime = v.GetDuration("database.conn_max_idle_time")
+	database.SlowThreshold = v.GetDuration("database.slow_threshold")
 
 	// Redis 配置
-	RedisHost = strings.TrimSpace(v.GetString("redis.host"))
-	RedisPassword = v.GetString("redis.password")
-	RedisKeyPrefix = strings.TrimSpace(v.GetString("redis.key_prefix"))
+	cfg.Redis.Host = strings.TrimSpace(v.GetString("redis.host"))
+	if cfg.Redis.Password, err = secretString("redis.password"); err != nil {
+		return nil, err
+	}
+	cfg.Redis.KeyPrefix = strings.TrimSpace(v.GetString("redis.key_prefix"))
+
+	// Queue 配置
+	queue := &cfg.Queue
+	queue.Enabled = v.GetBool("queue.enabled")
+	queue.Name = strings.TrimSpace(v.GetString("queue.name"))
+	if queue.Name == "" {
+		queue.Name = "default"
+	}
+	queue.Workers = v.GetInt("queue.workers")
+	queue.PollInterval = v.GetDuration("queue.poll_interval")
+	queue.JobTimeout = v.GetDuration("queue.job_timeout")
+	queue.MaxAttempts = v.GetInt("queue.max_attempts")
+	queue.RetryBase = v.GetDuration("queue.retry_base")
+	queue.RetryMax = v.GetDuration("queue.retry_max")
+
+	// Outbox 配置
+	outbox := &cfg.Outbox
+	outbox.Enabled = v.GetBool("outbox.enabled")
+	outbox.PollInterval = v.GetDuration("outbox.poll_interval")
+	outbox.BatchSize = v.GetInt("outbox.batch_size")
+	outbox.MaxAttempts = v.GetInt("outbox.max_attempts")
+	outbox.RetryBase = v.GetDuration("outbox.retry_base")
+	outbox.RetryMax = v.GetDuration("outbox.retry_max")
+	outbox.Retention = v.GetDuration("outbox.retention")
+	outbox.CleanupInterval = v.GetDuration("outbox.cleanup_interval")
+	outbox.StreamPrefix = strings.TrimSpace(v.GetString("outbox.stream_prefix"))
+	outbox.StreamMaxLen = v.GetInt64("outbox.stream_max_len")
+
+	return cfg, nil
+}
 
-	return nil
+// resolvePath 去掉首尾空白，相对路径按 AbsPath 解析；空值保持为空
+func resolvePath(value string) string {
+	value = strings.TrimSpace(value)
+	if value != "" && !filepath.IsAbs(value) {
+		value = filepath.Join(AbsPath, value)
+	}
+	return value
 }
 
 func getStringSlice(key string) []string {
-	values := v.GetStringSlice(key)
-	if len(values) == 1 && strings.Contains(values[0], ",") {
-		values = strings.Split(values[0], ",")
-	}
-	for index := range values {
-		values[index] = strings.TrimSpace(values[index])
+	// 环境变量会先被 viper 按空白切分，"a, b" 得到 ["a,", "b"]，因此每一段都再按逗号拆分
+	var values []string
+	for _, value := range v.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
 	}
 	return values
 }
 
-// WatchConfig 监听配置文件变化并自动重新加载（热重载）
+// WatchConfig 监听配置文件变化并自动重新加载（热重载）。监听基础配置所在目录与其中的 conf.d，
+// 任一层的 yaml 文件（包括新增或删除的片段）变化都会重新合并全部文件
 func WatchConfig(onChange func()) {
-	if v == nil || v.ConfigFileUsed() == "" {
+	publishMu.Lock()
+	l := loaded
+	publishMu.Unlock()
+	if v == nil || l == nil || len(l.files) == 0 {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		zap.L().Error("Failed to watch config files", zap.Error(err))
 		return
 	}
-	v.WatchConfig()
-	v.OnConfigChange(func(e fsnotify.Event) {
-		zap.L().Info("Config file changed, reloading...",
-			zap.String("file", e.Name),
-			zap.String("op", e.Op.String()),
-		)
-
-		// 重新应用配置
-		if err := applyConfig(); err != nil {
-			zap.L().Error("Failed to reload config", zap.Error(err))
-			return
+	for _, dir := range []string{l.dir, filepath.Join(l.dir, fragmentsDir)} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
 		}
+		if err := watcher.Add(dir); err != nil {
+			zap.L().Warn("Failed to watch config directory", zap.String("dir", dir), zap.Error(err))
+		}
+	}
 
-		// 执行回调
-		if onChange != nil {
-			onChange()
+	go func() {
+		for {
+			select {
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Kubernetes ConfigMap 通过替换 ..data 符号链接整体更新
+				if e.Op == fsnotify.Chmod || !(isYAMLFile(e.Name) || filepath.Base(e.Name) == "..data") {
+					continue
+				}
+				zap.L().Info("Config file changed, reloading...",
+					zap.String("file", e.Name),
+					zap.String("op", e.Op.String()),
+				)
+
+				// 新配置整体校验通过才会替换，失败时继续使用当前配置
+				if err := reloadConfig(); err != nil {
+					zap.L().Error("Failed to reload config, keeping current config", zap.Error(err))
+					continue
+				}
+
+				// 执行回调
+				if onChange != nil {
+					onChange()
+				}
+
+				zap.L().Info("Config reloaded successfully")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Warn("Config watcher error", zap.Error(err))
+			}
 		}
+	}()
+}
+
+// Setting 是一个配置项合并后的原始取值及其来源
+type Setting struct {
+	Key    string
+	Value  any
+	Source string // 文件路径（经 ${VAR} 展开时附带变量名）、"env <变量名>" 或 "default"
+}
+
+// Settings 返回当前加载的全部配置项，按 key 排序；敏感项的非空值以 ****** 代替
+func Settings() []Setting {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	if v == nil || loaded == nil {
+		return nil
+	}
 
-		zap.L().Info("Config reloaded successfully")
-	})
+	keys := v.AllKeys()
+	slices.Sort(keys)
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		value := v.Get(key)
+		if isSecretKey(key) && !isEmptyValue(value) {
+			value = redacted
+		} else if key == "log.sinks" {
+			value = redactLogSinks(value)
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: loaded.sourceOf(key)})
+	}
+	return settings
+}
+
+func isEmptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
 }
 
 // GetViper 返回 Viper 实例（用于高级用法）
@@ -185,25 +564,103 @@ func GetViper() *viper.Viper {
 	return v
 }
 
-// parseSize 解析大小字符串（支持 KB, MB, GB）
+// parseEpoch 解析 id.epoch，支持 RFC3339 或 2006-01-02（UTC）
+func parseEpoch(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	epoch, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if epoch, err = time.Parse(time.DateOnly, value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid id.epoch %q: want RFC3339 or YYYY-MM-DD", value)
+		}
+	}
+	if epoch.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("invalid id.epoch %q: in the future", value)
+	}
+	return epoch.UTC(), nil
+}
+
+// parseSize 解析大小字符串（支持 B, KB, MB, GB，不带单位按字节），拒绝负数与溢出
 func parseSize(sizeStr string) (int64, error) {
-	var size int64
-	var unit string
-	_, err := fmt.Sscanf(sizeStr, "%d%s", &size, &unit)
+	sizeStr = strings.TrimSpace(sizeStr)
+	digits := strings.IndexFunc(sizeStr, func(r rune) bool { return r < '0' || r > '9' })
+	if digits == -1 {
+		digits = len(sizeStr)
+	}
+	if digits == 0 {
+		return 0, fmt.Errorf("invalid size %q: want a non-negative number with optional unit B/KB/MB/GB", sizeStr)
+	}
+	size, err := strconv.ParseInt(sizeStr[:digits], 10, 64)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("invalid size %q: %w", sizeStr, err)
 	}
 
-	switch strings.ToUpper(unit) {
+	var multiplier int64
+	switch unit := strings.ToUpper(strings.TrimSpace(sizeStr[digits:])); unit {
 	case "B", "":
-		return size, nil
+		multiplier = 1
 	case "KB", "K":
-		return size * 1024, nil
+		multiplier = 1 << 10
 	case "MB", "M":
-		return size * 1024 * 1024, nil
+		multiplier = 1 << 20
 	case "GB", "G":
-		return size * 1024 * 1024 * 1024, nil
+		multiplier = 1 << 30
 	default:
 		return 0, fmt.Errorf("unknown size unit: %s", unit)
 	}
+	if size > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("invalid size %q: overflows int64", sizeStr)
+	}
+	return size * multiplier, nil
+}
+
+// getEncryptionConfig 解析字段加密密钥。encryption.keys 每项为 "<key id>:<base64 编码的 32 字节密钥>"，
+// primary_key 为空时使用第一项；密钥内容不会出现在错误信息中
+func getEncryptionConfig() (EncryptionConfig, error) {
+	keys := make(map[string][]byte)
+	var first string
+	entries, err := secretStringSlice("encryption.keys")
+	if err != nil {
+		return EncryptionConfig{}, err
+	}
+	for _, entry := range entries {
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return EncryptionConfig{}, errors.New("invalid encryption.keys entry: want <key id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return EncryptionConfig{}, fmt.Errorf("invalid encryption key %q: want base64 of 32 random bytes", id)
+		}
+		if _, dup := keys[id]; dup {
+			return EncryptionConfig{}, fmt.Errorf("duplicate encryption key id %q", id)
+		}
+		if first == "" {
+			first = id
+		}
+		keys[id] = key
+	}
+	primary := strings.TrimSpace(v.GetString("encryption.primary_key"))
+	if primary == "" {
+		primary = first
+	}
+	if len(keys) > 0 {
+		if _, ok := keys[primary]; !ok {
+			return EncryptionConfig{}, fmt.Errorf("encryption.primary_key %q not found in encryption.keys", primary)
+		}
+	} else if primary != "" {
+		return EncryptionConfig{}, fmt.Errorf("encryption.primary_key %q set without encryption.keys", primary)
+	}
+	var blindKey []byte
+	blindIndexKey, err := secretString("encryption.blind_index_key")
+	if err != nil {
+		return EncryptionConfig{}, err
+	}
+	if encoded := strings.TrimSpace(blindIndexKey); encoded != "" {
+		if blindKey, err = base64.StdEncoding.DecodeString(encoded); err != nil || len(blindKey) < 32 {
+			return EncryptionConfig{}, errors.New("invalid encryption.blind_index_key: want base64 of at least 32 random bytes")
+		}
+	}
+
+	return EncryptionConfig{Keys: keys, PrimaryKey: primary, BlindIndexKey: blindKey}, nil
 }
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"bytes"
+	"encoding/base64"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,6 +27,10 @@ func TestParseSize(t *testing.T) {
 		{"short form g", "2G", 2 * 1024 * 1024 * 1024, false},
 		{"invalid format", "invalid", 0, true},
 		{"unknown unit", "10XB", 0, true},
+		{"plain bytes", "1048576", 1048576, false},
+		{"space before unit", "10 MB", 10 * 1024 * 1024, false},
+		{"negative", "-5MB", 0, true},
+		{"overflow", "9999999999999GB", 0, true},
 	}
 
 	for _, tt := range tests {
@@ -57,13 +64,44 @@ func TestSetDefaults(t *testing.T) {
 		{"pid file", "server.pid_file", "http-services.pid"},
 		{"jwt expiration", "jwt.expiration", "12h"},
 		{"log max size", "log.max_size", 50},
+		{"log gorm level", "log.gorm_level", ""},
+		{"log level revert after", "log.level_revert_after", "10m"},
+		{"admin token", "admin.token", ""},
+		{"audit enabled", "audit.enabled", false},
+		{"audit file", "audit.file", "log/audit.jsonl"},
+		{"audit actor claim", "audit.actor_claim", "user_id"},
+		{"id machine id", "id.machine_id", -1},
+		{"id redis lease", "id.redis_lease", false},
+		{"id epoch", "id.epoch", "2014-09-01"},
+		{"password algorithm", "password.algorithm", "argon2id"},
+		{"password argon2 memory", "password.argon2_memory", 64 * 1024},
+		{"password bcrypt cost", "password.bcrypt_cost", 12},
+		{"password pepper", "password.pepper", ""},
+		{"encryption primary key", "encryption.primary_key", ""},
+		{"encryption blind index key", "encryption.blind_index_key", ""},
+		{"log compress", "log.compress", true},
+		{"log rotation", "log.rotation", "daily"},
+		{"log sampling enabled", "log.sampling.enabled", true},
 		{"enable rate limit", "server.enable_rate_limit", false},
 		{"static directory", "server.static_dir", "./static"},
 		{"enable cors", "server.enable_cors", true},
+		{"h2c", "server.h2c", false},
+		{"unix socket path", "server.unix_socket.path", ""},
+		{"unix socket mode", "server.unix_socket.mode", "0660"},
+		{"admin listen", "admin.listen", ""},
+		{"tls enabled", "server.tls.enabled", false},
+		{"tls min version", "server.tls.min_version", "1.2"},
+		{"tls client auth", "server.tls.client_auth", "none"},
 		{"database mysql dsn", "database.mysql_dsn", ""},
+		{"database replica policy", "database.replica_policy", "random"},
+		{"database max open conns", "database.max_open_conns", 100},
 		{"redis host", "redis.host", "127.0.0.1:6379"},
 		{"redis password", "redis.password", ""},
 		{"redis key prefix", "redis.key_prefix", ""},
+		{"queue enabled", "queue.enabled", false},
+		{"queue name", "queue.name", "default"},
+		{"queue workers", "queue.workers", 4},
+		{"queue max attempts", "queue.max_attempts", 5},
 	}
 
 	for _, tt := range tests {
@@ -76,48 +114,117 @@ func TestSetDefaults(t *testing.T) {
 	}
 }
 
+func TestDefaultsIgnoresEnvAndCurrent(t *testing.T) {
+	t.Setenv("HTTP_SERVICES_SERVER_PORT", "9999")
+	before := Current()
+
+	cfg, err := Defaults()
+	if err != nil {
+		t.Fatalf("Defaults() error = %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.Server.ShutdownTimeout != 10*time.Second || cfg.Redis.Host != "127.0.0.1:6379" {
+		t.Fatalf("Defaults() server = %+v, redis = %+v", cfg.Server, cfg.Redis)
+	}
+	if Current() != before {
+		t.Fatal("Defaults() published a new config")
+	}
+}
+
 func TestApplyConfig(t *testing.T) {
 	// 初始化配置
 	err := LoadConfig()
 	if err != nil {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
+	cfg := Current()
+
+	// 检查配置是否正确设置
+	if cfg.Server.Port != 8080 {
+		t.Errorf("Server.Port = %d, want 8080", cfg.Server.Port)
+	}
+	if cfg.Server.Host != "0.0.0.0" || cfg.Server.StaticDir != "./static" || !cfg.Server.EnableCORS {
+		t.Errorf("server exceptions = host %q static %q cors %v", cfg.Server.Host, cfg.Server.StaticDir, cfg.Server.EnableCORS)
+	}
+	if len(cfg.Server.TrustedProxies) != 2 || cfg.Server.TrustedProxies[0] != "127.0.0.1" || cfg.Server.TrustedProxies[1] != "::1" {
+		t.Errorf("Server.TrustedProxies = %#v", cfg.Server.TrustedProxies)
+	}
+
+	if cfg.Server.MaxBodySize != 10*1024*1024 {
+		t.Errorf("Server.MaxBodySize = %d, want %d", cfg.Server.MaxBodySize, 10*1024*1024)
+	}
+
+	if cfg.JWT.Expiration != 12*time.Hour {
+		t.Errorf("JWT.Expiration = %v, want %v", cfg.JWT.Expiration, 12*time.Hour)
+	}
+
+	if filepath.Base(cfg.Server.PidFile) != "http-services.pid" {
+		t.Errorf("Server.PidFile = %s, want base http-services.pid", cfg.Server.PidFile)
+	}
+
+	if cfg.Log.MaxSize != 50 {
+		t.Errorf("Log.MaxSize = %d, want 50", cfg.Log.MaxSize)
+	}
 
-	// 检查全局变量是否正确设置
-	if ListenPort != 8080 {
-		t.Errorf("ListenPort = %d, want 8080", ListenPort)
+	if cfg.Log.GormLevel != "" || cfg.Log.RedisLevel != "" || cfg.Log.CronLevel != "" || cfg.Log.LevelRevertAfter != 10*time.Minute || cfg.Admin.Token != "" {
+		t.Errorf("log level config = gorm %q redis %q cron %q revert %v admin token set %v",
+			cfg.Log.GormLevel, cfg.Log.RedisLevel, cfg.Log.CronLevel, cfg.Log.LevelRevertAfter, cfg.Admin.Token != "")
 	}
-	if ListenHost != "0.0.0.0" || StaticDir != "./static" || !EnableCORS {
-		t.Errorf("server exceptions = host %q static %q cors %v", ListenHost, StaticDir, EnableCORS)
+
+	if !cfg.Log.Compress || cfg.Log.MaxTotalSize != 0 || cfg.Log.Rotation != "daily" || !cfg.Log.Sampling.Enabled ||
+		cfg.Log.Sampling.First != 100 || cfg.Log.Sampling.Thereafter != 100 || len(cfg.Log.Sampling.ExemptLevels) != 2 {
+		t.Errorf("log retention config = compress %v total %d rotation %q sampling %v %d/%d exempt %v",
+			cfg.Log.Compress, cfg.Log.MaxTotalSize, cfg.Log.Rotation, cfg.Log.Sampling.Enabled, cfg.Log.Sampling.First, cfg.Log.Sampling.Thereafter, cfg.Log.Sampling.ExemptLevels)
 	}
-	if len(TrustedProxies) != 2 || TrustedProxies[0] != "127.0.0.1" || TrustedProxies[1] != "::1" {
-		t.Errorf("TrustedProxies = %#v", TrustedProxies)
+
+	if cfg.Audit.Enabled || len(cfg.Audit.Sinks) != 1 || cfg.Audit.Sinks[0] != "file" ||
+		filepath.Base(cfg.Audit.File) != "audit.jsonl" || !filepath.IsAbs(cfg.Audit.File) || cfg.Audit.ActorClaim != "user_id" {
+		t.Errorf("audit config = enabled %v sinks %v file %q actor claim %q", cfg.Audit.Enabled, cfg.Audit.Sinks, cfg.Audit.File, cfg.Audit.ActorClaim)
 	}
 
-	if MaxBodySize != 10*1024*1024 {
-		t.Errorf("MaxBodySize = %d, want %d", MaxBodySize, 10*1024*1024)
+	if cfg.ID.MachineID != -1 || cfg.ID.RedisLease || cfg.ID.LeaseTTL != 30*time.Second ||
+		!cfg.ID.Epoch.Equal(time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC)) || cfg.ID.MaxClockBackward != time.Second {
+		t.Errorf("id config = machine %d lease %v ttl %v epoch %v max backward %v",
+			cfg.ID.MachineID, cfg.ID.RedisLease, cfg.ID.LeaseTTL, cfg.ID.Epoch, cfg.ID.MaxClockBackward)
 	}
 
-	if JWTExpiration != 12*time.Hour {
-		t.Errorf("JWTExpiration = %v, want %v", JWTExpiration, 12*time.Hour)
+	if cfg.Password.Algorithm != "argon2id" || cfg.Password.Argon2Memory != 64*1024 || cfg.Password.Argon2Time != 3 ||
+		cfg.Password.Argon2Parallelism != 4 || cfg.Password.ScryptLogN != 15 || cfg.Password.ScryptR != 8 || cfg.Password.ScryptP != 1 ||
+		cfg.Password.BcryptCost != 12 || cfg.Password.Pepper != "" || cfg.Password.AllowUnpeppered {
+		t.Errorf("password config = %s argon2 m=%d t=%d p=%d scrypt ln=%d r=%d p=%d bcrypt %d pepper %q allow unpeppered %v",
+			cfg.Password.Algorithm, cfg.Password.Argon2Memory, cfg.Password.Argon2Time, cfg.Password.Argon2Parallelism,
+			cfg.Password.ScryptLogN, cfg.Password.ScryptR, cfg.Password.ScryptP, cfg.Password.BcryptCost, cfg.Password.Pepper, cfg.Password.AllowUnpeppered)
 	}
 
-	if filepath.Base(PidFile) != "http-services.pid" {
-		t.Errorf("PidFile = %s, want base http-services.pid", PidFile)
+	if len(cfg.Encryption.Keys) != 0 || cfg.Encryption.PrimaryKey != "" || cfg.Encryption.BlindIndexKey != nil {
+		t.Errorf("encryption config = %d keys primary %q blind index key set %v",
+			len(cfg.Encryption.Keys), cfg.Encryption.PrimaryKey, cfg.Encryption.BlindIndexKey != nil)
 	}
 
-	if LogMaxSize != 50 {
-		t.Errorf("LogMaxSize = %d, want 50", LogMaxSize)
+	if cfg.Database.MysqlDSN != "" {
+		t.Errorf("Database.MysqlDSN = %q, want empty string", cfg.Database.MysqlDSN)
+	}
+	if len(cfg.Database.ReplicaDSNs) != 0 || cfg.Database.MaxIdleConns != 25 || cfg.Database.MaxOpenConns != 100 ||
+		cfg.Database.ConnMaxLifetime != time.Hour || cfg.Database.ConnMaxIdleTime != 30*time.Minute ||
+		cfg.Database.SlowThreshold != 200*time.Millisecond {
+		t.Errorf("mysql pool config = replicas %v idle %d open %d lifetime %v idle time %v slow %v",
+			cfg.Database.ReplicaDSNs, cfg.Database.MaxIdleConns, cfg.Database.MaxOpenConns, cfg.Database.ConnMaxLifetime, cfg.Database.ConnMaxIdleTime, cfg.Database.SlowThreshold)
 	}
 
-	if MysqlDSN != "" {
-		t.Errorf("MysqlDSN = %q, want empty string", MysqlDSN)
+	if cfg.Redis.Host != "127.0.0.1:6379" {
+		t.Errorf("Redis.Host = %q, want 127.0.0.1:6379", cfg.Redis.Host)
 	}
 
-	if RedisHost != "127.0.0.1:6379" {
-		t.Errorf("RedisHost = %q, want 127.0.0.1:6379", RedisHost)
+	if cfg.Queue.Enabled || cfg.Queue.Name != "default" || cfg.Queue.Workers != 4 || cfg.Queue.JobTimeout != 5*time.Minute ||
+		cfg.Queue.RetryBase != 10*time.Second || cfg.Queue.RetryMax != 30*time.Minute {
+		t.Errorf("queue config = enabled %v name %q workers %d timeout %v retry %v/%v",
+			cfg.Queue.Enabled, cfg.Queue.Name, cfg.Queue.Workers, cfg.Queue.JobTimeout, cfg.Queue.RetryBase, cfg.Queue.RetryMax)
 	}
 
+	if cfg.Outbox.Enabled || cfg.Outbox.BatchSize != 100 || cfg.Outbox.MaxAttempts != 20 || cfg.Outbox.Retention != 7*24*time.Hour ||
+		cfg.Outbox.StreamPrefix != "outbox:" || cfg.Outbox.StreamMaxLen != 100000 {
+		t.Errorf("outbox config = enabled %v batch %d attempts %d retention %v stream %q/%d",
+			cfg.Outbox.Enabled, cfg.Outbox.BatchSize, cfg.Outbox.MaxAttempts, cfg.Outbox.Retention, cfg.Outbox.StreamPrefix, cfg.Outbox.StreamMaxLen)
+	}
 }
 
 func TestLoadConfigWithEnv(t *testing.T) {
@@ -129,7 +236,26 @@ func TestLoadConfigWithEnv(t *testing.T) {
 	t.Setenv("HTTP_SERVICES_SERVER_ENABLE_CORS", "false")
 	t.Setenv("HTTP_SERVICES_JWT_EXPIRATION", "24h")
 	t.Setenv("HTTP_SERVICES_DATABASE_MYSQL_DSN", "user:pass@tcp(127.0.0.1:3306)/app?charset=utf8mb4&parseTime=True&loc=Local")
+	t.Setenv("HTTP_SERVICES_DATABASE_REPLICA_DSNS", "ro:pass@tcp(10.0.0.2:3306)/app, ro:pass@tcp(10.0.0.3:3306)/app")
+	t.Setenv("HTTP_SERVICES_DATABASE_SLOW_THRESHOLD", "1s")
 	t.Setenv("HTTP_SERVICES_REDIS_HOST", "127.0.0.1:6380")
+	t.Setenv("HTTP_SERVICES_LOG_GORM_LEVEL", "debug")
+	t.Setenv("HTTP_SERVICES_LOG_LEVEL_REVERT_AFTER", "30m")
+	t.Setenv("HTTP_SERVICES_ADMIN_TOKEN", " admin-secret ")
+	t.Setenv("HTTP_SERVICES_LOG_MAX_TOTAL_SIZE", "2GB")
+	t.Setenv("HTTP_SERVICES_LOG_ROTATION", "Hourly")
+	t.Setenv("HTTP_SERVICES_LOG_SAMPLING_EXEMPT_LEVELS", "info, warn,error")
+	t.Setenv("HTTP_SERVICES_AUDIT_ENABLED", "true")
+	t.Setenv("HTTP_SERVICES_AUDIT_SINKS", "file, MySQL")
+	t.Setenv("HTTP_SERVICES_AUDIT_FILE", "/var/lib/http-services/audit.jsonl")
+	t.Setenv("HTTP_SERVICES_AUDIT_ACTOR_CLAIM", "username")
+	t.Setenv("HTTP_SERVICES_ID_MACHINE_ID", "513")
+	t.Setenv("HTTP_SERVICES_ID_EPOCH", "2024-01-01T08:00:00+08:00")
+	t.Setenv("HTTP_SERVICES_PASSWORD_ALGORITHM", "Scrypt")
+	t.Setenv("HTTP_SERVICES_PASSWORD_SCRYPT_LOG_N", "16")
+	t.Setenv("HTTP_SERVICES_PASSWORD_PEPPER", "env-pepper")
+	t.Setenv("HTTP_SERVICES_ENCRYPTION_KEYS", "k2:"+testEncryptionKey(2)+",k1:"+testEncryptionKey(1))
+	t.Setenv("HTTP_SERVICES_ENCRYPTION_BLIND_INDEX_KEY", testEncryptionKey(9))
 	pidPath := filepath.Join(t.TempDir(), "http-services.pid")
 	t.Setenv("HTTP_SERVICES_SERVER_PID_FILE", pidPath)
 
@@ -138,34 +264,60 @@ func TestLoadConfigWithEnv(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadConfig() error = %v", err)
 	}
+	cfg := Current()
 
 	// 验证环境变量覆盖
-	if ListenPort != 9090 {
-		t.Errorf("ListenPort = %d, want 9090 (from env)", ListenPort)
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Server.Port = %d, want 9090 (from env)", cfg.Server.Port)
 	}
-	if ListenHost != "127.0.0.2" || StaticDir != "public" || EnableCORS {
-		t.Errorf("server env exceptions = host %q static %q cors %v", ListenHost, StaticDir, EnableCORS)
+	if cfg.Server.Host != "127.0.0.2" || cfg.Server.StaticDir != "public" || cfg.Server.EnableCORS {
+		t.Errorf("server env exceptions = host %q static %q cors %v", cfg.Server.Host, cfg.Server.StaticDir, cfg.Server.EnableCORS)
 	}
-	if len(TrustedProxies) != 2 || TrustedProxies[0] != "127.0.0.2" || TrustedProxies[1] != "::1" {
-		t.Errorf("TrustedProxies = %#v", TrustedProxies)
+	if len(cfg.Server.TrustedProxies) != 2 || cfg.Server.TrustedProxies[0] != "127.0.0.2" || cfg.Server.TrustedProxies[1] != "::1" {
+		t.Errorf("Server.TrustedProxies = %#v", cfg.Server.TrustedProxies)
 	}
 
-	if JWTExpiration != 24*time.Hour {
-		t.Errorf("JWTExpiration = %v, want 24h (from env)", JWTExpiration)
+	if cfg.JWT.Expiration != 24*time.Hour {
+		t.Errorf("JWT.Expiration = %v, want 24h (from env)", cfg.JWT.Expiration)
 	}
 
-	if PidFile != pidPath {
-		t.Errorf("PidFile = %s, want %s (from env)", PidFile, pidPath)
+	if cfg.Server.PidFile != pidPath {
+		t.Errorf("Server.PidFile = %s, want %s (from env)", cfg.Server.PidFile, pidPath)
 	}
 
-	if MysqlDSN != "user:pass@tcp(127.0.0.1:3306)/app?charset=utf8mb4&parseTime=True&loc=Local" {
-		t.Errorf("MysqlDSN = %q, want env value", MysqlDSN)
+	if cfg.Database.MysqlDSN != "user:pass@tcp(127.0.0.1:3306)/app?charset=utf8mb4&parseTime=True&loc=Local" {
+		t.Errorf("Database.MysqlDSN = %q, want env value", cfg.Database.MysqlDSN)
 	}
-
-	if RedisHost != "127.0.0.1:6380" {
-		t.Errorf("RedisHost = %q, want 127.0.0.1:6380 (from env)", RedisHost)
+	if len(cfg.Database.ReplicaDSNs) != 2 || cfg.Database.ReplicaDSNs[1] != "ro:pass@tcp(10.0.0.3:3306)/app" {
+		t.Errorf("Database.ReplicaDSNs = %#v, want two trimmed env values", cfg.Database.ReplicaDSNs)
+	}
+	if cfg.Database.SlowThreshold != time.Second {
+		t.Errorf("Database.SlowThreshold = %v, want 1s (from env)", cfg.Database.SlowThreshold)
 	}
 
+	if cfg.Redis.Host != "127.0.0.1:6380" {
+		t.Errorf("Redis.Host = %q, want 127.0.0.1:6380 (from env)", cfg.Redis.Host)
+	}
+	if cfg.Log.GormLevel != "debug" || cfg.Log.LevelRevertAfter != 30*time.Minute || cfg.Admin.Token != "admin-secret" {
+		t.Errorf("log level env = gorm %q revert %v admin token %q", cfg.Log.GormLevel, cfg.Log.LevelRevertAfter, cfg.Admin.Token)
+	}
+	if cfg.Log.MaxTotalSize != 2*1024*1024*1024 || cfg.Log.Rotation != "hourly" || len(cfg.Log.Sampling.ExemptLevels) != 3 || cfg.Log.Sampling.ExemptLevels[1] != "warn" {
+		t.Errorf("log retention env = total %d rotation %q exempt %#v", cfg.Log.MaxTotalSize, cfg.Log.Rotation, cfg.Log.Sampling.ExemptLevels)
+	}
+	if !cfg.Audit.Enabled || len(cfg.Audit.Sinks) != 2 || cfg.Audit.Sinks[1] != "mysql" ||
+		cfg.Audit.File != "/var/lib/http-services/audit.jsonl" || cfg.Audit.ActorClaim != "username" {
+		t.Errorf("audit env = enabled %v sinks %v file %q actor claim %q", cfg.Audit.Enabled, cfg.Audit.Sinks, cfg.Audit.File, cfg.Audit.ActorClaim)
+	}
+	if cfg.ID.MachineID != 513 || !cfg.ID.Epoch.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("id env = machine %d epoch %v", cfg.ID.MachineID, cfg.ID.Epoch)
+	}
+	if cfg.Password.Algorithm != "scrypt" || cfg.Password.ScryptLogN != 16 || cfg.Password.Pepper != "env-pepper" {
+		t.Errorf("password env = %s ln=%d pepper %q", cfg.Password.Algorithm, cfg.Password.ScryptLogN, cfg.Password.Pepper)
+	}
+	if len(cfg.Encryption.Keys) != 2 || cfg.Encryption.PrimaryKey != "k2" || cfg.Encryption.Keys["k1"][0] != 1 || len(cfg.Encryption.BlindIndexKey) != 32 {
+		t.Errorf("encryption env = %d keys primary %q blind index key %d bytes",
+			len(cfg.Encryption.Keys), cfg.Encryption.PrimaryKey, len(cfg.Encryption.BlindIndexKey))
+	}
 }
 
 func TestLoadConfig_RedisKeyPrefix(t *testing.T) {
@@ -175,20 +327,22 @@ func TestLoadConfig_RedisKeyPrefix(t *testing.T) {
 		envValue string
 		setEnv   bool
 		want     string
+		wantErr  bool
 	}{
 		{name: "defaults to empty", want: ""},
 		{name: "trims yaml value", yaml: "redis:\n  key_prefix: \" service:env: \"\n", want: "service:env:"},
 		{name: "treats whitespace-only yaml value as empty", yaml: "redis:\n  key_prefix: \"   \"\n", want: ""},
 		{name: "reads trimmed environment value", envValue: " service:env: ", setEnv: true, want: "service:env:"},
 		{name: "environment overrides distinct yaml value", yaml: "redis:\n  key_prefix: \"yaml:\"\n", envValue: " env: ", setEnv: true, want: "env:"},
+		{name: "rejects prefix without trailing colon", envValue: "service", setEnv: true, wantErr: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			originalPrefix := RedisKeyPrefix
+			originalConfig := Current()
 			originalViper := v
 			t.Cleanup(func() {
-				RedisKeyPrefix = originalPrefix
+				Replace(originalConfig)
 				v = originalViper
 			})
 
@@ -205,11 +359,21 @@ func TestLoadConfig_RedisKeyPrefix(t *testing.T) {
 				}
 			}
 
-			if err := LoadConfig(); err != nil {
+			err := LoadConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig() with key_prefix %q succeeded, want error", tt.envValue)
+				}
+				if Current() != originalConfig {
+					t.Fatal("rejected config was published")
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("LoadConfig() error = %v", err)
 			}
-			if RedisKeyPrefix != tt.want {
-				t.Errorf("RedisKeyPrefix = %q, want %q", RedisKeyPrefix, tt.want)
+			if got := Current().Redis.KeyPrefix; got != tt.want {
+				t.Errorf("Redis.KeyPrefix = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -240,3 +404,203 @@ func TestWatchConfigWithoutLoadedFileIsNoop(t *testing.T) {
 		t.Fatal("WatchConfig callback called without a loaded config file")
 	}
 }
+
+func TestLoadConfig_LogSinks(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+
+	configDir := t.TempDir()
+	t.Chdir(configDir)
+	yaml := "log:\n  sinks:\n" +
+		"    - type: stdout\n      level: info\n" +
+		"    - type: HTTP\n      url: http://loki:3100/loki/api/v1/push\n      format: loki\n      flush_interval: 2s\n      labels:\n        - {name: serviceName, value: api}\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg := Current(); len(cfg.Log.Sinks) != 2 || cfg.Log.Sinks[0].Level != "info" || cfg.Log.Sinks[1].Type != LogSinkHTTP ||
+		cfg.Log.Sinks[1].FlushInterval != 2*time.Second || len(cfg.Log.Sinks[1].Labels) != 1 || cfg.Log.Sinks[1].Labels[0] != (LogLabel{Name: "serviceName", Value: "api"}) {
+		t.Fatalf("Log.Sinks = %+v", cfg.Log.Sinks)
+	}
+
+	t.Setenv("HTTP_SERVICES_LOG_SINKS", `[{"type":"syslog","network":"udp","address":"10.0.0.1:514","async":true}]`)
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() with env error = %v", err)
+	}
+	if cfg := Current(); len(cfg.Log.Sinks) != 1 || cfg.Log.Sinks[0].Type != LogSinkSyslog || !cfg.Log.Sinks[0].Async {
+		t.Fatalf("Log.Sinks from env = %+v", cfg.Log.Sinks)
+	}
+
+	for _, invalid := range []string{
+		`[{"type":"kafka"}]`,
+		`[{"type":"http"}]`,
+		`{"type":"stdout"}`,
+		`[{"type":"http","url":"http://loki","labels":{"job":"api"}}]`,
+		`[{"type":"http","url":"http://loki","labels":[{"name":"1job","value":"api"}]}]`,
+		`[{"type":"http","url":"http://loki","labels":[{"name":"job","value":"a"},{"name":"job","value":"b"}]}]`,
+	} {
+		t.Setenv("HTTP_SERVICES_LOG_SINKS", invalid)
+		if err := LoadConfig(); err == nil {
+			t.Errorf("LoadConfig() with log.sinks %s succeeded, want error", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_RejectsInvalidLogRetention(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	t.Setenv("HTTP_SERVICES_LOG_ROTATION", "weekly")
+	if err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() with log.rotation weekly succeeded, want error")
+	}
+	t.Setenv("HTTP_SERVICES_LOG_ROTATION", "daily")
+	t.Setenv("HTTP_SERVICES_LOG_MAX_TOTAL_SIZE", "lots")
+	if err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() with log.max_total_size lots succeeded, want error")
+	}
+}
+
+func TestLoadConfig_RejectsInvalidServerSettings(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"port zero", map[string]string{"HTTP_SERVICES_SERVER_PORT": "0"}, "server.port"},
+		{"port out of range", map[string]string{"HTTP_SERVICES_SERVER_PORT": "70000"}, "server.port"},
+		{"negative body size", map[string]string{"HTTP_SERVICES_SERVER_MAX_BODY_SIZE": "-1MB"}, "server.max_body_size"},
+		{"zero shutdown timeout", map[string]string{"HTTP_SERVICES_SERVER_SHUTDOWN_TIMEOUT": "0s"}, "server.shutdown_timeout"},
+		{"negative read timeout", map[string]string{"HTTP_SERVICES_SERVER_READ_TIMEOUT": "-1s"}, "server.read_timeout"},
+		{"negative pre-stop delay", map[string]string{"HTTP_SERVICES_SERVER_PRE_STOP_DELAY": "-1s"}, "server.pre_stop_delay"},
+		{"invalid trusted proxy", map[string]string{"HTTP_SERVICES_SERVER_TRUSTED_PROXIES": "127.0.0.1,proxy.local"}, "server.trusted_proxies[1]"},
+		{"negative pool size", map[string]string{"HTTP_SERVICES_DATABASE_MAX_OPEN_CONNS": "-1"}, "database.max_open_conns"},
+		{"uncapped outbox retry", map[string]string{"HTTP_SERVICES_OUTBOX_RETRY_MAX": "0s"}, "outbox.retry_max"},
+		{"unlimited outbox attempts", map[string]string{"HTTP_SERVICES_OUTBOX_MAX_ATTEMPTS": "0"}, "outbox.max_attempts"},
+		{"sampling without tick", map[string]string{"HTTP_SERVICES_LOG_SAMPLING_ENABLED": "true", "HTTP_SERVICES_LOG_SAMPLING_TICK": "0s"}, "log.sampling.tick"},
+		{"non-octal socket mode", map[string]string{"HTTP_SERVICES_SERVER_UNIX_SOCKET_MODE": "0698"}, "server.unix_socket.mode"},
+		{"malformed admin listen", map[string]string{"HTTP_SERVICES_ADMIN_LISTEN": "127.0.0.1"}, "admin.listen"},
+		{"public admin listen", map[string]string{"HTTP_SERVICES_ADMIN_LISTEN": "0.0.0.0:6060"}, "admin.listen"},
+		{"tls without certificate", map[string]string{"HTTP_SERVICES_SERVER_TLS_ENABLED": "true"}, "server.tls.cert_file"},
+		{"tls 1.1", map[string]string{"HTTP_SERVICES_SERVER_TLS_MIN_VERSION": "1.1"}, "server.tls.min_version"},
+		{"insecure cipher suite", map[string]string{"HTTP_SERVICES_SERVER_TLS_CIPHER_SUITES": "TLS_RSA_WITH_RC4_128_SHA"}, "server.tls.cipher_suites"},
+		{"mtls without client ca", map[string]string{"HTTP_SERVICES_SERVER_TLS_CLIENT_AUTH": "require"}, "server.tls.client_ca_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() with %v error = %v, want mention of %s", tt.env, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_RejectsUnknownAuditSink(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	t.Setenv("HTTP_SERVICES_AUDIT_SINKS", "file,kafka")
+	if err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() with audit.sinks kafka succeeded, want error")
+	}
+}
+
+func TestLoadConfig_RejectsInvalidIDSettings(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"machine id out of range", map[string]string{"HTTP_SERVICES_ID_MACHINE_ID": "65536"}},
+		{"explicit machine id with redis lease", map[string]string{"HTTP_SERVICES_ID_MACHINE_ID": "1", "HTTP_SERVICES_ID_REDIS_LEASE": "true"}},
+		{"short lease ttl", map[string]string{"HTTP_SERVICES_ID_REDIS_LEASE": "true", "HTTP_SERVICES_ID_LEASE_TTL": "1s"}},
+		{"future epoch", map[string]string{"HTTP_SERVICES_ID_EPOCH": time.Now().AddDate(1, 0, 0).Format(time.DateOnly)}},
+		{"malformed epoch", map[string]string{"HTTP_SERVICES_ID_EPOCH": "yesterday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if err := LoadConfig(); err == nil {
+				t.Errorf("LoadConfig() with %v succeeded, want error", tt.env)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_RejectsInvalidPasswordSettings(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"unknown algorithm", map[string]string{"HTTP_SERVICES_PASSWORD_ALGORITHM": "md5"}},
+		{"argon2 memory too small", map[string]string{"HTTP_SERVICES_PASSWORD_ARGON2_MEMORY": "1024"}},
+		{"argon2 zero time", map[string]string{"HTTP_SERVICES_PASSWORD_ARGON2_TIME": "0"}},
+		{"scrypt log n too small", map[string]string{"HTTP_SERVICES_PASSWORD_SCRYPT_LOG_N": "4"}},
+		{"bcrypt cost too high", map[string]string{"HTTP_SERVICES_PASSWORD_BCRYPT_COST": "32"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if err := LoadConfig(); err == nil {
+				t.Errorf("LoadConfig() with %v succeeded, want error", tt.env)
+			}
+		})
+	}
+}
+
+func testEncryptionKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestLoadConfig_RejectsInvalidEncryptionSettings(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"missing key id", map[string]string{"HTTP_SERVICES_ENCRYPTION_KEYS": testEncryptionKey(1)}},
+		{"short key", map[string]string{"HTTP_SERVICES_ENCRYPTION_KEYS": "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))}},
+		{"duplicate key id", map[string]string{"HTTP_SERVICES_ENCRYPTION_KEYS": "k1:" + testEncryptionKey(1) + ",k1:" + testEncryptionKey(2)}},
+		{"unknown primary", map[string]string{"HTTP_SERVICES_ENCRYPTION_KEYS": "k1:" + testEncryptionKey(1), "HTTP_SERVICES_ENCRYPTION_PRIMARY_KEY": "k2"}},
+		{"primary without keys", map[string]string{"HTTP_SERVICES_ENCRYPTION_PRIMARY_KEY": "k1"}},
+		{"short blind index key", map[string]string{"HTTP_SERVICES_ENCRYPTION_BLIND_INDEX_KEY": "c2hvcnQ="}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if err := LoadConfig(); err == nil {
+				t.Errorf("LoadConfig() with %v succeeded, want error", tt.env)
+			}
+		})
+	}
+}
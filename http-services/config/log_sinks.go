@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// 日志输出（log.sinks[].type）
+const (
+	LogSinkFile   = "file"
+	LogSinkStdout = "stdout"
+	LogSinkStderr = "stderr"
+	LogSinkSyslog = "syslog"
+	LogSinkHTTP   = "http"
+)
+
+// LogSink 描述一个日志输出目标，log.sinks 为空时沿用默认输出（release 写文件，dev 输出控制台）
+type LogSink struct {
+	Type    string `mapstructure:"type"`    // file / stdout / stderr / syslog / http
+	Level   string `mapstructure:"level"`   // 该输出的最低级别；为空时只受模块级别控制
+	Encoder string `mapstructure:"encoder"` // json / console；为空时 dev 模式的 stdout/stderr 用 console，其余用 json
+
+	// file：path 为空时业务日志与 gin 日志分别写入默认文件
+	Path string `mapstructure:"path"`
+
+	// syslog：network 为 unixgram/unix/udp/tcp，为空时连接本机 /dev/log
+	Network  string `mapstructure:"network"`
+	Address  string `mapstructure:"address"`
+	Tag      string `mapstructure:"tag"`
+	Facility string `mapstructure:"facility"`
+
+	// http：批量推送到 Loki / Elasticsearch 兼容接口
+	URL           string            `mapstructure:"url"`
+	Format        string            `mapstructure:"format"` // ndjson / loki / elastic
+	Headers       map[string]string `mapstructure:"headers"`
+	Labels        []LogLabel        `mapstructure:"labels"` // loki stream labels
+	BatchSize     int               `mapstructure:"batch_size"`
+	FlushInterval time.Duration     `mapstructure:"flush_interval"`
+	Timeout       time.Duration     `mapstructure:"timeout"`
+
+	// 异步缓冲：缓冲区满时丢弃日志并计数，不阻塞业务；http 输出总是异步
+	Async      bool `mapstructure:"async"`
+	BufferSize int  `mapstructure:"buffer_size"`
+}
+
+// LogLabel 是一个 Loki stream label
+// 以 name/value 列表配置：viper 会把配置文件中的 map key 统一转为小写，写成 map 时 serviceName 会变成 servicename
+type LogLabel struct {
+	Name  string `mapstructure:"name"`
+	Value string `mapstructure:"value"`
+}
+
+// getLogSinks 读取 log.sinks；环境变量 HTTP_SERVICES_LOG_SINKS 以 JSON 数组提供
+func getLogSinks() ([]LogSink, error) {
+	raw := v.Get("log.sinks")
+	if text, ok := raw.(string); ok {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			return nil, fmt.Errorf("log.sinks must be a JSON array: %w", err)
+		}
+		raw = decoded
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	if _, ok := raw.([]any); !ok {
+		return nil, fmt.Errorf("log.sinks must be a list, got %T", raw)
+	}
+
+	var sinks []LogSink
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &sinks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("invalid log.sinks: %w", err)
+	}
+
+	for index := range sinks {
+		sink := &sinks[index]
+		sink.Type = strings.ToLower(strings.TrimSpace(sink.Type))
+		sink.Encoder = strings.ToLower(strings.TrimSpace(sink.Encoder))
+		sink.Format = strings.ToLower(strings.TrimSpace(sink.Format))
+		sink.Network = strings.ToLower(strings.TrimSpace(sink.Network))
+		if err := validateLogSink(*sink); err != nil {
+			return nil, fmt.Errorf("log.sinks[%d]: %w", index, err)
+		}
+	}
+	return sinks, nil
+}
+
+func validateLogSink(sink LogSink) error {
+	switch sink.Type {
+	case LogSinkFile, LogSinkStdout, LogSinkStderr, LogSinkSyslog:
+	case LogSinkHTTP:
+		if strings.TrimSpace(sink.URL) == "" {
+			return fmt.Errorf("http sink requires url")
+		}
+		switch sink.Format {
+		case "", "ndjson", "loki", "elastic":
+		default:
+			return fmt.Errorf("unknown http format %q", sink.Format)
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q", sink.Type)
+	}
+	switch sink.Encoder {
+	case "", "json", "console":
+	default:
+		return fmt.Errorf("unknown encoder %q", sink.Encoder)
+	}
+	seen := make(map[string]bool, len(sink.Labels))
+	for index, label := range sink.Labels {
+		if !validLabelName(label.Name) {
+			return fmt.Errorf("labels[%d]: invalid label name %q, labels must be a list of {name, value}", index, label.Name)
+		}
+		if seen[label.Name] {
+			return fmt.Errorf("labels[%d]: duplicate label name %q", index, label.Name)
+		}
+		seen[label.Name] = true
+	}
+	return nil
+}
+
+// validLabelName 校验 Loki label 名称：[a-zA-Z_][a-zA-Z0-9_]*
+func validLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for index, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && index > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"http-services/utils/encryption"
+)
+
+// 敏感配置项（jwt.key、admin.token、admin.diagnostics_token、database.mysql_dsn、database.replica_dsns、
+// redis.password、password.pepper、encryption.keys、encryption.blind_index_key）通过 secretString / secretStringSlice 读取，
+// 取值来源按优先级：
+//  1. <key>_file：从文件读取（Kubernetes Secret 挂载），如 jwt.key_file / HTTP_SERVICES_JWT_KEY_FILE
+//  2. enc:<密文>：用主密钥解密，主密钥来自 HTTP_SERVICES_MASTER_KEY 或 HTTP_SERVICES_MASTER_KEY_FILE
+//  3. <scheme>://<ref>：交给已注册的 SecretProvider 解析，内置 file://<path>
+//
+// 其余配置项不做解析，普通配置中的 URL 不受影响。
+
+// secretKeys 是通过 secretString / secretStringSlice 读取的敏感配置项，Settings 输出时隐藏其取值；
+// log.sinks 中的 headers 与 url userinfo 由 redactLogSinks 单独处理
+var secretKeys = []string{
+	"jwt.key",
+	"admin.token",
+	"admin.diagnostics_token",
+	"database.mysql_dsn",
+	"database.replica_dsns",
+	"redis.password",
+	"password.pepper",
+	"encryption.keys",
+	"encryption.blind_index_key",
+}
+
+// redacted 替代敏感配置项的取值
+const redacted = "******"
+
+// redactLogSinks 隐藏 log.sinks 中的凭据：headers 的全部取值与 url 中的 userinfo。
+// 环境变量以 JSON 字符串提供的 log.sinks 先解码再处理，无法解码时整体隐藏
+func redactLogSinks(value any) any {
+	if text, ok := value.(string); ok {
+		if strings.TrimSpace(text) == "" {
+			return value
+		}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return redacted
+		}
+	}
+	sinks, ok := value.([]any)
+	if !ok {
+		return value
+	}
+	result := make([]any, 0, len(sinks))
+	for _, item := range sinks {
+		sink, ok := item.(map[string]any)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		copied := make(map[string]any, len(sink))
+		for field, fieldValue := range sink {
+			switch strings.ToLower(field) {
+			case "headers":
+				if headers, ok := fieldValue.(map[string]any); ok {
+					masked := make(map[string]any, len(headers))
+					for name := range headers {
+						masked[name] = redacted
+					}
+					fieldValue = masked
+				}
+			case "url":
+				if raw, ok := fieldValue.(string); ok {
+					fieldValue = stripUserinfo(raw)
+				}
+			}
+			copied[field] = fieldValue
+		}
+		result = append(result, copied)
+	}
+	return result
+}
+
+// stripUserinfo 去掉 URL 中的用户名与密码；无法解析时整体隐藏
+func stripUserinfo(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	u.User = nil
+	return u.String()
+}
+
+// isSecretKey 判断 key 是否属于 secretKeys，encryption.keys.<id> 这类子项同样视为敏感
+func isSecretKey(key string) bool {
+	for _, secret := range secretKeys {
+		if key == secret || strings.HasPrefix(key, secret+".") {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	encryptedValuePrefix = "enc:"
+	masterKeyEnv         = "HTTP_SERVICES_MASTER_KEY"
+	secretResolveTimeout = 10 * time.Second
+)
+
+// SecretProvider 解析 <scheme>://<ref> 形式的配置值，用于接入 Vault、云厂商密钥管理等外部系统
+type SecretProvider interface {
+	Scheme() string
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{"file": fileSecretProvider{}}
+)
+
+// RegisterSecretProvider 注册 SecretProvider，需在 LoadConfig 之前调用；返回同 scheme 的旧 provider
+func RegisterSecretProvider(provider SecretProvider) SecretProvider {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	old := secretProviders[provider.Scheme()]
+	secretProviders[provider.Scheme()] = provider
+	return old
+}
+
+// UnregisterSecretProvider 移除指定 scheme 的 provider
+func UnregisterSecretProvider(scheme string) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	delete(secretProviders, scheme)
+}
+
+func lookupSecretProvider(scheme string) (SecretProvider, bool) {
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+	provider, ok := secretProviders[scheme]
+	return provider, ok
+}
+
+// StaticSecretProvider 从内存 map 解析密钥，用于测试与本地开发时替代真实的密钥系统
+type StaticSecretProvider struct {
+	Name   string
+	Values map[string]string
+}
+
+// Scheme 实现 SecretProvider
+func (p StaticSecretProvider) Scheme() string {
+	return p.Name
+}
+
+// Resolve 实现 SecretProvider
+func (p StaticSecretProvider) Resolve(_ context.Context, ref string) (string, error) {
+	value, ok := p.Values[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s://%s not found", p.Name, ref)
+	}
+	return value, nil
+}
+
+// fileSecretProvider 解析 file://<path>
+type fileSecretProvider struct{}
+
+func (fileSecretProvider) Scheme() string {
+	return "file"
+}
+
+func (fileSecretProvider) Resolve(_ context.Context, ref string) (string, error) {
+	return readSecretFile(ref)
+}
+
+// readSecretFile 读取密钥文件并去掉末尾换行；相对路径相对 AbsPath
+func readSecretFile(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(AbsPath, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// secretString 读取敏感配置项，取值来源与优先级见本文件开头的说明
+func secretString(key string) (string, error) {
+	if path := strings.TrimSpace(v.GetString(key + "_file")); path != "" {
+		content, err := readSecretFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read %s_file: %w", key, err)
+		}
+		return resolveSecretValue(key, content)
+	}
+	return resolveSecretValue(key, v.GetString(key))
+}
+
+// secretStringSlice 读取列表型敏感配置项；文件中按行或逗号分隔
+func secretStringSlice(key string) ([]string, error) {
+	var items []string
+	if path := strings.TrimSpace(v.GetString(key + "_file")); path != "" {
+		content, err := readSecretFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s_file: %w", key, err)
+		}
+		for _, item := range strings.FieldsFunc(content, func(r rune) bool { return r == '\n' || r == ',' }) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	} else {
+		items = getStringSlice(key)
+	}
+	for i, item := range items {
+		resolved, err := resolveSecretValue(key, item)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = resolved
+	}
+	return items, nil
+}
+
+// resolveSecretValue 解密 enc: 值或交给 SecretProvider 解析；错误信息中不包含密钥内容
+func resolveSecretValue(key, value string) (string, error) {
+	if sealed, ok := strings.CutPrefix(value, encryptedValuePrefix); ok {
+		masterKey, err := loadMasterKey()
+		if err != nil {
+			return "", fmt.Errorf("decrypt %s: %w", key, err)
+		}
+		plaintext, err := encryption.OpenWithKey(masterKey, sealed)
+		if err != nil {
+			return "", fmt.Errorf("decrypt %s: %w", key, err)
+		}
+		return string(plaintext), nil
+	}
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok {
+		return value, nil
+	}
+	provider, ok := lookupSecretProvider(scheme)
+	if !ok {
+		return value, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	resolved, err := provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s from %s provider: %w", key, scheme, err)
+	}
+	return resolved, nil
+}
+
+// loadMasterKey 读取解密 enc: 配置值的主密钥（base64 编码的 32 字节）。
+// 主密钥只能来自环境变量或其指向的文件，不能写在配置文件里。
+func loadMasterKey() ([]byte, error) {
+	encoded := strings.TrimSpace(os.Getenv(masterKeyEnv))
+	if path := strings.TrimSpace(os.Getenv(masterKeyEnv + "_FILE")); path != "" {
+		content, err := readSecretFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s_FILE: %w", masterKeyEnv, err)
+		}
+		encoded = strings.TrimSpace(content)
+	}
+	if encoded == "" {
+		return nil, errors.New(masterKeyEnv + " is not set")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New(masterKeyEnv + " must be base64 of 32 random bytes")
+	}
+	return key, nil
+}
+
+// EncryptSecretValue 用主密钥加密配置值，返回可直接写入配置的 enc:<密文>；主密钥也可以来自 .env
+func EncryptSecretValue(plaintext string) (string, error) {
+	if err := loadEnvFile(); err != nil {
+		return "", err
+	}
+	masterKey, err := loadMasterKey()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := encryption.SealWithKey(masterKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return encryptedValuePrefix + sealed, nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_ResolvesSecretSources(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+
+	t.Setenv(masterKeyEnv, testEncryptionKey(7))
+	encryptedPassword, err := EncryptSecretValue("redis-secret")
+	if err != nil {
+		t.Fatalf("EncryptSecretValue() error = %v", err)
+	}
+	if !strings.HasPrefix(encryptedPassword, "enc:") || strings.Contains(encryptedPassword, "redis-secret") {
+		t.Fatalf("EncryptSecretValue() = %q", encryptedPassword)
+	}
+	RegisterSecretProvider(StaticSecretProvider{Name: "vault", Values: map[string]string{
+		"secret/db#dsn": "user:pass@tcp(db:3306)/app",
+	}})
+	t.Cleanup(func() { UnregisterSecretProvider("vault") })
+
+	t.Setenv("HTTP_SERVICES_JWT_KEY", "ignored-when-file-is-set")
+	t.Setenv("HTTP_SERVICES_JWT_KEY_FILE", writeSecretFile(t, "jwt", "jwt-from-file\n"))
+	t.Setenv("HTTP_SERVICES_REDIS_PASSWORD", encryptedPassword)
+	t.Setenv("HTTP_SERVICES_DATABASE_MYSQL_DSN", "vault://secret/db#dsn")
+	t.Setenv("HTTP_SERVICES_ADMIN_TOKEN", "file://"+writeSecretFile(t, "admin", "admin-token\n"))
+	t.Setenv("HTTP_SERVICES_ENCRYPTION_KEYS_FILE", writeSecretFile(t, "keys",
+		"k2:"+testEncryptionKey(2)+"\nk1:"+testEncryptionKey(1)+"\n"))
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg := Current()
+	if cfg.JWT.Key != "jwt-from-file" {
+		t.Errorf("JWT.Key = %q, want value from jwt.key_file", cfg.JWT.Key)
+	}
+	if cfg.Redis.Password != "redis-secret" {
+		t.Errorf("Redis.Password = %q, want decrypted value", cfg.Redis.Password)
+	}
+	if cfg.Database.MysqlDSN != "user:pass@tcp(db:3306)/app" {
+		t.Errorf("Database.MysqlDSN = %q, want value from vault provider", cfg.Database.MysqlDSN)
+	}
+	if cfg.Admin.Token != "admin-token" {
+		t.Errorf("Admin.Token = %q, want value from file:// reference", cfg.Admin.Token)
+	}
+	if len(cfg.Encryption.Keys) != 2 || cfg.Encryption.PrimaryKey != "k2" {
+		t.Errorf("encryption keys from file = %d keys primary %q", len(cfg.Encryption.Keys), cfg.Encryption.PrimaryKey)
+	}
+}
+
+func TestLoadConfig_RejectsUnresolvableSecrets(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+	RegisterSecretProvider(StaticSecretProvider{Name: "vault"})
+	t.Cleanup(func() { UnregisterSecretProvider("vault") })
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"encrypted value without master key", map[string]string{"HTTP_SERVICES_REDIS_PASSWORD": "enc:AAAA"}},
+		{"encrypted value with wrong master key", map[string]string{
+			"HTTP_SERVICES_REDIS_PASSWORD": "enc:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+			masterKeyEnv:                   testEncryptionKey(7),
+		}},
+		{"missing secret file", map[string]string{"HTTP_SERVICES_JWT_KEY_FILE": filepath.Join(t.TempDir(), "missing")}},
+		{"missing provider secret", map[string]string{"HTTP_SERVICES_DATABASE_MYSQL_DSN": "vault://secret/db#dsn"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(masterKeyEnv, "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			err := LoadConfig()
+			if err == nil {
+				t.Fatalf("LoadConfig() with %v succeeded, want error", tt.env)
+			}
+			if strings.Contains(err.Error(), "AAAA") {
+				t.Fatalf("LoadConfig() error leaks the secret value: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_LeavesPlainURLsAlone(t *testing.T) {
+	originalViper := v
+	t.Cleanup(func() { v = originalViper })
+	t.Setenv("HTTP_SERVICES_REDIS_PASSWORD", "https://not-a-registered-scheme")
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := Current().Redis.Password; got != "https://not-a-registered-scheme" {
+		t.Errorf("Redis.Password = %q", got)
+	}
+}
+
+func TestSettings_RedactsLogSinkCredentials(t *testing.T) {
+	originalViper := v
+	originalConfig := Current()
+	t.Cleanup(func() {
+		v = originalViper
+		Replace(originalConfig)
+	})
+	t.Chdir(t.TempDir())
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeLayerFile(t, file, `log:
+  sinks:
+    - type: http
+      url: https://user:pw@loki.internal/loki/api/v1/push
+      format: loki
+      headers:
+        Authorization: Bearer SUPERSECRET
+`)
+	tests := []struct {
+		name string
+		env  string
+	}{
+		{"config file", ""},
+		{"json env", `[{"type":"http","url":"https://user:pw@logs.internal/bulk","headers":{"Authorization":"Bearer SUPERSECRET"}}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("HTTP_SERVICES_LOG_SINKS", tt.env)
+			}
+			if err := LoadConfigWith(LoadOptions{File: file}); err != nil {
+				t.Fatalf("LoadConfigWith() error = %v", err)
+			}
+			if sinks := Current().Log.Sinks; len(sinks) != 1 || !strings.Contains(sinks[0].URL, "user:pw@") {
+				t.Fatalf("Log.Sinks = %+v, want credentials kept for the sink itself", sinks)
+			}
+
+			var output string
+			for _, setting := range Settings() {
+				if setting.Key == "log.sinks" {
+					output = fmt.Sprint(setting.Value)
+				}
+			}
+			if output == "" {
+				t.Fatal("Settings() has no log.sinks entry")
+			}
+			for _, secret := range []string{"SUPERSECRET", "user:pw", "pw@"} {
+				if strings.Contains(output, secret) {
+					t.Errorf("log.sinks setting = %s, leaks %q", output, secret)
+				}
+			}
+			if !strings.Contains(output, redacted) || !strings.Contains(output, ".internal/") {
+				t.Errorf("log.sinks setting = %s, want masked headers and url without userinfo", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validateOnce sync.Once
+	structValid  *validator.Validate
+)
+
+// configValidator 错误中的字段名使用配置文件里的 key（mapstructure 标签），例如 server.port
+func configValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		structValid = validator.New(validator.WithRequiredStructEnabled())
+		structValid.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+	})
+	return structValid
+}
+
+// validate 先按 validate 标签校验，再检查跨字段约束；所有问题一次性返回
+func (c *Config) validate() error {
+	var errs []error
+	if err := configValidator().Struct(c); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
+		for _, fieldErr := range fieldErrs {
+			errs = append(errs, describeFieldError(fieldErr))
+		}
+	}
+
+	if err := checkLoopbackListen(c.Admin.Listen); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, checkTLS(c.Server.TLS)...)
+	if c.ID.RedisLease && c.ID.MachineID >= 0 {
+		errs = append(errs, errors.New("id.machine_id and id.redis_lease are mutually exclusive"))
+	}
+	if c.ID.RedisLease && c.ID.LeaseTTL < 3*time.Second {
+		errs = append(errs, fmt.Errorf("invalid id.lease_ttl %v: want at least 3s", c.ID.LeaseTTL))
+	}
+	if r, p := c.Password.ScryptR, c.Password.ScryptP; r > 0 && p > 0 && uint64(r)*uint64(p) >= 1<<30 {
+		errs = append(errs, fmt.Errorf("invalid password.scrypt_r/scrypt_p %d/%d: want r*p < 2^30", r, p))
+	}
+	return errors.Join(errs...)
+}
+
+// checkLoopbackListen 确认 admin.listen 只绑定回环地址：管理端口上的 pprof 与 metrics 不要求 token
+func checkLoopbackListen(listen string) error {
+	if listen == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		// 格式错误已由 hostname_port 规则报告
+		return nil
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("invalid admin.listen %s: want a loopback address such as 127.0.0.1:6060", listen)
+}
+
+// checkTLS 检查密码套件名称，以及校验客户端证书时是否配置了 CA
+func checkTLS(cfg TLSConfig) []error {
+	var errs []error
+	for _, name := range cfg.CipherSuites {
+		if _, ok := CipherSuiteID(name); !ok {
+			errs = append(errs, fmt.Errorf("invalid server.tls.cipher_suites %s: want a secure TLS 1.2 suite name such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", name))
+		}
+	}
+	if (cfg.ClientAuth == "verify_if_given" || cfg.ClientAuth == "require") && cfg.ClientCAFile == "" {
+		errs = append(errs, fmt.Errorf("server.tls.client_auth %s requires server.tls.client_ca_file", cfg.ClientAuth))
+	}
+	return errs
+}
+
+// CipherSuiteID 按 Go 的名称查找安全的密码套件；crypto/tls 标记为不安全的套件不允许配置
+func CipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+func describeFieldError(fieldErr validator.FieldError) error {
+	// Namespace 形如 Config.server.port，去掉根类型名
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	rule := fieldErr.Tag()
+	if fieldErr.Param() != "" {
+		rule += "=" + fieldErr.Param()
+	}
+	return fmt.Errorf("invalid %s %v: want %s", path, fieldErr.Value(), rule)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"http-services/config"
+)
+
+// printConfig 逐项输出合并后的配置及其来源（配置文件、环境变量或默认值），敏感项已隐藏
+func printConfig(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, setting := range config.Settings() {
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return tw.Flush()
+}
@@ -15,7 +15,9 @@ type Migrator struct {
 	Migrate func(*gorm.DB) error
 }
 
-func MigrateAll() error {
+// MigrateAll 连接主库并依次执行 migrators；main 传入 App 按模块依赖顺序汇总的迁移，
+// 新增表域时在模块的 Migrations 中返回即可，无需修改这里
+func MigrateAll(migrators ...Migrator) error {
 	database, err := msqldb.Client()
 	if err != nil {
 		return fmt.Errorf("init mysql client: %w", err)
@@ -29,9 +31,7 @@ func MigrateAll() error {
 		return fmt.Errorf("ping mysql: %w", err)
 	}
 
-	// 新增业务表域后，在这里按依赖顺序注册迁移：
-	// return RunMigrators(database, Migrator{Name: "user", Migrate: user.Migrate})
-	return RunMigrators(database)
+	return RunMigrators(database, migrators...)
 }
 
 func RunMigrators(database *gorm.DB, migrators ...Migrator) error {
@@ -62,9 +62,8 @@ func TestRunMigratorsWrapsMigratorError(t *testing.T) {
 }
 
 func TestMigrateAllRequiresMysqlDSN(t *testing.T) {
-	oldDSN := config.MysqlDSN
-	config.MysqlDSN = ""
-	t.Cleanup(func() { config.MysqlDSN = oldDSN })
+	oldConfig := config.Update(func(c *config.Config) { c.Database.MysqlDSN = "" })
+	t.Cleanup(func() { config.Replace(oldConfig) })
 
 	if err := MigrateAll(); !errors.Is(err, msqldb.ErrMissingMysqlDSN) {
 		t.Fatalf("MigrateAll() error = %v, want %v", err, msqldb.ErrMissingMysqlDSN)
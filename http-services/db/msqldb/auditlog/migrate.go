@@ -0,0 +1,8 @@
+package auditlog
+
+import "gorm.io/gorm"
+
+// Migrate 创建或更新审计表结构
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Log{})
+}
@@ -0,0 +1,25 @@
+package auditlog
+
+import "time"
+
+// Log 是审计表中的一条记录
+// 审计记录只追加不修改：不嵌入 msqldb.BaseModel（没有 updated_at 与软删除），本包也不提供更新与删除 helper。
+type Log struct {
+	ID         uint64    `gorm:"primarykey"`
+	Actor      string    `gorm:"size:128;not null;default:'';index:idx_audit_actor"`
+	Action     string    `gorm:"size:128;not null;index:idx_audit_action"`
+	Resource   string    `gorm:"size:64;not null;default:'';index:idx_audit_resource,priority:1"`
+	ResourceID string    `gorm:"size:128;not null;default:'';index:idx_audit_resource,priority:2"`
+	Outcome    string    `gorm:"size:16;not null"`
+	Reason     string    `gorm:"size:1024;not null;default:''"`
+	ClientIP   string    `gorm:"size:64;not null;default:''"`
+	TraceID    string    `gorm:"size:64;not null;default:'';index:idx_audit_trace"`
+	Before     []byte    `gorm:"type:json"`
+	After      []byte    `gorm:"type:json"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null;index:idx_audit_created"`
+}
+
+// TableName 固定表名，避免受 GORM 复数化规则影响
+func (Log) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,156 @@
+// Package auditlog 提供审计表的模型、迁移、追加写入 Sink 与查询 helper。
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"http-services/utils/audit"
+)
+
+// Sink 将审计记录写入 audit_logs 表，实现 audit.Sink
+// 直接使用传入的连接而不是 msqldb.DB(ctx)：业务事务回滚时，失败的操作同样需要留下审计记录。
+type Sink struct {
+	DB *gorm.DB
+}
+
+// Name 实现 audit.Sink
+func (s Sink) Name() string {
+	return "mysql"
+}
+
+// Write 实现 audit.Sink
+func (s Sink) Write(ctx context.Context, entry audit.Entry) error {
+	if s.DB == nil {
+		return errors.New("auditlog: database is nil")
+	}
+	row, err := fromEntry(entry)
+	if err != nil {
+		return err
+	}
+	return s.DB.WithContext(ctx).Create(&row).Error
+}
+
+// Close 实现 audit.Sink；连接由 msqldb 统一关闭
+func (s Sink) Close() error {
+	return nil
+}
+
+func fromEntry(entry audit.Entry) (Log, error) {
+	row := Log{
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		ResourceID: entry.ResourceID,
+		Outcome:    entry.Outcome,
+		Reason:     truncate(entry.Reason, 1024),
+		ClientIP:   entry.ClientIP,
+		TraceID:    entry.TraceID,
+		CreatedAt:  entry.Time,
+	}
+	var err error
+	if row.Before, err = encodeFields(entry.Before); err != nil {
+		return Log{}, fmt.Errorf("auditlog: encode before: %w", err)
+	}
+	if row.After, err = encodeFields(entry.After); err != nil {
+		return Log{}, fmt.Errorf("auditlog: encode after: %w", err)
+	}
+	return row, nil
+}
+
+func encodeFields(fields map[string]any) ([]byte, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// Entry 将表记录还原为 audit.Entry
+func (l Log) Entry() audit.Entry {
+	entry := audit.Entry{
+		Time:       l.CreatedAt,
+		Actor:      l.Actor,
+		Action:     l.Action,
+		Resource:   l.Resource,
+		ResourceID: l.ResourceID,
+		Outcome:    l.Outcome,
+		Reason:     l.Reason,
+		ClientIP:   l.ClientIP,
+		TraceID:    l.TraceID,
+	}
+	// 写入时已校验为合法 JSON，解码失败只会丢失 diff，不影响其余字段
+	_ = decodeFields(l.Before, &entry.Before)
+	_ = decodeFields(l.After, &entry.After)
+	return entry
+}
+
+func decodeFields(data []byte, fields *map[string]any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, fields)
+}
+
+// Filter 是审计记录的查询条件，零值字段不参与过滤
+type Filter struct {
+	Actor      string
+	Action     string
+	Resource   string
+	ResourceID string
+	Outcome    string
+	TraceID    string
+	Since      time.Time // 包含
+	Until      time.Time // 不包含
+}
+
+// List 按时间倒序返回满足条件的审计记录及总数；limit <= 0 时不分页
+func List(ctx context.Context, db *gorm.DB, filter Filter, offset, limit int) ([]Log, int64, error) {
+	query := db.WithContext(ctx).Model(&Log{})
+	for _, cond := range []struct{ column, value string }{
+		{"actor", filter.Actor},
+		{"action", filter.Action},
+		{"resource", filter.Resource},
+		{"resource_id", filter.ResourceID},
+		{"outcome", filter.Outcome},
+		{"trace_id", filter.TraceID},
+	} {
+		if cond.value != "" {
+			query = query.Where(cond.column+" = ?", cond.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count audit logs: %w", err)
+	}
+	var logs []Log
+	if total == 0 {
+		return logs, 0, nil
+	}
+	query = query.Order("id DESC")
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list audit logs: %w", err)
+	}
+	return logs, total, nil
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
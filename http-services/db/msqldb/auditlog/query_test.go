@@ -0,0 +1,90 @@
+package auditlog
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"http-services/utils/audit"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	database, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return database, mock
+}
+
+func TestSinkWriteInsertsRowWithDiff(t *testing.T) {
+	database, mock := newMockDB(t)
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).
+		WithArgs("u1", "role.grant", "user", "42", audit.OutcomeSuccess, "", "10.0.0.1", "trace-1",
+			[]byte(nil), []byte(`{"role":"admin"}`), at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := Sink{DB: database}.Write(context.Background(), audit.Entry{
+		Time:       at,
+		Actor:      "u1",
+		Action:     "role.grant",
+		Resource:   "user",
+		ResourceID: "42",
+		Outcome:    audit.OutcomeSuccess,
+		ClientIP:   "10.0.0.1",
+		TraceID:    "trace-1",
+		After:      map[string]any{"role": "admin"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListAppliesFiltersAndPaging(t *testing.T) {
+	database, mock := newMockDB(t)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `audit_logs` WHERE actor = ? AND outcome = ? AND created_at >= ?",
+	)).
+		WithArgs("u1", audit.OutcomeDenied, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `audit_logs` WHERE actor = ? AND outcome = ? AND created_at >= ? ORDER BY id DESC LIMIT ? OFFSET ?",
+	)).
+		WithArgs("u1", audit.OutcomeDenied, since, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "outcome", "before"}).
+			AddRow(1, "u1", "order.export", audit.OutcomeDenied, []byte(`{"status":"draft"}`)))
+
+	logs, total, err := List(context.Background(), database, Filter{Actor: "u1", Outcome: audit.OutcomeDenied, Since: since}, 20, 10)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 21 || len(logs) != 1 {
+		t.Fatalf("List() = %d logs, total %d", len(logs), total)
+	}
+	if entry := logs[0].Entry(); entry.Action != "order.export" || entry.Before["status"] != "draft" {
+		t.Fatalf("Entry() = %+v", entry)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
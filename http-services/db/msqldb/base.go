@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"http-services/utils/id"
 )
 
 type BaseModel struct {
@@ -12,3 +14,42 @@ type BaseModel struct {
 	UpdatedAt time.Time      `gorm:"column:updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
+
+// StringBaseModel 与 BaseModel 字段相同，但主键是字符串；创建时 ID 为空则自动填充小写 ULID。
+// 适合需要对外暴露、跨库合并或由客户端预先生成主键的表。
+// 嵌入的模型若自己实现 BeforeCreate，会覆盖这里的实现，需要在其中手动调用 m.StringBaseModel.BeforeCreate(tx)。
+type StringBaseModel struct {
+	ID        string         `gorm:"primaryKey;type:varchar(64)"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// BeforeCreate 为空主键生成 ULID
+func (m *StringBaseModel) BeforeCreate(*gorm.DB) error {
+	if m.ID == "" {
+		m.ID = id.GenerateLowerULID()
+	}
+	return nil
+}
+
+// TypedBaseModel 使用带前缀的类型化主键（如 usr_01hx...），创建时 ID 为空则自动生成：
+//
+//	type User struct {
+//	    msqldb.TypedBaseModel[UserPrefix]
+//	    Username string `gorm:"column:username;type:varchar(64);not null;uniqueIndex"`
+//	}
+type TypedBaseModel[P id.Prefix] struct {
+	ID        id.Typed[P]    `gorm:"primaryKey;type:varchar(64)"`
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// BeforeCreate 为空主键生成前缀 ID
+func (m *TypedBaseModel[P]) BeforeCreate(*gorm.DB) error {
+	if m.ID.IsZero() {
+		m.ID = id.NewTyped[P]()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package msqldb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+
+	"http-services/utils/id"
+)
+
+type widgetPrefix struct{}
+
+func (widgetPrefix) Prefix() string { return "wgt" }
+
+type stringKeyed struct {
+	StringBaseModel
+	Name string
+}
+
+type typedKeyed struct {
+	TypedBaseModel[widgetPrefix]
+	Name string
+}
+
+func TestStringBaseModelFillsULIDOnCreate(t *testing.T) {
+	mock := useMockClient(t)
+	mock.ExpectExec("INSERT INTO `string_keyeds`").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	database, _ := DB(context.Background())
+	database = database.Session(&gorm.Session{SkipDefaultTransaction: true})
+	row := stringKeyed{Name: "a"}
+	if err := database.Create(&row).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := id.ULIDTime(row.ID); err != nil || row.ID != strings.ToLower(row.ID) {
+		t.Fatalf("ID = %q, want a lowercase ULID (%v)", row.ID, err)
+	}
+
+	preset := stringKeyed{StringBaseModel: StringBaseModel{ID: "client-generated"}}
+	mock.ExpectExec("INSERT INTO `string_keyeds`").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := database.Create(&preset).Error; err != nil || preset.ID != "client-generated" {
+		t.Fatalf("Create() with preset ID = %q, %v", preset.ID, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypedBaseModelFillsPrefixedIDAndScans(t *testing.T) {
+	mock := useMockClient(t)
+	database, _ := DB(context.Background())
+	database = database.Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	mock.ExpectExec("INSERT INTO `typed_keyeds`").WillReturnResult(sqlmock.NewResult(0, 1))
+	row := typedKeyed{Name: "a"}
+	if err := database.Create(&row).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(row.ID.String(), "wgt_") {
+		t.Fatalf("ID = %q, want wgt_ prefix", row.ID)
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM `typed_keyeds` WHERE `typed_keyeds`.`id` = \\?").
+		WithArgs(row.ID.String(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(row.ID.String(), "a"))
+	var found typedKeyed
+	if err := database.Where("`typed_keyeds`.`id` = ?", row.ID).Take(&found).Error; err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if found.ID != row.ID {
+		t.Fatalf("scanned ID = %q, want %q", found.ID, row.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
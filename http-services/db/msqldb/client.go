@@ -2,24 +2,26 @@ package msqldb
 
 import (
 	"errors"
-	stdlog "log"
+	"slices"
 	"strings"
 	"sync"
-	"time"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 
 	"http-services/config"
-	httplog "http-services/utils/log"
 )
 
 var (
 	client   *gorm.DB
+	resolver *dbresolver.DBResolver // 配置了只读副本时非 nil
 	clientMu sync.Mutex
+
+	// 初始化时使用的 DSN，用于在热重载时提示需要重启
+	initializedDSN         string
+	initializedReplicaDSNs []string
 )
 
 var ErrMissingMysqlDSN = errors.New("database.mysql_dsn is empty")
@@ -50,24 +52,14 @@ func Init() error {
 	if client != nil {
 		return nil
 	}
-	if strings.TrimSpace(config.MysqlDSN) == "" {
+	dbConfig := config.Current().Database
+	if strings.TrimSpace(dbConfig.MysqlDSN) == "" {
 		return ErrMissingMysqlDSN
 	}
 
-	zapLogger := zap.L().With(zap.String("component", "gorm"))
-	gormWriter := httplog.NewZapWriter(zapLogger, zapcore.InfoLevel)
-	gormLogger := logger.New(
-		stdlog.New(gormWriter, "\r\n", stdlog.LstdFlags),
-		logger.Config{
-			SlowThreshold:             200 * time.Millisecond,
-			LogLevel:                  logger.Warn,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  false,
-		},
-	)
-
+	gormLogger.setSlowThreshold(dbConfig.SlowThreshold)
 	database, err := gorm.Open(mysql.New(mysql.Config{
-		DSN:                       config.MysqlDSN,
+		DSN:                       dbConfig.MysqlDSN,
 		SkipInitializeWithVersion: true,
 	}), &gorm.Config{
 		Logger:                                   gormLogger,
@@ -83,18 +75,72 @@ func Init() error {
 		return err
 	}
 
-	sqlDB, err := database.DB()
-	if err != nil {
-		zap.L().Error("get mysql sql.DB failed", zap.Error(err))
+	var replicas *dbresolver.DBResolver
+	if len(dbConfig.ReplicaDSNs) > 0 {
+		replicas = newReplicaResolver(replicaDialectors(dbConfig.ReplicaDSNs), dbConfig.ReplicaPolicy)
+		if err := database.Use(replicas); err != nil {
+			zap.L().Error("register mysql replicas failed", zap.Error(err))
+			if sqlDB, dbErr := database.DB(); dbErr == nil {
+				_ = sqlDB.Close()
+			}
+			return err
+		}
+	}
+
+	// 连接池参数先应用到新连接，成功后才发布为 client，失败时不留下未配置的连接
+	if err := applyPoolConfig(database, replicas); err != nil {
+		zap.L().Error("apply mysql pool config failed", zap.Error(err))
+		if sqlDB, dbErr := database.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
 		return err
 	}
+	client = database
+	resolver = replicas
+	initializedDSN = dbConfig.MysqlDSN
+	initializedReplicaDSNs = slices.Clone(dbConfig.ReplicaDSNs)
+	zap.L().Info("mysql client initialized", zap.Int("replicas", len(dbConfig.ReplicaDSNs)))
+	return nil
+}
 
-	sqlDB.SetMaxIdleConns(25)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
+// ApplyConfig 将热重载后的连接池参数与慢查询阈值应用到已初始化的 client
+// 主库与副本 DSN 的变更需要重启进程才能生效，这里只记录告警。
+func ApplyConfig() {
+	clientMu.Lock()
+	defer clientMu.Unlock()
 
-	client = database
+	if client == nil {
+		return
+	}
+	dbConfig := config.Current().Database
+	gormLogger.setSlowThreshold(dbConfig.SlowThreshold)
+	if err := applyPoolConfig(client, resolver); err != nil {
+		zap.L().Warn("apply mysql pool config failed", zap.Error(err))
+	}
+	if dbConfig.MysqlDSN != initializedDSN || !slices.Equal(dbConfig.ReplicaDSNs, initializedReplicaDSNs) {
+		zap.L().Warn("mysql dsn changes require a restart to take effect")
+	}
+}
+
+// applyPoolConfig 设置 database 主库与 replicas 中全部副本的连接池参数
+func applyPoolConfig(database *gorm.DB, replicas *dbresolver.DBResolver) error {
+	dbConfig := config.Current().Database
+	if replicas != nil {
+		replicas.SetMaxIdleConns(dbConfig.MaxIdleConns).
+			SetMaxOpenConns(dbConfig.MaxOpenConns).
+			SetConnMaxLifetime(dbConfig.ConnMaxLifetime).
+			SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+		return nil
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
 	return nil
 }
 
@@ -106,6 +152,21 @@ func CloseClient() {
 		return
 	}
 
+	if resolver != nil {
+		// 关闭主库与全部副本的连接池
+		_ = resolver.Call(func(connPool gorm.ConnPool) error {
+			if closer, ok := connPool.(interface{ Close() error }); ok {
+				if err := closer.Close(); err != nil {
+					zap.L().Warn("close mysql connection pool failed", zap.Error(err))
+				}
+			}
+			return nil
+		})
+		client = nil
+		resolver = nil
+		return
+	}
+
 	sqlDB, err := client.DB()
 	if err != nil {
 		zap.L().Warn("get mysql sql.DB before close failed", zap.Error(err))
@@ -8,9 +8,8 @@ import (
 )
 
 func TestInitRequiresMysqlDSN(t *testing.T) {
-	oldDSN := config.MysqlDSN
-	config.MysqlDSN = ""
-	t.Cleanup(func() { config.MysqlDSN = oldDSN })
+	oldConfig := config.Update(func(c *config.Config) { c.Database.MysqlDSN = "" })
+	t.Cleanup(func() { config.Replace(oldConfig) })
 
 	if err := Init(); !errors.Is(err, ErrMissingMysqlDSN) {
 		t.Fatalf("Init() error = %v, want %v", err, ErrMissingMysqlDSN)
@@ -18,9 +17,8 @@ func TestInitRequiresMysqlDSN(t *testing.T) {
 }
 
 func TestClientRequiresMysqlDSN(t *testing.T) {
-	oldDSN := config.MysqlDSN
-	config.MysqlDSN = ""
-	t.Cleanup(func() { config.MysqlDSN = oldDSN })
+	oldConfig := config.Update(func(c *config.Config) { c.Database.MysqlDSN = "" })
+	t.Cleanup(func() { config.Replace(oldConfig) })
 
 	client, err := Client()
 	if client != nil {
@@ -0,0 +1,110 @@
+package msqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	httplog "http-services/utils/log"
+)
+
+// gormLogger 是注册到 GORM 的日志实现，热重载时只更新慢查询阈值
+// 输出级别由日志模块 gorm 的级别（log.gorm_level 或运行期调整）决定：
+// 查询错误记 error，慢查询记 warn，调到 debug 时记录每条 SQL。
+var gormLogger = &zapGormLogger{level: logger.Warn, slowThreshold: new(atomic.Int64)}
+
+type zapGormLogger struct {
+	level         logger.LogLevel
+	slowThreshold *atomic.Int64 // LogMode 派生的 logger 共享同一个阈值
+}
+
+func (l *zapGormLogger) setSlowThreshold(threshold time.Duration) {
+	l.slowThreshold.Store(int64(threshold))
+}
+
+// LogMode 返回调整级别后的 logger（如 db.Debug() 传入 logger.Info，此时 SQL 以 info 级别输出）
+func (l *zapGormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	next := *l
+	next.level = level
+	return &next
+}
+
+func (l *zapGormLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Info {
+		l.write(ctx, zapcore.InfoLevel, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Warn {
+		l.write(ctx, zapcore.WarnLevel, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Error {
+		l.write(ctx, zapcore.ErrorLevel, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	threshold := time.Duration(l.slowThreshold.Load())
+
+	var (
+		level zapcore.Level
+		msg   string
+	)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		level, msg = zapcore.ErrorLevel, "sql error"
+	case threshold > 0 && elapsed > threshold && l.level >= logger.Warn:
+		level, msg = zapcore.WarnLevel, "slow sql"
+	case l.level >= logger.Info:
+		level, msg = zapcore.InfoLevel, "sql"
+	default:
+		level, msg = zapcore.DebugLevel, "sql"
+	}
+
+	ce := httplog.Named(httplog.ModuleGorm).Check(level, msg)
+	if ce == nil {
+		return
+	}
+	// 只有确定要输出时才调用 fc，避免关闭 debug 时拼接 SQL 的开销
+	sql, rows := fc()
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+	}
+	if level == zapcore.WarnLevel {
+		fields = append(fields, zap.Duration("threshold", threshold))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(withTraceID(ctx, fields)...)
+}
+
+func (l *zapGormLogger) write(ctx context.Context, level zapcore.Level, msg string) {
+	if ce := httplog.Named(httplog.ModuleGorm).Check(level, msg); ce != nil {
+		ce.Write(withTraceID(ctx, nil)...)
+	}
+}
+
+func withTraceID(ctx context.Context, fields []zap.Field) []zap.Field {
+	if traceID, ok := httplog.TraceID(ctx); ok {
+		return append(fields, zap.String("trace_id", traceID))
+	}
+	return fields
+}
@@ -0,0 +1,55 @@
+package msqldb
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	httplog "http-services/utils/log"
+)
+
+func TestGormLoggerTrace_OnlyRendersSQLWhenEnabled(t *testing.T) {
+	t.Cleanup(func() { _ = httplog.ResetLevel(httplog.ModuleGorm) })
+	gormLogger := &zapGormLogger{level: logger.Warn, slowThreshold: new(atomic.Int64)}
+	gormLogger.setSlowThreshold(time.Second)
+
+	calls := 0
+	fc := func() (string, int64) {
+		calls++
+		return "SELECT 1", 1
+	}
+
+	if err := httplog.SetLevel(httplog.ModuleGorm, zapcore.InfoLevel, 0); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	gormLogger.Trace(context.Background(), time.Now(), fc, nil)
+	gormLogger.Trace(context.Background(), time.Now(), fc, gorm.ErrRecordNotFound)
+	if calls != 0 {
+		t.Fatalf("fc called %d times for fast queries at info level, want 0", calls)
+	}
+
+	gormLogger.Trace(context.Background(), time.Now().Add(-2*time.Second), fc, nil)
+	gormLogger.Trace(context.Background(), time.Now(), fc, errors.New("boom"))
+	if calls != 2 {
+		t.Fatalf("fc called %d times for slow query and error, want 2", calls)
+	}
+
+	if err := httplog.SetLevel(httplog.ModuleGorm, zapcore.DebugLevel, 0); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	gormLogger.Trace(context.Background(), time.Now(), fc, nil)
+	if calls != 3 {
+		t.Fatalf("fc called %d times after enabling debug, want 3", calls)
+	}
+
+	gormLogger.LogMode(logger.Silent).Trace(context.Background(), time.Now(), fc, errors.New("boom"))
+	if calls != 3 {
+		t.Fatalf("fc called %d times in silent mode, want 3", calls)
+	}
+}
@@ -0,0 +1,8 @@
+package outbox
+
+import "gorm.io/gorm"
+
+// Migrate 创建或更新 outbox 表结构
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Event{})
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import "time"
+
+// Event 是 outbox 表中的一条待投递领域事件
+// 与业务数据在同一事务内写入；不嵌入 msqldb.BaseModel，投递完成的记录由 relay 物理清理而非软删除。
+// 事件有三种状态：待投递（DeliveredAt 与 FailedAt 均为空）、已投递（DeliveredAt 非空）、
+// 失败（FailedAt 非空，重试次数用尽，不再投递也不会被清理，需人工处理）。
+type Event struct {
+	ID          uint64     `gorm:"primarykey"`
+	Topic       string     `gorm:"size:128;not null"`
+	Key         string     `gorm:"size:128;not null;default:'';index:idx_outbox_key"` // 聚合 ID 等分区键，非空时同一 key 的事件按写入顺序投递
+	Payload     []byte     `gorm:"type:json;not null"`
+	TraceID     string     `gorm:"size:64;not null;default:''"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"size:1024;not null;default:''"`
+	AvailableAt time.Time  `gorm:"not null;index:idx_outbox_pending,priority:3"`
+	DeliveredAt *time.Time `gorm:"index:idx_outbox_pending,priority:1"`
+	FailedAt    *time.Time `gorm:"index:idx_outbox_pending,priority:2;index:idx_outbox_failed"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+}
+
+// TableName 固定表名，避免受 GORM 复数化规则影响
+func (Event) TableName() string {
+	return "outbox_events"
+}
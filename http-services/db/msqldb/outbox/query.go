@@ -0,0 +1,148 @@
+// Package outbox 提供事务型 outbox 表的模型、迁移与查询 helper。
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"http-services/utils/log"
+)
+
+// Add 在调用方的事务 tx 内写入一条事件，事件与业务数据同时提交或回滚
+// payload 会被编码为 JSON；ctx 中的 trace_id 随事件保存，便于跨进程串联日志。
+//
+//	err := database.Transaction(func(tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return outbox.Add(ctx, tx, "order.created", order.No, OrderCreated{No: order.No})
+//	})
+func Add(ctx context.Context, tx *gorm.DB, topic, key string, payload any) error {
+	if tx == nil {
+		return errors.New("outbox: transaction is nil")
+	}
+	if topic == "" {
+		return errors.New("outbox: topic is empty")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("outbox: encode %s payload: %w", topic, err)
+	}
+	traceID, _ := log.TraceID(ctx)
+	event := Event{
+		Topic:       topic,
+		Key:         key,
+		Payload:     body,
+		TraceID:     traceID,
+		AvailableAt: time.Now(),
+	}
+	return tx.WithContext(ctx).Create(&event).Error
+}
+
+// pendingInOrder 排除同一 key 下还有更早未投递事件的记录：前一个事件投递失败推迟重试时，
+// 后续事件不会越过它先投递。key 为空的事件不参与排序，失败状态的事件不再阻塞后续事件
+const pendingInOrder = "(`key` = '' OR NOT EXISTS (" +
+	"SELECT 1 FROM `outbox_events` AS prior WHERE prior.`key` = `outbox_events`.`key` AND prior.id < `outbox_events`.id " +
+	"AND prior.delivered_at IS NULL AND prior.failed_at IS NULL))"
+
+// Process 在单个事务内以 FOR UPDATE SKIP LOCKED 锁定最多 limit 条到期待投递事件，逐条调用 deliver
+// deliver 成功的事件标记为已投递；失败的事件记录错误并推迟到 retryAt(attempts) 再次投递，
+// 累计失败达到 maxAttempts 次（> 0 时生效）的事件标记为失败，不再投递。
+// 同一 key 每次只领取最早的待投递事件；多个 relay 实例并发执行时互不阻塞、不会领取同一条事件。
+func Process(
+	ctx context.Context,
+	db *gorm.DB,
+	now time.Time,
+	limit int,
+	maxAttempts int,
+	deliver func(context.Context, Event) error,
+	retryAt func(attempts int) time.Time,
+) (delivered, failed int, err error) {
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []Event
+		if err := tx.
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+			Where("delivered_at IS NULL AND failed_at IS NULL AND available_at <= ? AND "+pendingInOrder, now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return fmt.Errorf("claim outbox events: %w", err)
+		}
+
+		deliveredIDs := make([]uint64, 0, len(events))
+		for _, event := range events {
+			deliverErr := deliver(ctx, event)
+			if deliverErr == nil {
+				deliveredIDs = append(deliveredIDs, event.ID)
+				continue
+			}
+			failed++
+			attempts := event.Attempts + 1
+			updates := map[string]any{
+				"attempts":   attempts,
+				"last_error": truncate(deliverErr.Error(), 1024),
+			}
+			if maxAttempts > 0 && attempts >= maxAttempts {
+				updates["failed_at"] = now
+			} else {
+				updates["available_at"] = retryAt(attempts)
+			}
+			if err := tx.Model(&Event{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("mark outbox event %d failed: %w", event.ID, err)
+			}
+		}
+
+		if len(deliveredIDs) > 0 {
+			if err := tx.Model(&Event{}).Where("id IN ?", deliveredIDs).Updates(map[string]any{
+				"delivered_at": now,
+				"last_error":   "",
+			}).Error; err != nil {
+				return fmt.Errorf("mark outbox events delivered: %w", err)
+			}
+		}
+		delivered = len(deliveredIDs)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return delivered, failed, nil
+}
+
+// DeleteDelivered 删除 before 之前已投递的事件，单次最多删除 limit 条；失败状态的事件保留
+func DeleteDelivered(ctx context.Context, db *gorm.DB, before time.Time, limit int) (int64, error) {
+	result := db.WithContext(ctx).
+		Where("delivered_at IS NOT NULL AND delivered_at < ?", before).
+		Limit(limit).
+		Delete(&Event{})
+	return result.RowsAffected, result.Error
+}
+
+// Stats outbox 表中未投递事件的数量
+type Stats struct {
+	Pending int64 // 待投递，含等待重试的事件
+	Failed  int64 // 重试次数用尽、不再投递的事件
+}
+
+// CountStats 按状态统计未投递事件，已投递的事件不计入
+func CountStats(ctx context.Context, db *gorm.DB) (Stats, error) {
+	var stats Stats
+	err := db.WithContext(ctx).Model(&Event{}).
+		Select("COUNT(CASE WHEN failed_at IS NULL THEN 1 END) AS pending, COUNT(failed_at) AS failed").
+		Where("delivered_at IS NULL").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
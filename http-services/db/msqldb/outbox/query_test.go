@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"http-services/utils/log"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	database, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return database, mock
+}
+
+func TestAddInsertsEventInsideTransaction(t *testing.T) {
+	database, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox_events`")).
+		WithArgs("order.created", "A-1", []byte(`{"no":"A-1"}`), "trace-1", 0, "", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	ctx := log.WithTraceID(context.Background(), "trace-1")
+	err := database.Transaction(func(tx *gorm.DB) error {
+		return Add(ctx, tx, "order.created", "A-1", map[string]string{"no": "A-1"})
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddRejectsEmptyTopic(t *testing.T) {
+	database, _ := newMockDB(t)
+	if err := Add(context.Background(), database, "", "", nil); err == nil {
+		t.Fatal("Add() with empty topic error = nil")
+	}
+}
+
+func TestProcessMarksDeliveredAndFailedEvents(t *testing.T) {
+	database, mock := newMockDB(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	retryAt := now.Add(time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `outbox_events` WHERE delivered_at IS NULL AND failed_at IS NULL AND available_at <= ? AND "+pendingInOrder+
+			" ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED",
+	)).
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "key", "payload", "attempts"}).
+			AddRow(1, "order.created", "A-1", []byte(`{}`), 0).
+			AddRow(2, "order.created", "A-2", []byte(`{}`), 2).
+			AddRow(3, "order.created", "A-3", []byte(`{}`), 4))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `attempts`=?,`available_at`=?,`last_error`=? WHERE id = ?")).
+		WithArgs(3, retryAt, "broker down", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `attempts`=?,`failed_at`=?,`last_error`=? WHERE id = ?")).
+		WithArgs(5, now, "broker down", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `delivered_at`=?,`last_error`=? WHERE id IN (?)")).
+		WithArgs(now, "", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var gotAttempts int
+	delivered, failed, err := Process(context.Background(), database, now, 10, 5,
+		func(_ context.Context, event Event) error {
+			if event.ID != 1 {
+				return errors.New("broker down")
+			}
+			return nil
+		},
+		func(attempts int) time.Time {
+			gotAttempts = attempts
+			return retryAt
+		},
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if delivered != 1 || failed != 2 || gotAttempts != 3 {
+		t.Fatalf("Process() = %d delivered, %d failed, retry attempts %d", delivered, failed, gotAttempts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessRollsBackWhenClaimFails(t *testing.T) {
+	database, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	_, _, err := Process(context.Background(), database, time.Now(), 10, 5,
+		func(context.Context, Event) error { return nil },
+		func(int) time.Time { return time.Now() },
+	)
+	if err == nil {
+		t.Fatal("Process() error = nil, want claim error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteDeliveredUsesLimit(t *testing.T) {
+	database, mock := newMockDB(t)
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `outbox_events` WHERE delivered_at IS NOT NULL AND delivered_at < ? LIMIT ?",
+	)).
+		WithArgs(before, 500).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	mock.ExpectCommit()
+
+	deleted, err := DeleteDelivered(context.Background(), database, before, 500)
+	if err != nil || deleted != 42 {
+		t.Fatalf("DeleteDelivered() = %d, %v", deleted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCountStatsExcludesDeliveredEvents(t *testing.T) {
+	database, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT COUNT(CASE WHEN failed_at IS NULL THEN 1 END) AS pending, COUNT(failed_at) AS failed FROM `outbox_events` WHERE delivered_at IS NULL",
+	)).WillReturnRows(sqlmock.NewRows([]string{"pending", "failed"}).AddRow(7, 2))
+
+	stats, err := CountStats(context.Background(), database)
+	if err != nil || stats.Pending != 7 || stats.Failed != 2 {
+		t.Fatalf("CountStats() = %#v, %v, want 7 pending and 2 failed", stats, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+package msqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"http-services/utils/encryption"
+)
+
+// defaultReencryptBatchSize 是每批扫描的行数，避免一次性加载整表
+const defaultReencryptBatchSize = 500
+
+// EncryptedColumns 描述一张表中保存 encryption.EncryptedString 密文的列
+type EncryptedColumns struct {
+	Table      string // 必须与 GORM 模型的表名一致，加密明文时作为附加数据
+	PrimaryKey string // 为空时使用 id
+	Columns    []string
+	// EncryptPlaintext 为 true 时同时加密尚未加密的明文值，用于把已有明文列迁移为加密列
+	EncryptPlaintext bool
+	BatchSize        int
+}
+
+// ReencryptStats 是一次重加密的统计
+type ReencryptStats struct {
+	Scanned   int // 扫描的行数
+	Updated   int // 更新的行数
+	Conflicts int // 扫描后被并发修改而跳过的行数；并发写入已使用 primary 密钥，无需处理
+	Plaintext int // 未加密且未开启 EncryptPlaintext 而跳过的值
+}
+
+// Reencrypt 按主键分批扫描 target，把非 primary 密钥包装的密文改为 primary 包装。
+// 更新时以旧值为条件，不会覆盖扫描期间的并发写入；可重复执行，完成后即可从配置中移除旧密钥。
+func Reencrypt(ctx context.Context, db *gorm.DB, keyring *encryption.Keyring, target EncryptedColumns) (ReencryptStats, error) {
+	var stats ReencryptStats
+	if db == nil || keyring == nil {
+		return stats, errors.New("reencrypt: database and keyring are required")
+	}
+	if target.Table == "" || len(target.Columns) == 0 {
+		return stats, errors.New("reencrypt: table and columns are required")
+	}
+	pk := target.PrimaryKey
+	if pk == "" {
+		pk = "id"
+	}
+	batchSize := target.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReencryptBatchSize
+	}
+	selected := clause.Select{Columns: []clause.Column{{Name: pk}}}
+	for _, column := range target.Columns {
+		selected.Columns = append(selected.Columns, clause.Column{Name: column})
+	}
+
+	var cursor any
+	for {
+		query := db.WithContext(ctx).Table(target.Table).Clauses(selected).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: pk}}).Limit(batchSize)
+		if cursor != nil {
+			query = query.Where(clause.Gt{Column: clause.Column{Name: pk}, Value: cursor})
+		}
+		rows, err := query.Rows()
+		if err != nil {
+			return stats, fmt.Errorf("reencrypt %s: scan: %w", target.Table, err)
+		}
+		batch, err := scanEncryptedRows(rows, len(target.Columns))
+		if err != nil {
+			return stats, fmt.Errorf("reencrypt %s: scan: %w", target.Table, err)
+		}
+		for _, row := range batch {
+			stats.Scanned++
+			if err := reencryptRow(ctx, db, keyring, target, pk, row, &stats); err != nil {
+				return stats, fmt.Errorf("reencrypt %s %s=%v: %w", target.Table, pk, row.key, err)
+			}
+		}
+		if len(batch) < batchSize {
+			return stats, nil
+		}
+		cursor = batch[len(batch)-1].key
+	}
+}
+
+type encryptedRow struct {
+	key    any
+	values []sql.NullString
+}
+
+func scanEncryptedRows(rows *sql.Rows, columns int) ([]encryptedRow, error) {
+	defer rows.Close()
+	var batch []encryptedRow
+	for rows.Next() {
+		row := encryptedRow{values: make([]sql.NullString, columns)}
+		dest := []any{&row.key}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		// 驱动复用 []byte 缓冲区，作为下一批游标前需要复制
+		if b, ok := row.key.([]byte); ok {
+			row.key = string(b)
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+func reencryptRow(ctx context.Context, db *gorm.DB, keyring *encryption.Keyring, target EncryptedColumns, pk string, row encryptedRow, stats *ReencryptStats) error {
+	updates := make(map[string]any)
+	query := db.WithContext(ctx).Table(target.Table).Where(clause.Eq{Column: clause.Column{Name: pk}, Value: row.key})
+	for i, column := range target.Columns {
+		value := row.values[i]
+		if !value.Valid || value.String == "" {
+			continue
+		}
+		var (
+			next string
+			err  error
+		)
+		switch {
+		case encryption.IsEncrypted(value.String):
+			if !keyring.NeedsReencrypt(value.String) {
+				continue
+			}
+			next, err = keyring.Reencrypt(value.String)
+		case target.EncryptPlaintext:
+			next, err = keyring.Encrypt([]byte(value.String), encryption.ColumnAAD(target.Table, column))
+		default:
+			stats.Plaintext++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+		updates[column] = next
+		query = query.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value.String})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	result := query.UpdateColumns(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		stats.Conflicts++
+		zap.L().Info("row changed during reencryption, skipped",
+			zap.String("table", target.Table),
+			zap.Any(pk, row.key),
+		)
+		return nil
+	}
+	stats.Updated++
+	return nil
+}
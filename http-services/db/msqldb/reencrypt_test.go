@@ -0,0 +1,109 @@
+package msqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+
+	"http-services/utils/encryption"
+)
+
+// wrappedBy 匹配由指定主密钥包装的密文参数
+type wrappedBy string
+
+func (w wrappedBy) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, "enc1:"+string(w)+":")
+}
+
+// encryptedFor 匹配以 aad 加密、可解密为 plaintext 的密文参数
+type encryptedFor struct {
+	keyring   *encryption.Keyring
+	aad       []byte
+	plaintext string
+}
+
+func (e encryptedFor) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	plaintext, err := e.keyring.Decrypt(s, e.aad)
+	return err == nil && string(plaintext) == e.plaintext
+}
+
+func TestReencryptRewrapsOldKeysAndSkipsConcurrentWrites(t *testing.T) {
+	mock := useMockClient(t)
+	database, _ := DB(context.Background())
+	database = database.Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	keys := map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32), "k2": bytes.Repeat([]byte{2}, 32)}
+	old, _ := encryption.NewKeyring("k1", keys, nil)
+	keyring, _ := encryption.NewKeyring("k2", keys, nil)
+	phone1, _ := old.Encrypt([]byte("13800138001"), encryption.ColumnAAD("users", "phone"))
+	phone2, _ := keyring.Encrypt([]byte("13800138002"), encryption.ColumnAAD("users", "phone"))
+	phone3, _ := old.Encrypt([]byte("13800138003"), encryption.ColumnAAD("users", "phone"))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`phone` FROM `users` ORDER BY `id` LIMIT ?")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phone"}).AddRow(1, phone1).AddRow(2, phone2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `phone`=? WHERE `id` = ? AND `phone` = ?")).
+		WithArgs(wrappedBy("k2"), 1, phone1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`phone` FROM `users` WHERE `id` > ? ORDER BY `id` LIMIT ?")).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phone"}).AddRow(3, phone3).AddRow(4, "13800138004"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `phone`=? WHERE `id` = ? AND `phone` = ?")).
+		WithArgs(wrappedBy("k2"), 3, phone3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`phone` FROM `users` WHERE `id` > ? ORDER BY `id` LIMIT ?")).
+		WithArgs(4, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phone"}))
+
+	stats, err := Reencrypt(context.Background(), database, keyring, EncryptedColumns{
+		Table:     "users",
+		Columns:   []string{"phone"},
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("Reencrypt() error = %v", err)
+	}
+	if stats != (ReencryptStats{Scanned: 4, Updated: 1, Conflicts: 1, Plaintext: 1}) {
+		t.Fatalf("Reencrypt() stats = %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReencryptEncryptsPlaintextWhenEnabled(t *testing.T) {
+	mock := useMockClient(t)
+	database, _ := DB(context.Background())
+	database = database.Session(&gorm.Session{SkipDefaultTransaction: true})
+	keyring, _ := encryption.NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `uid`,`id_number` FROM `profiles` ORDER BY `uid` LIMIT ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "id_number"}).AddRow("u1", "110101199001011234").AddRow("u2", nil))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `profiles` SET `id_number`=? WHERE `uid` = ? AND `id_number` = ?")).
+		WithArgs(encryptedFor{keyring, encryption.ColumnAAD("profiles", "id_number"), "110101199001011234"}, "u1", "110101199001011234").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stats, err := Reencrypt(context.Background(), database, keyring, EncryptedColumns{
+		Table:            "profiles",
+		PrimaryKey:       "uid",
+		Columns:          []string{"id_number"},
+		EncryptPlaintext: true,
+	})
+	if err != nil || stats.Updated != 1 || stats.Scanned != 2 {
+		t.Fatalf("Reencrypt() = %+v, %v", stats, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,63 @@
+package msqldb
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	// ReplicaPolicyRandom 随机选择副本
+	ReplicaPolicyRandom = "random"
+	// ReplicaPolicyRoundRobin 按顺序轮询副本
+	ReplicaPolicyRoundRobin = "round_robin"
+)
+
+type primaryContextKey struct{}
+
+// replicaDialectors 为每个副本 DSN 创建 MySQL dialector
+func replicaDialectors(dsns []string) []gorm.Dialector {
+	replicas := make([]gorm.Dialector, 0, len(dsns))
+	for _, dsn := range dsns {
+		replicas = append(replicas, mysql.New(mysql.Config{
+			DSN:                       dsn,
+			SkipInitializeWithVersion: true,
+		}))
+	}
+	return replicas
+}
+
+// newReplicaResolver 构建读写分离插件：写入、事务与带锁查询走主库，其余查询按策略分发到副本
+func newReplicaResolver(replicas []gorm.Dialector, policy string) *dbresolver.DBResolver {
+	return dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   replicaPolicy(policy),
+	})
+}
+
+func replicaPolicy(name string) dbresolver.Policy {
+	switch name {
+	case ReplicaPolicyRoundRobin:
+		return dbresolver.StrictRoundRobinPolicy()
+	case "", ReplicaPolicyRandom:
+		return dbresolver.RandomPolicy{}
+	default:
+		zap.L().Warn("unknown mysql replica policy, falling back to random", zap.String("policy", name))
+		return dbresolver.RandomPolicy{}
+	}
+}
+
+// WithPrimary 标记 ctx 中后续通过 DB(ctx) 发起的查询都走主库
+// 用于写入后立即读取（read your writes），避免副本复制延迟读到旧数据。
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// UsesPrimary 判断 ctx 是否要求读取主库
+func UsesPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
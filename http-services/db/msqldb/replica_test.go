@@ -0,0 +1,209 @@
+package msqldb
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+
+	"http-services/config"
+)
+
+type replicaTestUser struct {
+	ID   uint
+	Name string
+}
+
+func newMockConn(t *testing.T) (gorm.Dialector, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return gormmysql.New(gormmysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), mock
+}
+
+// useMockReplicaClient 将全局 client 替换为一个主库加两个副本的 sqlmock 组合
+func useMockReplicaClient(t *testing.T) (primary sqlmock.Sqlmock, replicas []sqlmock.Sqlmock) {
+	t.Helper()
+	primaryDialector, primary := newMockConn(t)
+	database, err := gorm.Open(primaryDialector, &gorm.Config{Logger: logger.Discard, DisableNestedTransaction: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	var dialectors []gorm.Dialector
+	for range 2 {
+		dialector, mock := newMockConn(t)
+		dialectors = append(dialectors, dialector)
+		replicas = append(replicas, mock)
+	}
+	replicaResolver := newReplicaResolver(dialectors, ReplicaPolicyRoundRobin)
+	if err := database.Use(replicaResolver); err != nil {
+		t.Fatalf("Use(resolver) error = %v", err)
+	}
+
+	clientMu.Lock()
+	oldClient, oldResolver := client, resolver
+	client, resolver = database, replicaResolver
+	clientMu.Unlock()
+	t.Cleanup(func() {
+		clientMu.Lock()
+		client, resolver = oldClient, oldResolver
+		clientMu.Unlock()
+	})
+	return primary, replicas
+}
+
+func expectSelect(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+}
+
+func verify(t *testing.T, mocks ...sqlmock.Sqlmock) {
+	t.Helper()
+	for _, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDBRoutesReadsToReplicasAndWritesToPrimary(t *testing.T) {
+	primary, replicas := useMockReplicaClient(t)
+	// StrictRoundRobinPolicy 从第二个副本开始轮询
+	expectSelect(replicas[1])
+	expectSelect(replicas[0])
+	primary.ExpectBegin()
+	primary.ExpectExec("INSERT INTO `replica_test_users`").WillReturnResult(sqlmock.NewResult(2, 1))
+	primary.ExpectCommit()
+
+	ctx := context.Background()
+	for range 2 {
+		database, err := DB(ctx)
+		if err != nil {
+			t.Fatalf("DB() error = %v", err)
+		}
+		var user replicaTestUser
+		if err := database.First(&user).Error; err != nil {
+			t.Fatalf("First() error = %v", err)
+		}
+	}
+	database, _ := DB(ctx)
+	if err := database.Create(&replicaTestUser{Name: "b"}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	verify(t, append(replicas, primary)...)
+}
+
+func TestDBWithPrimaryReadsFromPrimary(t *testing.T) {
+	primary, replicas := useMockReplicaClient(t)
+	expectSelect(primary)
+
+	database, err := DB(WithPrimary(context.Background()))
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	var user replicaTestUser
+	if err := database.First(&user).Error; err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	verify(t, append(replicas, primary)...)
+}
+
+func TestWithTxReadsFromPrimary(t *testing.T) {
+	primary, replicas := useMockReplicaClient(t)
+	primary.ExpectBegin()
+	expectSelect(primary)
+	primary.ExpectCommit()
+
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		database, err := DB(ctx)
+		if err != nil {
+			return err
+		}
+		var user replicaTestUser
+		return database.First(&user).Error
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	verify(t, append(replicas, primary)...)
+}
+
+func TestApplyConfigUpdatesPoolLimits(t *testing.T) {
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	database, err := gorm.Open(gormmysql.New(gormmysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	oldConfig := config.Current()
+	clientMu.Lock()
+	oldClient, oldResolver := client, resolver
+	client, resolver = database, nil
+	clientMu.Unlock()
+	t.Cleanup(func() {
+		config.Replace(oldConfig)
+		clientMu.Lock()
+		client, resolver = oldClient, oldResolver
+		clientMu.Unlock()
+	})
+
+	config.Update(func(c *config.Config) {
+		c.Database.MaxOpenConns = 7
+		c.Database.SlowThreshold = time.Second
+	})
+	ApplyConfig()
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != 7 {
+		t.Fatalf("MaxOpenConnections = %d, want 7", got)
+	}
+	if got := time.Duration(gormLogger.slowThreshold.Load()); got != time.Second {
+		t.Fatalf("slow threshold = %v, want 1s", got)
+	}
+}
+
+func TestReplicaPolicy(t *testing.T) {
+	if _, ok := replicaPolicy("unknown").(dbresolver.RandomPolicy); !ok {
+		t.Fatal("replicaPolicy(unknown) is not RandomPolicy")
+	}
+	if _, ok := replicaPolicy("").(dbresolver.RandomPolicy); !ok {
+		t.Fatal("replicaPolicy(\"\") is not RandomPolicy")
+	}
+
+	pools := []gorm.ConnPool{&gorm.PreparedStmtDB{}, &gorm.PreparedStmtDB{}}
+	policy := replicaPolicy(ReplicaPolicyRoundRobin)
+	first, second, third := policy.Resolve(pools), policy.Resolve(pools), policy.Resolve(pools)
+	if first == second || first != third {
+		t.Fatal("round_robin policy does not alternate between replicas")
+	}
+}
+
+func TestWithPrimary(t *testing.T) {
+	if UsesPrimary(context.Background()) || !UsesPrimary(WithPrimary(context.Background())) {
+		t.Fatal("UsesPrimary() does not reflect WithPrimary")
+	}
+}
+
+func TestStatsNamesPrimaryAndReplicaPools(t *testing.T) {
+	useMockReplicaClient(t)
+
+	stats := Stats()
+	var names []string
+	for _, pool := range stats {
+		names = append(names, pool.Name)
+	}
+	if want := []string{"primary", "replica-1", "replica-2"}; !slices.Equal(names, want) {
+		t.Fatalf("Stats() pools = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,63 @@
+package msqldb
+
+import (
+	"database/sql"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// PoolStats 是一个连接池的 database/sql 统计
+type PoolStats struct {
+	Name string `json:"name"` // primary 或 replica-<序号>
+	sql.DBStats
+}
+
+// Stats 返回主库与各副本的连接池统计；client 未初始化时返回 nil，不会触发连接
+func Stats() []PoolStats {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	if resolver == nil {
+		sqlDB, err := client.DB()
+		if err != nil {
+			return nil
+		}
+		return []PoolStats{{Name: "primary", DBStats: sqlDB.Stats()}}
+	}
+
+	// resolver 先遍历主库再按配置顺序遍历副本
+	var stats []PoolStats
+	_ = resolver.Call(func(connPool gorm.ConnPool) error {
+		sqlDB := poolDB(connPool)
+		if sqlDB == nil {
+			return nil
+		}
+		name := "primary"
+		if len(stats) > 0 {
+			name = "replica-" + strconv.Itoa(len(stats))
+		}
+		stats = append(stats, PoolStats{Name: name, DBStats: sqlDB.Stats()})
+		return nil
+	})
+	return stats
+}
+
+// poolDB 取出连接池底层的 *sql.DB；开启 PrepareStmt 时连接池被 gorm.PreparedStmtDB 包装
+func poolDB(connPool gorm.ConnPool) *sql.DB {
+	switch pool := connPool.(type) {
+	case *sql.DB:
+		return pool
+	case gorm.GetDBConnector:
+		sqlDB, err := pool.GetDBConn()
+		if err != nil {
+			return nil
+		}
+		return sqlDB
+	default:
+		return nil
+	}
+}
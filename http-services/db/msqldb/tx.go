@@ -0,0 +1,221 @@
+package msqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	httplog "http-services/utils/log"
+)
+
+const (
+	// mysqlErrLockDeadlock 死锁，MySQL 已回滚整个事务
+	mysqlErrLockDeadlock = 1213
+	// mysqlErrLockWaitTimeout 锁等待超时（innodb_lock_wait_timeout）
+	mysqlErrLockWaitTimeout = 1205
+
+	defaultTxMaxAttempts = 3
+	txRetryBaseDelay     = 20 * time.Millisecond
+)
+
+type txContextKey struct{}
+
+// unitOfWork 是存放在 context 中的事务状态，同一最外层事务内的嵌套调用共享它
+type unitOfWork struct {
+	tx          *gorm.DB
+	savepoints  int
+	afterCommit []func(ctx context.Context)
+}
+
+type txOptions struct {
+	maxAttempts int
+}
+
+// TxOption 调整 WithTx 的行为
+type TxOption func(*txOptions)
+
+// WithMaxAttempts 设置遇到死锁或锁等待超时时最外层事务的最大执行次数（含首次）
+func WithMaxAttempts(n int) TxOption {
+	return func(opts *txOptions) {
+		if n > 0 {
+			opts.maxAttempts = n
+		}
+	}
+}
+
+// WithTx 在事务中执行 fn，事务通过 ctx 传递给 fn 内部调用的 DB(ctx)
+// 最外层调用负责 BEGIN/COMMIT，遇到死锁或锁等待超时时整体重试，因此 fn 需可重复执行；
+// 嵌套调用使用 SAVEPOINT，fn 成功时释放保存点，出错时只回滚到该保存点，错误仍原样返回给外层决定是否继续。
+//
+//	err := msqldb.WithTx(ctx, func(ctx context.Context) error {
+//	    if err := userdb.Create(ctx, &user); err != nil {
+//	        return err
+//	    }
+//	    msqldb.AfterCommit(ctx, func(ctx context.Context) { cache.Delete(ctx, key) })
+//	    return balancedb.Init(ctx, user.ID)
+//	})
+func WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if uow, ok := ctx.Value(txContextKey{}).(*unitOfWork); ok {
+		return runSavepoint(ctx, uow, fn)
+	}
+	database, err := Client()
+	if err != nil {
+		return err
+	}
+	return runTx(ctx, database, fn, opts...)
+}
+
+// DB 返回 ctx 中的事务；不在事务中时返回绑定 ctx 的全局 client
+// repository 层统一通过 DB(ctx) 访问数据库，即可自动加入调用方开启的事务；
+// 配置了只读副本时，ctx 经 WithPrimary 标记后查询也固定走主库。
+func DB(ctx context.Context) (*gorm.DB, error) {
+	if uow, ok := ctx.Value(txContextKey{}).(*unitOfWork); ok {
+		return uow.tx.WithContext(ctx), nil
+	}
+	database, err := Client()
+	if err != nil {
+		return nil, err
+	}
+	database = database.WithContext(ctx)
+	if UsesPrimary(ctx) {
+		database = database.Clauses(dbresolver.Write)
+	}
+	return database, nil
+}
+
+// HasTx 判断 ctx 是否处于 WithTx 开启的事务中
+func HasTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*unitOfWork)
+	return ok
+}
+
+// AfterCommit 注册在最外层事务提交成功后执行的回调，适合缓存失效、发布事件等副作用
+// 事务回滚、所在保存点回滚或重试前的失败尝试中注册的回调都会被丢弃；不在事务中时立即执行。
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if uow, ok := ctx.Value(txContextKey{}).(*unitOfWork); ok {
+		uow.afterCommit = append(uow.afterCommit, fn)
+		return
+	}
+	runHook(ctx, fn)
+}
+
+func runTx(ctx context.Context, database *gorm.DB, fn func(ctx context.Context) error, opts ...TxOption) error {
+	options := txOptions{maxAttempts: defaultTxMaxAttempts}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for attempt := 1; ; attempt++ {
+		uow, err := runTxOnce(ctx, database, fn)
+		if err == nil {
+			for _, hook := range uow.afterCommit {
+				runHook(ctx, hook)
+			}
+			return nil
+		}
+		if !IsRetryableTxError(err) || attempt >= options.maxAttempts {
+			return err
+		}
+
+		delay := time.Duration(attempt)*txRetryBaseDelay + rand.N(txRetryBaseDelay)
+		httplog.FromStandardContext(ctx).Warn("mysql transaction conflict, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func runTxOnce(ctx context.Context, database *gorm.DB, fn func(ctx context.Context) error) (*unitOfWork, error) {
+	tx := database.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("begin transaction: %w", tx.Error)
+	}
+	uow := &unitOfWork{tx: tx}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		// fn panic 时同样回滚，再继续向上抛出
+		if rollbackErr := tx.Rollback().Error; rollbackErr != nil && !errors.Is(rollbackErr, gorm.ErrInvalidTransaction) {
+			zap.L().Warn("rollback transaction failed", zap.Error(rollbackErr))
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, uow)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	committed = true
+	return uow, nil
+}
+
+func runSavepoint(ctx context.Context, uow *unitOfWork, fn func(ctx context.Context) error) error {
+	uow.savepoints++
+	name := fmt.Sprintf("sp%d", uow.savepoints)
+	if err := uow.tx.SavePoint(name).Error; err != nil {
+		return fmt.Errorf("create savepoint %s: %w", name, err)
+	}
+	hooks := len(uow.afterCommit)
+
+	rollback := func() {
+		uow.afterCommit = uow.afterCommit[:hooks]
+		if rollbackErr := uow.tx.RollbackTo(name).Error; rollbackErr != nil {
+			zap.L().Warn("rollback to savepoint failed", zap.String("savepoint", name), zap.Error(rollbackErr))
+		}
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			rollback()
+			panic(recovered)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		rollback()
+		return err
+	}
+	// 成功后释放保存点，避免循环或深层嵌套的 WithTx 在整个事务期间累积保存点
+	if err := uow.tx.Exec("RELEASE SAVEPOINT " + name).Error; err != nil {
+		rollback()
+		return fmt.Errorf("release savepoint %s: %w", name, err)
+	}
+	return nil
+}
+
+// runHook 执行提交后回调；回调 panic 只记录日志，不影响已提交的事务结果与其他回调
+func runHook(ctx context.Context, hook func(ctx context.Context)) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			httplog.FromStandardContext(ctx).Error("after-commit hook panicked", zap.Any("panic", recovered), zap.Stack("stack"))
+		}
+	}()
+	hook(ctx)
+}
+
+// IsRetryableTxError 判断错误是否为可整体重试的事务冲突（死锁、锁等待超时）
+func IsRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
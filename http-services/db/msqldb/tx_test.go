@@ -0,0 +1,225 @@
+package msqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useMockClient 将全局 client 替换为 sqlmock 驱动的 GORM 实例
+func useMockClient(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	database, err := gorm.Open(gormmysql.New(gormmysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                   logger.Discard,
+		DisableNestedTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	clientMu.Lock()
+	oldClient := client
+	client = database
+	clientMu.Unlock()
+	t.Cleanup(func() {
+		clientMu.Lock()
+		client = oldClient
+		clientMu.Unlock()
+		_ = sqlDB.Close()
+	})
+	return mock
+}
+
+func execInTx(ctx context.Context, query string) error {
+	database, err := DB(ctx)
+	if err != nil {
+		return err
+	}
+	return database.Exec(query).Error
+}
+
+func TestWithTxCommitsAndRunsAfterCommitHooks(t *testing.T) {
+	mock := useMockClient(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	var hookRan bool
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		if !HasTx(ctx) {
+			t.Fatal("HasTx() = false inside WithTx")
+		}
+		AfterCommit(ctx, func(context.Context) {
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("hook ran before commit: %v", err)
+			}
+			hookRan = true
+		})
+		return execInTx(ctx, "INSERT INTO users (name) VALUES ('a')")
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if !hookRan {
+		t.Fatal("after-commit hook did not run")
+	}
+}
+
+func TestWithTxRollsBackOnErrorAndDropsHooks(t *testing.T) {
+	mock := useMockClient(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	errBusiness := errors.New("insufficient balance")
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(context.Context) { t.Error("hook ran after rollback") })
+		return errBusiness
+	})
+	if !errors.Is(err, errBusiness) {
+		t.Fatalf("WithTx() error = %v, want %v", err, errBusiness)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	mock := useMockClient(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was swallowed")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	_ = WithTx(context.Background(), func(context.Context) error { panic("boom") })
+}
+
+func TestWithTxNestedUsesSavepoints(t *testing.T) {
+	mock := useMockClient(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO audit").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var hooks []string
+	errOptional := errors.New("optional step failed")
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "outer") })
+		nestedErr := WithTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "rolled-back") })
+			if err := execInTx(ctx, "INSERT INTO audit (id) VALUES (1)"); err != nil {
+				return err
+			}
+			return errOptional
+		})
+		if !errors.Is(nestedErr, errOptional) {
+			t.Fatalf("nested WithTx() error = %v, want %v", nestedErr, errOptional)
+		}
+		return WithTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "inner") })
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(hooks) != "[outer inner]" {
+		t.Fatalf("hooks = %v, want [outer inner]", hooks)
+	}
+}
+
+func TestWithTxRetriesDeadlock(t *testing.T) {
+	mock := useMockClient(t)
+	deadlock := &mysql.MySQLError{Number: mysqlErrLockDeadlock, Message: "Deadlock found when trying to get lock"}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnError(deadlock)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	hookRuns := 0
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		AfterCommit(ctx, func(context.Context) { hookRuns++ })
+		return execInTx(ctx, "UPDATE accounts SET balance = balance - 1")
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if attempts != 2 || hookRuns != 1 {
+		t.Fatalf("attempts = %d, hook runs = %d, want 2 and 1", attempts, hookRuns)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTxStopsRetryingAfterMaxAttempts(t *testing.T) {
+	mock := useMockClient(t)
+	lockWait := &mysql.MySQLError{Number: mysqlErrLockWaitTimeout, Message: "Lock wait timeout exceeded"}
+	for range 2 {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE accounts").WillReturnError(lockWait)
+		mock.ExpectRollback()
+	}
+
+	err := WithTx(context.Background(), func(ctx context.Context) error {
+		return execInTx(ctx, "UPDATE accounts SET balance = 0")
+	}, WithMaxAttempts(2))
+	if !IsRetryableTxError(err) {
+		t.Fatalf("WithTx() error = %v, want lock wait timeout", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAfterCommitOutsideTxRunsImmediately(t *testing.T) {
+	ran := false
+	AfterCommit(context.Background(), func(context.Context) { ran = true })
+	if !ran {
+		t.Fatal("AfterCommit() outside transaction did not run hook")
+	}
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: mysqlErrLockDeadlock}, true},
+		{fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: mysqlErrLockWaitTimeout}), true},
+		{&mysql.MySQLError{Number: 1062}, false},
+		{errors.New("deadlock"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableTxError(tt.err); got != tt.want {
+			t.Errorf("IsRetryableTxError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
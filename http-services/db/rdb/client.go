@@ -36,17 +36,19 @@ func Init() error {
 	if client != nil {
 		return nil
 	}
-	if strings.TrimSpace(config.RedisHost) == "" {
+	redisConfig := config.Current().Redis
+	if strings.TrimSpace(redisConfig.Host) == "" {
 		return ErrMissingRedisHost
 	}
 
 	redisClient := redis.NewClient(&redis.Options{
-		Addr:         config.RedisHost,
-		Password:     config.RedisPassword,
+		Addr:         redisConfig.Host,
+		Password:     redisConfig.Password,
 		PoolSize:     100,
 		MinIdleConns: 10,
 	})
-	addRedisKeyPrefixHook(redisClient, config.RedisKeyPrefix)
+	addRedisKeyPrefixHook(redisClient, redisConfig.KeyPrefix)
+	addRedisLogHook(redisClient)
 
 	if err := redisClient.Ping(context.Background()).Err(); err != nil {
 		_ = redisClient.Close()
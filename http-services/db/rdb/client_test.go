@@ -8,9 +8,8 @@ import (
 )
 
 func TestInitRequiresRedisHost(t *testing.T) {
-	oldHost := config.RedisHost
-	config.RedisHost = ""
-	t.Cleanup(func() { config.RedisHost = oldHost })
+	oldConfig := config.Update(func(c *config.Config) { c.Redis.Host = "" })
+	t.Cleanup(func() { config.Replace(oldConfig) })
 
 	if err := Init(); !errors.Is(err, ErrMissingRedisHost) {
 		t.Fatalf("Init() error = %v, want %v", err, ErrMissingRedisHost)
@@ -18,9 +17,8 @@ func TestInitRequiresRedisHost(t *testing.T) {
 }
 
 func TestClientRequiresRedisHost(t *testing.T) {
-	oldHost := config.RedisHost
-	config.RedisHost = ""
-	t.Cleanup(func() { config.RedisHost = oldHost })
+	oldConfig := config.Update(func(c *config.Config) { c.Redis.Host = "" })
+	t.Cleanup(func() { config.Replace(oldConfig) })
 
 	client, err := Client()
 	if client != nil {
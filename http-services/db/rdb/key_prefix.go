@@ -49,7 +49,8 @@ func prefixRedisCommandArgs(prefix string, arguments []any) {
 		return
 	}
 	switch redisCommandName(arguments[0]) {
-	case "get", "set", "setnx", "getdel", "hget", "hset", "expire", "ttl", "sadd", "srem", "smembers", "incr", "decr", "incrby":
+	case "get", "set", "setnx", "getdel", "hget", "hset", "expire", "ttl", "sadd", "srem", "smembers", "incr", "decr", "incrby",
+		"xadd", "xlen", "xrange", "xrevrange", "xtrim", "xdel":
 		prefixRedisKeyArg(prefix, arguments, 1)
 	case "del", "exists", "mget":
 		prefixRedisKeyArgRange(prefix, arguments, 1, len(arguments))
@@ -18,6 +18,8 @@ func TestPrefixRedisCommandArgsPrefixesSimpleAndMultipleKeys(t *testing.T) {
 		{[]any{"rename", "live:key", "snapshot:key"}, []any{"rename", "app:live:key", "app:snapshot:key"}},
 		{[]any{"evalsha", "sha", 2, "key:a", "key:b", "argv:a"}, []any{"evalsha", "sha", 2, "app:key:a", "app:key:b", "argv:a"}},
 		{[]any{"scan", uint64(0), "match", "cache:*"}, []any{"scan", uint64(0), "match", "app:cache:*"}},
+		{[]any{"xadd", "outbox:order", "maxlen", "~", 10, "*", "event_id", "1"}, []any{"xadd", "app:outbox:order", "maxlen", "~", 10, "*", "event_id", "1"}},
+		{[]any{"xrange", "outbox:order", "-", "+"}, []any{"xrange", "app:outbox:order", "-", "+"}},
 	}
 	for _, testCase := range tests {
 		command := redis.NewCmd(context.Background(), testCase.args...)
@@ -0,0 +1,86 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	httplog "http-services/utils/log"
+)
+
+var setRedisLoggerOnce sync.Once
+
+// redisLogger 将 go-redis 内部日志（重连、连接池告警等）转发到 redis 模块 logger
+type redisLogger struct{}
+
+func (redisLogger) Printf(_ context.Context, format string, v ...any) {
+	l := httplog.Named(httplog.ModuleRedis)
+	if ce := l.Check(zapcore.WarnLevel, strings.TrimSpace(fmt.Sprintf(format, v...))); ce != nil {
+		ce.Write()
+	}
+}
+
+// redisLogHook 在 redis 模块调到 debug 时记录每条命令的名称、耗时与错误，不记录参数以免泄露数据
+type redisLogHook struct{}
+
+func addRedisLogHook(client *redis.Client) {
+	setRedisLoggerOnce.Do(func() { redis.SetLogger(redisLogger{}) })
+	if client != nil {
+		client.AddHook(redisLogHook{})
+	}
+}
+
+func (redisLogHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return next(ctx, network, address)
+	}
+}
+
+func (redisLogHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, command redis.Cmder) error {
+		l := httplog.Named(httplog.ModuleRedis)
+		if !l.Core().Enabled(zapcore.DebugLevel) {
+			return next(ctx, command)
+		}
+		begin := time.Now()
+		err := next(ctx, command)
+		logRedisCommand(ctx, l, command.FullName(), 1, time.Since(begin), err)
+		return err
+	}
+}
+
+func (redisLogHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, commands []redis.Cmder) error {
+		l := httplog.Named(httplog.ModuleRedis)
+		if !l.Core().Enabled(zapcore.DebugLevel) {
+			return next(ctx, commands)
+		}
+		begin := time.Now()
+		err := next(ctx, commands)
+		logRedisCommand(ctx, l, "pipeline", len(commands), time.Since(begin), err)
+		return err
+	}
+}
+
+func logRedisCommand(ctx context.Context, l *zap.Logger, name string, count int, elapsed time.Duration, err error) {
+	ce := l.Check(zapcore.DebugLevel, "redis command")
+	if ce == nil {
+		return
+	}
+	fields := []zap.Field{zap.String("command", name), zap.Int("count", count), zap.Duration("elapsed", elapsed)}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		fields = append(fields, zap.Error(err))
+	}
+	if traceID, ok := httplog.TraceID(ctx); ok {
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	ce.Write(fields...)
+}
@@ -0,0 +1,41 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap/zapcore"
+
+	httplog "http-services/utils/log"
+)
+
+func TestRedisLogHook_PassesThroughWhenDebugEnabled(t *testing.T) {
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	addRedisLogHook(redisClient)
+
+	if err := httplog.SetLevel(httplog.ModuleRedis, zapcore.DebugLevel, 0); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	t.Cleanup(func() { _ = httplog.ResetLevel(httplog.ModuleRedis) })
+
+	ctx := context.Background()
+	if err := redisClient.Set(ctx, "k", "v", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := redisClient.Get(ctx, "missing").Err(); !errors.Is(err, redis.Nil) {
+		t.Fatalf("Get(missing) error = %v, want redis.Nil", err)
+	}
+	results, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "k")
+		pipe.Incr(ctx, "n")
+		return nil
+	})
+	if err != nil || len(results) != 2 || results[0].(*redis.StringCmd).Val() != "v" {
+		t.Fatalf("Pipelined() = %v, %v", results, err)
+	}
+}
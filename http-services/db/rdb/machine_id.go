@@ -0,0 +1,196 @@
+package rdb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// machineIDKeyPrefix 是 machine id 租约的 key 前缀，完整 key 为 id:machine:<n>（同样会加上 redis.key_prefix）
+const machineIDKeyPrefix = "id:machine:"
+
+const machineIDSpace = 1 << 16
+
+var (
+	// ErrNoMachineID 表示 65536 个 machine id 都已被占用
+	ErrNoMachineID = errors.New("no free machine id")
+	// ErrMachineIDLeaseLost 表示租约已被其他实例占用，本实例不能再使用该 machine id
+	ErrMachineIDLeaseLost = errors.New("machine id lease lost")
+	// ErrMachineIDLeaseExpired 表示续约失败且租约已超过 TTL，其他实例可能已经拿到同一个 machine id
+	ErrMachineIDLeaseExpired = errors.New("machine id lease expired")
+)
+
+// renewMachineIDScript 续约：仍由本实例持有时刷新 TTL；key 已过期且无人占用时重新占用；被他人占用时返回 0
+var renewMachineIDScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if not owner then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseMachineIDScript 只删除本实例持有的租约
+var releaseMachineIDScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// MachineIDLease 是通过 Redis 租用的 Sonyflake machine id
+// 后台每 TTL/3 续约一次；续约失败超过 TTL 或租约被他人占用后 Err 返回错误，调用方应停止使用该 machine id。
+type MachineIDLease struct {
+	client *redis.Client
+	id     uint16
+	key    string
+	token  string
+	ttl    time.Duration
+
+	mu         sync.Mutex
+	renewedAt  time.Time
+	lost       bool
+	stop       chan struct{}
+	done       chan struct{}
+	releaseOne sync.Once
+}
+
+// AcquireMachineID 从随机位置开始用 SET NX 依次尝试占用 machine id，成功后启动后台续约
+func AcquireMachineID(ctx context.Context, client *redis.Client, ttl time.Duration) (*MachineIDLease, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	if ttl < 3*time.Second {
+		return nil, fmt.Errorf("machine id lease ttl %v is too short, want at least 3s", ttl)
+	}
+	token, err := leaseToken()
+	if err != nil {
+		return nil, err
+	}
+
+	start := mathrand.IntN(machineIDSpace)
+	for offset := range machineIDSpace {
+		id := uint16((start + offset) % machineIDSpace)
+		key := machineIDKeyPrefix + strconv.Itoa(int(id))
+		acquiredAt := time.Now()
+		ok, err := client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquire machine id: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		lease := &MachineIDLease{
+			client:    client,
+			id:        id,
+			key:       key,
+			token:     token,
+			ttl:       ttl,
+			renewedAt: acquiredAt,
+			stop:      make(chan struct{}),
+			done:      make(chan struct{}),
+		}
+		go lease.heartbeat()
+		return lease, nil
+	}
+	return nil, ErrNoMachineID
+}
+
+// leaseToken 标识租约持有者，便于在 Redis 中排查是哪个实例占用了 machine id
+func leaseToken() (string, error) {
+	var random [8]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", fmt.Errorf("generate lease token: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), hex.EncodeToString(random[:])), nil
+}
+
+// ID 返回租到的 machine id
+func (l *MachineIDLease) ID() uint16 {
+	return l.id
+}
+
+// Err 报告租约当前是否仍然有效
+func (l *MachineIDLease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lost {
+		return ErrMachineIDLeaseLost
+	}
+	if time.Since(l.renewedAt) >= l.ttl {
+		return ErrMachineIDLeaseExpired
+	}
+	return nil
+}
+
+func (l *MachineIDLease) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if !l.renew() {
+				return
+			}
+		}
+	}
+}
+
+// renew 续约一次；租约被他人占用时返回 false 并停止续约
+func (l *MachineIDLease) renew() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+	startedAt := time.Now()
+	held, err := renewMachineIDScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		zap.L().Warn("renew machine id lease failed",
+			zap.Uint16("machine_id", l.id),
+			zap.Error(err),
+		)
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if held == 0 {
+		l.lost = true
+		zap.L().Error("machine id lease taken by another instance",
+			zap.Uint16("machine_id", l.id),
+		)
+		return false
+	}
+	l.renewedAt = startedAt
+	return true
+}
+
+// Release 停止续约并删除本实例持有的租约，使 machine id 可以立即被其他实例复用
+func (l *MachineIDLease) Release(ctx context.Context) error {
+	var err error
+	l.releaseOne.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.mu.Lock()
+		l.lost = true
+		l.mu.Unlock()
+		err = releaseMachineIDScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+	})
+	return err
+}
@@ -0,0 +1,97 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newMiniredisClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+func TestAcquireMachineID_UniqueAndReleased(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+
+	first, err := AcquireMachineID(ctx, client, 30*time.Second)
+	if err != nil {
+		t.Fatalf("AcquireMachineID() error = %v", err)
+	}
+	second, err := AcquireMachineID(ctx, client, 30*time.Second)
+	if err != nil {
+		t.Fatalf("second AcquireMachineID() error = %v", err)
+	}
+	if first.ID() == second.ID() {
+		t.Fatalf("two leases share machine id %d", first.ID())
+	}
+	if err := first.Err(); err != nil {
+		t.Fatalf("fresh lease Err() = %v", err)
+	}
+
+	key := machineIDKeyPrefix + strconv.Itoa(int(first.ID()))
+	if ttl := server.TTL(key); ttl <= 0 || ttl > 30*time.Second {
+		t.Fatalf("lease key ttl = %v", ttl)
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if server.Exists(key) {
+		t.Fatal("Release() kept the lease key")
+	}
+	if !errors.Is(first.Err(), ErrMachineIDLeaseLost) {
+		t.Fatalf("released lease Err() = %v", first.Err())
+	}
+	_ = second.Release(ctx)
+}
+
+func TestMachineIDLease_RenewReclaimsOrDetectsTakeover(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+	lease, err := AcquireMachineID(ctx, client, 30*time.Second)
+	if err != nil {
+		t.Fatalf("AcquireMachineID() error = %v", err)
+	}
+	t.Cleanup(func() { _ = lease.Release(ctx) })
+	key := machineIDKeyPrefix + strconv.Itoa(int(lease.ID()))
+
+	// key 过期后无人占用：续约重新占用
+	server.Del(key)
+	if !lease.renew() || lease.Err() != nil {
+		t.Fatalf("renew after expiry failed: %v", lease.Err())
+	}
+	if got, _ := server.Get(key); got != lease.token {
+		t.Fatalf("lease key owner = %q, want own token", got)
+	}
+
+	// 租约超过 TTL 未续约：即使 Redis 不可达也视为失效
+	lease.mu.Lock()
+	lease.renewedAt = time.Now().Add(-time.Minute)
+	lease.mu.Unlock()
+	if !errors.Is(lease.Err(), ErrMachineIDLeaseExpired) {
+		t.Fatalf("stale lease Err() = %v", lease.Err())
+	}
+
+	// 被其他实例占用：租约丢失并停止续约
+	if err := server.Set(key, "other"); err != nil {
+		t.Fatal(err)
+	}
+	if lease.renew() || !errors.Is(lease.Err(), ErrMachineIDLeaseLost) {
+		t.Fatalf("renew after takeover = %v", lease.Err())
+	}
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if got, _ := server.Get(key); got != "other" {
+		t.Fatalf("Release() removed another owner's lease, value = %q", got)
+	}
+}
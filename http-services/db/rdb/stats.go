@@ -0,0 +1,14 @@
+package rdb
+
+import "github.com/redis/go-redis/v9"
+
+// Stats 返回连接池统计；client 未初始化时返回 nil，不会触发连接
+func Stats() *redis.PoolStats {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	return client.PoolStats()
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"http-services/db/msqldb"
+	"http-services/utils/encryption"
+)
+
+// ReencryptAll 将传入的加密列改用当前 primary 密钥包装，用于密钥轮换；
+// 加密列由各模块的 EncryptedColumns 提供，main 通过 App 汇总后传入
+func ReencryptAll(ctx context.Context, targets ...msqldb.EncryptedColumns) error {
+	keyring := encryption.DefaultKeyring()
+	if keyring == nil {
+		return errors.New("encryption keys are not configured")
+	}
+	database, err := msqldb.Client()
+	if err != nil {
+		return fmt.Errorf("init mysql client: %w", err)
+	}
+	return RunReencrypt(ctx, database, keyring, targets...)
+}
+
+// RunReencrypt 依次轮换 targets 中各表的加密列并记录统计，遇到错误立即返回；
+// 没有 targets 时只记录日志。ReencryptAll 与测试通过它指定数据库与密钥环
+func RunReencrypt(ctx context.Context, database *gorm.DB, keyring *encryption.Keyring, targets ...msqldb.EncryptedColumns) error {
+	if len(targets) == 0 {
+		zap.L().Info("no encrypted columns registered, nothing to reencrypt")
+		return nil
+	}
+	for _, target := range targets {
+		zap.L().Info("reencrypting table", zap.String("table", target.Table), zap.String("primary_key_id", keyring.PrimaryKeyID()))
+		stats, err := msqldb.Reencrypt(ctx, database, keyring, target)
+		if err != nil {
+			return err
+		}
+		zap.L().Info("table reencrypted",
+			zap.String("table", target.Table),
+			zap.Int("scanned", stats.Scanned),
+			zap.Int("updated", stats.Updated),
+			zap.Int("conflicts", stats.Conflicts),
+			zap.Int("plaintext_skipped", stats.Plaintext),
+		)
+	}
+	return nil
+}
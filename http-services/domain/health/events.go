@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+
+	"http-services/utils/eventbus"
+)
+
+// ReadinessChanged 服务就绪状态变更事件
+// 由进程生命周期（如开始优雅关闭）发布，health 模块据此调整对外报告的就绪状态。
+type ReadinessChanged struct {
+	Ready  bool
+	Reason string
+}
+
+// ready 当前就绪状态，进程启动后默认就绪
+var ready atomic.Bool
+
+func init() {
+	ready.Store(true)
+}
+
+// RegisterSubscribers 注册 health 模块的事件订阅
+func RegisterSubscribers(bus *eventbus.Bus) {
+	if bus == nil {
+		return
+	}
+	eventbus.Subscribe(bus, "health.readiness", onReadinessChanged)
+}
+
+func onReadinessChanged(_ context.Context, event ReadinessChanged) error {
+	ready.Store(event.Ready)
+	return nil
+}
@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"http-services/utils/eventbus"
+)
+
+// TestReadinessChangedUpdatesStatus 验证就绪事件会改变 GetStatus 报告的就绪状态
+func TestReadinessChangedUpdatesStatus(t *testing.T) {
+	t.Cleanup(func() { ready.Store(true) })
+	bus := eventbus.New()
+	RegisterSubscribers(bus)
+
+	if err := eventbus.Publish(context.Background(), bus, ReadinessChanged{Ready: false, Reason: "shutting down"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	status, _ := GetStatus()
+	if status.Ready {
+		t.Fatal("GetStatus().Ready = true after ReadinessChanged{Ready: false}")
+	}
+
+	if err := eventbus.Publish(context.Background(), bus, ReadinessChanged{Ready: true}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if status, _ := GetStatus(); !status.Ready {
+		t.Fatal("GetStatus().Ready = false after ReadinessChanged{Ready: true}")
+	}
+}
@@ -1,6 +1,10 @@
 package health
 
-import "time"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
 // Status 领域层的健康状态实体
 // 不关心具体展示格式，仅承载核心健康信息
@@ -9,18 +13,82 @@ type Status struct {
 	Ready     bool
 	Uptime    time.Duration
 	Timestamp int64
+	Checks    []CheckResult
 }
 
+// Check 是模块提供的依赖检查项，例如模块使用的数据库或下游服务是否可用
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// CheckResult 是一次检查的结果
+type CheckResult struct {
+	Name    string
+	Healthy bool
+	Error   string
+	Elapsed time.Duration
+}
+
+// checkTimeout 是单个检查项的超时，避免一个依赖卡住整个健康检查
+const checkTimeout = 2 * time.Second
+
 var startTime = time.Now()
 
 // GetStatus 获取当前服务的健康状态（领域层示例）
-// 这里简单基于进程启动时间和当前时间构造状态，
+// 这里简单基于进程启动时间和当前时间构造状态，就绪状态由 ReadinessChanged 事件维护；
 // 当前示例中始终返回 nil 错误，便于在 handler 中演示错误映射用法。
 func GetStatus() (Status, error) {
 	return Status{
 		Status:    "ok",
-		Ready:     true,
+		Ready:     ready.Load(),
 		Uptime:    time.Since(startTime),
 		Timestamp: time.Now().Unix(),
 	}, nil
 }
+
+// CheckStatus 在 GetStatus 的基础上并发执行各模块的检查项；任一检查失败时 Status 为 "degraded"，
+// 进程本身仍然存活，由调用方决定是否摘除流量
+func CheckStatus(ctx context.Context, checks []Check) (Status, error) {
+	status, err := GetStatus()
+	if err != nil || len(checks) == 0 {
+		return status, err
+	}
+
+	status.Checks = make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.Checks[i] = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+	for _, result := range status.Checks {
+		if !result.Healthy {
+			status.Status = "degraded"
+			break
+		}
+	}
+	return status, nil
+}
+
+func runCheck(ctx context.Context, check Check) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	start := time.Now()
+	result = CheckResult{Name: check.Name}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Error = "check panicked"
+		}
+		result.Elapsed = time.Since(start)
+	}()
+	if err := check.Check(ctx); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Healthy = true
+	return result
+}
@@ -1,6 +1,10 @@
 package health
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+)
 
 // TestGetStatus 基础单元测试：验证健康状态的核心字段
 func TestGetStatus(t *testing.T) {
@@ -26,3 +30,37 @@ func TestGetStatus(t *testing.T) {
 		t.Errorf("GetStatus().Timestamp = %d, want non-zero", status.Timestamp)
 	}
 }
+
+// TestCheckStatus 验证检查项失败时状态降级，并保留每一项的结果
+func TestCheckStatus(t *testing.T) {
+	status, err := CheckStatus(context.Background(), []Check{
+		{Name: "mysql", Check: func(context.Context) error { return nil }},
+		{Name: "redis", Check: func(context.Context) error { return errors.New("connection refused") }},
+		{Name: "broken", Check: func(context.Context) error { panic("boom") }},
+	})
+	if err != nil {
+		t.Fatalf("CheckStatus() error = %v", err)
+	}
+	if status.Status != "degraded" {
+		t.Errorf("CheckStatus().Status = %s, want 'degraded'", status.Status)
+	}
+	want := []struct {
+		name    string
+		healthy bool
+		err     string
+	}{
+		{"mysql", true, ""},
+		{"redis", false, "connection refused"},
+		{"broken", false, "check panicked"},
+	}
+	for i, w := range want {
+		got := status.Checks[i]
+		if got.Name != w.name || got.Healthy != w.healthy || got.Error != w.err {
+			t.Errorf("Checks[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if status, _ := CheckStatus(context.Background(), nil); status.Status != "ok" || status.Checks != nil {
+		t.Errorf("CheckStatus(nil) = %+v, want ok without checks", status)
+	}
+}
@@ -0,0 +1,16 @@
+// Package domain 聚合各业务领域模块的进程内事件订阅注册。
+package domain
+
+import (
+	"http-services/domain/health"
+	"http-services/utils/eventbus"
+)
+
+// RegisterSubscribers 统一注册各领域模块的事件订阅
+// 新增领域模块时在此调用其 RegisterSubscribers，模块之间通过事件而非直接 import 协作。
+func RegisterSubscribers(bus *eventbus.Bus) {
+	if bus == nil {
+		return
+	}
+	health.RegisterSubscribers(bus)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"http-services/domain/health"
+	"http-services/utils/eventbus"
+)
+
+func TestRegisterSubscribersWiresModules(t *testing.T) {
+	bus := eventbus.New()
+	RegisterSubscribers(bus)
+	t.Cleanup(func() {
+		_ = eventbus.Publish(context.Background(), bus, health.ReadinessChanged{Ready: true})
+	})
+
+	if err := eventbus.Publish(context.Background(), bus, health.ReadinessChanged{Ready: false}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if status, _ := health.GetStatus(); status.Ready {
+		t.Fatal("health module did not receive ReadinessChanged")
+	}
+}
+
+func TestRegisterSubscribersIgnoresNilBus(t *testing.T) {
+	RegisterSubscribers(nil)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"http-services/config"
+	"http-services/utils/encryption"
+
+	"go.uber.org/zap"
+)
+
+// applyEncryptionKeys 按 encryption 配置替换默认密钥环；未配置密钥时清空，
+// EncryptedString 读写会返回 ErrKeyringNotConfigured 而不是写入明文
+func applyEncryptionKeys() error {
+	encryptionConfig := config.Current().Encryption
+	if len(encryptionConfig.Keys) == 0 {
+		encryption.SetDefaultKeyring(nil)
+		return nil
+	}
+	keyring, err := encryption.NewKeyring(encryptionConfig.PrimaryKey, encryptionConfig.Keys, encryptionConfig.BlindIndexKey)
+	if err != nil {
+		return err
+	}
+	encryption.SetDefaultKeyring(keyring)
+	zap.L().Info("字段加密密钥已加载",
+		zap.String("primary_key_id", keyring.PrimaryKeyID()),
+		zap.Int("keys", len(encryptionConfig.Keys)),
+		zap.Bool("blind_index", len(encryptionConfig.BlindIndexKey) > 0),
+	)
+	return nil
+}
+
+// encryptSecretFromStdin 从标准输入读取一行明文并输出 enc: 配置值，避免明文出现在命令行参数与 shell 历史中
+func encryptSecretFromStdin() error {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return errors.New("no input on stdin")
+	}
+	plaintext := strings.TrimRight(line, "\r\n")
+	if plaintext == "" {
+		return errors.New("empty secret")
+	}
+	encrypted, err := config.EncryptSecretValue(plaintext)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"http-services/config"
+	"http-services/db/rdb"
+	"http-services/utils/id"
+
+	"go.uber.org/zap"
+)
+
+// initIDGenerator 按 id 配置重建 Sonyflake 生成器，返回的函数在退出时释放 Redis 租约
+// machine id 优先级：显式 id.machine_id > Redis 租约 > 自动推导。
+func initIDGenerator() (func(), error) {
+	idConfig := config.Current().ID
+	opts := id.Options{
+		Epoch:            idConfig.Epoch,
+		MaxClockBackward: idConfig.MaxClockBackward,
+	}
+	source := "auto"
+	release := func() {}
+
+	switch {
+	case idConfig.MachineID >= 0:
+		machineID := uint16(idConfig.MachineID)
+		opts.MachineID = func() (uint16, error) { return machineID, nil }
+		source = "config"
+	case idConfig.RedisLease:
+		client, err := rdb.Client()
+		if err != nil {
+			return nil, fmt.Errorf("init redis client for machine id lease: %w", err)
+		}
+		lease, err := rdb.AcquireMachineID(context.Background(), client, idConfig.LeaseTTL)
+		if err != nil {
+			return nil, err
+		}
+		opts.MachineID = func() (uint16, error) { return lease.ID(), nil }
+		opts.Guard = lease.Err
+		source = "redis"
+		release = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), idConfig.LeaseTTL/3)
+			defer cancel()
+			if err := lease.Release(ctx); err != nil {
+				zap.L().Warn("释放 machine id 租约失败", zap.Uint16("machine_id", lease.ID()), zap.Error(err))
+			}
+		}
+	}
+
+	if err := id.Init(opts); err != nil {
+		release()
+		return nil, err
+	}
+	zap.L().Info("ID 生成器已初始化",
+		zap.Uint16("machine_id", id.MachineID()),
+		zap.String("machine_id_source", source),
+		zap.Time("epoch", idConfig.Epoch),
+	)
+	return release, nil
+}
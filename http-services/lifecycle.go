@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"http-services/api/middleware"
+	"http-services/config"
+	"http-services/db/msqldb"
+	"http-services/db/rdb"
+	"http-services/utils/lifecycle"
+
+	"go.uber.org/zap"
+)
+
+// 同一阶段内按 Priority 从小到大停止，相同 Priority 并发执行
+const (
+	// PhaseWorkers：任务队列与定时任务先排空，outbox relay 投递它们写入的事件，随后按依赖逆序停止模块，
+	// 最后等待事件总线的异步订阅者
+	queuePriority    = 0
+	cronPriority     = 0
+	outboxPriority   = 10
+	modulePriority   = 15
+	eventBusPriority = 20
+
+	// PhaseStores：审计 Sink、machine id 租约等依赖连接的组件先关闭，随后关闭连接，pid 文件最后删除
+	storeUserPriority  = -10
+	connectionPriority = 0
+	pidFilePriority    = 10
+)
+
+// appendStoreHooks 注册进程内始终存在的存储清理，迁移等一次性命令也会执行
+func appendStoreHooks(lc *lifecycle.Manager) {
+	lc.Append(lifecycle.Hook{Name: "mysql", Phase: lifecycle.PhaseStores, Priority: connectionPriority, OnStop: func(context.Context) error {
+		msqldb.CloseClient()
+		return nil
+	}})
+	lc.Append(lifecycle.Hook{Name: "redis", Phase: lifecycle.PhaseStores, Priority: connectionPriority, OnStop: func(context.Context) error {
+		rdb.CloseClient()
+		return nil
+	}})
+	lc.Append(lifecycle.Hook{Name: "rate-limiters", Phase: lifecycle.PhaseStores, Priority: connectionPriority, OnStop: func(context.Context) error {
+		middleware.CleanupAllLimiters()
+		return nil
+	}})
+}
+
+// stopComponents 按阶段停止全部组件。pre_stop_delay 与 shutdown_timeout 共同构成关闭预算，
+// 部署时 terminationGracePeriodSeconds / TimeoutStopSec 需要大于两者之和
+func stopComponents(lc *lifecycle.Manager) error {
+	serverConfig := config.Current().Server
+	ctx, cancel := context.WithTimeout(context.Background(), serverConfig.PreStopDelay+serverConfig.ShutdownTimeout)
+	defer cancel()
+	if err := lc.Stop(ctx); err != nil {
+		zap.L().Error("部分组件未能正常停止", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// sleepContext 等待 d，ctx 先结束时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
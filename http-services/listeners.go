@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"http-services/api"
+	"http-services/application"
+	"http-services/config"
+	"http-services/utils/httpserver"
+	"http-services/utils/taskgroup"
+
+	"go.uber.org/zap"
+)
+
+// extraListeners 是主监听之外按配置启动的监听：server.unix_socket 与 admin.listen。
+// 地址只在启动时读取，修改需要重启；超时与 h2c 随热重载应用，关闭时与主监听一起排空
+type extraListeners struct {
+	unix      *httpserver.Server
+	unixAddr  string
+	socket    *unixSocket
+	admin     *httpserver.Server
+	adminAddr string
+	errCh     chan error
+}
+
+// startExtraListeners 按配置启动 unix socket 与管理端口；热升级时优先使用旧进程交接的同一地址的 socket。
+// 任一监听启动失败时关闭已经启动的部分并返回错误
+func startExtraListeners(a *application.App, handler http.Handler, upgrade *upgradeChild) (*extraListeners, error) {
+	l := &extraListeners{errCh: make(chan error, 2)}
+	cfg := a.Config()
+
+	if path := cfg.Server.UnixSocket.Path; path != "" {
+		var inherited net.Listener
+		if upgrade != nil {
+			inherited = upgrade.take("unix", func(ln net.Listener) bool { return ln.Addr().String() == path })
+		}
+		socket, err := openUnixSocket(cfg.Server.UnixSocket, inherited)
+		if err != nil {
+			return nil, err
+		}
+		l.socket = socket
+		l.unixAddr = path
+		l.unix = httpserver.New(unixPeerHandler(handler), l.unixServerConfig(cfg.Server))
+		if err := l.unix.StartListener(socket.listener); err != nil {
+			l.close()
+			return nil, err
+		}
+		go l.forwardErrors(l.unix)
+		zap.L().Info("unix socket 监听已启动", zap.String("path", path), zap.Stringer("mode", cfg.Server.UnixSocket.Mode))
+	}
+
+	if addr := cfg.Admin.Listen; addr != "" {
+		l.adminAddr = addr
+		l.admin = httpserver.New(api.NewAdminHandler(a), l.adminServerConfig(cfg.Server))
+		var inherited net.Listener
+		if upgrade != nil {
+			inherited = upgrade.take("admin", func(ln net.Listener) bool { return sameTCPAddr(ln, addr) })
+		}
+		var err error
+		if inherited != nil {
+			err = l.admin.StartListener(inherited)
+		} else {
+			err = l.admin.Start()
+		}
+		if err != nil {
+			l.close()
+			return nil, fmt.Errorf("start admin listener: %w", err)
+		}
+		go l.forwardErrors(l.admin)
+		zap.L().Info("管理端口已启动", zap.String("addr", l.admin.Addr().String()))
+	}
+	return l, nil
+}
+
+// unixPeerHandler 把 unix socket 对端视为本机反向代理：RemoteAddr 没有 IP，
+// 不改写时 ClientIP 为空，也不会按 trusted_proxies 采信 X-Forwarded-For
+func unixPeerHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := net.SplitHostPort(r.RemoteAddr); err != nil {
+			r.RemoteAddr = "127.0.0.1:0"
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// unixServerConfig 使用与主监听相同的超时；地址固定为启动时的 socket 路径，Reconfigure 不会重新绑定。
+// 本机反向代理经 socket 转发，不在 socket 上启用 TLS
+func (l *extraListeners) unixServerConfig(serverConfig config.ServerConfig) httpserver.Config {
+	cfg := httpServerConfig(serverConfig)
+	cfg.Addr = l.unixAddr
+	cfg.TLSConfig = nil
+	return cfg
+}
+
+// adminServerConfig 不设写超时：pprof 的 profile、trace 会按 seconds 参数持续输出
+func (l *extraListeners) adminServerConfig(serverConfig config.ServerConfig) httpserver.Config {
+	cfg := httpServerConfig(serverConfig)
+	cfg.Addr = l.adminAddr
+	cfg.WriteTimeout = 0
+	cfg.H2C = false
+	cfg.TLSConfig = nil
+	return cfg
+}
+
+func (l *extraListeners) forwardErrors(server *httpserver.Server) {
+	err, ok := <-server.Errors()
+	if !ok {
+		return
+	}
+	select {
+	case l.errCh <- err:
+	default:
+	}
+}
+
+// Errors 返回附加监听运行期不可恢复的错误；没有附加监听时返回 nil 通道
+func (l *extraListeners) Errors() <-chan error {
+	if l == nil {
+		return nil
+	}
+	return l.errCh
+}
+
+// servers 返回附加监听的 http server，key 为热升级交接时使用的名称
+func (l *extraListeners) servers() map[string]*httpserver.Server {
+	servers := make(map[string]*httpserver.Server)
+	if l == nil {
+		return servers
+	}
+	if l.unix != nil {
+		servers["unix"] = l.unix
+	}
+	if l.admin != nil {
+		servers["admin"] = l.admin
+	}
+	return servers
+}
+
+// reconfigure 把热重载后的超时与 h2c 应用到附加监听
+func (l *extraListeners) reconfigure(serverConfig config.ServerConfig) {
+	if l == nil {
+		return
+	}
+	if l.unix != nil {
+		if _, err := l.unix.Reconfigure(l.unixServerConfig(serverConfig), serverConfig.ShutdownTimeout); err != nil {
+			zap.L().Error("应用 unix socket 监听配置失败", zap.Error(err))
+		}
+	}
+	if l.admin != nil {
+		if _, err := l.admin.Reconfigure(l.adminServerConfig(serverConfig), serverConfig.ShutdownTimeout); err != nil {
+			zap.L().Error("应用管理端口监听配置失败", zap.Error(err))
+		}
+	}
+}
+
+// removeSocket 删除本进程创建的 socket 文件；已热升级交接给新进程时保留
+func (l *extraListeners) removeSocket(handedOff bool) {
+	if l == nil || l.socket == nil || handedOff {
+		return
+	}
+	l.socket.remove()
+}
+
+// close 用于启动失败时立即关闭已经启动的附加监听
+func (l *extraListeners) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = shutdownServers(ctx, l.unix, l.admin)
+	if l.socket != nil {
+		_ = l.socket.listener.Close()
+		l.socket.remove()
+	}
+}
+
+// shutdownServers 同时关闭所有监听，共用 ctx 的截止时间（server.shutdown_timeout），nil 会被跳过
+func shutdownServers(ctx context.Context, servers ...*httpserver.Server) error {
+	tasks := make([]taskgroup.Task, 0, len(servers))
+	for i, server := range servers {
+		if server == nil {
+			continue
+		}
+		tasks = append(tasks, taskgroup.ContinueOnError("http-server-"+strconv.Itoa(i), server.Shutdown))
+	}
+	return errors.Join(taskgroup.Run(ctx, tasks...)...)
+}
+
+// sameTCPAddr 判断继承的 TCP socket 与配置的地址是否一致：端口相同，且 host 为空时 socket 绑定在
+// 未指定地址上，host 为 IP 时与 socket 的 IP 相同，host 为主机名时其解析结果包含 socket 的 IP
+func sameTCPAddr(ln net.Listener, addr string) bool {
+	tcpAddr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || port != strconv.Itoa(tcpAddr.Port) {
+		return false
+	}
+	if host == "" {
+		return tcpAddr.IP.IsUnspecified()
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.Equal(tcpAddr.IP)
+	}
+	ips, err := net.LookupIP(host)
+	return err == nil && slices.ContainsFunc(ips, tcpAddr.IP.Equal)
+}
+
+// unixSocket 记录本进程创建（或继承）的 socket 文件，关闭时只删除仍然是这个 socket 的文件
+type unixSocket struct {
+	listener net.Listener
+	path     string
+	info     os.FileInfo
+}
+
+// openUnixSocket 创建 unix socket 并设置权限与属主；inherited 非空时直接使用热升级交接的 socket。
+// 路径上残留的 socket 文件（上次异常退出留下）会被删除，仍有进程在监听或不是 socket 时返回错误
+func openUnixSocket(cfg config.UnixSocketConfig, inherited net.Listener) (*unixSocket, error) {
+	if inherited != nil {
+		info, err := os.Stat(cfg.Path)
+		if err != nil {
+			_ = inherited.Close()
+			return nil, fmt.Errorf("stat inherited unix socket: %w", err)
+		}
+		return &unixSocket{listener: inherited, path: cfg.Path, info: info}, nil
+	}
+
+	if err := removeStaleSocket(cfg.Path); err != nil {
+		return nil, err
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: cfg.Path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listen unix socket: %w", err)
+	}
+	// 由 unixSocket.remove 按文件身份删除，避免热升级后旧进程删掉新进程正在使用的 socket
+	ln.SetUnlinkOnClose(false)
+	socket := &unixSocket{listener: ln, path: cfg.Path}
+	if err := os.Chmod(cfg.Path, cfg.Mode); err != nil {
+		_ = ln.Close()
+		_ = os.Remove(cfg.Path)
+		return nil, fmt.Errorf("chmod unix socket: %w", err)
+	}
+	if cfg.Owner != "" {
+		if err := chownSocket(cfg.Path, cfg.Owner); err != nil {
+			_ = ln.Close()
+			_ = os.Remove(cfg.Path)
+			return nil, err
+		}
+	}
+	if socket.info, err = os.Stat(cfg.Path); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("stat unix socket: %w", err)
+	}
+	return socket, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat unix socket: %w", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is in use by another process", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale unix socket: %w", err)
+	}
+	return nil
+}
+
+// chownSocket 按 user 或 user:group 修改 socket 属主，user / group 可以是名称或数字 id
+func chownSocket(path, owner string) error {
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+	uid, gid := -1, -1
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			if u, err = user.LookupId(userName); err != nil {
+				return fmt.Errorf("lookup unix socket owner %q: %w", userName, err)
+			}
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return fmt.Errorf("unix socket owner %q has non-numeric uid %q", userName, u.Uid)
+		}
+	}
+	if hasGroup && groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return fmt.Errorf("lookup unix socket group %q: %w", groupName, err)
+			}
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return fmt.Errorf("unix socket group %q has non-numeric gid %q", groupName, g.Gid)
+		}
+	}
+	if err := os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("chown unix socket: %w", err)
+	}
+	return nil
+}
+
+func (s *unixSocket) remove() {
+	info, err := os.Stat(s.path)
+	if err != nil || !os.SameFile(info, s.info) {
+		return
+	}
+	if err := os.Remove(s.path); err != nil {
+		zap.L().Warn("删除 unix socket 文件失败", zap.String("path", s.path), zap.Error(err))
+	}
+}
@@ -0,0 +1,173 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExtraListenersServeAndShutDownTogether(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过集成测试（-short）")
+	}
+
+	tmpDir := t.TempDir()
+	binPath := filepath.Join(tmpDir, "http-services-testbin")
+	if out, err := exec.Command("go", "build", "-o", binPath, ".").CombinedOutput(); err != nil {
+		t.Fatalf("构建测试二进制失败: %v\n%s", err, out)
+	}
+	port, adminPort := freeLocalPort(t), freeLocalPort(t)
+	socketPath := filepath.Join(tmpDir, "http.sock")
+
+	logFile, err := os.Create(filepath.Join(tmpDir, "output.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+	output := func() string {
+		content, _ := os.ReadFile(logFile.Name())
+		return string(content)
+	}
+
+	cmd := exec.Command(binPath, "--dev")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(),
+		"HTTP_SERVICES_SERVER_HOST=127.0.0.1",
+		"HTTP_SERVICES_SERVER_PORT="+port,
+		"HTTP_SERVICES_SERVER_SHUTDOWN_TIMEOUT=2s",
+		"HTTP_SERVICES_SERVER_H2C=true",
+		"HTTP_SERVICES_SERVER_UNIX_SOCKET_PATH="+socketPath,
+		"HTTP_SERVICES_SERVER_UNIX_SOCKET_MODE=0600",
+		"HTTP_SERVICES_ADMIN_LISTEN=127.0.0.1:"+adminPort,
+	)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("启动服务失败: %v", err)
+	}
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+	t.Cleanup(func() {
+		if cmd.ProcessState == nil {
+			_ = cmd.Process.Kill()
+			<-waitCh
+		}
+	})
+
+	adminURL := "http://127.0.0.1:" + adminPort
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 2 * time.Second}
+	waitFor(t, 10*time.Second, output, "管理端口未能开始监听", func() bool {
+		resp, err := client.Get(adminURL + "/debug/pprof/")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	})
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("unix socket 未创建: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unix socket mode = %v, want socket with 0600", info.Mode())
+	}
+	if status := getOverUnixSocket(t, socketPath, "/api/v1/open/health"); status != http.StatusOK {
+		t.Fatalf("通过 unix socket 访问 health status = %d, want 200", status)
+	}
+
+	resp, err := client.Get(adminURL + "/metrics")
+	if err != nil {
+		t.Fatalf("访问 /metrics 失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "uptime_seconds") {
+		t.Fatalf("/metrics = %s, want uptime_seconds", body)
+	}
+	// 管理接口仍然需要 admin token，监听在本机不代表放开鉴权
+	resp, err = client.Get(adminURL + "/api/v1/admin/config")
+	if err != nil {
+		t.Fatalf("访问配置接口失败: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "settings") {
+		t.Fatalf("未携带 admin token 访问配置接口 = %s，want 拒绝", body)
+	}
+
+	// 不经 TLS 直接以 HTTP/2 访问主端口
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	h2Client := &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 2 * time.Second}
+	resp, err = h2Client.Get("http://127.0.0.1:" + port + "/api/v1/open/health")
+	if err != nil {
+		t.Fatalf("h2c 请求失败: %v\n输出：\n%s", err, output())
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("h2c 响应协议 = %s, want HTTP/2", resp.Proto)
+	}
+	h2Client.CloseIdleConnections()
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("发送 SIGTERM 失败: %v", err)
+	}
+	select {
+	case err := <-waitCh:
+		if err != nil {
+			t.Fatalf("服务退出失败: %v\n输出：\n%s", err, output())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("服务未在预期时间内退出\n输出：\n%s", output())
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatalf("退出后 unix socket 仍然存在: %v", err)
+	}
+	if conn, err := net.DialTimeout("tcp", "127.0.0.1:"+adminPort, time.Second); err == nil {
+		conn.Close()
+		t.Fatal("退出后管理端口仍在监听")
+	}
+}
+
+// freeLocalPort 返回一个当前空闲的本地端口；与 reserveLocalPort 不同，端口不会被保留，服务进程可以直接绑定
+func freeLocalPort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("当前环境无法分配本地监听端口: %v", err)
+	}
+	defer ln.Close()
+	return strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+}
+
+// getOverUnixSocket 通过 unix socket 发起 GET 请求并返回状态码
+func getOverUnixSocket(t *testing.T, socketPath, path string) int {
+	t.Helper()
+	client := &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+		Timeout: 2 * time.Second,
+	}
+	resp, err := client.Get("http://unix" + path)
+	if err != nil {
+		t.Fatalf("通过 unix socket 访问 %s 失败: %v", path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
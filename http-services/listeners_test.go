@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestSameTCPAddr(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:" + port, true},
+		{"localhost:" + port, true},
+		{":" + port, false}, // 空 host 需要绑定在未指定地址上
+		{"0.0.0.0:" + port, false},
+		{"127.0.0.2:" + port, false},
+		{"127.0.0.1:1", false},
+	}
+	for _, tt := range tests {
+		if got := sameTCPAddr(ln, tt.addr); got != tt.want {
+			t.Errorf("sameTCPAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	any, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer any.Close()
+	if anyPort := strconv.Itoa(any.Addr().(*net.TCPAddr).Port); !sameTCPAddr(any, ":"+anyPort) || sameTCPAddr(any, "127.0.0.1:"+anyPort) {
+		t.Error("sameTCPAddr() should match an unspecified socket only for an empty host")
+	}
+}
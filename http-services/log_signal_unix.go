@@ -0,0 +1,42 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"http-services/config"
+	"http-services/utils/log"
+
+	"go.uber.org/zap"
+)
+
+// watchLogLevelSignals 监听日志级别切换信号：SIGUSR1 让所有模块更详细一级（SIGUSR2 用于热升级，见 upgrade_unix.go），
+// 调整在 log.level_revert_after 后自动恢复为配置级别；返回的函数用于停止监听。
+func watchLogLevelSignals() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case received := <-signals:
+				revertAfter := config.Current().Log.LevelRevertAfter
+				log.ShiftLevels(-1, revertAfter)
+				zap.L().Warn("log levels shifted by signal",
+					zap.String("signal", received.String()),
+					zap.Duration("revert_after", revertAfter),
+					zap.Any("levels", log.Levels()),
+				)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
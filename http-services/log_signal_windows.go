@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+// watchLogLevelSignals Windows 不支持 SIGUSR1，只能通过管理接口调整日志级别
+func watchLogLevelSignals() func() {
+	return func() {}
+}
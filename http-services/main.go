@@ -4,31 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 
 	"http-services/api"
-	"http-services/api/middleware"
+	"http-services/application"
 	"http-services/config"
 	"http-services/db"
-	"http-services/db/msqldb"
-	"http-services/db/rdb"
+	"http-services/domain"
+	domainhealth "http-services/domain/health"
+	_ "http-services/modules"
+	"http-services/services/cron"
+	"http-services/services/outbox"
+	"http-services/services/queue"
+	"http-services/utils/buildinfo"
+	"http-services/utils/eventbus"
+	"http-services/utils/httpserver"
+	"http-services/utils/id"
+	"http-services/utils/lifecycle"
 	"http-services/utils/log"
 	"http-services/utils/pidfile"
 	"http-services/utils/runmodel"
+	"http-services/utils/systemd"
 
 	"github.com/alecthomas/kong"
 	"go.uber.org/zap"
 )
 
 var CLI struct {
-	Dev     bool `help:"Run in development mode" short:"d"`
-	Version bool `help:"Show version information" short:"v"`
-	Migrate bool `help:"Run database migrations and exit" short:"m"`
+	Dev           bool   `help:"Run in development mode" short:"d"`
+	Version       bool   `help:"Show version information" short:"v"`
+	Migrate       bool   `help:"Run database migrations and exit" short:"m"`
+	Reencrypt     bool   `help:"Re-encrypt registered encrypted columns with the primary key and exit"`
+	EncryptSecret bool   `help:"Read a config value from stdin, print it encrypted with HTTP_SERVICES_MASTER_KEY and exit"`
+	Config        string `help:"Base config file (default: config.yaml in the working directory or /etc/http-services)" type:"path" short:"c"`
+	ConfigEnv     string `help:"Layer config.<env>.yaml on top of the base config (default: the run mode, dev or release)"`
+
+	Serve     struct{} `cmd:"" default:"1" help:"Run the HTTP server (default)"`
+	ConfigCmd struct {
+		Print struct{} `cmd:"" help:"Print every merged config value with the file or env var it came from"`
+	} `cmd:"" name:"config" help:"Inspect the loaded configuration"`
 }
 
 var (
@@ -45,6 +61,7 @@ func main() {
 		kong.UsageOnError(),
 	)
 
+	buildinfo.Set(Version, BuildTime, GitCommit)
 	if CLI.Version {
 		fmt.Printf("Version:    %s\n", Version)
 		fmt.Printf("Build Time: %s\n", BuildTime)
@@ -52,11 +69,32 @@ func main() {
 		return
 	}
 
-	if err := config.LoadConfig(); err != nil {
-		fmt.Printf("Failed to load configuration: %v\n", err)
-		command.Exit(1)
+	if CLI.EncryptSecret {
+		if err := encryptSecretFromStdin(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encrypt secret: %v\n", err)
+			command.Exit(1)
+		}
+		return
 	}
+
 	runmodel.Detect(CLI.Dev)
+	configEnv := CLI.ConfigEnv
+	if configEnv == "" {
+		configEnv = config.RunModel
+	}
+	loadErr := config.LoadConfigWith(config.LoadOptions{File: CLI.Config, Env: configEnv})
+	if command.Command() == "config print" {
+		// 校验失败时仍然输出已合并的取值，便于定位是哪一层写错了
+		if err := printConfig(os.Stdout); err != nil || loadErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", errors.Join(loadErr, err))
+			command.Exit(1)
+		}
+		return
+	}
+	if loadErr != nil {
+		fmt.Printf("Failed to load configuration: %v\n", loadErr)
+		command.Exit(1)
+	}
 	if runmodel.IsRelease() {
 		if err := os.MkdirAll(config.LogDir, 0o750); err != nil {
 			fmt.Printf("Failed to create log directory: %v\n", err)
@@ -65,94 +103,256 @@ func main() {
 	}
 	log.GetLogger()
 	log.StartMonitor()
-	config.WatchConfig(func() {
-		log.SetLogger()
-		zap.L().Info(
-			"Configuration reloaded",
-			zap.Int("port", config.ListenPort),
-			zap.Bool("rate_limit_enabled", config.EnableRateLimit),
-		)
-	})
+	// 组件初始化后立即注册停止 hook，启动失败、一次性命令与正常退出都通过 exit 按阶段清理
+	lc := lifecycle.New()
+	appendStoreHooks(lc)
+	exit := func(code int) {
+		if err := stopComponents(lc); err != nil {
+			code = 1
+		}
+		zap.L().Info("Process exited", zap.Int("exit_code", code))
+		log.StopMonitor()
+		command.Exit(code)
+	}
+	// App 持有配置、logger、存储与事件总线，并按依赖顺序构建 modules 包导入的全部模块；
+	// 路由与模块通过构造函数从这里取得依赖
+	a, err := application.New(application.Options{Lifecycle: lc})
+	if err != nil {
+		zap.L().Error("初始化业务模块失败", zap.Error(err))
+		exit(1)
+	}
+	// 模块在后台任务停止后、事件总线关闭前按依赖逆序停止
+	lc.Append(lifecycle.Hook{Name: "modules", Phase: lifecycle.PhaseWorkers, Priority: modulePriority, OnStop: a.ShutdownModules})
 
+	applyPasswordPolicy()
+	if err := applyEncryptionKeys(); err != nil {
+		zap.L().Error("加载字段加密密钥失败", zap.Error(err))
+		exit(1)
+	}
 	if CLI.Migrate {
 		zap.L().Info("Running database migrations...")
-		if err := db.MigrateAll(); err != nil {
+		if err := db.MigrateAll(a.Migrations()...); err != nil {
 			zap.L().Error("Database migration failed", zap.Error(err))
-			msqldb.CloseClient()
-			rdb.CloseClient()
-			middleware.CleanupAllLimiters()
-			log.StopMonitor()
-			command.Exit(1)
+			exit(1)
 		}
 		zap.L().Info("Database migration completed successfully")
-		msqldb.CloseClient()
-		rdb.CloseClient()
-		middleware.CleanupAllLimiters()
-		log.StopMonitor()
-		return
+		exit(0)
+	}
+
+	if CLI.Reencrypt {
+		zap.L().Info("Re-encrypting encrypted columns...")
+		if err := db.ReencryptAll(context.Background(), a.EncryptedColumns()...); err != nil {
+			zap.L().Error("Re-encryption failed", zap.Error(err))
+			exit(1)
+		}
+		zap.L().Info("Re-encryption completed successfully")
+		exit(0)
+	}
+
+	releaseIDGenerator, err := initIDGenerator()
+	if err != nil {
+		zap.L().Error("初始化 ID 生成器失败", zap.Error(err))
+		exit(1)
 	}
+	// 租约依赖 Redis 连接，先于 rdb 释放，使 machine id 可以立即被新实例复用
+	lc.Append(lifecycle.Hook{Name: "id-generator", Phase: lifecycle.PhaseStores, Priority: storeUserPriority, OnStop: func(context.Context) error {
+		releaseIDGenerator()
+		return nil
+	}})
+
+	auditRecorder, err := openAuditRecorder()
+	if err != nil {
+		zap.L().Error("初始化审计日志失败", zap.Error(err))
+		exit(1)
+	}
+	// 审计 Sink 可能依赖 MySQL 连接，先于 msqldb 关闭
+	lc.Append(lifecycle.Hook{Name: "audit", Phase: lifecycle.PhaseStores, Priority: storeUserPriority, OnStop: func(context.Context) error {
+		return auditRecorder.Close()
+	}})
+
+	// 领域模块的事件订阅与路由一样按模块逐级注册
+	bus := a.Bus()
+	domain.RegisterSubscribers(bus)
+	lc.Append(lifecycle.Hook{Name: "eventbus", Phase: lifecycle.PhaseWorkers, Priority: eventBusPriority, OnStop: bus.Close})
 
-	router := api.InitApi()
-	server := &http.Server{
-		Addr:           net.JoinHostPort(config.ListenHost, strconv.Itoa(config.ListenPort)),
-		Handler:        router,
-		ReadTimeout:    config.ReadTimeout,
-		WriteTimeout:   config.WriteTimeout,
-		IdleTimeout:    config.IdleTimeout,
-		MaxHeaderBytes: config.MaxHeaderBytes,
+	stopLogLevelSignals := watchLogLevelSignals()
+	defer stopLogLevelSignals()
+
+	// pid 文件只在启动时读取一次；监听地址与超时在热重载时由 applyReload 切换
+	serverConfig := config.Current().Server
+	stopTLSWatch, err := setupServerTLS(serverConfig.TLS)
+	if err != nil {
+		zap.L().Error("加载 TLS 证书失败", zap.Error(err))
+		exit(1)
 	}
+	lc.Append(lifecycle.Hook{Name: "tls-watch", Phase: lifecycle.PhaseWorkers, OnStop: func(context.Context) error {
+		stopTLSWatch()
+		return nil
+	}})
+	handler := api.NewHandler(a)
+	server := httpserver.New(handler, httpServerConfig(serverConfig))
+
+	// serving 在监听全部启动后置为 true；upgradedAway 表示监听已交接给热升级的新进程
+	serving, upgradedAway := false, false
+	var extras *extraListeners
+	lc.Append(lifecycle.Hook{Name: "readiness", Phase: lifecycle.PhaseReadiness, OnStop: func(ctx context.Context) error {
+		if !upgradedAway {
+			notifySystemd(systemd.Stopping, systemd.Status("draining connections"))
+		}
+		return eventbus.Publish(ctx, bus, domainhealth.ReadinessChanged{Ready: false, Reason: "shutting down"})
+	}})
+	lc.Append(lifecycle.Hook{Name: "pre-stop-delay", Phase: lifecycle.PhasePreStop, OnStop: func(ctx context.Context) error {
+		// 新进程已接管流量或从未开始服务时无需等待负载均衡摘除
+		if !serving || upgradedAway {
+			return nil
+		}
+		return sleepContext(ctx, config.Current().Server.PreStopDelay)
+	}})
+	lc.Append(lifecycle.Hook{Name: "http", Phase: lifecycle.PhaseDrain, OnStop: func(ctx context.Context) error {
+		// 主监听、unix socket 与管理端口同时排空，共用 shutdown_timeout
+		servers := []*httpserver.Server{server}
+		for _, extra := range extras.servers() {
+			servers = append(servers, extra)
+		}
+		err := shutdownServers(ctx, servers...)
+		extras.removeSocket(upgradedAway)
+		return err
+	}})
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	defer signal.Stop(quit)
 
+	// 由旧进程 SIGUSR2 热升级启动时，继承其监听 socket，pid 文件在就绪时从旧进程手中接管
+	upgrade, err := inheritUpgrade()
+	if err != nil {
+		zap.L().Warn("读取热升级交接的监听 socket 失败，按普通方式启动", zap.Error(err))
+	}
+	if upgrade != nil && !config.Current().ID.RedisLease {
+		// 旧进程排空期间仍在处理请求，两个进程使用相同的 machine id 时可能生成重复的 Sonyflake ID
+		zap.L().Warn("热升级期间新旧进程同时生成 ID，未启用 id.redis_lease 时需确认 id.machine_id 与旧进程不同",
+			zap.Uint16("machine_id", id.MachineID()))
+	}
+
 	pid := os.Getpid()
-	if config.PidFile != "" {
-		if err := pidfile.Write(config.PidFile, pid); err != nil {
-			zap.L().Error("写入 pid 文件失败", zap.String("pid_file", config.PidFile), zap.Error(err))
-			msqldb.CloseClient()
-			rdb.CloseClient()
-			middleware.CleanupAllLimiters()
-			log.StopMonitor()
-			command.Exit(1)
+	pidWritten := false
+	lc.Append(lifecycle.Hook{Name: "pid-file", Phase: lifecycle.PhaseStores, Priority: pidFilePriority, OnStop: func(context.Context) error {
+		if !pidWritten {
+			return nil
+		}
+		return pidfile.Remove(serverConfig.PidFile, pid)
+	}})
+	if serverConfig.PidFile != "" && upgrade == nil {
+		if err := pidfile.Write(serverConfig.PidFile, pid); err != nil {
+			zap.L().Error("写入 pid 文件失败", zap.String("pid_file", serverConfig.PidFile), zap.Error(err))
+			exit(1)
 		}
-		zap.L().Info("PID 文件已写入", zap.String("pid_file", config.PidFile), zap.Int("pid", pid))
+		pidWritten = true
+		zap.L().Info("PID 文件已写入", zap.String("pid_file", serverConfig.PidFile), zap.Int("pid", pid))
 	}
 
-	serverErrCh := make(chan error, 1)
-	go func() {
-		zap.L().Info("Server is starting...", zap.String("addr", server.Addr), zap.String("version", Version))
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			serverErrCh <- err
+	// 任务队列、outbox relay 与定时任务统称后台 worker；热升级启动时等旧进程退出后再启动，
+	// 避免新旧进程同时执行定时任务、消费队列
+	var workers []lifecycle.Hook
+
+	// HTTP 排空后再排空任务 worker，避免请求中新入队的任务丢失执行机会
+	if config.Current().Queue.Enabled {
+		jobQueue, err := queue.Default()
+		if err != nil {
+			zap.L().Error("初始化任务队列失败", zap.Error(err))
+			exit(1)
 		}
-	}()
+		workers = append(workers, lifecycle.Hook{Name: "queue", Phase: lifecycle.PhaseWorkers, Priority: queuePriority, OnStart: jobQueue.Start, OnStop: jobQueue.Stop})
+	}
 
-	exitCode := 0
-	select {
-	case received := <-quit:
-		zap.L().Info("Received stop signal, shutting down gracefully", zap.String("signal", received.String()))
-	case err := <-serverErrCh:
-		exitCode = 1
-		zap.L().Error("HTTP 服务异常退出，开始执行清理与退出", zap.Error(err))
+	// 任务执行中也可能写入 outbox，relay 在队列之后停止；未投递的事件留在表中，下次启动继续投递
+	if config.Current().Outbox.Enabled {
+		relay, err := outbox.NewFromConfig()
+		if err != nil {
+			zap.L().Error("初始化 outbox relay 失败", zap.Error(err))
+			exit(1)
+		}
+		workers = append(workers, lifecycle.Hook{Name: "outbox-relay", Phase: lifecycle.PhaseWorkers, Priority: outboxPriority, OnStart: relay.Start, OnStop: relay.Stop})
 	}
 
-	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), config.ShutdownTimeout)
-	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		exitCode = 1
-		zap.L().Error("Server forced to shutdown", zap.Error(err))
+	// 各模块的定时任务与任务队列一起在 HTTP 排空后停止
+	if jobs := a.Jobs(); len(jobs) > 0 {
+		scheduler, err := cron.New(jobs...)
+		if err != nil {
+			zap.L().Error("初始化定时任务失败", zap.Error(err))
+			exit(1)
+		}
+		workers = append(workers, lifecycle.Hook{Name: "cron", Phase: lifecycle.PhaseWorkers, Priority: cronPriority, OnStart: scheduler.Start, OnStop: scheduler.Stop})
+	}
+	if upgrade == nil {
+		for _, hook := range workers {
+			lc.Append(hook)
+		}
+	}
+
+	if err := lc.Start(context.Background()); err != nil {
+		zap.L().Error("启动后台组件失败", zap.Error(err))
+		exit(1)
+	}
+
+	exitCode := 0
+	var workerErrs <-chan error
+	startErr := startServer(server, upgrade, serverConfig)
+	if startErr == nil {
+		extras, startErr = startExtraListeners(a, handler, upgrade)
+	}
+	if startErr == nil && upgrade != nil {
+		startErr = upgrade.takeOver(serverConfig.PidFile, pid)
+		pidWritten = startErr == nil && serverConfig.PidFile != ""
+		if startErr == nil {
+			workerErrs = startWorkersAfterParent(lc, upgrade, workers)
+		}
 	}
-	cancelShutdown()
+	if startErr != nil {
+		if upgrade != nil {
+			upgrade.abandon()
+		}
+		exitCode = 1
+		zap.L().Error("HTTP 服务异常退出，开始执行清理与退出", zap.Error(startErr))
+	} else {
+		serving = true
+		zap.L().Info("Server is starting...", zap.String("addr", server.Addr().String()), zap.String("version", Version))
+		if upgrade != nil {
+			// 热升级的新进程由旧进程启动，需要告诉 systemd 主进程已经换成自己（NotifyAccess=all）
+			notifySystemd(systemd.MainPID(pid), systemd.Ready, systemd.Status("serving on "+server.Addr().String()))
+		} else {
+			notifySystemd(systemd.Ready, systemd.Status("serving on "+server.Addr().String()))
+		}
+		stopWatchdog := startWatchdog(watchdogCheck(func() []domainhealth.Check { return watchdogChecks(a, server) }))
+		// 服务启动后才开始监听配置变更，重载时需要把新配置应用到 handler 与 server
+		unsubscribeReload := config.Subscribe(func(old, cfg *config.Config) {
+			applyReload(old, cfg, handler, server, extras)
+		})
+		defer unsubscribeReload()
+		config.WatchConfig(nil)
+		upgradeServers := extras.servers()
+		upgradeServers["http"] = server
+		upgraded, stopUpgradeSignal := watchUpgradeSignal(upgradeServers)
+		defer stopUpgradeSignal()
 
-	zap.L().Info("Cleaning up resources...")
-	middleware.CleanupAllLimiters()
-	msqldb.CloseClient()
-	rdb.CloseClient()
-	if config.PidFile != "" {
-		if err := pidfile.Remove(config.PidFile, pid); err != nil {
-			zap.L().Warn("删除 pid 文件失败", zap.String("pid_file", config.PidFile), zap.Error(err))
+		select {
+		case received := <-quit:
+			zap.L().Info("Received stop signal, shutting down gracefully", zap.String("signal", received.String()))
+		case <-upgraded:
+			// 新进程已接管监听 socket、pid 文件与 systemd 通知，本进程只需排空已有请求
+			pidWritten = false
+			stopWatchdog()
+			upgradedAway = true
+		case err := <-server.Errors():
+			exitCode = 1
+			zap.L().Error("HTTP 服务异常退出，开始执行清理与退出", zap.Error(err))
+		case err := <-extras.Errors():
+			exitCode = 1
+			zap.L().Error("附加监听异常退出，开始执行清理与退出", zap.Error(err))
+		case err := <-workerErrs:
+			exitCode = 1
+			zap.L().Error("启动后台组件失败", zap.Error(err))
 		}
 	}
-	zap.L().Info("Server exited", zap.Int("exit_code", exitCode))
-	log.StopMonitor()
-	command.Exit(exitCode)
+	exit(exitCode)
 }
@@ -0,0 +1,11 @@
+// Package modules 导入全部业务模块，使其在 init 中登记到 application。
+// main 只需导入本包，路由、迁移、定时任务、健康检查与停止即按模块依赖顺序自动发现；
+// 新增模块时实现 application.Module、在模块包的 init 中调用 application.RegisterModule，
+// 再在这里追加一行导入即可。
+package modules
+
+import (
+	_ "http-services/api/app/v1/admin/audit"
+	_ "http-services/api/app/v1/open/health"
+	_ "http-services/services/outbox"
+)
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"http-services/config"
+	"http-services/utils/encryption"
+
+	"go.uber.org/zap"
+)
+
+// applyPasswordPolicy 按 password 配置替换默认密码策略；配置热加载后也会重新调用，
+// 调整算法或成本参数后，用户下次登录时旧哈希会被标记为需要升级
+func applyPasswordPolicy() {
+	passwordConfig := config.Current().Password
+	var current encryption.PasswordHasher
+	switch passwordConfig.Algorithm {
+	case "scrypt":
+		current = encryption.NewScryptHasher(encryption.ScryptParams{
+			LogN: uint8(passwordConfig.ScryptLogN),
+			R:    passwordConfig.ScryptR,
+			P:    passwordConfig.ScryptP,
+		})
+	case "bcrypt":
+		current = encryption.NewBcryptHasher(passwordConfig.BcryptCost)
+	default:
+		current = encryption.NewArgon2idHasher(encryption.Argon2idParams{
+			Memory:      uint32(passwordConfig.Argon2Memory),
+			Time:        uint32(passwordConfig.Argon2Time),
+			Parallelism: uint8(passwordConfig.Argon2Parallelism),
+		})
+	}
+	encryption.SetDefaultPasswordPolicy(encryption.NewPasswordPolicy(current, encryption.PasswordOptions{
+		Pepper:          passwordConfig.Pepper,
+		AllowUnpeppered: passwordConfig.AllowUnpeppered,
+	}))
+	zap.L().Debug("密码策略已应用",
+		zap.String("algorithm", current.Algorithm()),
+		zap.Bool("pepper", passwordConfig.Pepper != ""),
+	)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"http-services/api"
+	"http-services/config"
+	"http-services/db/msqldb"
+	"http-services/utils/httpserver"
+	"http-services/utils/log"
+
+	"go.uber.org/zap"
+)
+
+// restartRequiredKeys 是热重载后不会生效、需要重启进程的配置 key；以 . 结尾的表示整段。
+// 这些组件只在启动时构建一次（连接、租约、后台 worker、已打开的文件），其余配置都会在重载时应用。
+var restartRequiredKeys = []string{
+	"server.pid_file",
+	"server.unix_socket.",
+	"server.tls.",
+	"admin.listen",
+	"audit.enabled",
+	"audit.sinks",
+	"audit.file",
+	"id.",
+	"database.mysql_dsn",
+	"database.replica_dsns",
+	"redis.",
+	"queue.",
+	"outbox.",
+}
+
+// needsRestart 判断配置 key 是否属于 restartRequiredKeys
+func needsRestart(key string) bool {
+	for _, pattern := range restartRequiredKeys {
+		if key == pattern || (strings.HasSuffix(pattern, ".") && strings.HasPrefix(key, pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitReloadKeys 把变化的 key 分成已热应用与需要重启两组
+func splitReloadKeys(changed []string) (applied, restart []string) {
+	for _, key := range changed {
+		if needsRestart(key) {
+			restart = append(restart, key)
+		} else {
+			applied = append(applied, key)
+		}
+	}
+	return applied, restart
+}
+
+// httpServerConfig 从 server 配置段取出 http.Server 的监听与连接参数
+func httpServerConfig(serverConfig config.ServerConfig) httpserver.Config {
+	return httpserver.Config{
+		Addr:           net.JoinHostPort(serverConfig.Host, strconv.Itoa(serverConfig.Port)),
+		ReadTimeout:    serverConfig.ReadTimeout,
+		WriteTimeout:   serverConfig.WriteTimeout,
+		IdleTimeout:    serverConfig.IdleTimeout,
+		MaxHeaderBytes: serverConfig.MaxHeaderBytes,
+		H2C:            serverConfig.H2C,
+		TLSConfig:      serverTLS,
+	}
+}
+
+// applyReload 把新发布的配置应用到运行中的组件，并记录哪些 key 已生效、哪些需要重启。
+// 限流、跨域、请求体上限、JWT、admin token 等在使用处读取 config.Current()，这里无需处理。
+func applyReload(old, cfg *config.Config, handler *api.Handler, server *httpserver.Server, extras *extraListeners) {
+	changed := config.ChangedKeys(old, cfg)
+	if len(changed) == 0 {
+		return
+	}
+
+	log.SetLogger()
+	msqldb.ApplyConfig()
+	applyPasswordPolicy()
+	if err := applyEncryptionKeys(); err != nil {
+		zap.L().Error("重新加载字段加密密钥失败，继续使用旧密钥", zap.Error(err))
+	}
+	if handler.Reload(cfg.Server) {
+		zap.L().Info("路由已按新的 trusted_proxies / static_dir 重建")
+	}
+
+	applied, restart := splitReloadKeys(changed)
+	listenConfig := httpServerConfig(cfg.Server)
+	if systemdListenAddr != "" {
+		// 监听 socket 来自 systemd，地址只能在 .socket 单元中修改
+		listenConfig.Addr = systemdListenAddr
+		applied, restart = moveKeys(applied, restart, systemdListenKeys)
+	}
+	if swapped, err := server.Reconfigure(listenConfig, cfg.Server.ShutdownTimeout); err != nil {
+		// 新地址绑定失败时原监听保持不变，监听相关的 key 归入未生效
+		zap.L().Error("应用新的监听配置失败，继续使用原监听", zap.Error(err))
+		applied, restart = moveKeys(applied, restart, serverListenKeys)
+	} else if swapped {
+		zap.L().Info("HTTP server 已切换到新的监听配置", zap.String("addr", server.Addr().String()))
+	}
+	extras.reconfigure(cfg.Server)
+
+	fields := []zap.Field{zap.Strings("applied", applied)}
+	if len(restart) > 0 {
+		fields = append(fields, zap.Strings("restart_required", restart))
+		zap.L().Warn("Configuration reloaded, some changes need a restart", fields...)
+		return
+	}
+	zap.L().Info("Configuration reloaded", fields...)
+}
+
+// serverListenKeys 是由 httpserver.Server.Reconfigure 应用的配置 key
+var serverListenKeys = []string{
+	"server.host",
+	"server.port",
+	"server.read_timeout",
+	"server.write_timeout",
+	"server.idle_timeout",
+	"server.max_header_bytes",
+	"server.h2c",
+}
+
+// systemdListenKeys 是 socket activation 时由 .socket 单元决定、不能热应用的配置 key
+var systemdListenKeys = []string{"server.host", "server.port"}
+
+// moveKeys 把 applied 中属于 keys 的配置移到 restart
+func moveKeys(applied, restart, keys []string) ([]string, []string) {
+	kept := applied[:0]
+	for _, key := range applied {
+		if slices.Contains(keys, key) {
+			restart = append(restart, key)
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	return kept, restart
+}
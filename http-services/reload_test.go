@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitReloadKeys(t *testing.T) {
+	changed := []string{
+		"server.port",
+		"server.enable_cors",
+		"server.pid_file",
+		"log.level",
+		"audit.actor_claim",
+		"audit.file",
+		"database.max_open_conns",
+		"database.mysql_dsn",
+		"redis.host",
+		"id.machine_id",
+	}
+	applied, restart := splitReloadKeys(changed)
+
+	wantApplied := []string{"server.port", "server.enable_cors", "log.level", "audit.actor_claim", "database.max_open_conns"}
+	wantRestart := []string{"server.pid_file", "audit.file", "database.mysql_dsn", "redis.host", "id.machine_id"}
+	if !slices.Equal(applied, wantApplied) {
+		t.Errorf("applied = %v, want %v", applied, wantApplied)
+	}
+	if !slices.Equal(restart, wantRestart) {
+		t.Errorf("restart = %v, want %v", restart, wantRestart)
+	}
+
+	// 新监听地址绑定失败时，监听相关的 key 改为需要重启
+	applied, restart = moveKeys(applied, restart, serverListenKeys)
+	if slices.Contains(applied, "server.port") || !slices.Contains(restart, "server.port") {
+		t.Errorf("after failed rebind applied = %v restart = %v, want server.port moved", applied, restart)
+	}
+}
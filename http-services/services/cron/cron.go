@@ -0,0 +1,130 @@
+// Package cron 按固定间隔执行模块登记的定时任务。
+//
+// 每个任务在独立的 goroutine 中运行，上一次执行结束前不会开始下一次；执行返回的错误与 panic
+// 只记录日志，不影响其他任务和后续执行。多实例部署时每个实例都会执行，需要全局只执行一次的任务
+// 应自行加锁或改用任务队列。
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"http-services/utils/taskgroup"
+)
+
+// ErrStopped 表示调度器已停止，不再接受启动请求
+var ErrStopped = errors.New("cron: scheduler stopped")
+
+// Job 是一个定时任务
+type Job struct {
+	Name     string                          // 任务名称，用于日志
+	Interval time.Duration                   // 执行间隔，从启动后经过一个间隔开始第一次执行
+	Run      func(ctx context.Context) error // ctx 在调度器停止时取消
+}
+
+// Scheduler 运行一组定时任务
+type Scheduler struct {
+	jobs []Job
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New 创建调度器；任务缺少名称、执行函数或间隔不为正时返回错误
+func New(jobs ...Job) (*Scheduler, error) {
+	for _, job := range jobs {
+		if job.Name == "" || job.Run == nil {
+			return nil, fmt.Errorf("cron: job %q has no name or run function", job.Name)
+		}
+		if job.Interval <= 0 {
+			return nil, fmt.Errorf("cron: job %q interval must be positive", job.Name)
+		}
+	}
+	return &Scheduler{jobs: jobs}, nil
+}
+
+// Start 启动全部任务，立即返回
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrStopped
+	}
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	tasks := make([]taskgroup.Task, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		tasks = append(tasks, taskgroup.ContinueOnError("cron-"+job.Name, func(ctx context.Context) error {
+			return loop(ctx, job)
+		}))
+	}
+	go func() {
+		defer close(s.done)
+		for _, err := range taskgroup.Run(runCtx, tasks...) {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				zap.L().Error("cron job exited unexpectedly", zap.Error(err))
+			}
+		}
+	}()
+
+	zap.L().Info("cron scheduler started", zap.Int("jobs", len(s.jobs)))
+	return nil
+}
+
+// Stop 停止调度并等待正在执行的任务返回；ctx 到期时返回 ctx.Err()
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+		zap.L().Info("cron scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func loop(ctx context.Context, job Job) error {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			runOnce(ctx, job)
+		}
+	}
+}
+
+// runOnce 执行一次任务，panic 被转换为错误记录，不会中断后续执行
+func runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	err := taskgroup.Run(ctx, taskgroup.ContinueOnError(job.Name, job.Run))[0]
+	if err != nil && ctx.Err() == nil {
+		zap.L().Error("cron job failed", zap.String("job", job.Name), zap.Duration("elapsed", time.Since(start)), zap.Error(err))
+	}
+}
@@ -0,0 +1,102 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerKeepsRunningAfterErrorsAndPanics(t *testing.T) {
+	var runs, panics atomic.Int32
+	scheduler, err := New(
+		Job{Name: "flaky", Interval: 10 * time.Millisecond, Run: func(context.Context) error {
+			runs.Add(1)
+			return errors.New("temporary failure")
+		}},
+		Job{Name: "panicky", Interval: 10 * time.Millisecond, Run: func(context.Context) error {
+			panics.Add(1)
+			panic("boom")
+		}},
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() < 3 || panics.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("runs = %d, panics = %d, want each job to keep running", runs.Load(), panics.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	stoppedAt := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != stoppedAt {
+		t.Fatal("job ran after Stop returned")
+	}
+	if err := scheduler.Start(context.Background()); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Start() after Stop error = %v, want ErrStopped", err)
+	}
+}
+
+func TestStopWaitsForRunningJob(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var finished atomic.Bool
+	scheduler, err := New(Job{Name: "slow", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		finished.Store(true)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := scheduler.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() while job running error = %v, want DeadlineExceeded", err)
+	}
+	close(release)
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("Stop returned before the running job finished")
+	}
+}
+
+func TestNewRejectsInvalidJobs(t *testing.T) {
+	run := func(context.Context) error { return nil }
+	tests := []struct {
+		name string
+		job  Job
+	}{
+		{"missing name", Job{Interval: time.Second, Run: run}},
+		{"missing run", Job{Name: "job", Interval: time.Second}},
+		{"zero interval", Job{Name: "job", Run: run}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.job); err == nil {
+				t.Fatal("New() error = nil, want error")
+			}
+		})
+	}
+}
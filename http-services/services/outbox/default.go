@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"fmt"
+
+	"http-services/config"
+	"http-services/db/msqldb"
+	"http-services/db/rdb"
+)
+
+// NewFromConfig 基于 config 与 db/msqldb、db/rdb 客户端构建 relay，投递目标为 Redis Stream
+func NewFromConfig() (*Relay, error) {
+	database, err := msqldb.Client()
+	if err != nil {
+		return nil, fmt.Errorf("init outbox mysql store: %w", err)
+	}
+	client, err := rdb.Client()
+	if err != nil {
+		return nil, fmt.Errorf("init outbox redis publisher: %w", err)
+	}
+	outboxConfig := config.Current().Outbox
+	publisher := NewRedisStreamPublisher(client, outboxConfig.StreamPrefix, outboxConfig.StreamMaxLen)
+	return New(GormStore{DB: database}, publisher, OptionsFromConfig()), nil
+}
+
+// OptionsFromConfig 从 outbox 配置段构建 relay 参数
+func OptionsFromConfig() Options {
+	outboxConfig := config.Current().Outbox
+	return Options{
+		PollInterval:    outboxConfig.PollInterval,
+		BatchSize:       outboxConfig.BatchSize,
+		MaxAttempts:     outboxConfig.MaxAttempts,
+		RetryBase:       outboxConfig.RetryBase,
+		RetryMax:        outboxConfig.RetryMax,
+		Retention:       outboxConfig.Retention,
+		CleanupInterval: outboxConfig.CleanupInterval,
+	}
+}
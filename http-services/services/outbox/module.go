@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+
+	"http-services/application"
+	"http-services/db"
+	dbOutbox "http-services/db/msqldb/outbox"
+	domainhealth "http-services/domain/health"
+)
+
+func init() {
+	application.RegisterModule(NewModule)
+}
+
+// Module 把 outbox 接入 App：提供 outbox 表迁移，启用 relay 时检查 MySQL 与 Redis 连通性。
+// relay 的启动与停止依赖 outbox.enabled，仍由 main 注册到生命周期
+type Module struct {
+	application.BaseModule
+	app *application.App
+}
+
+// NewModule 创建 outbox 模块
+func NewModule(a *application.App) application.Module {
+	return &Module{app: a}
+}
+
+// Name 模块名称
+func (m *Module) Name() string { return "outbox" }
+
+// Migrations 返回 outbox 表迁移
+func (m *Module) Migrations() []db.Migrator {
+	return []db.Migrator{{Name: "outbox", Migrate: dbOutbox.Migrate}}
+}
+
+// HealthChecks 在启用 relay 时检查投递依赖的 MySQL 与 Redis，未启用时检查项直接通过
+func (m *Module) HealthChecks() []domainhealth.Check {
+	return []domainhealth.Check{{Name: "outbox", Check: func(ctx context.Context) error {
+		if !m.app.Config().Outbox.Enabled {
+			return nil
+		}
+		return errors.Join(m.app.PingDB(ctx), m.app.PingRedis(ctx))
+	}}}
+}
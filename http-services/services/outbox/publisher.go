@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	dbOutbox "http-services/db/msqldb/outbox"
+)
+
+// Publisher 将 outbox 事件投递到外部消息系统
+// 返回 nil 表示消息已被下游持久接收；relay 只保证至少一次投递，消费者需按事件 ID 幂等处理。
+type Publisher interface {
+	Publish(ctx context.Context, event dbOutbox.Event) error
+}
+
+// PublisherFunc 允许使用普通函数作为 Publisher
+type PublisherFunc func(ctx context.Context, event dbOutbox.Event) error
+
+// Publish 调用函数本身
+func (f PublisherFunc) Publish(ctx context.Context, event dbOutbox.Event) error {
+	return f(ctx, event)
+}
+
+// RedisStreamPublisher 将事件写入 Redis Stream，每个 topic 对应一个 stream
+// stream key 为 prefix + topic，并受 db/rdb 全局 key 前缀约束。
+type RedisStreamPublisher struct {
+	client redis.Cmdable
+	prefix string
+	maxLen int64
+}
+
+// NewRedisStreamPublisher 创建 Redis Stream publisher；maxLen > 0 时以近似裁剪限制 stream 长度
+func NewRedisStreamPublisher(client redis.Cmdable, prefix string, maxLen int64) *RedisStreamPublisher {
+	return &RedisStreamPublisher{client: client, prefix: prefix, maxLen: maxLen}
+}
+
+// Stream 返回 topic 对应的 stream key（不含 db/rdb 全局前缀）
+func (p *RedisStreamPublisher) Stream(topic string) string {
+	return p.prefix + topic
+}
+
+// Publish 以 XADD 写入事件，字段包含事件 ID、分区键、payload 与 trace_id
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event dbOutbox.Event) error {
+	args := &redis.XAddArgs{
+		Stream: p.Stream(event.Topic),
+		Values: []any{
+			"event_id", strconv.FormatUint(event.ID, 10),
+			"topic", event.Topic,
+			"key", event.Key,
+			"payload", string(event.Payload),
+			"trace_id", event.TraceID,
+			"created_at", event.CreatedAt.UnixMilli(),
+		},
+	}
+	if p.maxLen > 0 {
+		args.MaxLen = p.maxLen
+		args.Approx = true
+	}
+	return p.client.XAdd(ctx, args).Err()
+}
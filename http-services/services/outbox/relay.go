@@ -0,0 +1,262 @@
+// Package outbox 提供事务型 outbox 的 relay：轮询 outbox 表并将事件可靠投递到 Publisher。
+//
+// 写入端使用 db/msqldb/outbox.Add 在业务事务内登记事件；relay 以 SKIP LOCKED 领取事件，
+// 投递成功后标记为已投递，失败则指数退避重试，失败次数达到上限后标记为失败不再投递；
+// 已投递事件超过保留期后被清理，失败事件保留待人工处理。
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	dbOutbox "http-services/db/msqldb/outbox"
+	"http-services/utils/backoff"
+	"http-services/utils/log"
+	"http-services/utils/taskgroup"
+)
+
+// ErrStopped 表示 relay 已停止，不再接受启动请求
+var ErrStopped = errors.New("outbox: relay stopped")
+
+// Store 抽象 outbox 表的领取与清理操作，便于在测试中替换
+type Store interface {
+	Process(
+		ctx context.Context,
+		now time.Time,
+		limit int,
+		maxAttempts int,
+		deliver func(context.Context, dbOutbox.Event) error,
+		retryAt func(attempts int) time.Time,
+	) (delivered, failed int, err error)
+	DeleteDelivered(ctx context.Context, before time.Time, limit int) (int64, error)
+	Stats(ctx context.Context) (dbOutbox.Stats, error)
+}
+
+// GormStore 基于 GORM 的 Store 实现
+type GormStore struct {
+	DB *gorm.DB
+}
+
+// Process 见 dbOutbox.Process
+func (s GormStore) Process(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	maxAttempts int,
+	deliver func(context.Context, dbOutbox.Event) error,
+	retryAt func(attempts int) time.Time,
+) (int, int, error) {
+	return dbOutbox.Process(ctx, s.DB, now, limit, maxAttempts, deliver, retryAt)
+}
+
+// DeleteDelivered 见 dbOutbox.DeleteDelivered
+func (s GormStore) DeleteDelivered(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return dbOutbox.DeleteDelivered(ctx, s.DB, before, limit)
+}
+
+// Stats 见 dbOutbox.CountStats
+func (s GormStore) Stats(ctx context.Context) (dbOutbox.Stats, error) {
+	return dbOutbox.CountStats(ctx, s.DB)
+}
+
+// Options relay 运行参数
+type Options struct {
+	PollInterval    time.Duration // 无待投递事件时的轮询间隔
+	BatchSize       int           // 单次事务领取的事件数
+	MaxAttempts     int           // 最大投递次数（含首次），用尽后事件标记为失败
+	RetryBase       time.Duration // 首次重试等待时间，之后指数增长
+	RetryMax        time.Duration // 重试等待时间上限
+	Retention       time.Duration // 已投递事件保留时长，<= 0 表示不清理
+	CleanupInterval time.Duration // 清理间隔
+}
+
+// Relay 轮询 outbox 表并投递事件
+type Relay struct {
+	store     Store
+	publisher Publisher
+	opts      Options
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New 创建 relay；Options 中未设置的字段使用默认值
+func New(store Store, publisher Publisher, opts Options) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 20
+	}
+	if opts.RetryBase <= 0 {
+		opts.RetryBase = time.Second
+	}
+	if opts.RetryMax <= 0 {
+		opts.RetryMax = 5 * time.Minute
+	}
+	if opts.CleanupInterval <= 0 {
+		opts.CleanupInterval = time.Hour
+	}
+	return &Relay{store: store, publisher: publisher, opts: opts}
+}
+
+// RunOnce 领取并投递一批到期事件，返回成功投递与失败的数量
+func (r *Relay) RunOnce(ctx context.Context) (delivered, failed int, err error) {
+	now := time.Now()
+	return r.store.Process(ctx, now, r.opts.BatchSize, r.opts.MaxAttempts, r.deliver, func(attempts int) time.Time {
+		return now.Add(backoff.Exponential(attempts, r.opts.RetryBase, r.opts.RetryMax))
+	})
+}
+
+// Stats 返回待投递与失败的事件数量
+func (r *Relay) Stats(ctx context.Context) (dbOutbox.Stats, error) {
+	return r.store.Stats(ctx)
+}
+
+// Cleanup 删除超过保留期的已投递事件，返回删除条数
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	if r.opts.Retention <= 0 {
+		return 0, nil
+	}
+	before := time.Now().Add(-r.opts.Retention)
+	var total int64
+	for {
+		deleted, err := r.store.DeleteDelivered(ctx, before, r.opts.BatchSize)
+		total += deleted
+		if err != nil || deleted < int64(r.opts.BatchSize) || ctx.Err() != nil {
+			return total, err
+		}
+	}
+}
+
+// Start 启动投递与清理循环，立即返回
+func (r *Relay) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return ErrStopped
+	}
+	if r.started {
+		return nil
+	}
+	r.started = true
+
+	runCtx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		for _, err := range taskgroup.Run(runCtx,
+			taskgroup.ContinueOnError("outbox-relay", r.relayLoop),
+			taskgroup.ContinueOnError("outbox-cleanup", r.cleanupLoop),
+		) {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				zap.L().Error("outbox relay exited unexpectedly", zap.Error(err))
+			}
+		}
+	}()
+
+	zap.L().Info("outbox relay started", zap.Duration("poll_interval", r.opts.PollInterval))
+	return nil
+}
+
+// Stop 停止 relay 并等待当前批次结束；ctx 到期时返回 ctx.Err()
+// 未完成的批次事务会回滚，事件留待下次启动后重新投递。
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	if !r.started {
+		r.mu.Unlock()
+		return nil
+	}
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+		zap.L().Info("outbox relay stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Relay) relayLoop(ctx context.Context) error {
+	for ctx.Err() == nil {
+		delivered, failed, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			zap.L().Error("outbox relay batch failed", zap.Error(err))
+		}
+		// 满批说明可能还有积压，立即进入下一批
+		if err == nil && delivered+failed >= r.opts.BatchSize {
+			continue
+		}
+		wait(ctx, r.opts.PollInterval)
+	}
+	return ctx.Err()
+}
+
+func (r *Relay) cleanupLoop(ctx context.Context) error {
+	if r.opts.Retention <= 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	for {
+		wait(ctx, r.opts.CleanupInterval)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		deleted, err := r.Cleanup(ctx)
+		if err != nil && ctx.Err() == nil {
+			zap.L().Error("outbox cleanup failed", zap.Error(err))
+			continue
+		}
+		if deleted > 0 {
+			zap.L().Info("outbox delivered events cleaned", zap.Int64("deleted", deleted))
+		}
+	}
+}
+
+func (r *Relay) deliver(ctx context.Context, event dbOutbox.Event) error {
+	if event.TraceID != "" {
+		ctx = log.WithTraceID(ctx, event.TraceID)
+	}
+	err := r.publisher.Publish(ctx, event)
+	if err == nil {
+		return nil
+	}
+	fields := []zap.Field{
+		zap.Uint64("event_id", event.ID),
+		zap.String("topic", event.Topic),
+		zap.Int("attempts", event.Attempts+1),
+		zap.Error(err),
+	}
+	if event.Attempts+1 >= r.opts.MaxAttempts {
+		log.FromStandardContext(ctx).Error("outbox event exhausted delivery attempts, marked failed", fields...)
+	} else {
+		log.FromStandardContext(ctx).Warn("publish outbox event failed, retry scheduled", fields...)
+	}
+	return err
+}
+
+func wait(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,305 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	dbOutbox "http-services/db/msqldb/outbox"
+	"http-services/utils/log"
+)
+
+// memoryStore 在内存中模拟 outbox 表的领取、标记与清理语义
+type memoryStore struct {
+	mu     sync.Mutex
+	events []*dbOutbox.Event
+}
+
+func (s *memoryStore) add(event dbOutbox.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.ID = uint64(len(s.events) + 1)
+	s.events = append(s.events, &event)
+}
+
+func (s *memoryStore) get(id uint64) dbOutbox.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.events[id-1]
+}
+
+func (s *memoryStore) Process(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	maxAttempts int,
+	deliver func(context.Context, dbOutbox.Event) error,
+	retryAt func(attempts int) time.Time,
+) (delivered, failed int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocked := make(map[string]bool)
+	for _, event := range s.events {
+		if delivered+failed >= limit {
+			break
+		}
+		if event.DeliveredAt != nil || event.FailedAt != nil {
+			continue
+		}
+		// 同一 key 只领取最早的待投递事件
+		ordered := event.Key != "" && blocked[event.Key]
+		blocked[event.Key] = true
+		if ordered || event.AvailableAt.After(now) {
+			continue
+		}
+		if err := deliver(ctx, *event); err != nil {
+			failed++
+			event.Attempts++
+			event.LastError = err.Error()
+			if event.Attempts >= maxAttempts {
+				failedAt := now
+				event.FailedAt = &failedAt
+			} else {
+				event.AvailableAt = retryAt(event.Attempts)
+			}
+			continue
+		}
+		delivered++
+		deliveredAt := now
+		event.DeliveredAt = &deliveredAt
+	}
+	return delivered, failed, nil
+}
+
+func (s *memoryStore) DeleteDelivered(_ context.Context, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	kept := s.events[:0]
+	for _, event := range s.events {
+		if deleted < int64(limit) && event.DeliveredAt != nil && event.DeliveredAt.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	s.events = kept
+	return deleted, nil
+}
+
+func (s *memoryStore) Stats(context.Context) (dbOutbox.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stats dbOutbox.Stats
+	for _, event := range s.events {
+		switch {
+		case event.FailedAt != nil:
+			stats.Failed++
+		case event.DeliveredAt == nil:
+			stats.Pending++
+		}
+	}
+	return stats, nil
+}
+
+func TestRelay_RunOnceRetriesFailedEventsWithBackoff(t *testing.T) {
+	store := &memoryStore{}
+	store.add(dbOutbox.Event{Topic: "order.created", AvailableAt: time.Now()})
+	store.add(dbOutbox.Event{Topic: "order.paid", AvailableAt: time.Now()})
+
+	publisher := PublisherFunc(func(_ context.Context, event dbOutbox.Event) error {
+		if event.Topic == "order.paid" {
+			return errors.New("broker down")
+		}
+		return nil
+	})
+	relay := New(store, publisher, Options{BatchSize: 10, RetryBase: time.Minute, RetryMax: time.Hour})
+
+	before := time.Now()
+	delivered, failed, err := relay.RunOnce(context.Background())
+	if err != nil || delivered != 1 || failed != 1 {
+		t.Fatalf("RunOnce() = %d, %d, %v", delivered, failed, err)
+	}
+	if store.get(1).DeliveredAt == nil {
+		t.Fatal("successful event not marked delivered")
+	}
+	paid := store.get(2)
+	if paid.DeliveredAt != nil || paid.Attempts != 1 || paid.LastError != "broker down" {
+		t.Fatalf("failed event = %#v", paid)
+	}
+	if paid.AvailableAt.Before(before.Add(time.Minute)) {
+		t.Fatalf("retry scheduled at %v, want >= 1m later", paid.AvailableAt)
+	}
+
+	// 未到重试时间的事件不会被再次领取
+	delivered, failed, err = relay.RunOnce(context.Background())
+	if err != nil || delivered != 0 || failed != 0 {
+		t.Fatalf("second RunOnce() = %d, %d, %v", delivered, failed, err)
+	}
+}
+
+func TestRelay_DeliverRestoresTraceID(t *testing.T) {
+	store := &memoryStore{}
+	store.add(dbOutbox.Event{Topic: "order.created", TraceID: "trace-1", AvailableAt: time.Now()})
+
+	var got string
+	relay := New(store, PublisherFunc(func(ctx context.Context, _ dbOutbox.Event) error {
+		got, _ = log.TraceID(ctx)
+		return nil
+	}), Options{})
+	if _, _, err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if got != "trace-1" {
+		t.Fatalf("publisher trace ID = %q, want trace-1", got)
+	}
+}
+
+func TestRelay_CleanupDeletesExpiredDeliveredEvents(t *testing.T) {
+	store := &memoryStore{}
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now()
+	for range 3 {
+		store.add(dbOutbox.Event{Topic: "old", DeliveredAt: &old})
+	}
+	store.add(dbOutbox.Event{Topic: "recent", DeliveredAt: &recent})
+	store.add(dbOutbox.Event{Topic: "pending", AvailableAt: time.Now().Add(time.Hour)})
+
+	relay := New(store, PublisherFunc(func(context.Context, dbOutbox.Event) error { return nil }), Options{
+		BatchSize: 2,
+		Retention: 24 * time.Hour,
+	})
+	deleted, err := relay.Cleanup(context.Background())
+	if err != nil || deleted != 3 {
+		t.Fatalf("Cleanup() = %d, %v, want 3", deleted, err)
+	}
+	if len(store.events) != 2 {
+		t.Fatalf("remaining events = %d, want 2", len(store.events))
+	}
+}
+
+func TestRelay_StartDeliversUntilStopped(t *testing.T) {
+	store := &memoryStore{}
+	published := make(chan uint64, 1)
+	relay := New(store, PublisherFunc(func(_ context.Context, event dbOutbox.Event) error {
+		published <- event.ID
+		return nil
+	}), Options{PollInterval: 5 * time.Millisecond})
+
+	if err := relay.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	store.add(dbOutbox.Event{Topic: "order.created", AvailableAt: time.Now()})
+	select {
+	case id := <-published:
+		if id != 1 {
+			t.Fatalf("published event %d, want 1", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not published")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := relay.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := relay.Start(context.Background()); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Start() after Stop error = %v, want %v", err, ErrStopped)
+	}
+}
+
+func TestRedisStreamPublisher_PublishesToTopicStream(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	publisher := NewRedisStreamPublisher(client, "outbox:", 100)
+	event := dbOutbox.Event{ID: 7, Topic: "order.created", Key: "A-1", Payload: []byte(`{"no":"A-1"}`), TraceID: "trace-1"}
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	messages, err := client.XRange(context.Background(), "outbox:order.created", "-", "+").Result()
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("XRange() = %v, %v", messages, err)
+	}
+	values := messages[0].Values
+	if values["event_id"] != "7" || values["key"] != "A-1" || values["payload"] != `{"no":"A-1"}` || values["trace_id"] != "trace-1" {
+		t.Fatalf("stream message = %#v", values)
+	}
+}
+
+func TestRelay_RunOnceMarksEventFailedAfterMaxAttempts(t *testing.T) {
+	store := &memoryStore{}
+	store.add(dbOutbox.Event{Topic: "order.paid", AvailableAt: time.Now()})
+
+	relay := New(store, PublisherFunc(func(context.Context, dbOutbox.Event) error {
+		return errors.New("broker down")
+	}), Options{MaxAttempts: 2, RetryBase: time.Nanosecond})
+
+	for range 3 {
+		if _, _, err := relay.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	event := store.get(1)
+	if event.FailedAt == nil || event.Attempts != 2 {
+		t.Fatalf("event after max attempts = %#v, want failed after 2 attempts", event)
+	}
+	stats, err := relay.Stats(context.Background())
+	if err != nil || stats.Pending != 0 || stats.Failed != 1 {
+		t.Fatalf("Stats() = %#v, %v, want 1 failed and none pending", stats, err)
+	}
+	if deleted, err := relay.Cleanup(context.Background()); err != nil || deleted != 0 {
+		t.Fatalf("Cleanup() = %d, %v, want failed event kept", deleted, err)
+	}
+}
+
+func TestRelay_RunOnceKeepsPerKeyOrder(t *testing.T) {
+	store := &memoryStore{}
+	store.add(dbOutbox.Event{Topic: "order.created", Key: "A-1", AvailableAt: time.Now()})
+	store.add(dbOutbox.Event{Topic: "order.paid", Key: "A-1", AvailableAt: time.Now()})
+	store.add(dbOutbox.Event{Topic: "order.created", Key: "B-1", AvailableAt: time.Now()})
+
+	brokerDown := true
+	var published []string
+	relay := New(store, PublisherFunc(func(_ context.Context, event dbOutbox.Event) error {
+		if event.Key == "A-1" && brokerDown {
+			return errors.New("broker down")
+		}
+		published = append(published, event.Key+" "+event.Topic)
+		return nil
+	}), Options{BatchSize: 10, RetryBase: time.Nanosecond})
+
+	// A-1 的第一个事件失败后，第二个事件不会越过它先投递
+	if delivered, failed, err := relay.RunOnce(context.Background()); err != nil || delivered != 1 || failed != 1 {
+		t.Fatalf("RunOnce() = %d, %d, %v, want B-1 delivered and A-1 failed", delivered, failed, err)
+	}
+	if store.get(2).DeliveredAt != nil {
+		t.Fatal("later event with the same key delivered before the failed one")
+	}
+
+	brokerDown = false
+	time.Sleep(time.Millisecond)
+	for range 2 {
+		if _, _, err := relay.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+	}
+	want := []string{"B-1 order.created", "A-1 order.created", "A-1 order.paid"}
+	if len(published) != len(want) {
+		t.Fatalf("published = %v, want %v", published, want)
+	}
+	for i := range want {
+		if published[i] != want[i] {
+			t.Fatalf("published = %v, want %v", published, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend 是任务的持久化存储
+// 语义：Reserve 领取的任务在 lease 到期前对其他 worker 不可见，
+// 到期仍未 Ack/Retry/Bury 的任务会被重新投递（进程崩溃时保证至少一次执行）。
+// 每次领取（包括 lease 到期后的重新投递）都在后端原子地把 Attempts 加 1 并保存，
+// 执行中崩溃的任务同样消耗执行次数，不会无限重新投递。
+type Backend interface {
+	// Enqueue 保存任务；RunAt 晚于 now 的任务进入延迟集合
+	Enqueue(ctx context.Context, job *Job) error
+	// Reserve 领取一个可执行任务并累加其 Attempts，没有任务时返回 ErrNoJob
+	Reserve(ctx context.Context, now time.Time, lease time.Duration) (*Job, error)
+	// Ack 确认任务执行成功并删除
+	Ack(ctx context.Context, job *Job) error
+	// Retry 将已领取任务放回延迟集合，在 job.RunAt 后再次执行
+	Retry(ctx context.Context, job *Job) error
+	// Bury 将已领取任务移入死信队列
+	Bury(ctx context.Context, job *Job) error
+	// DeadLetters 按进入顺序返回最多 limit 个死信任务
+	DeadLetters(ctx context.Context, limit int) ([]*Job, error)
+	// Revive 将死信任务重新放回就绪队列
+	Revive(ctx context.Context, id string, now time.Time) error
+	// Stats 返回各状态任务数量
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Stats 队列各状态的任务数量
+type Stats struct {
+	Ready      int64 `json:"ready"`
+	Delayed    int64 `json:"delayed"`
+	Processing int64 `json:"processing"`
+	Dead       int64 `json:"dead"`
+}
+
+// MemoryBackend 是进程内实现，适用于测试和单进程开发场景，进程退出后任务丢失
+type MemoryBackend struct {
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	ready      []string
+	delayed    map[string]time.Time
+	processing map[string]time.Time
+	dead       []string
+}
+
+// NewMemoryBackend 创建内存任务后端
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		jobs:       make(map[string]*Job),
+		delayed:    make(map[string]time.Time),
+		processing: make(map[string]time.Time),
+	}
+}
+
+func (b *MemoryBackend) Enqueue(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.jobs[job.ID] = cloneJob(job)
+	if job.RunAt.After(time.Now()) {
+		b.delayed[job.ID] = job.RunAt
+		return nil
+	}
+	b.ready = append(b.ready, job.ID)
+	return nil
+}
+
+func (b *MemoryBackend) Reserve(_ context.Context, now time.Time, lease time.Duration) (*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.promoteLocked(b.delayed, now)
+	b.promoteLocked(b.processing, now)
+	if len(b.ready) == 0 {
+		return nil, ErrNoJob
+	}
+	id := b.ready[0]
+	b.ready = b.ready[1:]
+	b.processing[id] = now.Add(lease)
+	b.jobs[id].Attempts++
+	return cloneJob(b.jobs[id]), nil
+}
+
+// promoteLocked 将到期的任务按到期时间顺序移入就绪队列（需要持有锁）
+func (b *MemoryBackend) promoteLocked(set map[string]time.Time, now time.Time) {
+	due := make([]string, 0)
+	for id, at := range set {
+		if !at.After(now) {
+			due = append(due, id)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return set[due[i]].Before(set[due[j]]) })
+	for _, id := range due {
+		delete(set, id)
+		b.ready = append(b.ready, id)
+	}
+}
+
+func (b *MemoryBackend) Ack(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.processing[job.ID]; !ok {
+		return ErrJobNotFound
+	}
+	delete(b.processing, job.ID)
+	delete(b.jobs, job.ID)
+	return nil
+}
+
+func (b *MemoryBackend) Retry(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.processing[job.ID]; !ok {
+		return ErrJobNotFound
+	}
+	delete(b.processing, job.ID)
+	b.jobs[job.ID] = cloneJob(job)
+	b.delayed[job.ID] = job.RunAt
+	return nil
+}
+
+func (b *MemoryBackend) Bury(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.processing[job.ID]; !ok {
+		return ErrJobNotFound
+	}
+	delete(b.processing, job.ID)
+	b.jobs[job.ID] = cloneJob(job)
+	b.dead = append(b.dead, job.ID)
+	return nil
+}
+
+func (b *MemoryBackend) DeadLetters(_ context.Context, limit int) ([]*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if limit <= 0 || limit > len(b.dead) {
+		limit = len(b.dead)
+	}
+	result := make([]*Job, 0, limit)
+	for _, id := range b.dead[:limit] {
+		result = append(result, cloneJob(b.jobs[id]))
+	}
+	return result, nil
+}
+
+func (b *MemoryBackend) Revive(_ context.Context, id string, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for index, deadID := range b.dead {
+		if deadID != id {
+			continue
+		}
+		b.dead = append(b.dead[:index], b.dead[index+1:]...)
+		job := b.jobs[id]
+		job.Attempts = 0
+		job.RunAt = now
+		job.FailedAt = nil
+		b.ready = append(b.ready, id)
+		return nil
+	}
+	return ErrJobNotFound
+}
+
+func (b *MemoryBackend) Stats(_ context.Context) (Stats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Stats{
+		Ready:      int64(len(b.ready)),
+		Delayed:    int64(len(b.delayed)),
+		Processing: int64(len(b.processing)),
+		Dead:       int64(len(b.dead)),
+	}, nil
+}
+
+func cloneJob(job *Job) *Job {
+	if job == nil {
+		return nil
+	}
+	copied := *job
+	copied.Payload = append([]byte(nil), job.Payload...)
+	if job.FailedAt != nil {
+		failedAt := *job.FailedAt
+		copied.FailedAt = &failedAt
+	}
+	return &copied
+}
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+
+	"http-services/config"
+	"http-services/db/rdb"
+)
+
+var (
+	defaultQueue   *Queue
+	defaultQueueMu sync.Mutex
+)
+
+// Default 返回基于 config 与 db/rdb 构建的全局队列，首次调用时初始化
+// 业务模块在启动阶段通过 Handle 注册 handler，在请求中通过 Enqueue 投递任务。
+func Default() (*Queue, error) {
+	defaultQueueMu.Lock()
+	defer defaultQueueMu.Unlock()
+
+	if defaultQueue != nil {
+		return defaultQueue, nil
+	}
+	client, err := rdb.Client()
+	if err != nil {
+		return nil, fmt.Errorf("init queue redis backend: %w", err)
+	}
+	defaultQueue = New(NewRedisBackend(client, config.Current().Queue.Name), OptionsFromConfig())
+	return defaultQueue, nil
+}
+
+// SetDefault 替换全局队列（测试中可注入 MemoryBackend 队列），传入 nil 表示重置
+func SetDefault(q *Queue) {
+	defaultQueueMu.Lock()
+	defer defaultQueueMu.Unlock()
+	defaultQueue = q
+}
+
+// OptionsFromConfig 从 queue 配置段构建队列参数
+func OptionsFromConfig() Options {
+	queueConfig := config.Current().Queue
+	return Options{
+		Workers:      queueConfig.Workers,
+		PollInterval: queueConfig.PollInterval,
+		JobTimeout:   queueConfig.JobTimeout,
+		MaxAttempts:  queueConfig.MaxAttempts,
+		RetryBase:    queueConfig.RetryBase,
+		RetryMax:     queueConfig.RetryMax,
+	}
+}
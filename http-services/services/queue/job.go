@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNoJob 表示当前没有可领取的任务
+	ErrNoJob = errors.New("queue: no job available")
+	// ErrJobNotFound 表示指定任务不存在（通常是已被确认或已被删除）
+	ErrJobNotFound = errors.New("queue: job not found")
+	// ErrStopped 表示队列已停止，不再接受启动请求
+	ErrStopped = errors.New("queue: stopped")
+)
+
+// Job 是持久化在后端中的任务记录
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`     // 已开始执行的次数
+	MaxAttempts int             `json:"max_attempts"` // 最大执行次数，达到后进入死信队列
+	RunAt       time.Time       `json:"run_at"`       // 最早可执行时间
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+	TraceID     string          `json:"trace_id,omitempty"` // 入队请求的追踪 ID
+	LastError   string          `json:"last_error,omitempty"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`
+}
+
+// EnqueueOption 调整单个任务的入队参数
+type EnqueueOption func(*Job)
+
+// WithDelay 延迟 d 后再执行任务
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(job *Job) {
+		if d > 0 {
+			job.RunAt = job.EnqueuedAt.Add(d)
+		}
+	}
+}
+
+// WithRunAt 指定任务最早执行时间
+func WithRunAt(at time.Time) EnqueueOption {
+	return func(job *Job) {
+		if !at.IsZero() {
+			job.RunAt = at
+		}
+	}
+}
+
+// WithMaxAttempts 覆盖队列默认的最大执行次数
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(job *Job) {
+		if n > 0 {
+			job.MaxAttempts = n
+		}
+	}
+}
+
+// permanentError 标记不应重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 包装 handler 返回的错误，使任务跳过剩余重试直接进入死信队列
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否被标记为不可重试
+func IsPermanent(err error) bool {
+	var target *permanentError
+	return errors.As(err, &target)
+}
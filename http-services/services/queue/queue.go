@@ -0,0 +1,287 @@
+// Package queue 提供持久化后台任务队列：类型化 handler、worker 池、指数退避重试、延迟任务与死信队列。
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"http-services/utils/backoff"
+	"http-services/utils/id"
+	"http-services/utils/log"
+	"http-services/utils/taskgroup"
+
+	"go.uber.org/zap"
+)
+
+// leaseMargin 在任务超时之外额外保留的 lease 时间，避免 handler 刚超时就被其他 worker 重复领取
+const leaseMargin = 30 * time.Second
+
+// Options 队列运行参数
+type Options struct {
+	Workers      int           // worker 数量
+	PollInterval time.Duration // 队列为空时的轮询间隔
+	JobTimeout   time.Duration // 单个任务执行超时
+	MaxAttempts  int           // 默认最大执行次数
+	RetryBase    time.Duration // 首次重试等待时间
+	RetryMax     time.Duration // 重试等待时间上限，<= 0 时使用 30 分钟
+}
+
+type handlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Queue 管理任务入队与 worker 池
+type Queue struct {
+	backend Backend
+	opts    Options
+
+	mu       sync.RWMutex
+	handlers map[string]handlerFunc
+
+	lifecycleMu sync.Mutex
+	started     bool
+	stopped     bool
+	stopFetch   context.CancelFunc // 停止领取新任务
+	abortJobs   context.CancelFunc // 排空超时后中断执行中的任务
+	done        chan struct{}
+}
+
+// New 创建队列；Options 中未设置的字段使用默认值
+func New(backend Backend, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = 5 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.RetryMax <= 0 {
+		opts.RetryMax = 30 * time.Minute
+	}
+	return &Queue{
+		backend:  backend,
+		opts:     opts,
+		handlers: make(map[string]handlerFunc),
+	}
+}
+
+// Backend 返回队列使用的存储后端（用于查询统计与死信）
+func (q *Queue) Backend() Backend {
+	return q.backend
+}
+
+// Handle 为 jobType 注册类型化 handler；payload 按 JSON 解码为 T
+// 重复注册同一类型会覆盖旧 handler。
+func Handle[T any](q *Queue, jobType string, handler func(ctx context.Context, payload T) error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return Permanent(fmt.Errorf("decode %s payload: %w", jobType, err))
+		}
+		return handler(ctx, payload)
+	}
+}
+
+// Enqueue 将 payload 编码后入队，返回任务 ID
+// ctx 中的 trace_id 会随任务持久化，worker 执行时恢复到 handler 的 context 中。
+func Enqueue[T any](ctx context.Context, q *Queue, jobType string, payload T, opts ...EnqueueOption) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode %s payload: %w", jobType, err)
+	}
+	jobID, err := id.GenerateUUIDv7()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:          jobID.String(),
+		Type:        jobType,
+		Payload:     body,
+		MaxAttempts: q.opts.MaxAttempts,
+		RunAt:       now,
+		EnqueuedAt:  now,
+	}
+	if traceID, ok := log.TraceID(ctx); ok {
+		job.TraceID = traceID
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.backend.Enqueue(ctx, job); err != nil {
+		return "", fmt.Errorf("enqueue %s: %w", jobType, err)
+	}
+	log.FromStandardContext(ctx).Debug("job enqueued",
+		zap.String("job_id", job.ID),
+		zap.String("job_type", jobType),
+		zap.Time("run_at", job.RunAt),
+	)
+	return job.ID, nil
+}
+
+// Start 启动 worker 池，立即返回；ctx 取消等同于调用 Stop 但不等待排空
+func (q *Queue) Start(ctx context.Context) error {
+	q.lifecycleMu.Lock()
+	defer q.lifecycleMu.Unlock()
+
+	if q.stopped {
+		return ErrStopped
+	}
+	if q.started {
+		return nil
+	}
+	q.started = true
+
+	fetchCtx, stopFetch := context.WithCancel(ctx)
+	jobCtx, abortJobs := context.WithCancel(context.WithoutCancel(ctx))
+	q.stopFetch = stopFetch
+	q.abortJobs = abortJobs
+	q.done = make(chan struct{})
+
+	tasks := make([]taskgroup.Task, 0, q.opts.Workers)
+	for index := range q.opts.Workers {
+		name := "queue-worker-" + strconv.Itoa(index)
+		tasks = append(tasks, taskgroup.ContinueOnError(name, func(ctx context.Context) error {
+			q.work(ctx, jobCtx)
+			return nil
+		}))
+	}
+	go func() {
+		defer close(q.done)
+		for _, err := range taskgroup.Run(fetchCtx, tasks...) {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				zap.L().Error("queue worker exited unexpectedly", zap.Error(err))
+			}
+		}
+	}()
+
+	zap.L().Info("queue workers started", zap.Int("workers", q.opts.Workers))
+	return nil
+}
+
+// Stop 停止领取新任务并等待执行中的任务完成
+// ctx 到期时中断仍在执行的任务并返回 ctx.Err()；被中断的任务会按失败处理并重试。
+func (q *Queue) Stop(ctx context.Context) error {
+	q.lifecycleMu.Lock()
+	q.stopped = true
+	if !q.started {
+		q.lifecycleMu.Unlock()
+		return nil
+	}
+	stopFetch, abortJobs, done := q.stopFetch, q.abortJobs, q.done
+	q.lifecycleMu.Unlock()
+
+	stopFetch()
+	select {
+	case <-done:
+		abortJobs()
+		zap.L().Info("queue workers drained")
+		return nil
+	case <-ctx.Done():
+		abortJobs()
+		<-done
+		zap.L().Warn("queue drain timed out, in-flight jobs aborted", zap.Error(ctx.Err()))
+		return ctx.Err()
+	}
+}
+
+// work 是单个 worker 的领取循环：fetchCtx 控制是否继续领取，jobCtx 控制执行中的任务
+func (q *Queue) work(fetchCtx, jobCtx context.Context) {
+	for fetchCtx.Err() == nil {
+		job, err := q.backend.Reserve(fetchCtx, time.Now(), q.opts.JobTimeout+leaseMargin)
+		if err != nil {
+			if !errors.Is(err, ErrNoJob) && fetchCtx.Err() == nil {
+				zap.L().Error("reserve job failed", zap.Error(err))
+			}
+			q.wait(fetchCtx)
+			continue
+		}
+		q.process(jobCtx, job)
+	}
+}
+
+func (q *Queue) wait(ctx context.Context) {
+	timer := time.NewTimer(q.opts.PollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// process 执行单个任务并根据结果确认、重试或移入死信队列
+func (q *Queue) process(parent context.Context, job *Job) {
+	ctx := parent
+	if job.TraceID != "" {
+		ctx = log.WithTraceID(ctx, job.TraceID)
+	}
+	logger := log.FromStandardContext(ctx).With(
+		zap.String("job_id", job.ID),
+		zap.String("job_type", job.Type),
+	)
+	// Attempts 已由 Reserve 累加；超过上限说明之前的执行没有写回结果（进程崩溃或 lease 过期），不再执行
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		err = Permanent(fmt.Errorf("lease expired without result after %d attempts", job.MaxAttempts))
+	} else {
+		err = q.run(ctx, job)
+	}
+	// 状态变更使用独立 context，保证任务被中断后仍能写回结果
+	storeCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		if ackErr := q.backend.Ack(storeCtx, job); ackErr != nil {
+			logger.Error("ack job failed", zap.Error(ackErr))
+		}
+		logger.Debug("job completed", zap.Int("attempts", job.Attempts))
+		return
+	}
+
+	now := time.Now()
+	job.LastError = err.Error()
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		job.FailedAt = &now
+		if buryErr := q.backend.Bury(storeCtx, job); buryErr != nil {
+			logger.Error("move job to dead letter queue failed", zap.Error(buryErr))
+		}
+		logger.Error("job moved to dead letter queue", zap.Int("attempts", job.Attempts), zap.Error(err))
+		return
+	}
+
+	job.RunAt = now.Add(backoff.Exponential(job.Attempts, q.opts.RetryBase, q.opts.RetryMax))
+	if retryErr := q.backend.Retry(storeCtx, job); retryErr != nil {
+		logger.Error("schedule job retry failed", zap.Error(retryErr))
+	}
+	logger.Warn("job failed, retry scheduled",
+		zap.Int("attempts", job.Attempts),
+		zap.Time("retry_at", job.RunAt),
+		zap.Error(err),
+	)
+}
+
+// run 在超时 context 中执行 handler，并借助 taskgroup 捕获 panic
+func (q *Queue) run(ctx context.Context, job *Job) error {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, q.opts.JobTimeout)
+	defer cancel()
+	return taskgroup.Run(runCtx, taskgroup.ContinueOnError(job.Type, func(ctx context.Context) error {
+		return handler(ctx, job.Payload)
+	}))[0]
+}
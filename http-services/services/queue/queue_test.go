@@ -0,0 +1,286 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"http-services/utils/log"
+)
+
+type emailPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+func newTestQueue(backend Backend) *Queue {
+	return New(backend, Options{
+		Workers:      2,
+		PollInterval: 5 * time.Millisecond,
+		JobTimeout:   time.Second,
+		MaxAttempts:  3,
+		RetryBase:    time.Millisecond,
+		RetryMax:     5 * time.Millisecond,
+	})
+}
+
+func startQueue(t *testing.T, q *Queue) {
+	t.Helper()
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = q.Stop(ctx)
+	})
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueue_RunsTypedHandlerWithTraceID(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	received := make(chan emailPayload, 1)
+	traceIDs := make(chan string, 1)
+	Handle(q, "email.send", func(ctx context.Context, payload emailPayload) error {
+		traceID, _ := log.TraceID(ctx)
+		traceIDs <- traceID
+		received <- payload
+		return nil
+	})
+	startQueue(t, q)
+
+	ctx := log.WithTraceID(context.Background(), "trace-from-request")
+	if _, err := Enqueue(ctx, q, "email.send", emailPayload{To: "a@example.com", Subject: "hi"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.To != "a@example.com" || payload.Subject != "hi" {
+			t.Fatalf("payload = %#v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler was not called")
+	}
+	if traceID := <-traceIDs; traceID != "trace-from-request" {
+		t.Fatalf("trace ID = %q, want trace-from-request", traceID)
+	}
+	waitFor(t, func() bool {
+		stats, _ := backend.Stats(context.Background())
+		return stats == Stats{}
+	})
+}
+
+func TestQueue_RetriesThenMovesToDeadLetter(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	var calls atomic.Int32
+	Handle(q, "always.fail", func(context.Context, emailPayload) error {
+		calls.Add(1)
+		return errors.New("smtp down")
+	})
+	startQueue(t, q)
+
+	if _, err := Enqueue(context.Background(), q, "always.fail", emailPayload{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	waitFor(t, func() bool {
+		stats, _ := backend.Stats(context.Background())
+		return stats.Dead == 1
+	})
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("handler calls = %d, want 3", got)
+	}
+	dead, err := backend.DeadLetters(context.Background(), 10)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("DeadLetters() = %v, %v", dead, err)
+	}
+	if dead[0].Attempts != 3 || dead[0].LastError != "smtp down" || dead[0].FailedAt == nil {
+		t.Fatalf("dead job = %#v", dead[0])
+	}
+}
+
+func TestQueue_PermanentErrorSkipsRetries(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	var calls atomic.Int32
+	Handle(q, "invalid", func(context.Context, emailPayload) error {
+		calls.Add(1)
+		return Permanent(errors.New("bad address"))
+	})
+	startQueue(t, q)
+
+	if _, err := Enqueue(context.Background(), q, "invalid", emailPayload{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	waitFor(t, func() bool {
+		stats, _ := backend.Stats(context.Background())
+		return stats.Dead == 1
+	})
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler calls = %d, want 1", got)
+	}
+}
+
+func TestQueue_BuriesJobWhoseLeasesExpiredTooOften(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	var runs atomic.Int32
+	Handle(q, "crashy", func(context.Context, emailPayload) error {
+		runs.Add(1)
+		return nil
+	})
+	if _, err := Enqueue(context.Background(), q, "crashy", emailPayload{To: "a"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	// 模拟 worker 每次领取后都崩溃，没有写回结果，lease 到期后被重新投递
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		job, err := backend.Reserve(context.Background(), now, 0)
+		if err != nil || job.Attempts != i {
+			t.Fatalf("Reserve() #%d = %#v, %v", i, job, err)
+		}
+	}
+
+	startQueue(t, q)
+	waitFor(t, func() bool {
+		stats, _ := backend.Stats(context.Background())
+		return stats.Dead == 1
+	})
+	if runs.Load() != 0 {
+		t.Fatalf("handler ran %d times after max attempts were used up", runs.Load())
+	}
+	dead, _ := backend.DeadLetters(context.Background(), 1)
+	if len(dead) != 1 || dead[0].Attempts != 4 || dead[0].LastError == "" {
+		t.Fatalf("dead letters = %#v", dead)
+	}
+}
+
+func TestQueue_RecoversHandlerPanic(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	Handle(q, "panics", func(context.Context, emailPayload) error {
+		panic("boom")
+	})
+	startQueue(t, q)
+
+	if _, err := Enqueue(context.Background(), q, "panics", emailPayload{}, WithMaxAttempts(1)); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	waitFor(t, func() bool {
+		stats, _ := backend.Stats(context.Background())
+		return stats.Dead == 1
+	})
+}
+
+func TestQueue_DelayedJobWaitsUntilRunAt(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	ran := make(chan time.Time, 1)
+	Handle(q, "later", func(context.Context, emailPayload) error {
+		ran <- time.Now()
+		return nil
+	})
+	startQueue(t, q)
+
+	enqueuedAt := time.Now()
+	if _, err := Enqueue(context.Background(), q, "later", emailPayload{}, WithDelay(100*time.Millisecond)); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	select {
+	case at := <-ran:
+		if at.Sub(enqueuedAt) < 100*time.Millisecond {
+			t.Fatalf("delayed job ran after %v, want >= 100ms", at.Sub(enqueuedAt))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("delayed job did not run")
+	}
+}
+
+func TestQueue_StopDrainsInFlightJobs(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	Handle(q, "slow", func(ctx context.Context, _ emailPayload) error {
+		close(started)
+		<-release
+		finished.Store(true)
+		return ctx.Err()
+	})
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := Enqueue(context.Background(), q, "slow", emailPayload{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- q.Stop(context.Background()) }()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before in-flight job finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("in-flight job did not finish")
+	}
+	stats, _ := backend.Stats(context.Background())
+	if stats != (Stats{}) {
+		t.Fatalf("stats after drain = %#v, want empty", stats)
+	}
+	if err := q.Start(context.Background()); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Start() after Stop error = %v, want %v", err, ErrStopped)
+	}
+}
+
+func TestQueue_StopTimeoutAbortsInFlightJobs(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := newTestQueue(backend)
+	started := make(chan struct{})
+	Handle(q, "stuck", func(ctx context.Context, _ emailPayload) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := Enqueue(context.Background(), q, "stuck", emailPayload{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() error = %v, want deadline exceeded", err)
+	}
+	stats, _ := backend.Stats(context.Background())
+	if stats.Delayed != 1 {
+		t.Fatalf("aborted job stats = %#v, want scheduled for retry", stats)
+	}
+}
@@ -0,0 +1,268 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// promoteBatch 单次 Reserve 最多迁移的到期任务数量，避免单个脚本执行过久
+const promoteBatch = 100
+
+// 所有会修改多个 key 的操作都通过 Lua 脚本原子执行；
+// 脚本声明的 KEYS 会由 db/rdb 的公共前缀 hook 自动加上 redis.key_prefix。
+var (
+	enqueueScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+if tonumber(ARGV[3]) > tonumber(ARGV[4]) then
+  redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+else
+  redis.call('RPUSH', KEYS[2], ARGV[1])
+end
+return 1`)
+
+	// 领取时在 attempts 中原子地累加执行次数：lease 到期被重新投递的任务也计数，
+	// 执行中崩溃的任务不会无限重试
+	reserveScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+for _, key in ipairs({KEYS[3], KEYS[4]}) do
+  local due = redis.call('ZRANGEBYSCORE', key, '-inf', now, 'LIMIT', 0, tonumber(ARGV[3]))
+  for _, id in ipairs(due) do
+    redis.call('ZREM', key, id)
+    redis.call('RPUSH', KEYS[2], id)
+  end
+end
+while true do
+  local id = redis.call('LPOP', KEYS[2])
+  if not id then
+    return false
+  end
+  local body = redis.call('HGET', KEYS[1], id)
+  if body then
+    redis.call('ZADD', KEYS[4], ARGV[2], id)
+    return {body, redis.call('HINCRBY', KEYS[5], id, 1)}
+  end
+end`)
+
+	ackScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1`)
+
+	retryScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1`)
+
+	buryScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('RPUSH', KEYS[3], ARGV[1])
+return 1`)
+
+	reviveScript = redis.NewScript(`
+if redis.call('LREM', KEYS[2], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('RPUSH', KEYS[3], ARGV[1])
+return 1`)
+
+	deadLettersScript = redis.NewScript(`
+local ids = redis.call('LRANGE', KEYS[2], 0, tonumber(ARGV[1]) - 1)
+if #ids == 0 then
+  return {}
+end
+return redis.call('HMGET', KEYS[1], unpack(ids))`)
+
+	statsScript = redis.NewScript(`
+return {
+  redis.call('LLEN', KEYS[1]),
+  redis.call('ZCARD', KEYS[2]),
+  redis.call('ZCARD', KEYS[3]),
+  redis.call('LLEN', KEYS[4])
+}`)
+)
+
+// RedisBackend 基于 Redis 的可靠队列实现
+// key 布局（均位于 queue:<name>: 下）：
+//   - jobs       HASH  任务 ID -> 任务 JSON
+//   - attempts   HASH  任务 ID -> 已领取次数，Reserve 时原子累加，是 Job.Attempts 的来源
+//   - ready      LIST  可立即执行的任务 ID
+//   - delayed    ZSET  延迟/重试任务，score 为可执行时间（毫秒）
+//   - processing ZSET  已领取任务，score 为 lease 到期时间（毫秒）
+//   - dead       LIST  死信任务 ID
+type RedisBackend struct {
+	client     redis.Cmdable
+	jobs       string
+	attempts   string
+	ready      string
+	delayed    string
+	processing string
+	dead       string
+}
+
+// NewRedisBackend 创建 Redis 任务后端，name 用于隔离不同队列
+func NewRedisBackend(client redis.Cmdable, name string) *RedisBackend {
+	base := "queue:" + name + ":"
+	return &RedisBackend{
+		client:     client,
+		jobs:       base + "jobs",
+		attempts:   base + "attempts",
+		ready:      base + "ready",
+		delayed:    base + "delayed",
+		processing: base + "processing",
+		dead:       base + "dead",
+	}
+}
+
+func (b *RedisBackend) Enqueue(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	return enqueueScript.Run(ctx, b.client,
+		[]string{b.jobs, b.ready, b.delayed},
+		job.ID, body, job.RunAt.UnixMilli(), time.Now().UnixMilli(),
+	).Err()
+}
+
+func (b *RedisBackend) Reserve(ctx context.Context, now time.Time, lease time.Duration) (*Job, error) {
+	values, err := reserveScript.Run(ctx, b.client,
+		[]string{b.jobs, b.ready, b.delayed, b.processing, b.attempts},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), promoteBatch,
+	).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNoJob
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 2 {
+		return nil, fmt.Errorf("unexpected reserve result: %v", values)
+	}
+	body, ok := values[0].(string)
+	attempts, ok2 := values[1].(int64)
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("unexpected reserve result: %v", values)
+	}
+	job, err := decodeJob(body)
+	if err != nil {
+		return nil, err
+	}
+	job.Attempts = int(attempts)
+	return job, nil
+}
+
+func (b *RedisBackend) Ack(ctx context.Context, job *Job) error {
+	return checkAffected(ackScript.Run(ctx, b.client, []string{b.jobs, b.processing, b.attempts}, job.ID).Int())
+}
+
+func (b *RedisBackend) Retry(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	return checkAffected(retryScript.Run(ctx, b.client,
+		[]string{b.jobs, b.processing, b.delayed},
+		job.ID, body, job.RunAt.UnixMilli(),
+	).Int())
+}
+
+func (b *RedisBackend) Bury(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	return checkAffected(buryScript.Run(ctx, b.client,
+		[]string{b.jobs, b.processing, b.dead},
+		job.ID, body,
+	).Int())
+}
+
+func (b *RedisBackend) DeadLetters(ctx context.Context, limit int) ([]*Job, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	values, err := deadLettersScript.Run(ctx, b.client, []string{b.jobs, b.dead}, limit).Slice()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Job, 0, len(values))
+	for _, value := range values {
+		body, ok := value.(string)
+		if !ok {
+			continue
+		}
+		job, err := decodeJob(body)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, job)
+	}
+	return result, nil
+}
+
+func (b *RedisBackend) Revive(ctx context.Context, id string, now time.Time) error {
+	body, err := b.client.HGet(ctx, b.jobs, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return err
+	}
+	job, err := decodeJob(body)
+	if err != nil {
+		return err
+	}
+	job.Attempts = 0
+	job.RunAt = now
+	job.FailedAt = nil
+	updated, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	return checkAffected(reviveScript.Run(ctx, b.client, []string{b.jobs, b.dead, b.ready, b.attempts}, id, updated).Int())
+}
+
+func (b *RedisBackend) Stats(ctx context.Context) (Stats, error) {
+	values, err := statsScript.Run(ctx, b.client, []string{b.ready, b.delayed, b.processing, b.dead}).Int64Slice()
+	if err != nil {
+		return Stats{}, err
+	}
+	if len(values) != 4 {
+		return Stats{}, fmt.Errorf("unexpected stats result: %v", values)
+	}
+	return Stats{Ready: values[0], Delayed: values[1], Processing: values[2], Dead: values[3]}, nil
+}
+
+func decodeJob(body string) (*Job, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(body), &job); err != nil {
+		return nil, fmt.Errorf("decode job: %w", err)
+	}
+	return &job, nil
+}
+
+func checkAffected(affected int, err error) error {
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisBackend(t *testing.T) (*RedisBackend, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisBackend(client, "test"), server
+}
+
+func TestRedisBackend_ReserveAckLifecycle(t *testing.T) {
+	backend, server := newTestRedisBackend(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	job := &Job{ID: "job-1", Type: "email.send", Payload: []byte(`{"to":"a"}`), MaxAttempts: 3, RunAt: now, EnqueuedAt: now, TraceID: "trace-1"}
+	if err := backend.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if !server.Exists("queue:test:jobs") || !server.Exists("queue:test:ready") {
+		t.Fatalf("unexpected key layout: %v", server.Keys())
+	}
+
+	reserved, err := backend.Reserve(ctx, now, time.Minute)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if reserved.ID != job.ID || reserved.TraceID != "trace-1" || string(reserved.Payload) != `{"to":"a"}` {
+		t.Fatalf("reserved = %#v", reserved)
+	}
+	if _, err := backend.Reserve(ctx, now, time.Minute); !errors.Is(err, ErrNoJob) {
+		t.Fatalf("second Reserve() error = %v, want %v", err, ErrNoJob)
+	}
+
+	if err := backend.Ack(ctx, reserved); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := backend.Ack(ctx, reserved); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("duplicate Ack() error = %v, want %v", err, ErrJobNotFound)
+	}
+	stats, err := backend.Stats(ctx)
+	if err != nil || stats != (Stats{}) {
+		t.Fatalf("Stats() = %#v, %v", stats, err)
+	}
+}
+
+func TestRedisBackend_DelayedAndExpiredLease(t *testing.T) {
+	backend, _ := newTestRedisBackend(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	delayed := &Job{ID: "later", Type: "t", RunAt: now.Add(time.Hour), EnqueuedAt: now}
+	if err := backend.Enqueue(ctx, delayed); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := backend.Reserve(ctx, now, time.Minute); !errors.Is(err, ErrNoJob) {
+		t.Fatalf("Reserve() before run_at error = %v, want %v", err, ErrNoJob)
+	}
+
+	reserved, err := backend.Reserve(ctx, now.Add(2*time.Hour), time.Minute)
+	if err != nil || reserved.ID != "later" {
+		t.Fatalf("Reserve() after run_at = %v, %v", reserved, err)
+	}
+
+	// lease 到期未确认的任务会被重新投递
+	redelivered, err := backend.Reserve(ctx, now.Add(3*time.Hour), time.Minute)
+	if err != nil || redelivered.ID != "later" || redelivered.Attempts != 2 {
+		t.Fatalf("Reserve() after lease expiry = %v, %v", redelivered, err)
+	}
+}
+
+func TestRedisBackend_RetryBuryAndRevive(t *testing.T) {
+	backend, _ := newTestRedisBackend(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := backend.Enqueue(ctx, &Job{ID: "job", Type: "t", RunAt: now, EnqueuedAt: now}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	job, err := backend.Reserve(ctx, now, time.Minute)
+	if err != nil || job.Attempts != 1 {
+		t.Fatalf("Reserve() = %#v, %v", job, err)
+	}
+	job.LastError = "temporary"
+	job.RunAt = now.Add(time.Second)
+	if err := backend.Retry(ctx, job); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if stats, _ := backend.Stats(ctx); stats.Delayed != 1 || stats.Processing != 0 {
+		t.Fatalf("stats after retry = %#v", stats)
+	}
+
+	job, err = backend.Reserve(ctx, now.Add(2*time.Second), time.Minute)
+	if err != nil || job.Attempts != 2 || job.LastError != "temporary" {
+		t.Fatalf("Reserve() retried job = %#v, %v", job, err)
+	}
+	failedAt := now
+	job.FailedAt = &failedAt
+	if err := backend.Bury(ctx, job); err != nil {
+		t.Fatalf("Bury() error = %v", err)
+	}
+
+	dead, err := backend.DeadLetters(ctx, 10)
+	if err != nil || len(dead) != 1 || dead[0].ID != "job" || dead[0].Attempts != 2 {
+		t.Fatalf("DeadLetters() = %#v, %v", dead, err)
+	}
+
+	if err := backend.Revive(ctx, "job", now); err != nil {
+		t.Fatalf("Revive() error = %v", err)
+	}
+	if err := backend.Revive(ctx, "job", now); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("second Revive() error = %v, want %v", err, ErrJobNotFound)
+	}
+	revived, err := backend.Reserve(ctx, now, time.Minute)
+	if err != nil || revived.Attempts != 1 || revived.FailedAt != nil {
+		t.Fatalf("revived job = %#v, %v", revived, err)
+	}
+}
+
+func TestRedisBackend_QueueEndToEnd(t *testing.T) {
+	backend, _ := newTestRedisBackend(t)
+	q := newTestQueue(backend)
+	done := make(chan string, 1)
+	Handle(q, "echo", func(_ context.Context, payload emailPayload) error {
+		done <- payload.To
+		return nil
+	})
+	startQueue(t, q)
+
+	if _, err := Enqueue(context.Background(), q, "echo", emailPayload{To: "redis"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	select {
+	case to := <-done:
+		if to != "redis" {
+			t.Fatalf("payload.To = %q", to)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not processed")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"http-services/application"
+	domainhealth "http-services/domain/health"
+	"http-services/utils/httpserver"
+	"http-services/utils/systemd"
+
+	"go.uber.org/zap"
+)
+
+// systemdListenAddr 非空时 HTTP 监听 socket 由 systemd socket activation 传入，值为启动时配置的地址。
+// socket 由 .socket 单元持有，热重载不再按 server.host / server.port 重新绑定
+var systemdListenAddr string
+
+// activatedListener 取 systemd 传入的 HTTP 监听 socket：优先使用 FileDescriptorName=http 的 fd，
+// 只传入一个 fd 时直接使用；不是 socket activation 启动时返回 nil
+func activatedListener() (net.Listener, error) {
+	listeners, err := systemd.Listeners()
+	if err != nil || len(listeners) == 0 {
+		return nil, err
+	}
+	chosen := -1
+	for i, ln := range listeners {
+		if ln.Name == "http" {
+			chosen = i
+			break
+		}
+	}
+	if chosen < 0 && len(listeners) == 1 {
+		chosen = 0
+	}
+	for i, ln := range listeners {
+		if i != chosen {
+			zap.L().Warn("忽略 systemd 传入的未知监听 socket", zap.String("name", ln.Name), zap.String("addr", ln.Addr().String()))
+			_ = ln.Close()
+		}
+	}
+	if chosen < 0 {
+		return nil, fmt.Errorf("systemd passed %d sockets but none is named http", len(listeners))
+	}
+	return listeners[chosen].Listener, nil
+}
+
+// notifySystemd 向 systemd 报告状态；不在 systemd 下运行时什么也不做
+func notifySystemd(states ...string) {
+	if _, err := systemd.Notify(states...); err != nil {
+		zap.L().Warn("通知 systemd 失败", zap.Strings("states", states), zap.Error(err))
+	}
+}
+
+// watchdogProbeTimeout 是一轮 watchdog 检查的总超时，单个检查项另有 2 秒超时
+const watchdogProbeTimeout = 5 * time.Second
+
+// startWatchdog 在 systemd 启用 WatchdogSec= 时按 check 的结果定期喂狗，检查失败时停止喂狗，
+// 持续失败超过 WatchdogSec 后由 systemd 按 Restart= 重启服务；返回的函数用于停止喂狗
+func startWatchdog(check func() error) func() {
+	interval, err := systemd.WatchdogInterval()
+	if err != nil {
+		zap.L().Warn("读取 systemd watchdog 配置失败，不启用 watchdog", zap.Error(err))
+	}
+	if interval <= 0 {
+		return func() {}
+	}
+	zap.L().Info("systemd watchdog 已启用", zap.Duration("interval", interval))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		systemd.Watchdog(ctx, interval, check)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// watchdogChecks 组成 watchdog 的检查项：主监听仍在响应、已配置的 MySQL 与使用中的 Redis 可以连通，
+// 以及各模块的检查项。Redis 在启用任务队列或 Redis 租约时检查，outbox 等模块的依赖由模块自己的检查项覆盖
+func watchdogChecks(a *application.App, server *httpserver.Server) []domainhealth.Check {
+	cfg := a.Config()
+	checks := []domainhealth.Check{serverCheck(server, cfg.Server.TLS.Enabled)}
+	if cfg.Database.MysqlDSN != "" {
+		checks = append(checks, domainhealth.Check{Name: "mysql", Check: a.PingDB})
+	}
+	if cfg.Queue.Enabled || cfg.ID.RedisLease {
+		checks = append(checks, domainhealth.Check{Name: "redis", Check: a.PingRedis})
+	}
+	return append(checks, a.HealthChecks()...)
+}
+
+// watchdogCheck 返回 watchdog 使用的健康检查，每次执行 checks() 返回的全部检查项，任一失败即暂停喂狗。
+// 优雅关闭期间（就绪状态为 false）不再检查并继续喂狗，避免排空连接、关闭存储时被 systemd 当作卡死重启
+func watchdogCheck(checks func() []domainhealth.Check) func() error {
+	return func() error {
+		status, err := domainhealth.GetStatus()
+		if err != nil {
+			zap.L().Warn("健康检查失败，暂停 systemd watchdog", zap.Error(err))
+			return err
+		}
+		if !status.Ready {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), watchdogProbeTimeout)
+		defer cancel()
+		status, err = domainhealth.CheckStatus(ctx, checks())
+		if err != nil {
+			zap.L().Warn("健康检查失败，暂停 systemd watchdog", zap.Error(err))
+			return err
+		}
+		for _, result := range status.Checks {
+			if !result.Healthy {
+				zap.L().Warn("健康检查失败，暂停 systemd watchdog", zap.String("check", result.Name), zap.String("error", result.Error))
+				return fmt.Errorf("%s: %s", result.Name, result.Error)
+			}
+		}
+		return nil
+	}
+}
+
+// serverCheck 确认主监听仍在响应：未启用 TLS 时经监听地址发送一个 HEAD 请求，收到任何响应即通过；
+// 启用 TLS 时握手可能因要求客户端证书而失败，只确认监听仍能建立连接
+func serverCheck(server *httpserver.Server, tlsEnabled bool) domainhealth.Check {
+	return domainhealth.Check{Name: "http", Check: func(ctx context.Context) error {
+		addr := server.Addr()
+		if addr == nil {
+			return errors.New("http server is not listening")
+		}
+		dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, addr.Network(), addr.String())
+		}
+		if tlsEnabled {
+			conn, err := dial(ctx, "", "")
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}
+		client := &http.Client{Transport: &http.Transport{DialContext: dial, DisableKeepAlives: true}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, "http://watchdog/", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}}
+}
@@ -0,0 +1,116 @@
+//go:build !windows
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSystemdSocketActivationAndNotify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过集成测试（-short）")
+	}
+
+	tmpDir := t.TempDir()
+	binPath := filepath.Join(tmpDir, "http-services-testbin")
+	if out, err := exec.Command("go", "build", "-o", binPath, ".").CombinedOutput(); err != nil {
+		t.Fatalf("构建测试二进制失败: %v\n%s", err, out)
+	}
+
+	// 模拟 systemd：监听 socket 作为 fd 3 传入，READY / STOPPING 通过 unix datagram socket 上报
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("当前环境无法分配本地监听端口: %v", err)
+	}
+	defer ln.Close()
+	socketFile, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer socketFile.Close()
+	notifyPath := filepath.Join(tmpDir, "notify.sock")
+	notify, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifyPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notify.Close()
+
+	logFile, err := os.Create(filepath.Join(tmpDir, "output.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+	output := func() string {
+		content, _ := os.ReadFile(logFile.Name())
+		return string(content)
+	}
+
+	// LISTEN_PID 必须是服务进程自身的 pid，由 shell exec 前设置为 $$
+	cmd := exec.Command("sh", "-c", `LISTEN_PID=$$ LISTEN_FDS=1 LISTEN_FDNAMES=http exec "$0" --dev`, binPath)
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(),
+		"NOTIFY_SOCKET="+notifyPath,
+		// 配置的端口与 systemd 传入的不同，服务应当只使用传入的 socket
+		"HTTP_SERVICES_SERVER_HOST=127.0.0.1",
+		"HTTP_SERVICES_SERVER_PORT="+reserveLocalPort(t),
+		"HTTP_SERVICES_SERVER_SHUTDOWN_TIMEOUT=1s",
+	)
+	cmd.ExtraFiles = []*os.File{socketFile}
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("启动服务失败: %v", err)
+	}
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+	t.Cleanup(func() {
+		if cmd.ProcessState == nil {
+			_ = cmd.Process.Kill()
+			<-waitCh
+		}
+	})
+
+	readNotify := func(want string) {
+		t.Helper()
+		buf := make([]byte, 4096)
+		_ = notify.SetReadDeadline(time.Now().Add(10 * time.Second))
+		n, err := notify.Read(buf)
+		if err != nil {
+			t.Fatalf("未收到 %s 通知: %v\n输出：\n%s", want, err, output())
+		}
+		if got := string(buf[:n]); !strings.Contains(got, want) {
+			t.Fatalf("通知 = %q，want 包含 %s", got, want)
+		}
+	}
+	readNotify("READY=1")
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 2 * time.Second}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/api/v1/open/health")
+	if err != nil {
+		t.Fatalf("通过 systemd 传入的 socket 访问失败: %v\n输出：\n%s", err, output())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("health status = %d, want 200", resp.StatusCode)
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("发送 SIGTERM 失败: %v", err)
+	}
+	readNotify("STOPPING=1")
+	select {
+	case err := <-waitCh:
+		if err != nil {
+			t.Fatalf("服务退出失败: %v\n输出：\n%s", err, output())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("服务未在预期时间内退出\n输出：\n%s", output())
+	}
+}
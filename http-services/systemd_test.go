@@ -0,0 +1,85 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domainhealth "http-services/domain/health"
+	"http-services/utils/httpserver"
+	"http-services/utils/systemd"
+)
+
+func TestWatchdogStopsPingingWhenHealthCheckFails(t *testing.T) {
+	server := httpserver.New(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), httpserver.Config{Addr: "127.0.0.1:0"})
+	if err := server.Start(); err != nil {
+		t.Skipf("当前环境无法分配本地监听端口: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	var mysqlDown atomic.Bool
+	check := watchdogCheck(func() []domainhealth.Check {
+		return []domainhealth.Check{
+			serverCheck(server, false),
+			{Name: "mysql", Check: func(context.Context) error {
+				if mysqlDown.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}},
+		}
+	})
+	if err := check(); err != nil {
+		t.Fatalf("check() with healthy dependencies error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	notify, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { notify.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	mysqlDown.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		systemd.Watchdog(ctx, 20*time.Millisecond, check)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	buf := make([]byte, 256)
+	_ = notify.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := notify.Read(buf)
+	if err != nil || !strings.HasPrefix(string(buf[:n]), "STATUS=health check failed: mysql") {
+		t.Fatalf("first notification = %q, %v, want failure status", buf[:n], err)
+	}
+	_ = notify.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, err := notify.Read(buf); err == nil {
+		t.Fatalf("received %q while a check fails, want no ping", buf[:n])
+	}
+
+	// 依赖恢复后，主监听停止响应同样会暂停喂狗
+	mysqlDown.Store(false)
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := check(); err == nil || !strings.HasPrefix(err.Error(), "http:") {
+		t.Fatalf("check() after server shutdown error = %v, want http failure", err)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+
+	"http-services/config"
+	"http-services/utils/tlscert"
+
+	"go.uber.org/zap"
+)
+
+// serverTLS 非 nil 时主监听启用 TLS。它只在启动时按 server.tls 构建一次，
+// 证书、私钥与客户端 CA 文件的变化由 tlscert 监听并在下一次握手时生效
+var serverTLS *tls.Config
+
+// clientAuthTypes 是 server.tls.client_auth 与 tls.ClientAuthType 的对应关系
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":            tls.NoClientCert,
+	"request":         tls.RequestClientCert,
+	"verify_if_given": tls.VerifyClientCertIfGiven,
+	"require":         tls.RequireAndVerifyClientCert,
+}
+
+// setupServerTLS 加载证书并设置 serverTLS，返回的函数停止监听证书文件；未启用 TLS 时什么也不做
+func setupServerTLS(cfg config.TLSConfig) (stop func(), err error) {
+	if !cfg.Enabled {
+		return func() {}, nil
+	}
+	reloader, err := tlscert.New(tlscert.Files{CertFile: cfg.CertFile, KeyFile: cfg.KeyFile, ClientCAFile: cfg.ClientCAFile})
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuthTypes[cfg.ClientAuth],
+		// 每次握手的配置由 GetConfigForClient 生成，ALPN 需要在这里声明，否则 TLS 上无法协商 HTTP/2
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if cfg.MinVersion == "1.3" {
+		base.MinVersion = tls.VersionTLS13
+	}
+	for _, name := range cfg.CipherSuites {
+		id, _ := config.CipherSuiteID(name)
+		base.CipherSuites = append(base.CipherSuites, id)
+	}
+	serverTLS = reloader.Apply(base)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := reloader.Watch(ctx); err != nil {
+			zap.L().Warn("监听证书文件失败，证书更新后需要重启", zap.Error(err))
+		}
+	}()
+	fields := []zap.Field{zap.String("cert_file", cfg.CertFile), zap.String("min_version", cfg.MinVersion), zap.String("client_auth", cfg.ClientAuth)}
+	if leaf := reloader.Certificate().Leaf; leaf != nil {
+		fields = append(fields, zap.String("subject", leaf.Subject.String()), zap.Time("not_after", leaf.NotAfter))
+	}
+	zap.L().Info("主监听已启用 TLS", fields...)
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
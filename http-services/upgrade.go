@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+
+	"http-services/config"
+	"http-services/utils/httpserver"
+	"http-services/utils/lifecycle"
+	"http-services/utils/pidfile"
+
+	"go.uber.org/zap"
+)
+
+// startServer 选择监听 socket：热升级启动且地址（host 与端口）未变时使用继承的 socket，systemd socket activation
+// 启动时使用 systemd 传入的 socket，否则绑定配置的地址
+func startServer(server *httpserver.Server, upgrade *upgradeChild, serverConfig config.ServerConfig) error {
+	if upgrade == nil {
+		ln, err := activatedListener()
+		if err != nil {
+			return err
+		}
+		if ln == nil {
+			return server.Start()
+		}
+		systemdListenAddr = httpServerConfig(serverConfig).Addr
+		return server.StartListener(ln)
+	}
+	addr := httpServerConfig(serverConfig).Addr
+	ln := upgrade.take("http", func(ln net.Listener) bool { return sameTCPAddr(ln, addr) })
+	if ln != nil {
+		return server.StartListener(ln)
+	}
+	return server.Start()
+}
+
+// upgradeChild 描述以热升级方式启动的子进程从父进程继承的 fd：按名称区分的监听 socket 与就绪通知管道
+type upgradeChild struct {
+	parentPID int
+	listeners map[string]net.Listener
+	ready     *os.File
+}
+
+// take 取出名为 name 的继承 socket；新版本配置改了地址（matches 返回 false）时关闭它并返回 nil，
+// 由调用方绑定新地址
+func (c *upgradeChild) take(name string, matches func(net.Listener) bool) net.Listener {
+	ln, ok := c.listeners[name]
+	if !ok {
+		return nil
+	}
+	delete(c.listeners, name)
+	if matches(ln) {
+		return ln
+	}
+	zap.L().Warn("新配置的地址与继承的监听 socket 不同，改为绑定新地址",
+		zap.String("listener", name), zap.String("inherited", ln.Addr().String()))
+	_ = ln.Close()
+	return nil
+}
+
+// closeUnused 关闭新版本不再使用的继承 socket，例如配置中去掉了 unix socket 或管理端口
+func (c *upgradeChild) closeUnused() {
+	for name, ln := range c.listeners {
+		zap.L().Info("关闭未使用的继承监听 socket", zap.String("listener", name), zap.String("addr", ln.Addr().String()))
+		_ = ln.Close()
+		delete(c.listeners, name)
+	}
+}
+
+// takeOver 把 pid 文件从父进程原子替换为本进程后通知父进程就绪；父进程没有写 pid 文件时直接创建
+func (c *upgradeChild) takeOver(pidFile string, pid int) error {
+	c.closeUnused()
+	if pidFile != "" {
+		err := pidfile.Replace(pidFile, c.parentPID, pid)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = pidfile.Write(pidFile, pid)
+		}
+		if err != nil {
+			return err
+		}
+		zap.L().Info("PID 文件已从旧进程接管", zap.String("pid_file", pidFile), zap.Int("pid", pid), zap.Int("parent_pid", c.parentPID))
+	}
+	return c.notifyReady()
+}
+
+// notifyReady 通知父进程本进程已就绪，父进程随后开始排空退出
+func (c *upgradeChild) notifyReady() error {
+	defer c.ready.Close()
+	_, err := c.ready.Write([]byte{1})
+	return err
+}
+
+// abandon 放弃热升级（启动失败），关闭就绪管道让父进程立即得知并继续服务
+func (c *upgradeChild) abandon() {
+	c.closeUnused()
+	_ = c.ready.Close()
+}
+
+// parentExitPollInterval 是热升级的新进程检查旧进程是否已退出的间隔
+const parentExitPollInterval = 100 * time.Millisecond
+
+// waitParentExit 等待旧进程退出：旧进程退出后本进程被重新托管，父进程 ID 随之改变
+func (c *upgradeChild) waitParentExit(ctx context.Context) error {
+	ticker := time.NewTicker(parentExitPollInterval)
+	defer ticker.Stop()
+	for os.Getppid() == c.parentPID {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// startWorkersAfterParent 在旧进程排空退出后注册并启动后台 worker（任务队列、outbox relay、定时任务）：
+// 旧进程排空期间仍在执行它们，同时启动会重复执行定时任务。本进程先开始退出时不再启动。
+// 返回的 channel 在启动失败时收到错误
+func startWorkersAfterParent(lc *lifecycle.Manager, upgrade *upgradeChild, workers []lifecycle.Hook) <-chan error {
+	failed := make(chan error, 1)
+	if len(workers) == 0 {
+		return failed
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(lifecycle.Hook{Name: "deferred-workers", Phase: lifecycle.PhaseReadiness, OnStop: func(context.Context) error {
+		cancel()
+		return nil
+	}})
+	go func() {
+		zap.L().Info("等待旧进程退出后启动后台任务", zap.Int("parent_pid", upgrade.parentPID))
+		if upgrade.waitParentExit(ctx) != nil {
+			return
+		}
+		for _, hook := range workers {
+			lc.Append(hook)
+		}
+		// 组件会保留 Start 的 ctx 直到停止，不能使用上面在 readiness 阶段取消的 ctx
+		if err := lc.Start(context.Background()); err != nil && ctx.Err() == nil {
+			failed <- err
+			return
+		}
+		zap.L().Info("旧进程已退出，后台任务已启动")
+	}()
+	return failed
+}
@@ -0,0 +1,175 @@
+//go:build !windows
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestUpgradeHandsOffListenerAndPidFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过集成测试（-short）")
+	}
+
+	tmpDir := t.TempDir()
+	binPath := filepath.Join(tmpDir, "http-services-testbin")
+	if out, err := exec.Command("go", "build", "-o", binPath, ".").CombinedOutput(); err != nil {
+		t.Fatalf("构建测试二进制失败: %v\n%s", err, out)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("当前环境无法分配本地监听端口: %v", err)
+	}
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	ln.Close()
+
+	// 新进程继承标准输出，用文件而不是管道收集输出，避免 Wait 等待新进程关闭管道
+	logFile, err := os.Create(filepath.Join(tmpDir, "output.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+	output := func() string {
+		content, _ := os.ReadFile(logFile.Name())
+		return string(content)
+	}
+
+	pidPath := filepath.Join(tmpDir, "http-services.pid")
+	socketPath := filepath.Join(tmpDir, "http.sock")
+	cmd := exec.Command(binPath, "--dev")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(),
+		"HTTP_SERVICES_SERVER_HOST=127.0.0.1",
+		"HTTP_SERVICES_SERVER_PORT="+port,
+		"HTTP_SERVICES_SERVER_SHUTDOWN_TIMEOUT=2s",
+		"HTTP_SERVICES_SERVER_UNIX_SOCKET_PATH="+socketPath,
+	)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("启动服务失败: %v", err)
+	}
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+	var newPID atomic.Int64
+	t.Cleanup(func() {
+		if pid := newPID.Load(); pid > 0 {
+			_ = syscall.Kill(int(pid), syscall.SIGKILL)
+		}
+		if cmd.ProcessState == nil {
+			_ = cmd.Process.Kill()
+			<-waitCh
+		}
+	})
+
+	url := "http://127.0.0.1:" + port + "/api/v1/open/health"
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 2 * time.Second}
+	waitFor(t, 10*time.Second, output, "服务未能开始监听", func() bool {
+		resp, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	})
+	oldPID := strconv.Itoa(cmd.Process.Pid)
+	if got := readPID(pidPath); got != oldPID {
+		t.Fatalf("pid 文件 = %q，want %s", got, oldPID)
+	}
+
+	// 升级过程中持续发起新连接，全部应该成功
+	var failures atomic.Int64
+	var firstErr atomic.Value
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			resp, err := client.Get(url)
+			if err != nil {
+				failures.Add(1)
+				firstErr.CompareAndSwap(nil, err.Error())
+				continue
+			}
+			resp.Body.Close()
+		}
+	}()
+
+	if err := cmd.Process.Signal(syscall.SIGUSR2); err != nil {
+		t.Fatalf("发送 SIGUSR2 失败: %v", err)
+	}
+	select {
+	case err := <-waitCh:
+		if err != nil {
+			t.Fatalf("旧进程退出失败: %v\n输出：\n%s", err, output())
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatalf("旧进程未在预期时间内退出\n输出：\n%s", output())
+	}
+
+	got := readPID(pidPath)
+	if got == "" || got == oldPID {
+		t.Fatalf("升级后 pid 文件 = %q，want 新进程 pid\n输出：\n%s", got, output())
+	}
+	pid, _ := strconv.Atoi(got)
+	newPID.Store(int64(pid))
+	if err := syscall.Kill(pid, 0); err != nil {
+		t.Fatalf("pid 文件中的新进程 %d 不存在: %v", pid, err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	if n := failures.Load(); n > 0 {
+		t.Fatalf("升级期间 %d 个请求失败，首个错误: %v\n输出：\n%s", n, firstErr.Load(), output())
+	}
+	// unix socket 同样交接给新进程，旧进程退出时不能删除 socket 文件
+	if status := getOverUnixSocket(t, socketPath, "/api/v1/open/health"); status != http.StatusOK {
+		t.Fatalf("升级后通过 unix socket 访问 health status = %d, want 200\n输出：\n%s", status, output())
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		t.Fatalf("停止新进程失败: %v", err)
+	}
+	waitFor(t, 10*time.Second, output, "新进程退出后 pid 文件与 unix socket 未删除", func() bool {
+		_, pidErr := os.Stat(pidPath)
+		_, socketErr := os.Stat(socketPath)
+		return os.IsNotExist(pidErr) && os.IsNotExist(socketErr)
+	})
+}
+
+func readPID(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func waitFor(t *testing.T, timeout time.Duration, output func() string, message string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s\n输出：\n%s", message, output())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,207 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"http-services/utils/httpserver"
+
+	"go.uber.org/zap"
+)
+
+// 热升级：旧进程收到 SIGUSR2 后以相同参数启动新的可执行文件，把就绪通知管道作为 fd 3、
+// 各监听 socket（主监听、unix socket、管理端口）依次作为 fd 4 起交给子进程，名称与 fd 的对应关系
+// 通过环境变量传递。子进程在继承的 socket 上开始服务并接管 pid 文件后写入就绪通知，
+// 旧进程收到通知才进入优雅退出；子进程启动失败或超时未就绪时旧进程继续服务。
+const (
+	upgradeListenersEnv = "HTTP_SERVICES_UPGRADE_LISTENERS"
+	upgradeReadyEnv     = "HTTP_SERVICES_UPGRADE_READY_FD"
+	upgradeReadyTimeout = time.Minute
+)
+
+// inheritUpgrade 读取父进程交接的 fd；不是热升级启动时返回 nil。
+// 读取后清除相关环境变量，避免本进程再次升级时把它们传给下一代
+func inheritUpgrade() (*upgradeChild, error) {
+	readyFD, ok := os.LookupEnv(upgradeReadyEnv)
+	if !ok {
+		return nil, nil
+	}
+	listenerFDs := os.Getenv(upgradeListenersEnv)
+	_ = os.Unsetenv(upgradeReadyEnv)
+	_ = os.Unsetenv(upgradeListenersEnv)
+
+	rfd, err := strconv.Atoi(readyFD)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", upgradeReadyEnv, readyFD)
+	}
+	child := &upgradeChild{
+		parentPID: os.Getppid(),
+		listeners: make(map[string]net.Listener),
+		ready:     os.NewFile(uintptr(rfd), "upgrade-ready"),
+	}
+	for _, entry := range strings.Split(listenerFDs, ",") {
+		if entry == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		fd, err := strconv.Atoi(value)
+		if err != nil || name == "" {
+			child.abandon()
+			return nil, fmt.Errorf("invalid %s entry %q", upgradeListenersEnv, entry)
+		}
+		file := os.NewFile(uintptr(fd), "upgrade-"+name)
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			child.abandon()
+			return nil, fmt.Errorf("inherit %s listener: %w", name, err)
+		}
+		child.listeners[name] = listener
+	}
+	return child, nil
+}
+
+// watchUpgradeSignal 监听 SIGUSR2 并执行热升级，servers 的 key 为交接给新进程的监听名称；
+// 新进程就绪后关闭返回的通道，调用方据此进入优雅退出。升级过程中再次收到的 SIGUSR2 会被忽略
+func watchUpgradeSignal(servers map[string]*httpserver.Server) (upgraded <-chan struct{}, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	done := make(chan struct{})
+	handedOff := make(chan struct{})
+	var upgrading atomic.Bool
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if !upgrading.CompareAndSwap(false, true) {
+					zap.L().Warn("热升级进行中，忽略重复的 SIGUSR2")
+					continue
+				}
+				zap.L().Info("收到 SIGUSR2，开始热升级")
+				pid, err := startUpgradedProcess(servers)
+				if err != nil {
+					upgrading.Store(false)
+					zap.L().Error("热升级失败，继续由当前进程服务", zap.Error(err))
+					continue
+				}
+				zap.L().Info("新进程已就绪，当前进程开始排空退出", zap.Int("new_pid", pid))
+				close(handedOff)
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+	return handedOff, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// startUpgradedProcess 启动新进程并等待其就绪，返回新进程 pid；失败时新进程已退出或被终止
+func startUpgradedProcess(servers map[string]*httpserver.Server) (int, error) {
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("create ready pipe: %w", err)
+	}
+	defer readyReader.Close()
+	// ExtraFiles[i] 在子进程中的 fd 为 3+i：就绪管道在前，监听 socket 按名称排序依次排在后面
+	extraFiles := []*os.File{readyWriter}
+	defer func() {
+		for _, file := range extraFiles[1:] {
+			_ = file.Close()
+		}
+	}()
+	names := slices.Sorted(maps.Keys(servers))
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		file, err := servers[name].ListenerFile()
+		if err != nil {
+			_ = readyWriter.Close()
+			return 0, fmt.Errorf("%s listener: %w", name, err)
+		}
+		entries = append(entries, name+"="+strconv.Itoa(3+len(extraFiles)))
+		extraFiles = append(extraFiles, file)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		_ = readyWriter.Close()
+		return 0, fmt.Errorf("locate executable: %w", err)
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = extraFiles
+	cmd.Env = append(upgradeEnviron(), upgradeReadyEnv+"=3", upgradeListenersEnv+"="+strings.Join(entries, ","))
+	if err := cmd.Start(); err != nil {
+		_ = readyWriter.Close()
+		return 0, fmt.Errorf("start %s: %w", executable, err)
+	}
+	// 父进程不再持有写端，子进程退出或放弃时读端立即得到 EOF
+	_ = readyWriter.Close()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := readyReader.Read(buf)
+		ready <- err
+	}()
+
+	timer := time.NewTimer(upgradeReadyTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-ready:
+		if err == nil {
+			return cmd.Process.Pid, nil
+		}
+		// 子进程放弃升级后会自行退出，这里等待它结束再返回，避免留下僵尸进程
+		select {
+		case waitErr := <-exited:
+			return 0, exitedBeforeReady(waitErr)
+		case <-timer.C:
+			_ = cmd.Process.Kill()
+			return 0, errors.New("new process closed the ready pipe but did not exit")
+		}
+	case err := <-exited:
+		return 0, exitedBeforeReady(err)
+	case <-timer.C:
+		_ = cmd.Process.Kill()
+		<-exited
+		return 0, fmt.Errorf("new process not ready within %s", upgradeReadyTimeout)
+	}
+}
+
+// upgradeEnviron 返回新进程的环境变量：去掉指向旧进程的 WATCHDOG_PID，
+// 使 systemd watchdog 在新进程上报 MAINPID 后由新进程继续喂狗
+func upgradeEnviron() []string {
+	environ := os.Environ()
+	kept := environ[:0]
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, "WATCHDOG_PID=") {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
+
+func exitedBeforeReady(waitErr error) error {
+	if waitErr == nil {
+		return errors.New("new process exited before ready")
+	}
+	return fmt.Errorf("new process exited before ready: %w", waitErr)
+}
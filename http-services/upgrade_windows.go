@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import "http-services/utils/httpserver"
+
+// inheritUpgrade Windows 不支持 SIGUSR2 热升级，总是按普通方式启动
+func inheritUpgrade() (*upgradeChild, error) {
+	return nil, nil
+}
+
+// watchUpgradeSignal Windows 不支持 SIGUSR2 热升级，返回的通道永远不会关闭
+func watchUpgradeSignal(_ map[string]*httpserver.Server) (upgraded <-chan struct{}, stop func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,202 @@
+// Package audit 记录登录、权限变更、数据导出等安全相关操作，与业务日志分开存放。
+//
+// 审计记录同步写入所有已配置的 Sink（hash 链文件、MySQL 表等），写入失败会返回错误而不是静默丢弃；
+// 未启用审计时默认 Recorder 没有任何 Sink，Record 直接返回。
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"http-services/utils/log"
+
+	"go.uber.org/zap"
+)
+
+// 操作结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied" // 未认证或无权限
+)
+
+// ErrMissingAction 在记录缺少 action 时返回
+var ErrMissingAction = errors.New("audit: action is empty")
+
+// Entry 是一条审计记录
+type Entry struct {
+	Time       time.Time      `json:"time"`
+	Actor      string         `json:"actor"`                 // 操作者，通常取自 JWT claims；管理接口为 "admin"
+	Action     string         `json:"action"`                // 操作，如 user.login、role.grant、order.export
+	Resource   string         `json:"resource,omitempty"`    // 资源类型
+	ResourceID string         `json:"resource_id,omitempty"` // 资源 ID
+	Outcome    string         `json:"outcome"`
+	Reason     string         `json:"reason,omitempty"` // 失败原因
+	ClientIP   string         `json:"client_ip,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	Before     map[string]any `json:"before,omitempty"` // 变更前发生变化的字段
+	After      map[string]any `json:"after,omitempty"`  // 变更后发生变化的字段
+}
+
+// SetChange 只保留 before 与 after 之间发生变化的顶层字段，避免整条记录重复存储未修改的数据
+// before/after 可以是结构体、map 或 nil（创建时 before 为 nil，删除时 after 为 nil），按 JSON 字段名比较。
+func (e *Entry) SetChange(before, after any) error {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return fmt.Errorf("audit: encode before: %w", err)
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return fmt.Errorf("audit: encode after: %w", err)
+	}
+	e.Before, e.After = nil, nil
+	for key, value := range beforeFields {
+		if other, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			setField(&e.Before, key, value)
+		}
+	}
+	for key, value := range afterFields {
+		if other, ok := beforeFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			setField(&e.After, key, value)
+		}
+	}
+	return nil
+}
+
+func toFields(value any) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func setField(fields *map[string]any, key string, value any) {
+	if *fields == nil {
+		*fields = make(map[string]any)
+	}
+	(*fields)[key] = value
+}
+
+// Sink 是审计记录的追加写入目标；实现不得修改或删除已写入的记录
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, entry Entry) error
+	Close() error
+}
+
+// Recorder 将审计记录写入一组 Sink
+type Recorder struct {
+	sinks []Sink
+}
+
+// New 创建写入 sinks 的 Recorder；没有 sink 时 Record 不做任何事
+func New(sinks ...Sink) *Recorder {
+	return &Recorder{sinks: sinks}
+}
+
+// Enabled 报告是否配置了至少一个 Sink
+func (r *Recorder) Enabled() bool {
+	return r != nil && len(r.sinks) > 0
+}
+
+// Record 补全时间与 trace_id 后依次写入每个 Sink；任一 Sink 失败都会记录错误日志并返回合并后的错误
+func (r *Recorder) Record(ctx context.Context, entry Entry) error {
+	if !r.Enabled() {
+		return nil
+	}
+	if entry.Action == "" {
+		return ErrMissingAction
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC().Truncate(time.Microsecond)
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+	if entry.TraceID == "" {
+		entry.TraceID, _ = log.TraceID(ctx)
+	}
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			log.FromStandardContext(ctx).Error("write audit entry failed",
+				zap.String("sink", sink.Name()),
+				zap.String("action", entry.Action),
+				zap.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("audit sink %s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭全部 Sink
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("audit sink %s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRecorder = New()
+)
+
+// SetDefault 替换默认 Recorder，返回旧的 Recorder 供调用方关闭
+func SetDefault(r *Recorder) *Recorder {
+	if r == nil {
+		r = New()
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	old := defaultRecorder
+	defaultRecorder = r
+	return old
+}
+
+// Default 返回默认 Recorder
+func Default() *Recorder {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRecorder
+}
+
+// Enabled 报告默认 Recorder 是否启用
+func Enabled() bool {
+	return Default().Enabled()
+}
+
+// Record 使用默认 Recorder 写入一条审计记录
+//
+//	err := audit.Record(ctx, audit.Entry{
+//	    Actor:    user.Name,
+//	    Action:   "user.login",
+//	    Outcome:  audit.OutcomeFailure,
+//	    Reason:   "wrong password",
+//	    ClientIP: c.ClientIP(),
+//	})
+func Record(ctx context.Context, entry Entry) error {
+	return Default().Record(ctx, entry)
+}
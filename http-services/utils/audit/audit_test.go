@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"http-services/utils/log"
+)
+
+type memorySink struct {
+	entries []Entry
+	err     error
+}
+
+func (s *memorySink) Name() string { return "memory" }
+
+func (s *memorySink) Write(_ context.Context, entry Entry) error {
+	s.entries = append(s.entries, entry)
+	return s.err
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestRecord_FillsDefaultsAndJoinsErrors(t *testing.T) {
+	ok := &memorySink{}
+	failing := &memorySink{err: errors.New("disk full")}
+	recorder := New(ok, failing)
+
+	ctx := log.WithTraceID(context.Background(), "trace-1")
+	err := recorder.Record(ctx, Entry{Actor: "u1", Action: "user.login"})
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Record() error = %v, want sink error", err)
+	}
+	if len(ok.entries) != 1 {
+		t.Fatalf("healthy sink got %d entries, want 1", len(ok.entries))
+	}
+	entry := ok.entries[0]
+	if entry.TraceID != "trace-1" || entry.Outcome != OutcomeSuccess || entry.Time.IsZero() {
+		t.Fatalf("entry = %+v, want trace_id, default outcome and time", entry)
+	}
+
+	if err := recorder.Record(ctx, Entry{Actor: "u1"}); !errors.Is(err, ErrMissingAction) {
+		t.Fatalf("Record() without action error = %v", err)
+	}
+	if err := New().Record(ctx, Entry{}); err != nil {
+		t.Fatalf("disabled Record() error = %v", err)
+	}
+}
+
+func TestEntrySetChange_KeepsChangedFieldsOnly(t *testing.T) {
+	type role struct {
+		Name  string   `json:"name"`
+		Perms []string `json:"perms"`
+		Note  string   `json:"note,omitempty"`
+	}
+	var entry Entry
+	err := entry.SetChange(
+		role{Name: "ops", Perms: []string{"read"}},
+		role{Name: "ops", Perms: []string{"read", "write"}, Note: "on-call"},
+	)
+	if err != nil {
+		t.Fatalf("SetChange() error = %v", err)
+	}
+	if _, ok := entry.Before["name"]; ok {
+		t.Fatalf("unchanged field kept: %v", entry.Before)
+	}
+	if len(entry.Before) != 1 || len(entry.After) != 2 || entry.After["note"] != "on-call" {
+		t.Fatalf("before = %v, after = %v", entry.Before, entry.After)
+	}
+
+	if err := entry.SetChange(nil, map[string]string{"name": "new"}); err != nil {
+		t.Fatalf("SetChange(nil, after) error = %v", err)
+	}
+	if entry.Before != nil || entry.After["name"] != "new" {
+		t.Fatalf("create change = %v -> %v", entry.Before, entry.After)
+	}
+}
+
+func TestFileSink_ChainSurvivesReopenAndDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	sink, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	recorder := New(sink)
+	for _, action := range []string{"user.login", "role.grant"} {
+		if err := recorder.Record(context.Background(), Entry{Actor: "u1", Action: action}); err != nil {
+			t.Fatalf("Record(%s) error = %v", action, err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// 重新打开后继续同一条链
+	sink, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if err := sink.Write(context.Background(), Entry{Actor: "u1", Action: "order.export"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	_ = sink.Close()
+
+	if count, err := Verify(path); err != nil || count != 3 {
+		t.Fatalf("Verify() = %d, %v; want 3 records", count, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(content), `"role.grant"`, `"role.revoke"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	count, err := Verify(path)
+	if !errors.Is(err, ErrChainBroken) || !strings.Contains(err.Error(), "line 2") || count != 1 {
+		t.Fatalf("Verify() after tampering = %d, %v; want break at line 2", count, err)
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if err := os.WriteFile(path, []byte(lines[0]+lines[2]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("Verify() after deletion error = %v", err)
+	}
+}
+
+func TestOpenFile_RejectsPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(`{"seq":1,"prev_hash":"","hash":"x","entry":{`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("OpenFile() error = %v, want ErrChainBroken", err)
+	}
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// ErrChainBroken 表示审计文件的 hash 链校验失败：记录被修改、删除、插入或重排
+var ErrChainBroken = errors.New("audit: hash chain broken")
+
+// fileRecord 是审计文件中的一行
+// hash = sha256(prev_hash + "\n" + seq + "\n" + entry)，entry 按写入时的原始字节参与计算，
+// 修改任一记录都会使它之后的整条链失效。
+type fileRecord struct {
+	Seq      uint64          `json:"seq"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+	Entry    json.RawMessage `json:"entry"`
+}
+
+func chainHash(prevHash string, seq uint64, entry []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(prevHash))
+	sum.Write([]byte("\n" + strconv.FormatUint(seq, 10) + "\n"))
+	sum.Write(entry)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// FileSink 以 JSON Lines 追加写入 hash 链审计文件，每条记录写入后立即 fsync
+// 文件只以 O_APPEND 打开，不参与日志轮转与 log.max_total_size 清理。
+type FileSink struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      uint64
+	lastHash string
+}
+
+// OpenFile 打开（必要时创建）审计文件，并从已有记录末尾接续 hash 链
+// 文件末尾存在不完整的记录（如写入中途宕机）时返回错误，需人工确认后再启动。
+func OpenFile(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit directory: %w", err)
+	}
+	seq, lastHash, err := scanChain(path, false)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	return &FileSink{path: path, file: file, seq: seq, lastHash: lastHash}, nil
+}
+
+// Name 实现 Sink
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Write 实现 Sink
+func (s *FileSink) Write(_ context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	seq := s.seq + 1
+	record := fileRecord{Seq: seq, PrevHash: s.lastHash, Hash: chainHash(s.lastHash, seq, data), Entry: data}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.seq = seq
+	s.lastHash = record.Hash
+	return nil
+}
+
+// Close 实现 Sink
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Verify 从头校验审计文件的 hash 链，返回校验通过的记录数
+// 校验失败时错误包装 ErrChainBroken 并指出第一条异常记录所在的行号。
+func Verify(path string) (uint64, error) {
+	seq, _, err := scanChain(path, true)
+	return seq, err
+}
+
+// scanChain 逐行读取审计文件，返回最后一条记录的序号与 hash；verify 为 true 时同时校验每一条记录
+func scanChain(path string, verify bool) (uint64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	var (
+		seq      uint64
+		lastHash string
+	)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(data)) > 0 {
+				return seq, lastHash, fmt.Errorf("%w: line %d is incomplete", ErrChainBroken, line)
+			}
+			return seq, lastHash, nil
+		}
+		if err != nil {
+			return seq, lastHash, err
+		}
+
+		var record fileRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return seq, lastHash, fmt.Errorf("%w: line %d: %v", ErrChainBroken, line, err)
+		}
+		if verify {
+			switch {
+			case record.Seq != seq+1:
+				return seq, lastHash, fmt.Errorf("%w: line %d has seq %d, want %d", ErrChainBroken, line, record.Seq, seq+1)
+			case record.PrevHash != lastHash:
+				return seq, lastHash, fmt.Errorf("%w: line %d does not link to the previous record", ErrChainBroken, line)
+			case record.Hash != chainHash(record.PrevHash, record.Seq, record.Entry):
+				return seq, lastHash, fmt.Errorf("%w: line %d hash mismatch", ErrChainBroken, line)
+			}
+		}
+		seq = record.Seq
+		lastHash = record.Hash
+	}
+}
@@ -23,8 +23,26 @@ type MapClaims struct {
 	jwt.RegisteredClaims
 }
 
+// JWT 按构造时传入的配置来源签发与校验 token，每次调用时读取，支持热重载；
+// 包级函数使用 config.Current()，测试与 App 可以传入独立的配置
+type JWT struct {
+	config func() config.JWTConfig
+}
+
+// NewJWT 创建使用 cfg 返回的密钥与有效期的 JWT
+func NewJWT(cfg func() config.JWTConfig) *JWT {
+	return &JWT{config: cfg}
+}
+
+var defaultJWT = NewJWT(func() config.JWTConfig { return config.Current().JWT })
+
 // PrepareRegisteredClaims 填充默认的 RegisteredClaims 字段
 func PrepareRegisteredClaims(rc *jwt.RegisteredClaims) {
+	defaultJWT.PrepareRegisteredClaims(rc)
+}
+
+// PrepareRegisteredClaims 填充默认的 RegisteredClaims 字段，有效期取自 jwt.expiration
+func (j *JWT) PrepareRegisteredClaims(rc *jwt.RegisteredClaims) {
 	if rc == nil {
 		return
 	}
@@ -47,24 +65,34 @@ func PrepareRegisteredClaims(rc *jwt.RegisteredClaims) {
 	if rc.NotBefore == nil {
 		rc.NotBefore = jwt.NewNumericDate(now)
 	}
-	if rc.ExpiresAt == nil && config.JWTExpiration > 0 {
-		rc.ExpiresAt = jwt.NewNumericDate(now.Add(config.JWTExpiration))
+	if expiration := j.config().Expiration; rc.ExpiresAt == nil && expiration > 0 {
+		rc.ExpiresAt = jwt.NewNumericDate(now.Add(expiration))
 	}
 }
 
 // SignHS256 使用 HS256 对 claims 进行签名
 func SignHS256(claims jwt.Claims) (string, error) {
+	return defaultJWT.SignHS256(claims)
+}
+
+// SignHS256 使用 HS256 对 claims 进行签名
+func (j *JWT) SignHS256(claims jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.JWTKey))
+	return token.SignedString([]byte(j.config().Key))
 }
 
 // ParseHS256 使用 HS256 验证并解析 token，结果写入传入的 claims
 func ParseHS256(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return defaultJWT.ParseHS256(tokenString, claims)
+}
+
+// ParseHS256 使用 HS256 验证并解析 token，结果写入传入的 claims
+func (j *JWT) ParseHS256(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
 	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(config.JWTKey), nil
+		return []byte(j.config().Key), nil
 	})
 }
 
@@ -80,15 +108,25 @@ func ParseHS256(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
 //	}
 //	token, err := JWTIssue(data)
 func JWTIssue(data map[string]interface{}) (string, error) {
+	return defaultJWT.Issue(data)
+}
+
+// Issue 签发 JWT Token，见 JWTIssue
+func (j *JWT) Issue(data map[string]interface{}) (string, error) {
 	claims := MapClaims{Data: data}
-	PrepareRegisteredClaims(&claims.RegisteredClaims)
-	return SignHS256(&claims)
+	j.PrepareRegisteredClaims(&claims.RegisteredClaims)
+	return j.SignHS256(&claims)
 }
 
 // JWTDecrypt 解析 JWT Token，返回 map 数据
 func JWTDecrypt(tokenString string) (map[string]interface{}, error) {
+	return defaultJWT.Decrypt(tokenString)
+}
+
+// Decrypt 解析 JWT Token，返回 map 数据
+func (j *JWT) Decrypt(tokenString string) (map[string]interface{}, error) {
 	claims := &MapClaims{}
-	token, err := ParseHS256(tokenString, claims)
+	token, err := j.ParseHS256(tokenString, claims)
 	if err != nil {
 		return nil, err
 	}
@@ -11,8 +11,10 @@ import (
 
 func init() {
 	// 设置测试用的配置
-	config.JWTKey = "test-secret-key-at-least-32-chars-long"
-	config.JWTExpiration = 1 * time.Hour
+	config.Update(func(c *config.Config) {
+		c.JWT.Key = "test-secret-key-at-least-32-chars-long"
+		c.JWT.Expiration = 1 * time.Hour
+	})
 }
 
 func TestJWTIssueAndDecrypt(t *testing.T) {
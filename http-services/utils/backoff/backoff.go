@@ -0,0 +1,23 @@
+// Package backoff 计算失败重试的等待时间，供任务队列与 outbox relay 共用。
+package backoff
+
+import "time"
+
+// Exponential 返回第 attempt 次失败后的等待时间：base * 2^(attempt-1)，并以 max 封顶。
+// 翻倍即将超过 max 时直接返回 max，不会溢出为负数；max <= 0 时按 time.Duration 的最大值封顶
+func Exponential(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	if max <= 0 {
+		max = time.Duration(1<<63 - 1)
+	}
+	wait := min(base, max)
+	for i := 1; i < attempt; i++ {
+		if wait > max/2 {
+			return max
+		}
+		wait *= 2
+	}
+	return wait
+}
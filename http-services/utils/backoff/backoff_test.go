@@ -0,0 +1,31 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		base    time.Duration
+		max     time.Duration
+		want    time.Duration
+	}{
+		{"first attempt", 1, time.Second, time.Minute, time.Second},
+		{"doubles", 4, time.Second, time.Minute, 8 * time.Second},
+		{"capped", 10, time.Second, time.Minute, time.Minute},
+		{"base above max", 1, time.Hour, time.Minute, time.Minute},
+		{"zero base", 5, 0, time.Minute, 0},
+		{"many attempts do not overflow", 1000, time.Second, time.Hour, time.Hour},
+		{"no max does not overflow", 1000, time.Second, 0, time.Duration(1<<63 - 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Exponential(tt.attempt, tt.base, tt.max); got != tt.want {
+				t.Errorf("Exponential(%d, %v, %v) = %v, want %v", tt.attempt, tt.base, tt.max, got, tt.want)
+			}
+		})
+	}
+}
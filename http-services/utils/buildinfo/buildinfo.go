@@ -0,0 +1,65 @@
+// Package buildinfo 保存 main 包通过 -ldflags 注入的版本信息与进程启动时间，供诊断接口读取。
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	mu        sync.RWMutex
+	version   = "dev"
+	buildTime = "unknown"
+	gitCommit = "unknown"
+
+	startTime = time.Now()
+)
+
+// Info 是构建与进程信息
+type Info struct {
+	Version   string            `json:"version"`
+	BuildTime string            `json:"build_time"`
+	GitCommit string            `json:"git_commit"`
+	GoVersion string            `json:"go_version"`
+	Module    string            `json:"module"`
+	Settings  map[string]string `json:"settings,omitempty"` // go 工具链记录的构建参数，如 vcs.revision、GOARCH、-tags
+	StartTime time.Time         `json:"start_time"`
+}
+
+// Set 记录 main.Version / BuildTime / GitCommit，启动时调用一次
+func Set(v, built, commit string) {
+	mu.Lock()
+	defer mu.Unlock()
+	version, buildTime, gitCommit = v, built, commit
+}
+
+// Get 返回构建信息；Settings 来自 debug.ReadBuildInfo，go run 或测试二进制中可能为空
+func Get() Info {
+	mu.RLock()
+	info := Info{
+		Version:   version,
+		BuildTime: buildTime,
+		GitCommit: gitCommit,
+		GoVersion: runtime.Version(),
+		StartTime: startTime,
+	}
+	mu.RUnlock()
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Module = build.Main.Path
+		if len(build.Settings) > 0 {
+			info.Settings = make(map[string]string, len(build.Settings))
+			for _, setting := range build.Settings {
+				info.Settings[setting.Key] = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// Uptime 返回进程已运行的时长
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
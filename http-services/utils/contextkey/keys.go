@@ -13,6 +13,14 @@ const (
 	JWTData = "jwtData"
 	// BoundParams 是 Gin context 中存放已绑定业务参数的 key。
 	BoundParams = "__bound_params__"
+	// ResponseCode 是 Gin context 中存放统一响应业务码的 key，供审计等后置中间件判断请求结果。
+	ResponseCode = "__response_code__"
+	// Actor 是 Gin context 中存放非 JWT 认证（如管理接口 token）操作者标识的 key。
+	Actor = "actor"
+	// AuditEntry 是 Gin context 中存放当前请求待写入审计记录的 key。
+	AuditEntry = "__audit_entry__"
+	// ClientPrincipal 是 Gin context 中存放已校验客户端证书身份（mTLS）的 key。
+	ClientPrincipal = "client_principal"
 )
 
 type traceIDKey struct{}
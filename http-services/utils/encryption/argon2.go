@@ -0,0 +1,112 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id 默认参数取自 RFC 9106 第二推荐配置（64 MiB、3 轮、4 并行度）
+const (
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Time        = 3
+	DefaultArgon2Parallelism = 4
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// Argon2idParams 是 Argon2id 的成本参数，零值字段使用默认值
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Time        uint32
+	Parallelism uint8
+}
+
+// Argon2idHasher 生成 PHC 格式的 Argon2id 哈希：
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>（salt/hash 为无填充的标准 base64）
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher 创建 Argon2id 哈希器
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Memory
+	}
+	if params.Time == 0 {
+		params.Time = DefaultArgon2Time
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2Parallelism
+	}
+	return &Argon2idHasher{params: params}
+}
+
+// Algorithm 实现 PasswordHasher
+func (h *Argon2idHasher) Algorithm() string {
+	return "argon2id"
+}
+
+// Identifies 实现 PasswordHasher
+func (h *Argon2idHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+// Hash 实现 PasswordHasher
+func (h *Argon2idHasher) Hash(password []byte) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey(password, salt, h.params.Time, h.params.Memory, h.params.Parallelism, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Time, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 实现 PasswordHasher
+func (h *Argon2idHasher) Verify(password []byte, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey(password, salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// NeedsRehash 实现 PasswordHasher
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, key, err := decodeArgon2id(encoded)
+	return err != nil || params != h.params || len(key) != argon2KeyLen
+}
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, ErrMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version %q", ErrMalformedHash, parts[2])
+	}
+	var params Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil ||
+		params.Memory == 0 || params.Time == 0 || params.Parallelism == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2 params %q", ErrMalformedHash, parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2 salt: %v", ErrMalformedHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: argon2 hash", ErrMalformedHash)
+	}
+	return params, salt, key, nil
+}
@@ -1,6 +1,8 @@
 package encryption
 
 import (
+	"errors"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -10,6 +12,7 @@ const (
 )
 
 // HashPasswordWithBcrypt 使用bcrypt算法对密码进行哈希
+// 新代码请使用 HashPassword / VerifyPassword，算法、参数与 pepper 由配置决定。
 func HashPasswordWithBcrypt(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), BCryptCost)
 	if err != nil {
@@ -32,3 +35,58 @@ func IsBcryptHash(hash string) bool {
 	}
 	return hash[0] == '$' && (hash[1] == '2') && (hash[2] == 'a' || hash[2] == 'b' || hash[2] == 'x' || hash[2] == 'y')
 }
+
+// BcryptHasher 以 PasswordHasher 形式提供 bcrypt，主要用于校验与升级存量哈希。
+// bcrypt 只使用密码的前 72 字节，超长密码 Hash 会返回错误；配置 pepper 后输入固定为 44 字节。
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 哈希器，cost 为 0 时使用 BCryptCost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = BCryptCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Algorithm 实现 PasswordHasher
+func (h *BcryptHasher) Algorithm() string {
+	return "bcrypt"
+}
+
+// Identifies 实现 PasswordHasher
+func (h *BcryptHasher) Identifies(encoded string) bool {
+	return IsBcryptHash(encoded)
+}
+
+// Hash 实现 PasswordHasher
+func (h *BcryptHasher) Hash(password []byte) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(password, h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify 实现 PasswordHasher
+func (h *BcryptHasher) Verify(password []byte, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), password)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	case errors.Is(err, bcrypt.ErrPasswordTooLong):
+		// 与 bcrypt 生成时的行为一致：超长密码不可能匹配
+		return false, nil
+	default:
+		return false, errors.Join(ErrMalformedHash, err)
+	}
+}
+
+// NeedsRehash 实现 PasswordHasher，cost 与当前配置不同时返回 true
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
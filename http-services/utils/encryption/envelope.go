@@ -0,0 +1,280 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// envelopePrefix 标识密文格式版本：enc1:<key id>:<包装后的数据密钥>:<数据密文>
+const envelopePrefix = "enc1:"
+
+const (
+	keySize   = 32 // AES-256
+	nonceSize = 12
+)
+
+var (
+	// ErrKeyringNotConfigured 表示尚未设置默认密钥环
+	ErrKeyringNotConfigured = errors.New("encryption: keyring not configured")
+	// ErrUnknownKeyID 表示密文使用的主密钥不在密钥环中（可能已被移除）
+	ErrUnknownKeyID = errors.New("encryption: unknown key id")
+	// ErrMalformedCiphertext 表示字符串不是合法的 enc1 密文
+	ErrMalformedCiphertext = errors.New("encryption: malformed ciphertext")
+	// ErrDecrypt 表示密文被篡改或主密钥不匹配
+	ErrDecrypt = errors.New("encryption: decrypt failed")
+	// ErrNoBlindIndexKey 表示未配置盲索引密钥
+	ErrNoBlindIndexKey = errors.New("encryption: blind index key not configured")
+)
+
+// Keyring 持有按 ID 区分版本的 AES-256 主密钥，采用信封加密：
+// 每个值使用随机数据密钥做 AES-256-GCM 加密，数据密钥再由主密钥包装后与密文一起保存。
+// 加密时传入的附加数据（如 ColumnAAD 的表名与列名）参与认证但不保存在密文中，解密时必须传入相同的值，
+// 有数据库写权限的人把密文复制到其他表、列或行时会解密失败。
+// 新数据使用 primary 包装；轮换时把新密钥设为 primary，旧密钥保留到 Reencrypt 完成后再移除。
+type Keyring struct {
+	primary  string
+	keys     map[string]cipher.AEAD
+	blindKey []byte
+}
+
+// NewKeyring 创建密钥环；keys 的值必须是 32 字节，key id 不能包含冒号。
+// blindIndexKey 为空时 BlindIndex 返回 ErrNoBlindIndexKey。
+func NewKeyring(primary string, keys map[string][]byte, blindIndexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("encryption: primary key %q not in keyring", primary)
+	}
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption: invalid key id %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if len(blindIndexKey) > 0 {
+		k.blindKey = append([]byte(nil), blindIndexKey...)
+	}
+	return k, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("want %d-byte key, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// PrimaryKeyID 返回加密新数据使用的主密钥 ID
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Encrypt 加密明文并绑定附加数据 aad（可为 nil），返回 enc1 格式的文本密文，可直接存入 varchar/text 列
+func (k *Keyring) Encrypt(plaintext, aad []byte) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("generate data key: %w", err)
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	payload, err := seal(data, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.primary], dek, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+	return format(k.primary, wrapped, payload), nil
+}
+
+// Decrypt 解密 enc1 密文；aad 必须与加密时相同，否则返回 ErrDecrypt
+func (k *Keyring) Decrypt(ciphertext string, aad []byte) ([]byte, error) {
+	keyID, wrapped, payload, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return open(data, payload, aad)
+}
+
+// NeedsReencrypt 报告密文是否由非 primary 的主密钥包装
+func (k *Keyring) NeedsReencrypt(ciphertext string) bool {
+	keyID, err := KeyID(ciphertext)
+	return err == nil && keyID != k.primary
+}
+
+// Reencrypt 用 primary 重新包装数据密钥，数据密文本身不变，因此不需要附加数据；已是 primary 时原样返回
+func (k *Keyring) Reencrypt(ciphertext string) (string, error) {
+	keyID, wrapped, payload, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if keyID == k.primary {
+		return ciphertext, nil
+	}
+	dek, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := seal(k.keys[k.primary], dek, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+	return format(k.primary, rewrapped, payload), nil
+}
+
+// BlindIndex 计算用于等值查询的盲索引：HMAC-SHA256(blind key, purpose || 0 || value) 的前 16 字节（hex）。
+// purpose 区分不同字段（如 "user.phone"），相同值在不同字段得到不同索引；调用方需先做规范化（如去除空格、统一大小写）。
+// 盲索引密钥不随主密钥轮换，更换后必须重建所有索引列。
+func (k *Keyring) BlindIndex(purpose, value string) (string, error) {
+	if len(k.blindKey) == 0 {
+		return "", ErrNoBlindIndexKey
+	}
+	mac := hmac.New(sha256.New, k.blindKey)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16]), nil
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, keyID)
+	}
+	return open(kek, wrapped, []byte(keyID))
+}
+
+// ColumnAAD 返回把密文绑定到表与列的附加数据，主键已知时（如应用层生成的 ID）可一并绑定到行。
+// EncryptedString 绑定表与列；自增主键在插入前未知，需要按行绑定时在应用层调用 Encrypt 并传入主键
+func ColumnAAD(table, column string, primaryKey ...string) []byte {
+	return []byte(strings.Join(append([]string{table, column}, primaryKey...), "\x00"))
+}
+
+// IsEncrypted 报告字符串是否为 enc1 格式（不校验内容）
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// KeyID 返回密文使用的主密钥 ID
+func KeyID(ciphertext string) (string, error) {
+	keyID, _, _, err := parse(ciphertext)
+	return keyID, err
+}
+
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize, nonceSize+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < nonceSize+aead.Overhead() {
+		return nil, ErrMalformedCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func format(keyID string, wrapped, payload []byte) string {
+	return envelopePrefix + keyID + ":" +
+		base64.RawURLEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func parse(ciphertext string) (keyID string, wrapped, payload []byte, err error) {
+	body, ok := strings.CutPrefix(ciphertext, envelopePrefix)
+	if !ok {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	parts := strings.Split(body, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	if wrapped, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	if payload, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	return parts[0], wrapped, payload, nil
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefaultKeyring 设置 EncryptedString 等使用的默认密钥环，返回旧值；传 nil 表示关闭
+func SetDefaultKeyring(keyring *Keyring) *Keyring {
+	return defaultKeyring.Swap(keyring)
+}
+
+// DefaultKeyring 返回默认密钥环，未配置时为 nil
+func DefaultKeyring() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// BlindIndex 使用默认密钥环计算盲索引
+func BlindIndex(purpose, value string) (string, error) {
+	keyring := DefaultKeyring()
+	if keyring == nil {
+		return "", ErrKeyringNotConfigured
+	}
+	return keyring.BlindIndex(purpose, value)
+}
+
+// SealWithKey 直接用 32 字节密钥做 AES-256-GCM 加密，返回 base64url 文本（nonce || 密文）。
+// 适合配置项等少量数据；数据库字段请使用 Keyring 的信封加密。
+func SealWithKey(key, plaintext []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", fmt.Errorf("encryption: %w", err)
+	}
+	sealed, err := seal(aead, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenWithKey 解密 SealWithKey 的输出
+func OpenWithKey(key []byte, sealed string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+	return open(aead, raw, nil)
+}
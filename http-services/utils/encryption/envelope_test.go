@@ -0,0 +1,229 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func newTestKeyring(t *testing.T, primary string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(primary, map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, testKey(9))
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring(t, "k1")
+	ciphertext, err := keyring.Encrypt([]byte("13800138000"), nil)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(ciphertext) || !strings.HasPrefix(ciphertext, "enc1:k1:") || strings.Contains(ciphertext, "13800138000") {
+		t.Fatalf("Encrypt() = %q", ciphertext)
+	}
+	again, _ := keyring.Encrypt([]byte("13800138000"), nil)
+	if again == ciphertext {
+		t.Fatal("Encrypt() is deterministic, want a fresh data key and nonce per call")
+	}
+	plaintext, err := keyring.Decrypt(ciphertext, nil)
+	if err != nil || string(plaintext) != "13800138000" {
+		t.Fatalf("Decrypt() = %q, %v", plaintext, err)
+	}
+}
+
+func TestKeyring_DecryptRejectsTamperingAndUnknownKeys(t *testing.T) {
+	keyring := newTestKeyring(t, "k1")
+	ciphertext, _ := keyring.Encrypt([]byte("secret"), nil)
+
+	// 翻转数据密文的最后一个字节
+	i := strings.LastIndexByte(ciphertext, ':')
+	payload, _ := base64.RawURLEncoding.DecodeString(ciphertext[i+1:])
+	payload[len(payload)-1] ^= 1
+	tampered := ciphertext[:i+1] + base64.RawURLEncoding.EncodeToString(payload)
+	if _, err := keyring.Decrypt(tampered, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt(tampered) error = %v, want ErrDecrypt", err)
+	}
+	// 把 key id 换成另一个存在的密钥，包装数据密钥的 AAD 不匹配
+	swapped := strings.Replace(ciphertext, "enc1:k1:", "enc1:k2:", 1)
+	if _, err := keyring.Decrypt(swapped, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt(swapped key id) error = %v, want ErrDecrypt", err)
+	}
+	other, _ := NewKeyring("k3", map[string][]byte{"k3": testKey(3)}, nil)
+	if _, err := other.Decrypt(ciphertext, nil); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Decrypt() with missing key error = %v, want ErrUnknownKeyID", err)
+	}
+	for _, bad := range []string{"plain", "enc1:", "enc1:k1:@@:@@", "enc1:k1:AAAA"} {
+		if _, err := keyring.Decrypt(bad, nil); !errors.Is(err, ErrMalformedCiphertext) {
+			t.Errorf("Decrypt(%q) error = %v, want ErrMalformedCiphertext", bad, err)
+		}
+	}
+}
+
+func TestKeyring_DecryptRequiresSameAAD(t *testing.T) {
+	keyring := newTestKeyring(t, "k1")
+	ciphertext, _ := keyring.Encrypt([]byte("13800138000"), ColumnAAD("users", "phone"))
+
+	if plaintext, err := keyring.Decrypt(ciphertext, ColumnAAD("users", "phone")); err != nil || string(plaintext) != "13800138000" {
+		t.Fatalf("Decrypt() = %q, %v", plaintext, err)
+	}
+	for name, aad := range map[string][]byte{
+		"nil":          nil,
+		"other column": ColumnAAD("users", "id_number"),
+		"other table":  ColumnAAD("admins", "phone"),
+		"with row key": ColumnAAD("users", "phone", "1"),
+		"joined parts": ColumnAAD("usersphone", ""),
+	} {
+		if _, err := keyring.Decrypt(ciphertext, aad); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Decrypt() with %s AAD error = %v, want ErrDecrypt", name, err)
+		}
+	}
+	// 更换主密钥只重新包装数据密钥，附加数据仍然有效
+	next, _ := newTestKeyring(t, "k2").Reencrypt(ciphertext)
+	if plaintext, err := keyring.Decrypt(next, ColumnAAD("users", "phone")); err != nil || string(plaintext) != "13800138000" {
+		t.Fatalf("Decrypt() after Reencrypt() = %q, %v", plaintext, err)
+	}
+}
+
+func TestKeyring_ReencryptRewrapsDataKey(t *testing.T) {
+	old := newTestKeyring(t, "k1")
+	ciphertext, _ := old.Encrypt([]byte("110101199001011234"), nil)
+
+	rotated := newTestKeyring(t, "k2")
+	if !rotated.NeedsReencrypt(ciphertext) || old.NeedsReencrypt(ciphertext) {
+		t.Fatal("NeedsReencrypt() should only report ciphertexts wrapped by a non-primary key")
+	}
+	next, err := rotated.Reencrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Reencrypt() error = %v", err)
+	}
+	if keyID, _ := KeyID(next); keyID != "k2" || rotated.NeedsReencrypt(next) {
+		t.Fatalf("Reencrypt() = %q, want wrapped by k2", next)
+	}
+	// 信封加密只重新包装数据密钥，数据密文部分保持不变
+	if next[strings.LastIndexByte(next, ':'):] != ciphertext[strings.LastIndexByte(ciphertext, ':'):] {
+		t.Fatal("Reencrypt() changed the data ciphertext")
+	}
+	k2Only, _ := NewKeyring("k2", map[string][]byte{"k2": testKey(2)}, nil)
+	if plaintext, err := k2Only.Decrypt(next, nil); err != nil || string(plaintext) != "110101199001011234" {
+		t.Fatalf("Decrypt() after removing k1 = %q, %v", plaintext, err)
+	}
+	if same, _ := rotated.Reencrypt(next); same != next {
+		t.Fatal("Reencrypt() of a primary-wrapped ciphertext should be a no-op")
+	}
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	keyring := newTestKeyring(t, "k1")
+	a, err := keyring.BlindIndex("user.phone", "13800138000")
+	if err != nil || len(a) != 32 {
+		t.Fatalf("BlindIndex() = %q, %v", a, err)
+	}
+	if b, _ := keyring.BlindIndex("user.phone", "13800138000"); b != a {
+		t.Fatal("BlindIndex() is not deterministic")
+	}
+	if c, _ := keyring.BlindIndex("user.id_number", "13800138000"); c == a {
+		t.Fatal("BlindIndex() ignores purpose")
+	}
+	noBlind, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)}, nil)
+	if _, err := noBlind.BlindIndex("user.phone", "x"); !errors.Is(err, ErrNoBlindIndexKey) {
+		t.Fatalf("BlindIndex() without key error = %v", err)
+	}
+}
+
+func TestNewKeyring_RejectsInvalidKeys(t *testing.T) {
+	for name, tc := range map[string]struct {
+		primary string
+		keys    map[string][]byte
+	}{
+		"missing primary": {"k9", map[string][]byte{"k1": testKey(1)}},
+		"short key":       {"k1", map[string][]byte{"k1": testKey(1)[:16]}},
+		"colon in id":     {"a:b", map[string][]byte{"a:b": testKey(1)}},
+	} {
+		if _, err := NewKeyring(tc.primary, tc.keys, nil); err == nil {
+			t.Errorf("%s: NewKeyring() succeeded, want error", name)
+		}
+	}
+}
+
+type encryptedUser struct {
+	ID       uint
+	Phone    EncryptedString
+	IDNumber EncryptedString
+}
+
+func parseEncryptedUser(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(&encryptedUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("schema.Parse() error = %v", err)
+	}
+	return s
+}
+
+func TestEncryptedString_ValueAndScan(t *testing.T) {
+	old := SetDefaultKeyring(nil)
+	t.Cleanup(func() { SetDefaultKeyring(old) })
+	ctx := context.Background()
+	s := parseEncryptedUser(t)
+	phone, idNumber := s.LookUpField("phone"), s.LookUpField("id_number")
+
+	user := &encryptedUser{Phone: "13800138000"}
+	dst := reflect.ValueOf(user).Elem()
+	if _, err := phone.Serializer.Value(ctx, phone, dst, user.Phone); !errors.Is(err, ErrKeyringNotConfigured) {
+		t.Fatalf("Value() without keyring error = %v", err)
+	}
+	keyring := newTestKeyring(t, "k1")
+	SetDefaultKeyring(keyring)
+
+	value, err := phone.Serializer.Value(ctx, phone, dst, user.Phone)
+	if err != nil || !IsEncrypted(value.(string)) {
+		t.Fatalf("Value() = %v, %v", value, err)
+	}
+	if plaintext, err := keyring.Decrypt(value.(string), ColumnAAD("encrypted_users", "phone")); err != nil || string(plaintext) != "13800138000" {
+		t.Fatalf("Value() is not bound to encrypted_users.phone: %q, %v", plaintext, err)
+	}
+
+	var scanned encryptedUser
+	dst = reflect.ValueOf(&scanned).Elem()
+	if err := scanField(ctx, phone, dst, []byte(value.(string))); err != nil || scanned.Phone != "13800138000" {
+		t.Fatalf("Scan() = %q, %v", scanned.Phone, err)
+	}
+	// 复制到同表的另一列后无法解密
+	if err := scanField(ctx, idNumber, dst, value); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("Scan() of a ciphertext from another column error = %v, want ErrDecrypt", err)
+	}
+	if value, _ := phone.Serializer.Value(ctx, phone, dst, EncryptedString("")); value != "" {
+		t.Fatalf("empty Value() = %v, want empty string", value)
+	}
+	if err := scanField(ctx, phone, dst, nil); err != nil || scanned.Phone != "" {
+		t.Fatalf("Scan(nil) = %q, %v", scanned.Phone, err)
+	}
+	if err := scanField(ctx, phone, dst, "13800138000"); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Fatalf("Scan(plaintext) error = %v, want ErrMalformedCiphertext", err)
+	}
+}
+
+// scanField 按 GORM 读取行的方式把数据库值经 serializer 写入字段
+func scanField(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	value := field.NewValuePool.Get()
+	defer field.NewValuePool.Put(value)
+	if err := value.(sql.Scanner).Scan(dbValue); err != nil {
+		return err
+	}
+	return field.Set(ctx, dst, value)
+}
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// EncryptedString 是落库时透明加密的字符串，用于手机号、证件号等敏感列：
+//
+//	type User struct {
+//	    msqldb.BaseModel
+//	    Phone      encryption.EncryptedString `gorm:"column:phone;type:varchar(255)"`
+//	    PhoneIndex string                     `gorm:"column:phone_index;type:char(32);index"`
+//	}
+//
+// 它实现 GORM 的 serializer 接口：写入时用默认密钥环加密，读取时解密，表名与列名作为附加数据（ColumnAAD）
+// 绑定到密文，把密文复制到其他表或列后读取会返回 ErrDecrypt；同一列不同行之间的互换无法发现，需要时见 ColumnAAD。
+// 只能作为 GORM 模型的非指针字段读写，database/sql 直接扫描得到的是密文。空字符串按空字符串保存，不做加密，NULL 读为空字符串。
+// 密文无法排序或按条件查询，等值查询请配合 BlindIndex 列。列宽至少 varchar(255)，明文越长密文越长（约 4/3 倍再加 130 字节）。
+type EncryptedString string
+
+// Value 实现 schema.SerializerValuerInterface
+func (EncryptedString) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	s, ok := fieldValue.(EncryptedString)
+	if !ok {
+		return nil, fmt.Errorf("encryption: cannot encrypt %T", fieldValue)
+	}
+	if s == "" {
+		return "", nil
+	}
+	keyring := DefaultKeyring()
+	if keyring == nil {
+		return nil, ErrKeyringNotConfigured
+	}
+	return keyring.Encrypt([]byte(s), fieldAAD(field))
+}
+
+// Scan 实现 schema.SerializerInterface，GORM 随后把解密结果复制到字段；
+// NULL 与空字符串读为空，其余必须是以同一表、列加密的 enc1 密文
+func (s *EncryptedString) Scan(_ context.Context, field *schema.Field, _ reflect.Value, dbValue any) error {
+	var ciphertext string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrMalformedCiphertext, dbValue)
+	}
+	if ciphertext == "" {
+		*s = ""
+		return nil
+	}
+	keyring := DefaultKeyring()
+	if keyring == nil {
+		return ErrKeyringNotConfigured
+	}
+	plaintext, err := keyring.Decrypt(ciphertext, fieldAAD(field))
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+func fieldAAD(field *schema.Field) []byte {
+	return ColumnAAD(field.Schema.Table, field.DBName)
+}
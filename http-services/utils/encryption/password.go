@@ -0,0 +1,157 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	// ErrUnknownHashFormat 表示哈希串不属于任何已支持的算法
+	ErrUnknownHashFormat = errors.New("encryption: unknown password hash format")
+	// ErrMalformedHash 表示哈希串能识别算法，但参数或编码不完整
+	ErrMalformedHash = errors.New("encryption: malformed password hash")
+)
+
+// PasswordHasher 是一种密码哈希算法。Hash 使用实例自身的参数；
+// Verify 使用哈希串中记录的参数，因此同一算法调整参数后旧哈希依然可以校验。
+type PasswordHasher interface {
+	// Algorithm 返回算法名，如 argon2id、scrypt、bcrypt
+	Algorithm() string
+	// Identifies 报告哈希串是否由该算法生成
+	Identifies(encoded string) bool
+	Hash(password []byte) (string, error)
+	Verify(password []byte, encoded string) (bool, error)
+	// NeedsRehash 报告哈希串的参数是否与当前实例不同
+	NeedsRehash(encoded string) bool
+}
+
+// PasswordOptions 是与算法无关的选项
+type PasswordOptions struct {
+	// Pepper 是不随数据库保存的全局密钥：密码先做 HMAC-SHA256(pepper) 再交给哈希算法，
+	// 只拿到数据库无法离线爆破。HMAC 结果固定 44 字节，也避开了 bcrypt 的 72 字节上限。
+	Pepper string
+	// AllowUnpeppered 允许校验启用 pepper 之前生成的哈希，通过后 NeedsRehash 为 true；
+	// 存量哈希全部升级后应关闭。
+	AllowUnpeppered bool
+}
+
+// PasswordPolicy 用 current 生成新哈希，同时能校验所有已支持算法的存量哈希
+type PasswordPolicy struct {
+	current         PasswordHasher
+	verifiers       []PasswordHasher
+	pepper          []byte
+	allowUnpeppered bool
+}
+
+// NewPasswordPolicy 创建密码策略，current 为 nil 时使用默认参数的 Argon2id
+func NewPasswordPolicy(current PasswordHasher, opts PasswordOptions) *PasswordPolicy {
+	if current == nil {
+		current = NewArgon2idHasher(Argon2idParams{})
+	}
+	return &PasswordPolicy{
+		current: current,
+		verifiers: []PasswordHasher{
+			current,
+			NewArgon2idHasher(Argon2idParams{}),
+			NewScryptHasher(ScryptParams{}),
+			NewBcryptHasher(0),
+		},
+		pepper:          []byte(opts.Pepper),
+		allowUnpeppered: opts.AllowUnpeppered,
+	}
+}
+
+// Algorithm 返回生成新哈希使用的算法
+func (p *PasswordPolicy) Algorithm() string {
+	return p.current.Algorithm()
+}
+
+// Hash 使用当前算法与 pepper 生成密码哈希
+func (p *PasswordPolicy) Hash(password string) (string, error) {
+	return p.current.Hash(p.pepperize(password))
+}
+
+// Verify 校验密码；ok 为 true 且 needsRehash 为 true 时，调用方应使用 Hash 重新生成并保存哈希
+// （算法不同、参数变化或是未加 pepper 的旧哈希）。密码错误返回 false, false, nil。
+func (p *PasswordPolicy) Verify(password, encoded string) (ok, needsRehash bool, err error) {
+	hasher := p.hasherFor(encoded)
+	if hasher == nil {
+		return false, false, ErrUnknownHashFormat
+	}
+	ok, err = hasher.Verify(p.pepperize(password), encoded)
+	if err != nil {
+		return false, false, err
+	}
+	unpeppered := false
+	if !ok && len(p.pepper) > 0 && p.allowUnpeppered {
+		if ok, err = hasher.Verify([]byte(password), encoded); err != nil {
+			return false, false, err
+		}
+		unpeppered = ok
+	}
+	if !ok {
+		return false, false, nil
+	}
+	return true, unpeppered || p.NeedsRehash(encoded), nil
+}
+
+// NeedsRehash 报告哈希串是否不是由当前算法与参数生成的（不含 pepper 判断）
+func (p *PasswordPolicy) NeedsRehash(encoded string) bool {
+	if !p.current.Identifies(encoded) {
+		return true
+	}
+	return p.current.NeedsRehash(encoded)
+}
+
+func (p *PasswordPolicy) hasherFor(encoded string) PasswordHasher {
+	for _, hasher := range p.verifiers {
+		if hasher.Identifies(encoded) {
+			return hasher
+		}
+	}
+	return nil
+}
+
+func (p *PasswordPolicy) pepperize(password string) []byte {
+	if len(p.pepper) == 0 {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, p.pepper)
+	mac.Write([]byte(password))
+	sum := mac.Sum(nil)
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+	base64.StdEncoding.Encode(out, sum)
+	return out
+}
+
+var defaultPasswordPolicy atomic.Pointer[PasswordPolicy]
+
+func init() {
+	defaultPasswordPolicy.Store(NewPasswordPolicy(nil, PasswordOptions{}))
+}
+
+// SetDefaultPasswordPolicy 替换 HashPassword/VerifyPassword 使用的策略，返回旧策略；nil 会被忽略
+func SetDefaultPasswordPolicy(policy *PasswordPolicy) *PasswordPolicy {
+	if policy == nil {
+		return defaultPasswordPolicy.Load()
+	}
+	return defaultPasswordPolicy.Swap(policy)
+}
+
+// DefaultPasswordPolicy 返回当前的默认策略
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return defaultPasswordPolicy.Load()
+}
+
+// HashPassword 使用默认策略生成密码哈希
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordPolicy().Hash(password)
+}
+
+// VerifyPassword 使用默认策略校验密码，见 PasswordPolicy.Verify
+func VerifyPassword(password, encoded string) (ok, needsRehash bool, err error) {
+	return DefaultPasswordPolicy().Verify(password, encoded)
+}
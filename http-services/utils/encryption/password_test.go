@@ -0,0 +1,154 @@
+package encryption
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 测试中使用较低的成本参数，避免拖慢测试
+var (
+	fastArgon2 = Argon2idParams{Memory: 1024, Time: 1, Parallelism: 1}
+	fastScrypt = ScryptParams{LogN: 10, R: 8, P: 1}
+)
+
+func TestPasswordHashers_RoundTripInPHCFormat(t *testing.T) {
+	tests := []struct {
+		hasher PasswordHasher
+		prefix string
+	}{
+		{NewArgon2idHasher(fastArgon2), "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{NewScryptHasher(fastScrypt), "$scrypt$ln=10,r=8,p=1$"},
+		{NewBcryptHasher(4), "$2a$04$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hasher.Algorithm(), func(t *testing.T) {
+			encoded, err := tt.hasher.Hash([]byte("correct horse"))
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.prefix) || !tt.hasher.Identifies(encoded) {
+				t.Fatalf("Hash() = %q, want prefix %q", encoded, tt.prefix)
+			}
+			if ok, err := tt.hasher.Verify([]byte("correct horse"), encoded); !ok || err != nil {
+				t.Fatalf("Verify(correct) = %v, %v", ok, err)
+			}
+			if ok, err := tt.hasher.Verify([]byte("wrong horse"), encoded); ok || err != nil {
+				t.Fatalf("Verify(wrong) = %v, %v", ok, err)
+			}
+			if tt.hasher.NeedsRehash(encoded) {
+				t.Fatal("NeedsRehash() = true for a hash with current params")
+			}
+		})
+	}
+}
+
+func TestPasswordHashers_NeedsRehashWhenParamsChange(t *testing.T) {
+	argonHash, _ := NewArgon2idHasher(fastArgon2).Hash([]byte("pw"))
+	if !NewArgon2idHasher(Argon2idParams{Memory: 2048, Time: 1, Parallelism: 1}).NeedsRehash(argonHash) {
+		t.Error("argon2id NeedsRehash() = false after raising memory")
+	}
+	scryptHash, _ := NewScryptHasher(fastScrypt).Hash([]byte("pw"))
+	if !NewScryptHasher(ScryptParams{LogN: 11, R: 8, P: 1}).NeedsRehash(scryptHash) {
+		t.Error("scrypt NeedsRehash() = false after raising N")
+	}
+	bcryptHash, _ := NewBcryptHasher(4).Hash([]byte("pw"))
+	if !NewBcryptHasher(5).NeedsRehash(bcryptHash) {
+		t.Error("bcrypt NeedsRehash() = false after raising cost")
+	}
+}
+
+func TestPasswordHashers_RejectMalformedHashes(t *testing.T) {
+	for _, encoded := range []string{
+		"$argon2id$v=19$m=1024,t=1$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$aGFzaA",
+		"$scrypt$ln=0,r=8,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8,p=1$c2FsdA$",
+	} {
+		ok, _, err := NewPasswordPolicy(nil, PasswordOptions{}).Verify("pw", encoded)
+		if ok || !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("Verify(%q) = %v, %v; want ErrMalformedHash", encoded, ok, err)
+		}
+	}
+	if _, _, err := NewPasswordPolicy(nil, PasswordOptions{}).Verify("pw", "plain-text"); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Errorf("Verify(plain-text) error = %v, want ErrUnknownHashFormat", err)
+	}
+}
+
+func TestPasswordPolicy_UpgradesLegacyBcrypt(t *testing.T) {
+	legacy, err := HashPasswordWithBcrypt("s3cret")
+	if err != nil {
+		t.Fatalf("HashPasswordWithBcrypt() error = %v", err)
+	}
+	policy := NewPasswordPolicy(NewArgon2idHasher(fastArgon2), PasswordOptions{})
+
+	ok, needsRehash, err := policy.Verify("s3cret", legacy)
+	if !ok || !needsRehash || err != nil {
+		t.Fatalf("Verify(legacy bcrypt) = %v, %v, %v; want ok and needs rehash", ok, needsRehash, err)
+	}
+	upgraded, err := policy.Hash("s3cret")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	ok, needsRehash, err = policy.Verify("s3cret", upgraded)
+	if !ok || needsRehash || err != nil {
+		t.Fatalf("Verify(upgraded) = %v, %v, %v; want ok without rehash", ok, needsRehash, err)
+	}
+	if ok, needsRehash, _ := policy.Verify("wrong", legacy); ok || needsRehash {
+		t.Fatalf("Verify(wrong) = %v, %v", ok, needsRehash)
+	}
+}
+
+func TestPasswordPolicy_Pepper(t *testing.T) {
+	plain := NewPasswordPolicy(NewScryptHasher(fastScrypt), PasswordOptions{})
+	peppered := NewPasswordPolicy(NewScryptHasher(fastScrypt), PasswordOptions{Pepper: "pepper-1"})
+	migrating := NewPasswordPolicy(NewScryptHasher(fastScrypt), PasswordOptions{Pepper: "pepper-1", AllowUnpeppered: true})
+
+	old, _ := plain.Hash("pw")
+	if ok, _, _ := peppered.Verify("pw", old); ok {
+		t.Fatal("peppered policy accepted an unpeppered hash without AllowUnpeppered")
+	}
+	if ok, needsRehash, err := migrating.Verify("pw", old); !ok || !needsRehash || err != nil {
+		t.Fatalf("migrating Verify(unpeppered) = %v, %v, %v; want ok and needs rehash", ok, needsRehash, err)
+	}
+
+	fresh, _ := peppered.Hash("pw")
+	if ok, _, _ := plain.Verify("pw", fresh); ok {
+		t.Fatal("hash verified without the pepper")
+	}
+	if ok, needsRehash, err := migrating.Verify("pw", fresh); !ok || needsRehash || err != nil {
+		t.Fatalf("migrating Verify(peppered) = %v, %v, %v", ok, needsRehash, err)
+	}
+	if ok, _, _ := NewPasswordPolicy(NewScryptHasher(fastScrypt), PasswordOptions{Pepper: "pepper-2"}).Verify("pw", fresh); ok {
+		t.Fatal("hash verified with a different pepper")
+	}
+}
+
+func TestPasswordPolicy_PepperLiftsBcryptLengthLimit(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	if _, err := NewBcryptHasher(4).Hash([]byte(long)); err == nil {
+		t.Fatal("bcrypt accepted a password over 72 bytes")
+	}
+	policy := NewPasswordPolicy(NewBcryptHasher(4), PasswordOptions{Pepper: "pepper"})
+	encoded, err := policy.Hash(long)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if ok, _, _ := policy.Verify(long[:99]+"b", encoded); ok {
+		t.Fatal("bcrypt with pepper ignored bytes beyond 72")
+	}
+}
+
+func TestDefaultPasswordPolicy(t *testing.T) {
+	old := SetDefaultPasswordPolicy(NewPasswordPolicy(NewArgon2idHasher(fastArgon2), PasswordOptions{}))
+	t.Cleanup(func() { SetDefaultPasswordPolicy(old) })
+
+	encoded, err := HashPassword("pw")
+	if err != nil || !strings.HasPrefix(encoded, "$argon2id$") {
+		t.Fatalf("HashPassword() = %q, %v", encoded, err)
+	}
+	if ok, needsRehash, err := VerifyPassword("pw", encoded); !ok || needsRehash || err != nil {
+		t.Fatalf("VerifyPassword() = %v, %v, %v", ok, needsRehash, err)
+	}
+}
@@ -0,0 +1,112 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt 默认参数：N=2^15、r=8、p=1，约占用 32 MiB 内存
+const (
+	DefaultScryptLogN = 15
+	DefaultScryptR    = 8
+	DefaultScryptP    = 1
+
+	scryptSaltLen = 16
+	scryptKeyLen  = 32
+)
+
+// ScryptParams 是 scrypt 的成本参数，零值字段使用默认值；N = 2^LogN
+type ScryptParams struct {
+	LogN uint8
+	R    int
+	P    int
+}
+
+// ScryptHasher 生成 PHC 格式的 scrypt 哈希：$scrypt$ln=15,r=8,p=1$<salt>$<hash>
+type ScryptHasher struct {
+	params ScryptParams
+}
+
+// NewScryptHasher 创建 scrypt 哈希器
+func NewScryptHasher(params ScryptParams) *ScryptHasher {
+	if params.LogN == 0 {
+		params.LogN = DefaultScryptLogN
+	}
+	if params.R == 0 {
+		params.R = DefaultScryptR
+	}
+	if params.P == 0 {
+		params.P = DefaultScryptP
+	}
+	return &ScryptHasher{params: params}
+}
+
+// Algorithm 实现 PasswordHasher
+func (h *ScryptHasher) Algorithm() string {
+	return "scrypt"
+}
+
+// Identifies 实现 PasswordHasher
+func (h *ScryptHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$scrypt$")
+}
+
+// Hash 实现 PasswordHasher
+func (h *ScryptHasher) Hash(password []byte) (string, error) {
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key, err := scrypt.Key(password, salt, 1<<h.params.LogN, h.params.R, h.params.P, scryptKeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", h.params.LogN, h.params.R, h.params.P,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 实现 PasswordHasher
+func (h *ScryptHasher) Verify(password []byte, encoded string) (bool, error) {
+	params, salt, key, err := decodeScrypt(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual, err := scrypt.Key(password, salt, 1<<params.LogN, params.R, params.P, len(key))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// NeedsRehash 实现 PasswordHasher
+func (h *ScryptHasher) NeedsRehash(encoded string) bool {
+	params, _, key, err := decodeScrypt(encoded)
+	return err != nil || params != h.params || len(key) != scryptKeyLen
+}
+
+func decodeScrypt(encoded string) (ScryptParams, []byte, []byte, error) {
+	// "", "scrypt", "ln=..,r=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return ScryptParams{}, nil, nil, ErrMalformedHash
+	}
+	var params ScryptParams
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &params.LogN, &params.R, &params.P); err != nil ||
+		params.LogN == 0 || params.LogN > 62 || params.R <= 0 || params.P <= 0 {
+		return ScryptParams{}, nil, nil, fmt.Errorf("%w: scrypt params %q", ErrMalformedHash, parts[2])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return ScryptParams{}, nil, nil, fmt.Errorf("%w: scrypt salt: %v", ErrMalformedHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return ScryptParams{}, nil, nil, fmt.Errorf("%w: scrypt hash", ErrMalformedHash)
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,152 @@
+// Package eventbus 提供进程内按类型发布/订阅的事件总线，用于解耦业务模块
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"http-services/utils/log"
+	"http-services/utils/taskgroup"
+
+	"go.uber.org/zap"
+)
+
+// ErrClosed Close 之后调用 Publish 时返回
+var ErrClosed = errors.New("eventbus: closed")
+
+type subscriber struct {
+	name   string
+	async  bool
+	handle func(ctx context.Context, event any) error
+}
+
+// Bus 按事件的 Go 类型把事件分发给订阅者
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[reflect.Type][]subscriber
+	closed      bool
+	inflight    sync.WaitGroup
+}
+
+// New 创建一个没有订阅者的事件总线
+func New() *Bus {
+	return &Bus{subscribers: make(map[reflect.Type][]subscriber)}
+}
+
+var defaultBus = New()
+
+// Default 返回业务模块共用的进程级事件总线
+func Default() *Bus {
+	return defaultBus
+}
+
+// Subscribe 注册类型 T 的同步订阅者
+// Publish 会等待同步订阅者执行完并返回它们的错误
+func Subscribe[T any](b *Bus, name string, handler func(ctx context.Context, event T) error) {
+	b.add(reflect.TypeFor[T](), subscriber{name: name, handle: wrap(handler)})
+}
+
+// SubscribeAsync 注册类型 T 的异步订阅者，在后台执行
+// 传入的 context 保留发布方的值（包括 trace ID），但不继承其取消；错误与 panic 只记录日志，不返回给发布方
+func SubscribeAsync[T any](b *Bus, name string, handler func(ctx context.Context, event T) error) {
+	b.add(reflect.TypeFor[T](), subscriber{name: name, async: true, handle: wrap(handler)})
+}
+
+// Publish 把事件投递给类型 T 的全部订阅者
+// 同步订阅者并发执行且互相隔离，某个订阅者出错或 panic 不影响其他订阅者，返回值合并了所有同步订阅者的错误
+func Publish[T any](ctx context.Context, b *Bus, event T) error {
+	eventType := reflect.TypeFor[T]()
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	var syncSubs, asyncSubs []subscriber
+	for _, sub := range b.subscribers[eventType] {
+		if sub.async {
+			asyncSubs = append(asyncSubs, sub)
+		} else {
+			syncSubs = append(syncSubs, sub)
+		}
+	}
+	if len(asyncSubs) > 0 {
+		b.inflight.Add(1)
+	}
+	b.mu.RUnlock()
+
+	if len(asyncSubs) > 0 {
+		asyncCtx := context.WithoutCancel(ctx)
+		go func() {
+			defer b.inflight.Done()
+			for index, err := range dispatch(asyncCtx, asyncSubs, event) {
+				if err != nil {
+					log.FromStandardContext(asyncCtx).Error("async event subscriber failed",
+						zap.String("event", eventType.String()),
+						zap.String("subscriber", asyncSubs[index].name),
+						zap.Error(err),
+					)
+				}
+			}
+		}()
+	}
+
+	errs := dispatch(ctx, syncSubs, event)
+	for index, err := range errs {
+		if err != nil {
+			errs[index] = fmt.Errorf("subscriber %q: %w", syncSubs[index].name, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatch 并发执行订阅者，每个订阅者使用独立的 task group：taskgroup 在任务 panic 时会取消同组任务，
+// 共用一个 group 会让 panic 的订阅者取消其他订阅者
+func dispatch(ctx context.Context, subscribers []subscriber, event any) []error {
+	errs := make([]error, len(subscribers))
+	var waitGroup sync.WaitGroup
+	for index, sub := range subscribers {
+		waitGroup.Go(func() {
+			errs[index] = taskgroup.Run(ctx, taskgroup.ContinueOnError(sub.name, func(ctx context.Context) error {
+				return sub.handle(ctx, event)
+			}))[0]
+		})
+	}
+	waitGroup.Wait()
+	return errs
+}
+
+// Close 停止接收新事件并等待正在执行的异步订阅者
+// ctx 结束前仍未执行完时返回 ctx.Err()
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) add(eventType reflect.Type, sub subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[eventType] = append(b.subscribers[eventType], sub)
+}
+
+func wrap[T any](handler func(ctx context.Context, event T) error) func(context.Context, any) error {
+	return func(ctx context.Context, event any) error {
+		return handler(ctx, event.(T))
+	}
+}
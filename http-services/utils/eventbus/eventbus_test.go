@@ -0,0 +1,151 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"http-services/utils/log"
+	"http-services/utils/taskgroup"
+)
+
+type userCreated struct {
+	ID string
+}
+
+type userDeleted struct {
+	ID string
+}
+
+func TestPublish_DispatchesByEventType(t *testing.T) {
+	bus := New()
+	var created, deleted atomic.Int32
+	Subscribe(bus, "created", func(_ context.Context, event userCreated) error {
+		if event.ID != "u1" {
+			t.Errorf("event ID = %q, want u1", event.ID)
+		}
+		created.Add(1)
+		return nil
+	})
+	Subscribe(bus, "deleted", func(context.Context, userDeleted) error {
+		deleted.Add(1)
+		return nil
+	})
+
+	if err := Publish(context.Background(), bus, userCreated{ID: "u1"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if created.Load() != 1 || deleted.Load() != 0 {
+		t.Fatalf("created = %d, deleted = %d", created.Load(), deleted.Load())
+	}
+}
+
+func TestPublish_WithoutSubscribers(t *testing.T) {
+	if err := Publish(context.Background(), New(), userCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+func TestPublish_IsolatesSubscriberFailures(t *testing.T) {
+	bus := New()
+	errBoom := errors.New("boom")
+	var healthyCtxErr atomic.Value
+	Subscribe(bus, "fails", func(context.Context, userCreated) error { return errBoom })
+	Subscribe(bus, "panics", func(context.Context, userCreated) error { panic("bad subscriber") })
+	Subscribe(bus, "healthy", func(ctx context.Context, _ userCreated) error {
+		time.Sleep(10 * time.Millisecond)
+		healthyCtxErr.Store(ctx.Err() == nil)
+		return nil
+	})
+
+	err := Publish(context.Background(), bus, userCreated{})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Publish() error = %v, want %v", err, errBoom)
+	}
+	var panicErr *taskgroup.PanicError
+	if !errors.As(err, &panicErr) || panicErr.TaskName() != "panics" {
+		t.Fatalf("Publish() error = %v, want panic from subscriber panics", err)
+	}
+	if !strings.Contains(err.Error(), `subscriber "fails"`) {
+		t.Fatalf("Publish() error = %q, want subscriber name", err)
+	}
+	if ok, _ := healthyCtxErr.Load().(bool); !ok {
+		t.Fatal("healthy subscriber context was canceled by a failing peer")
+	}
+}
+
+func TestPublish_AsyncSubscriberKeepsTraceIDAfterCancel(t *testing.T) {
+	bus := New()
+	traceIDs := make(chan string, 1)
+	release := make(chan struct{})
+	SubscribeAsync(bus, "async", func(ctx context.Context, _ userCreated) error {
+		<-release
+		if ctx.Err() != nil {
+			t.Errorf("async subscriber context canceled: %v", ctx.Err())
+		}
+		traceID, _ := log.TraceID(ctx)
+		traceIDs <- traceID
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(log.WithTraceID(context.Background(), "trace-1"))
+	if err := Publish(ctx, bus, userCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	cancel()
+	close(release)
+
+	select {
+	case traceID := <-traceIDs:
+		if traceID != "trace-1" {
+			t.Fatalf("trace ID = %q, want trace-1", traceID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("async subscriber was not called")
+	}
+}
+
+func TestPublish_AsyncFailureIsNotReturned(t *testing.T) {
+	bus := New()
+	SubscribeAsync(bus, "async", func(context.Context, userCreated) error { panic("ignored") })
+	if err := Publish(context.Background(), bus, userCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestClose_DrainsAsyncSubscribersAndRejectsPublish(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	var finished atomic.Bool
+	SubscribeAsync(bus, "slow", func(context.Context, userCreated) error {
+		<-release
+		finished.Store(true)
+		return nil
+	})
+	if err := Publish(context.Background(), bus, userCreated{}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("async subscriber did not finish before Close returned")
+	}
+	if err := Publish(context.Background(), bus, userCreated{}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Publish() after Close error = %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,405 @@
+// Package httpserver 在自己持有的监听 socket 上运行 http.Server，支持运行期替换监听地址与 server 参数。
+//
+// http.Server 的超时、MaxHeaderBytes 在 Serve 之后不能安全修改，因此每次参数变化都会创建新的
+// http.Server：监听 socket 上的 accept 循环只有一个，新连接交给最新的 http.Server，旧的 http.Server
+// 调用 Shutdown 处理完已有连接后退出。监听地址变化时先绑定新地址，成功后才关闭旧 socket，
+// 绑定失败则保留原监听继续服务。
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// freshConnGrace 是关闭 http.Server 前等待新连接读到首个请求的上限，与 net/http 在 Shutdown 时
+// 把超过 5 秒仍未发送请求的新连接视为空闲连接保持一致
+const freshConnGrace = 5 * time.Second
+
+// Config 是 http.Server 的监听与连接参数
+type Config struct {
+	Addr           string
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	H2C            bool        // 同时接受不加密的 HTTP/2（prior knowledge，客户端直接发送 HTTP/2 preface）
+	TLSConfig      *tls.Config // 非 nil 时在 accept 的连接上做 TLS 握手；按指针比较，证书轮换应通过 GetCertificate 完成
+}
+
+// Server 管理监听 socket 与当前生效的 http.Server
+type Server struct {
+	handler http.Handler
+
+	mu       sync.Mutex
+	cfg      Config
+	listener net.Listener
+	closed   bool
+	current  atomic.Pointer[generation]
+	accept   sync.WaitGroup
+	draining sync.WaitGroup
+	errCh    chan error
+}
+
+// generation 是某一组参数下的 http.Server 及其连接来源
+type generation struct {
+	srv *http.Server
+	ln  *connListener
+}
+
+// shutdown 等已交付的新连接读到首个请求后再关闭 http.Server。net/http 在 Shutdown 开始后读到的请求
+// 不会处理而是直接断开连接，刚 accept 的连接若赶上切换或退出，客户端会在发出请求后收到 EOF
+func (g *generation) shutdown(ctx context.Context) error {
+	waitCtx, cancel := context.WithTimeout(ctx, freshConnGrace)
+	g.ln.waitFresh(waitCtx)
+	cancel()
+	return g.srv.Shutdown(ctx)
+}
+
+// New 创建 Server，调用 Start 后开始监听
+func New(handler http.Handler, cfg Config) *Server {
+	return &Server{
+		handler: handler,
+		cfg:     cfg,
+		errCh:   make(chan error, 1),
+	}
+}
+
+// Start 绑定 cfg.Addr 并开始服务；绑定失败直接返回错误
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("httpserver: %w", err)
+	}
+	if err := s.StartListener(ln); err != nil {
+		_ = ln.Close()
+		return err
+	}
+	return nil
+}
+
+// StartListener 在已经打开的 ln 上开始服务，用于从父进程继承监听 socket 的场景；
+// ln 应当对应 cfg.Addr，之后的 Reconfigure 仍以 cfg.Addr 判断地址是否变化
+func (s *Server) StartListener(ln net.Listener) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return errors.New("httpserver: already started")
+	}
+	s.listener = ln
+	s.startGeneration(ln.Addr())
+	s.goAccept(ln)
+	return nil
+}
+
+// ListenerFile 返回监听 socket 的 fd 副本，用于交给子进程继续 accept；调用方负责关闭返回的文件
+func (s *Server) ListenerFile() (*os.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.listener == nil {
+		return nil, errors.New("httpserver: not running")
+	}
+	filer, ok := s.listener.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("httpserver: listener %T does not expose a file descriptor", s.listener)
+	}
+	return filer.File()
+}
+
+// Addr 返回实际监听的地址，端口配置为 0 时可以由此得到系统分配的端口
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Errors 返回运行期不可恢复的错误，例如 http.Server 非正常退出
+func (s *Server) Errors() <-chan error {
+	return s.errCh
+}
+
+// Reconfigure 应用新的参数，返回是否发生了变化。Addr 变化时先绑定新地址，失败时保持原监听不变并返回错误；
+// 其余参数变化时在原 socket 上切换到新的 http.Server。旧 http.Server 在后台排空已有连接，
+// 超过 drainTimeout 后强制关闭，drainTimeout <= 0 表示一直等待。
+func (s *Server) Reconfigure(cfg Config, drainTimeout time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.listener == nil {
+		return false, errors.New("httpserver: not running")
+	}
+	if cfg == s.cfg {
+		return false, nil
+	}
+
+	oldListener := s.listener
+	if cfg.Addr != s.cfg.Addr {
+		ln, err := net.Listen("tcp", cfg.Addr)
+		if err != nil {
+			return false, fmt.Errorf("httpserver: %w", err)
+		}
+		s.listener = ln
+	}
+	s.cfg = cfg
+	old := s.startGeneration(s.listener.Addr())
+	if s.listener != oldListener {
+		s.goAccept(s.listener)
+		_ = oldListener.Close()
+	}
+
+	s.draining.Add(1)
+	go func() {
+		defer s.draining.Done()
+		s.drain(old, drainTimeout)
+	}()
+	return true, nil
+}
+
+// drain 等待被替换的 http.Server 处理完已有请求，超时后强制关闭剩余连接
+func (s *Server) drain(old *generation, timeout time.Duration) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := old.shutdown(ctx); err != nil {
+		zap.L().Warn("旧 HTTP server 未能在超时内排空，强制关闭剩余连接", zap.Error(err))
+		_ = old.srv.Close()
+	}
+}
+
+// Shutdown 关闭监听 socket，并等待当前与正在排空的 http.Server 处理完已有请求
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	// 关闭 socket 前已经 accept 的连接仍交给当前 http.Server，由它处理完再退出；
+	// 热升级时 socket 仍由新进程持有，这里丢弃连接就会让客户端看到连接被重置
+	s.accept.Wait()
+	gen := s.current.Swap(nil)
+
+	if gen != nil {
+		if shutdownErr := gen.shutdown(ctx); shutdownErr != nil {
+			return shutdownErr
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.draining.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// startGeneration 以当前参数启动新的 http.Server 并设为当前，返回被替换的旧 generation（需要持有 mu）
+func (s *Server) startGeneration(addr net.Addr) *generation {
+	ln := newConnListener(addr)
+	var protocols *http.Protocols
+	if s.cfg.H2C {
+		protocols = new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	var serveLn net.Listener = ln
+	var tlsConfig *tls.Config
+	if s.cfg.TLSConfig != nil {
+		// 与 ServeTLS 一样声明 ALPN，使 TLS 连接可以协商 HTTP/2
+		tlsConfig = s.cfg.TLSConfig.Clone()
+		if len(tlsConfig.NextProtos) == 0 {
+			tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+		serveLn = tls.NewListener(ln, tlsConfig)
+	}
+	gen := &generation{
+		srv: &http.Server{
+			Addr:           s.cfg.Addr,
+			Handler:        s.handler,
+			ReadTimeout:    s.cfg.ReadTimeout,
+			WriteTimeout:   s.cfg.WriteTimeout,
+			IdleTimeout:    s.cfg.IdleTimeout,
+			MaxHeaderBytes: s.cfg.MaxHeaderBytes,
+			ConnState:      ln.connState,
+			Protocols:      protocols,
+			TLSConfig:      tlsConfig,
+		},
+		ln: ln,
+	}
+	go func() {
+		if err := gen.srv.Serve(serveLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case s.errCh <- err:
+			default:
+			}
+		}
+	}()
+	return s.current.Swap(gen)
+}
+
+func (s *Server) goAccept(ln net.Listener) {
+	s.accept.Add(1)
+	go func() {
+		defer s.accept.Done()
+		s.acceptLoop(ln)
+	}()
+}
+
+// acceptLoop 从 socket 接收连接并交给当前的 http.Server，socket 关闭后退出
+func (s *Server) acceptLoop(ln net.Listener) {
+	var backoff time.Duration
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// 与 http.Server 相同，fd 耗尽等临时错误退避后重试，而不是放弃监听
+			if backoff == 0 {
+				backoff = 5 * time.Millisecond
+			} else {
+				backoff = min(backoff*2, time.Second)
+			}
+			zap.L().Warn("HTTP accept 失败，稍后重试", zap.Duration("backoff", backoff), zap.Error(err))
+			time.Sleep(backoff)
+			continue
+		}
+		backoff = 0
+		s.dispatch(conn)
+	}
+}
+
+// dispatch 把连接交给当前 http.Server；交付过程中恰好发生切换时改交给新的 http.Server
+func (s *Server) dispatch(conn net.Conn) {
+	for {
+		gen := s.current.Load()
+		if gen == nil {
+			_ = conn.Close()
+			return
+		}
+		if gen.ln.deliver(conn) {
+			return
+		}
+	}
+}
+
+// connListener 把 accept 循环交付的连接以 net.Listener 的形式提供给 http.Server，
+// 并记录已交付但还没读到首个请求的连接
+type connListener struct {
+	addr      net.Addr
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	fresh map[net.Conn]struct{}
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+		fresh: make(map[net.Conn]struct{}),
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr { return l.addr }
+
+// deliver 在 listener 关闭后返回 false，由调用方转交给下一个 http.Server
+func (l *connListener) deliver(conn net.Conn) bool {
+	// 交付前登记，避免 http.Server 拿到连接但还没回调 ConnState 时被 waitFresh 漏掉
+	l.setFresh(conn, true)
+	select {
+	case l.conns <- conn:
+		return true
+	case <-l.done:
+		l.setFresh(conn, false)
+		return false
+	}
+}
+
+// connState 作为 http.Server.ConnState，连接离开 StateNew（读到请求、关闭或被劫持）后不再等待它
+func (l *connListener) connState(conn net.Conn, state http.ConnState) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	if state != http.StateNew {
+		l.setFresh(conn, false)
+	}
+}
+
+func (l *connListener) setFresh(conn net.Conn, fresh bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if fresh {
+		l.fresh[conn] = struct{}{}
+	} else {
+		delete(l.fresh, conn)
+	}
+}
+
+// waitFresh 等待所有已交付的连接离开 StateNew，ctx 结束时提前返回
+func (l *connListener) waitFresh(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		l.mu.Lock()
+		n := len(l.fresh)
+		l.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func startTestServer(t *testing.T, handler http.Handler) *Server {
+	t.Helper()
+	server := New(handler, Config{Addr: "127.0.0.1:0"})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+	return server
+}
+
+// freeAddr 返回一个当前空闲的本地地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func get(t *testing.T, addr string) string {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("GET %s error = %v", addr, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestReconfigure_RebindsAndDrainsInFlightRequests(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			started <- struct{}{}
+			<-release
+		}
+		_, _ = io.WriteString(w, "ok")
+	})
+	server := startTestServer(t, handler)
+	oldAddr := server.Addr().String()
+
+	slowDone := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + oldAddr + "/?slow=1")
+		if err != nil {
+			slowDone <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		slowDone <- string(body)
+	}()
+	<-started
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseOnce)
+
+	changed, err := server.Reconfigure(Config{Addr: freeAddr(t), ReadTimeout: time.Second}, 5*time.Second)
+	if err != nil || !changed {
+		t.Fatalf("Reconfigure() = %v, %v, want true, nil", changed, err)
+	}
+	newAddr := server.Addr().String()
+	if newAddr == oldAddr {
+		t.Fatalf("Addr() = %s, want a new listener", newAddr)
+	}
+	if got := get(t, newAddr); got != "ok" {
+		t.Fatalf("GET new addr = %q, want ok", got)
+	}
+	if conn, err := net.DialTimeout("tcp", oldAddr, time.Second); err == nil {
+		conn.Close()
+		t.Fatalf("old listener %s still accepts connections", oldAddr)
+	}
+
+	// 切换前进入的请求由旧 http.Server 处理完
+	releaseOnce()
+	if got := <-slowDone; got != "ok" {
+		t.Fatalf("in-flight request = %q, want ok", got)
+	}
+}
+
+func TestReconfigure_KeepsListenerWhenBindFails(t *testing.T) {
+	server := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	addr := server.Addr().String()
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer occupied.Close()
+
+	changed, err := server.Reconfigure(Config{Addr: occupied.Addr().String()}, time.Second)
+	if err == nil || changed {
+		t.Fatalf("Reconfigure() to occupied addr = %v, %v, want error", changed, err)
+	}
+	if server.Addr().String() != addr {
+		t.Fatalf("Addr() = %s, want unchanged %s", server.Addr(), addr)
+	}
+	if got := get(t, addr); got != "ok" {
+		t.Fatalf("GET after failed rebind = %q, want ok", got)
+	}
+
+	// 同一地址只改超时时，在原 socket 上切换 http.Server
+	changed, err = server.Reconfigure(Config{Addr: "127.0.0.1:0", IdleTimeout: time.Second}, time.Second)
+	if err != nil || !changed {
+		t.Fatalf("Reconfigure() timeouts = %v, %v, want true, nil", changed, err)
+	}
+	if server.Addr().String() != addr {
+		t.Fatalf("Addr() = %s, want unchanged %s", server.Addr(), addr)
+	}
+	if got := get(t, addr); got != "ok" {
+		t.Fatalf("GET after timeout change = %q, want ok", got)
+	}
+}
+
+func TestShutdown_ServesRequestOnConnAcceptedBeforeShutdown(t *testing.T) {
+	server := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	conn, err := net.DialTimeout("tcp", server.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	gen := server.current.Load()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		gen.ln.mu.Lock()
+		n := len(gen.ln.fresh)
+		gen.ln.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection was not delivered to http.Server")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownDone <- server.Shutdown(ctx)
+	}()
+	// 已 accept 的连接在 Shutdown 开始后才发出请求，仍应得到响应而不是被直接断开
+	time.Sleep(50 * time.Millisecond)
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: close\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	if !strings.HasPrefix(string(resp), "HTTP/1.1 200") || !strings.HasSuffix(string(resp), "ok") {
+		t.Fatalf("response = %q, want 200 ok", resp)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestReconfigure_EnablesH2C(t *testing.T) {
+	server := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	addr := server.Addr().String()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	h2c := &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+	if resp, err := h2c.Get("http://" + addr + "/"); err == nil {
+		resp.Body.Close()
+		t.Fatal("HTTP/2 cleartext request succeeded before h2c was enabled")
+	}
+
+	if changed, err := server.Reconfigure(Config{Addr: "127.0.0.1:0", H2C: true}, time.Second); err != nil || !changed {
+		t.Fatalf("Reconfigure() = %v, %v, want true, nil", changed, err)
+	}
+	resp, err := h2c.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("h2c GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "HTTP/2.0" {
+		t.Fatalf("proto = %q, want HTTP/2.0", body)
+	}
+	if got := get(t, addr); got != "HTTP/1.1" {
+		t.Fatalf("HTTP/1.1 request proto = %q, want HTTP/1.1 still served", got)
+	}
+}
+
+func TestStart_ServesTLSAndShutsDownPromptly(t *testing.T) {
+	// 借用 httptest 内置的测试证书（包含 127.0.0.1）
+	issuer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	issuer.StartTLS()
+	cert, leaf := issuer.TLS.Certificates[0], issuer.Certificate()
+	issuer.Close()
+
+	server := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}), Config{Addr: "127.0.0.1:0", TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}}})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}, ForceAttemptHTTP2: true},
+		Timeout:   5 * time.Second,
+	}
+	resp, err := client.Get("https://" + server.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("HTTPS GET error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Fatalf("proto = %q, want HTTP/2.0 negotiated over ALPN", body)
+	}
+
+	// TLS 连接读到请求后不再算作新连接，Shutdown 不需要等待 freshConnGrace
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*freshConnGrace)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= freshConnGrace {
+		t.Fatalf("Shutdown() took %v, want TLS connections tracked past StateNew", elapsed)
+	}
+}
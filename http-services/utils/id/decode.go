@@ -0,0 +1,42 @@
+package id
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sony/sonyflake"
+)
+
+// Parts 是 Sonyflake ID 拆解后的各个部分，用于排查 ID 来源与生成时间
+type Parts struct {
+	ID        uint64    `json:"id"`
+	Time      time.Time `json:"time"`
+	MachineID uint16    `json:"machine_id"`
+	Sequence  uint16    `json:"sequence"`
+}
+
+// Decode 按当前 Epoch 拆解 Sonyflake ID；Epoch 与生成时不同会得到错误的时间
+func Decode(id uint64) Parts {
+	flakeMu.RLock()
+	epoch := options.Epoch
+	flakeMu.RUnlock()
+	return Parts{
+		ID:        id,
+		Time:      epoch.Add(sonyflake.ElapsedTime(id)).UTC(),
+		MachineID: uint16(sonyflake.MachineID(id)),
+		Sequence:  uint16(sonyflake.SequenceNumber(id)),
+	}
+}
+
+// DecodeString 拆解 IssueID 返回的十进制字符串；GenerateID 的 MD5 结果不可逆，无法拆解
+func DecodeString(value string) (Parts, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return Parts{}, fmt.Errorf("id: %q is not a sonyflake id: %w", value, err)
+	}
+	if id>>63 != 0 {
+		return Parts{}, fmt.Errorf("id: %q is not a sonyflake id: most significant bit set", value)
+	}
+	return Decode(id), nil
+}
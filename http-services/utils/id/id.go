@@ -3,30 +3,150 @@ package id
 import (
 	"crypto/md5"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"net"
 	"os"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/sony/sonyflake"
+	"go.uber.org/zap"
 )
 
-var flake *sonyflake.Sonyflake
-var fallbackSeq uint64
+// DefaultEpoch 是 Sonyflake 的默认起始时间；39 位时间戳（10ms 单位）可使用约 174 年
+var DefaultEpoch = time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	// ErrNotInitialized 表示 Sonyflake 生成器不可用
+	ErrNotInitialized = errors.New("id: sonyflake not initialized")
+	// ErrClockMovedBackwards 表示系统时钟回拨超过 Options.MaxClockBackward
+	ErrClockMovedBackwards = errors.New("id: clock moved backwards")
+)
+
+// fallbackLogInterval 限制降级告警频率，时钟回拨或租约丢失期间不会刷屏
+const fallbackLogInterval = time.Second
+
+// clockJitter 是在 MaxClockBackward 之外始终容忍的回拨：并发调用 NextID 时，先读取时间的 goroutine
+// 可能晚于后读取的 goroutine 执行检查，看到的 lastSeen 略大于自己的时间；这不是真正的回拨，
+// 而且小于 Sonyflake 的 10ms 时间单位，沿用上次的时间递增序号即可
+const clockJitter = 10 * time.Millisecond
+
+// Options 配置 Sonyflake 生成器
+type Options struct {
+	// MachineID 返回本实例的 16 位 machine id；为 nil 时依次从私有 IPv4、MAC、主机名 hash 推导
+	MachineID func() (uint16, error)
+	// Epoch 是 ID 时间部分的起点，零值使用 DefaultEpoch；已有数据的系统修改 Epoch 会导致 ID 重复
+	Epoch time.Time
+	// MaxClockBackward 是可容忍的时钟回拨幅度：范围内 Sonyflake 沿用上次的时间继续递增序号，
+	// 超出时 NextID 返回 ErrClockMovedBackwards，直到时钟追上；为 0 时仍容忍并发调用造成的 10ms 以内的抖动
+	MaxClockBackward time.Duration
+	// Guard 在每次生成前调用，返回错误时拒绝生成（如 machine id 租约已失效）
+	Guard func() error
+}
+
+var (
+	flake       *sonyflake.Sonyflake
+	flakeMu     sync.RWMutex
+	options     = Options{Epoch: DefaultEpoch}
+	machineID   uint16
+	fallbackSeq uint64
+
+	lastSeen        atomic.Int64 // 生成过 ID 的最大 wall clock（UnixNano），用于检测回拨
+	fallbackCount   atomic.Uint64
+	lastFallbackLog atomic.Int64
+)
+
+// Init 按 opts 重建 Sonyflake 生成器；失败时保留原生成器并返回错误
+func Init(opts Options) error {
+	if opts.Epoch.IsZero() {
+		opts.Epoch = DefaultEpoch
+	}
+	machineSource := opts.MachineID
+	if machineSource == nil {
+		machineSource = func() (uint16, error) {
+			return resolveMachineID(), nil
+		}
+	}
+	var resolved uint16
+	sf, err := sonyflake.New(sonyflake.Settings{
+		StartTime: opts.Epoch,
+		MachineID: func() (uint16, error) {
+			id, err := machineSource()
+			resolved = id
+			return id, err
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("id: init sonyflake: %w", err)
+	}
+
+	flakeMu.Lock()
+	defer flakeMu.Unlock()
+	flake = sf
+	options = opts
+	machineID = resolved
+	return nil
+}
+
+// MachineID 返回当前生成器使用的 machine id
+func MachineID() uint16 {
+	flakeMu.RLock()
+	defer flakeMu.RUnlock()
+	return machineID
+}
+
+// FallbackCount 返回因 Sonyflake 不可用而降级生成的 ID 数量
+func FallbackCount() uint64 {
+	return fallbackCount.Load()
+}
+
+// NextID 生成 Sonyflake ID；时钟回拨超限、Guard 拒绝或时间位溢出时返回错误
+func NextID() (uint64, error) {
+	flakeMu.RLock()
+	sf, opts := flake, options
+	flakeMu.RUnlock()
+	if sf == nil {
+		return 0, ErrNotInitialized
+	}
+	if opts.Guard != nil {
+		if err := opts.Guard(); err != nil {
+			return 0, err
+		}
+	}
+	if err := checkClock(time.Now(), opts.MaxClockBackward); err != nil {
+		return 0, err
+	}
+	return sf.NextID()
+}
+
+// checkClock 记录见过的最大时间；当前时间落后超过 maxBackward + clockJitter 时返回错误
+func checkClock(now time.Time, maxBackward time.Duration) error {
+	current := now.UnixNano()
+	for {
+		last := lastSeen.Load()
+		if current < last {
+			if backward := time.Duration(last - current); backward > maxBackward+clockJitter {
+				return fmt.Errorf("%w by %v", ErrClockMovedBackwards, backward)
+			}
+			return nil
+		}
+		if lastSeen.CompareAndSwap(last, current) {
+			return nil
+		}
+	}
+}
 
 // IssueID 生成唯一 ID (基于 Sony 改进的 Snowflake 算法)
 // https://github.com/sony/sonyflake
+// Sonyflake 不可用时降级为毫秒时间戳 + 进程内序号，并记录错误日志；需要感知失败的调用方请使用 NextID。
 func IssueID() string {
-	if flake == nil {
-		return fallbackID()
-	}
-
-	next, err := flake.NextID()
+	next, err := NextID()
 	if err != nil {
-		return fallbackID()
+		return fallbackID(err)
 	}
 	return strconv.FormatUint(next, 10)
 }
@@ -46,31 +166,14 @@ func GenerateNumericID() string {
 }
 
 func init() {
-	flake = newSonyflake()
+	// 自动推导的 machine id 不会返回错误，Init 只在系统时间早于 DefaultEpoch 时失败，此时 IssueID 走降级逻辑
+	_ = Init(Options{})
 }
 
 // Sonyflake 默认会从本机私有 IPv4 生成 machine id；
 // 在仅有 loopback/仅 IPv6 等环境下可能返回 nil，进而触发空指针 panic。
 // 这里显式提供 MachineID 计算逻辑，保证初始化与生成 ID 的稳定性。
-func newSonyflake() *sonyflake.Sonyflake {
-	sf, err := sonyflake.New(sonyflake.Settings{
-		MachineID: func() (uint16, error) {
-			return resolveMachineID(), nil
-		},
-	})
-	if err == nil && sf != nil {
-		return sf
-	}
-
-	// 理论上不会走到这里（MachineID 不返回 error），但保留一个不会失败的兜底。
-	sf, _ = sonyflake.New(sonyflake.Settings{
-		MachineID: func() (uint16, error) {
-			return 0, nil
-		},
-	})
-	return sf
-}
-
+// 主机名 hash 在 Kubernetes 等环境中可能碰撞，多副本部署应显式配置 id.machine_id 或使用 Redis 租约。
 func resolveMachineID() uint16 {
 	if id, ok := machineIDFromIPv4(); ok {
 		return id
@@ -178,7 +281,16 @@ func randomUint16() uint16 {
 	return uint16(uint64(time.Now().UnixNano()) & 0xFFFF)
 }
 
-func fallbackID() string {
+func fallbackID(cause error) string {
+	total := fallbackCount.Add(1)
+	now := time.Now().UnixNano()
+	if last := lastFallbackLog.Load(); now-last >= int64(fallbackLogInterval) && lastFallbackLog.CompareAndSwap(last, now) {
+		zap.L().Error("sonyflake unavailable, issuing fallback id",
+			zap.Error(cause),
+			zap.Uint64("fallback_total", total),
+		)
+	}
+
 	nowMs := uint64(time.Now().UnixMilli())
 	seq := atomic.AddUint64(&fallbackSeq, 1) & 0xFFFF
 	next := (nowMs << 16) | seq
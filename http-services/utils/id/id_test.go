@@ -1,8 +1,12 @@
 package id
 
 import (
+	"errors"
 	"regexp"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestIssueID(t *testing.T) {
@@ -129,3 +133,113 @@ func BenchmarkGenerateID(b *testing.B) {
 		GenerateID()
 	}
 }
+
+func useOptions(t *testing.T, opts Options) {
+	t.Helper()
+	flakeMu.RLock()
+	oldFlake, oldOptions, oldMachineID := flake, options, machineID
+	flakeMu.RUnlock()
+	oldLastSeen := lastSeen.Load()
+	t.Cleanup(func() {
+		flakeMu.Lock()
+		flake, options, machineID = oldFlake, oldOptions, oldMachineID
+		flakeMu.Unlock()
+		lastSeen.Store(oldLastSeen)
+	})
+	if err := Init(opts); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+}
+
+func TestInit_ExplicitMachineIDAndEpochRoundTrip(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	useOptions(t, Options{
+		MachineID: func() (uint16, error) { return 4242, nil },
+		Epoch:     epoch,
+	})
+	if MachineID() != 4242 {
+		t.Fatalf("MachineID() = %d, want 4242", MachineID())
+	}
+
+	before := time.Now()
+	value, err := NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+	parts, err := DecodeString(strconv.FormatUint(value, 10))
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	if parts.MachineID != 4242 || parts.ID != value {
+		t.Fatalf("Decode() = %+v", parts)
+	}
+	if drift := parts.Time.Sub(before); drift < -20*time.Millisecond || drift > time.Second {
+		t.Fatalf("decoded time %v, generated around %v", parts.Time, before)
+	}
+
+	if _, err := DecodeString(GenerateID()); err == nil {
+		t.Fatal("DecodeString(md5) error = nil")
+	}
+}
+
+func TestInit_RejectsFailingMachineIDAndFutureEpoch(t *testing.T) {
+	useOptions(t, Options{MachineID: func() (uint16, error) { return 1, nil }})
+
+	if err := Init(Options{MachineID: func() (uint16, error) { return 0, errors.New("lease unavailable") }}); err == nil {
+		t.Fatal("Init() with failing machine id error = nil")
+	}
+	if err := Init(Options{Epoch: time.Now().Add(time.Hour)}); err == nil {
+		t.Fatal("Init() with future epoch error = nil")
+	}
+	if MachineID() != 1 {
+		t.Fatalf("failed Init replaced generator, machine id = %d", MachineID())
+	}
+}
+
+func TestNextID_ClockRollbackAndGuard(t *testing.T) {
+	guardErr := errors.New("lease lost")
+	var guardFails atomic.Bool
+	useOptions(t, Options{
+		MaxClockBackward: time.Second,
+		Guard: func() error {
+			if guardFails.Load() {
+				return guardErr
+			}
+			return nil
+		},
+	})
+
+	lastSeen.Store(time.Now().Add(500 * time.Millisecond).UnixNano())
+	if _, err := NextID(); err != nil {
+		t.Fatalf("NextID() within tolerated rollback error = %v", err)
+	}
+	lastSeen.Store(time.Now().Add(time.Minute).UnixNano())
+	if _, err := NextID(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("NextID() after rollback error = %v, want ErrClockMovedBackwards", err)
+	}
+	before := FallbackCount()
+	if value := IssueID(); value == "" || FallbackCount() != before+1 {
+		t.Fatalf("IssueID() = %q, fallback count %d -> %d", value, before, FallbackCount())
+	}
+
+	lastSeen.Store(0)
+	guardFails.Store(true)
+	if _, err := NextID(); !errors.Is(err, guardErr) {
+		t.Fatalf("NextID() with failing guard error = %v", err)
+	}
+}
+
+func TestCheckClockToleratesConcurrentReaders(t *testing.T) {
+	oldLastSeen := lastSeen.Load()
+	t.Cleanup(func() { lastSeen.Store(oldLastSeen) })
+
+	// 另一个 goroutine 先读取时间却后记录：MaxClockBackward 为 0 时也不应视为回拨
+	now := time.Now()
+	lastSeen.Store(now.Add(time.Millisecond).UnixNano())
+	if err := checkClock(now, 0); err != nil {
+		t.Fatalf("checkClock() with concurrent reader error = %v", err)
+	}
+	if err := checkClock(now.Add(-time.Second), 0); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("checkClock() after rollback error = %v, want ErrClockMovedBackwards", err)
+	}
+}
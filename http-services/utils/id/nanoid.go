@@ -0,0 +1,71 @@
+package id
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// NanoIDAlphabet 是 NanoID 的默认 URL 安全字符集
+	NanoIDAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// NanoIDSize 是默认长度，21 位时碰撞概率与 UUIDv4 相当
+	NanoIDSize = 21
+)
+
+var defaultNanoID = &NanoID{alphabet: []byte(NanoIDAlphabet), size: NanoIDSize, mask: 63}
+
+// NanoID 按固定字符集和长度生成随机 ID，可并发使用
+type NanoID struct {
+	alphabet []byte
+	size     int
+	mask     byte
+}
+
+// NewNanoID 创建自定义字符集的 NanoID 生成器；字符集为 2~256 个互不重复的单字节字符
+func NewNanoID(alphabet string, size int) (*NanoID, error) {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return nil, fmt.Errorf("nanoid alphabet length %d out of range [2, 256]", len(alphabet))
+	}
+	if size <= 0 {
+		return nil, errors.New("nanoid size must be positive")
+	}
+	var seen [256]bool
+	for i := 0; i < len(alphabet); i++ {
+		if seen[alphabet[i]] {
+			return nil, fmt.Errorf("nanoid alphabet has duplicate character %q", alphabet[i])
+		}
+		seen[alphabet[i]] = true
+	}
+	// mask 是能覆盖所有下标的最小 2^n-1，超出字符集的随机字节直接丢弃，避免取模带来的分布偏差
+	mask := byte(1<<bits.Len(uint(len(alphabet)-1)) - 1)
+	return &NanoID{alphabet: []byte(alphabet), size: size, mask: mask}, nil
+}
+
+// Generate 生成一个 NanoID
+func (n *NanoID) Generate() string {
+	out := make([]byte, 0, n.size)
+	// 每轮多取一些随机字节，弥补被丢弃的部分，减少读取次数
+	step := 1 + int(1.6*float64(int(n.mask)*n.size)/float64(len(n.alphabet)))
+	buf := make([]byte, step)
+	for {
+		// crypto/rand.Read 在 Go 1.24+ 不会返回错误
+		_, _ = rand.Read(buf)
+		for _, b := range buf {
+			b &= n.mask
+			if int(b) >= len(n.alphabet) {
+				continue
+			}
+			out = append(out, n.alphabet[b])
+			if len(out) == n.size {
+				return string(out)
+			}
+		}
+	}
+}
+
+// GenerateNanoID 使用默认字符集生成 21 位 URL 安全的 NanoID
+func GenerateNanoID() string {
+	return defaultNanoID.Generate()
+}
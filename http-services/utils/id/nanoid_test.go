@@ -0,0 +1,61 @@
+package id
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateNanoID_UsesURLSafeAlphabet(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		generated := GenerateNanoID()
+		if len(generated) != NanoIDSize {
+			t.Fatalf("GenerateNanoID() = %q, want %d characters", generated, NanoIDSize)
+		}
+		if strings.Trim(generated, NanoIDAlphabet) != "" {
+			t.Fatalf("GenerateNanoID() = %q contains characters outside the alphabet", generated)
+		}
+		if seen[generated] {
+			t.Fatalf("GenerateNanoID() repeated %q", generated)
+		}
+		seen[generated] = true
+	}
+}
+
+func TestNewNanoID_CustomAlphabetCoversAllCharacters(t *testing.T) {
+	gen, err := NewNanoID("abc", 8)
+	if err != nil {
+		t.Fatalf("NewNanoID() error = %v", err)
+	}
+	counts := make(map[rune]int)
+	for range 300 {
+		for _, r := range gen.Generate() {
+			counts[r]++
+		}
+	}
+	if len(counts) != 3 {
+		t.Fatalf("characters used = %v, want exactly a, b and c", counts)
+	}
+	for r, n := range counts {
+		// 2400 个字符均匀分布时每个约 800，取宽松下界即可发现取模偏差之类的问题
+		if n < 600 {
+			t.Fatalf("character %q used %d times, distribution is skewed: %v", r, n, counts)
+		}
+	}
+}
+
+func TestNewNanoID_RejectsInvalidOptions(t *testing.T) {
+	for _, tc := range []struct {
+		alphabet string
+		size     int
+	}{
+		{"a", 10},
+		{"abca", 10},
+		{"abc", 0},
+		{strings.Repeat("x", 257), 10},
+	} {
+		if _, err := NewNanoID(tc.alphabet, tc.size); err == nil {
+			t.Errorf("NewNanoID(%q, %d) succeeded, want error", tc.alphabet, tc.size)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package id
+
+import (
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/segmentio/ksuid"
+)
+
+// GenerateULID 生成 26 位 Crockford base32 的 ULID（48 位毫秒时间 + 80 位随机数）。
+// 同一毫秒内单调递增，字典序即生成顺序，适合作为字符串主键。
+func GenerateULID() string {
+	return ulid.Make().String()
+}
+
+// GenerateLowerULID 生成小写形式的 ULID；MySQL 默认排序规则不区分大小写，小写便于与前缀 ID 保持一致
+func GenerateLowerULID() string {
+	return strings.ToLower(GenerateULID())
+}
+
+// ULIDTime 返回 ULID 中记录的生成时间（毫秒精度），大小写均可
+func ULIDTime(s string) (time.Time, error) {
+	parsed, err := ulid.ParseStrict(strings.ToUpper(s))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.Timestamp(), nil
+}
+
+// GenerateKSUID 生成 27 位 base62 的 KSUID（32 位秒级时间 + 128 位随机数）。
+// KSUID 区分大小写，存入 MySQL 时列需要使用 _bin 排序规则，否则请选择 ULID。
+func GenerateKSUID() string {
+	return ksuid.New().String()
+}
+
+// KSUIDTime 返回 KSUID 中记录的生成时间（秒精度）
+func KSUIDTime(s string) (time.Time, error) {
+	parsed, err := ksuid.Parse(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.Time(), nil
+}
@@ -0,0 +1,45 @@
+package id
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGenerateULID_IsMonotonicAndCarriesTime(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = GenerateULID()
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Fatal("ULIDs generated in sequence are not lexicographically sorted")
+	}
+	if ids[0] == ids[1] || len(ids[0]) != 26 {
+		t.Fatalf("GenerateULID() = %q, %q", ids[0], ids[1])
+	}
+	at, err := ULIDTime(GenerateLowerULID())
+	if err != nil {
+		t.Fatalf("ULIDTime() error = %v", err)
+	}
+	if at.Before(before) || at.After(time.Now()) {
+		t.Fatalf("ULIDTime() = %v, want around %v", at, before)
+	}
+	if _, err := ULIDTime("not-a-ulid"); err == nil {
+		t.Fatal("ULIDTime() accepted invalid input")
+	}
+}
+
+func TestGenerateKSUID_CarriesTime(t *testing.T) {
+	generated := GenerateKSUID()
+	if len(generated) != 27 {
+		t.Fatalf("GenerateKSUID() = %q, want 27 characters", generated)
+	}
+	at, err := KSUIDTime(generated)
+	if err != nil {
+		t.Fatalf("KSUIDTime() error = %v", err)
+	}
+	if d := time.Since(at); d < 0 || d > time.Minute {
+		t.Fatalf("KSUIDTime() = %v, want close to now", at)
+	}
+}
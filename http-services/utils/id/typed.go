@@ -0,0 +1,121 @@
+package id
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrInvalidTypedID 表示字符串不是当前类型的前缀 ID
+var ErrInvalidTypedID = errors.New("id: invalid typed id")
+
+// Prefix 声明一类 ID 的前缀，通常用空结构体实现：
+//
+//	type UserPrefix struct{}
+//
+//	func (UserPrefix) Prefix() string { return "usr" }
+//
+//	type UserID = id.Typed[UserPrefix]
+type Prefix interface {
+	Prefix() string
+}
+
+// Typed 是带类型前缀的字符串 ID，格式为 <prefix>_<小写 ULID>，如 usr_01hx5v3k8m4r2tq9d6b7c0f1ze。
+// 不同前缀是不同的 Go 类型，编译期即可避免把订单 ID 传给用户查询；
+// 实现 sql.Scanner / driver.Valuer 与文本（JSON）编解码，读写时都会校验前缀。零值表示空 ID。
+type Typed[P Prefix] string
+
+// NewTyped 生成一个新的前缀 ID
+func NewTyped[P Prefix]() Typed[P] {
+	return Typed[P](prefixOf[P]() + "_" + GenerateLowerULID())
+}
+
+// ParseTyped 校验并解析前缀 ID，ULID 部分大小写均可，返回值统一为小写
+func ParseTyped[P Prefix](s string) (Typed[P], error) {
+	prefix := prefixOf[P]()
+	body, ok := strings.CutPrefix(s, prefix+"_")
+	if !ok {
+		return "", fmt.Errorf("%w: %q does not start with %q", ErrInvalidTypedID, s, prefix+"_")
+	}
+	if _, err := ulid.ParseStrict(strings.ToUpper(body)); err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrInvalidTypedID, s, err)
+	}
+	return Typed[P](prefix + "_" + strings.ToLower(body)), nil
+}
+
+func prefixOf[P Prefix]() string {
+	var p P
+	return p.Prefix()
+}
+
+// String 返回完整的前缀 ID
+func (t Typed[P]) String() string {
+	return string(t)
+}
+
+// IsZero 报告是否为空 ID
+func (t Typed[P]) IsZero() bool {
+	return t == ""
+}
+
+// Time 返回 ID 的生成时间（毫秒精度）；空 ID 或格式错误时返回零值
+func (t Typed[P]) Time() time.Time {
+	at, err := ULIDTime(string(t)[strings.LastIndexByte(string(t), '_')+1:])
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// Value 实现 driver.Valuer；空 ID 写入 NULL
+func (t Typed[P]) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return string(t), nil
+}
+
+// Scan 实现 sql.Scanner；NULL 读为空 ID
+func (t *Typed[P]) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*t = ""
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidTypedID, src)
+	}
+	parsed, err := ParseTyped[P](s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler，JSON 中编码为字符串
+func (t Typed[P]) MarshalText() ([]byte, error) {
+	return []byte(t), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler；空字符串解码为空 ID，其余必须是合法的前缀 ID
+func (t *Typed[P]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = ""
+		return nil
+	}
+	parsed, err := ParseTyped[P](string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
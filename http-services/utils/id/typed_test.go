@@ -0,0 +1,89 @@
+package id
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type userPrefix struct{}
+
+func (userPrefix) Prefix() string { return "usr" }
+
+type orderPrefix struct{}
+
+func (orderPrefix) Prefix() string { return "ord" }
+
+type userID = Typed[userPrefix]
+
+func TestTyped_NewParseAndTime(t *testing.T) {
+	generated := NewTyped[userPrefix]()
+	if !strings.HasPrefix(generated.String(), "usr_") || len(generated) != len("usr_")+26 {
+		t.Fatalf("NewTyped() = %q", generated)
+	}
+	if generated.String() != strings.ToLower(generated.String()) {
+		t.Fatalf("NewTyped() = %q, want lowercase", generated)
+	}
+	if generated.Time().IsZero() {
+		t.Fatal("Time() is zero for a generated ID")
+	}
+
+	parsed, err := ParseTyped[userPrefix]("usr_" + strings.ToUpper(generated.String()[4:]))
+	if err != nil || parsed != generated {
+		t.Fatalf("ParseTyped() = %q, %v; want %q", parsed, err, generated)
+	}
+	for _, bad := range []string{"", generated.String()[4:], "ord_" + generated.String()[4:], "usr_not-a-ulid"} {
+		if _, err := ParseTyped[userPrefix](bad); !errors.Is(err, ErrInvalidTypedID) {
+			t.Errorf("ParseTyped(%q) error = %v, want ErrInvalidTypedID", bad, err)
+		}
+	}
+}
+
+func TestTyped_SQLRoundTrip(t *testing.T) {
+	generated := NewTyped[userPrefix]()
+	value, err := generated.Value()
+	if err != nil || value != generated.String() {
+		t.Fatalf("Value() = %v, %v", value, err)
+	}
+	if value, _ := userID("").Value(); value != nil {
+		t.Fatalf("zero Value() = %v, want nil", value)
+	}
+
+	var scanned userID
+	if err := scanned.Scan([]byte(generated.String())); err != nil || scanned != generated {
+		t.Fatalf("Scan([]byte) = %q, %v", scanned, err)
+	}
+	if err := scanned.Scan(nil); err != nil || !scanned.IsZero() {
+		t.Fatalf("Scan(nil) = %q, %v", scanned, err)
+	}
+	var order Typed[orderPrefix]
+	if err := order.Scan(generated.String()); !errors.Is(err, ErrInvalidTypedID) {
+		t.Fatalf("Scan() of a user ID into an order ID error = %v", err)
+	}
+	if err := order.Scan(42); !errors.Is(err, ErrInvalidTypedID) {
+		t.Fatalf("Scan(int) error = %v", err)
+	}
+}
+
+func TestTyped_JSONRoundTrip(t *testing.T) {
+	type payload struct {
+		ID      userID  `json:"id"`
+		Manager *userID `json:"manager,omitempty"`
+	}
+	in := payload{ID: NewTyped[userPrefix]()}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"id":"`+in.ID.String()+`"}` {
+		t.Fatalf("Marshal() = %s", data)
+	}
+	var out payload
+	if err := json.Unmarshal(data, &out); err != nil || out.ID != in.ID {
+		t.Fatalf("Unmarshal() = %+v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"ord_01hx5v3k8m4r2tq9d6b7c0f1ze"}`), &out); !errors.Is(err, ErrInvalidTypedID) {
+		t.Fatalf("Unmarshal() of a foreign prefix error = %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Package lifecycle 统一管理常驻组件的启动与停止钩子
+//
+// Stop 按固定阶段执行：撤销就绪状态、等待 pre-stop 延迟、排空监听、停止后台任务，最后关闭存储。
+// 上一阶段结束后才开始下一阶段；阶段内按 Priority 从小到大执行，相同 Priority 的钩子通过 taskgroup 并发执行。
+// 关闭 context 到期后仍会停止每个已启动的钩子，保证存储总能被关闭
+package lifecycle
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"http-services/utils/taskgroup"
+
+	"go.uber.org/zap"
+)
+
+// Phase 是关闭阶段，按声明顺序停止、按相反顺序启动
+type Phase int
+
+const (
+	// PhaseReadiness 撤销就绪状态，让负载均衡停止转发新请求
+	PhaseReadiness Phase = iota
+	// PhasePreStop 等待负载均衡感知就绪状态的变化
+	PhasePreStop
+	// PhaseDrain 关闭监听并等待处理中的请求
+	PhaseDrain
+	// PhaseWorkers 停止后台任务、定时任务与异步订阅者
+	PhaseWorkers
+	// PhaseStores 关闭连接，释放租约与文件
+	PhaseStores
+)
+
+var phaseNames = [...]string{"readiness", "pre-stop", "drain", "workers", "stores"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+	return phaseNames[p]
+}
+
+// Hook 是注册到 Manager 的组件
+type Hook struct {
+	Name     string
+	Phase    Phase
+	Priority int // 同一阶段内 Priority 小的先停止、后启动
+	// Timeout 在传给 Stop 的 context 之外额外限制 OnStop 的耗时，为 0 时不额外限制
+	Timeout time.Duration
+	// OnStart 收到传给 Start 的 context，可以在组件整个生命周期内持有；
+	// OnStart 为 nil 表示注册时组件已经在运行
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+type entry struct {
+	Hook
+	seq     int
+	started bool
+}
+
+// Manager 按阶段与优先级顺序执行钩子，零值不可用，需通过 New 创建
+type Manager struct {
+	mu      sync.Mutex
+	entries []*entry
+	stopped bool
+}
+
+// New 返回没有任何钩子的 Manager
+func New() *Manager {
+	return &Manager{}
+}
+
+// Append 注册钩子，Stop 之后注册的钩子会被忽略
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	m.entries = append(m.entries, &entry{Hook: hook, seq: len(m.entries), started: hook.OnStart == nil})
+}
+
+// Start 执行所有尚未启动的钩子的 OnStart：先启动存储，最后启动监听
+// 遇到第一个错误时停止并返回该错误，出错前已启动的钩子保持启动状态，由 Stop 负责停止
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return errors.New("lifecycle: start after stop")
+	}
+
+	pending := make([]*entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		if !e.started {
+			pending = append(pending, e)
+		}
+	}
+	slices.SortStableFunc(pending, func(a, b *entry) int {
+		return cmp.Or(cmp.Compare(b.Phase, a.Phase), cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.seq, b.seq))
+	})
+	for _, e := range pending {
+		if err := e.OnStart(ctx); err != nil {
+			return fmt.Errorf("lifecycle: start %s: %w", e.Name, err)
+		}
+		e.started = true
+	}
+	return nil
+}
+
+// Stop 按阶段执行已启动组件的停止钩子，返回合并后的全部钩子错误
+// 只有第一次调用会停止组件，之后的调用直接返回 nil
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	var hooks []*entry
+	for _, e := range m.entries {
+		if e.started && e.OnStop != nil {
+			hooks = append(hooks, e)
+		}
+	}
+	m.mu.Unlock()
+
+	slices.SortStableFunc(hooks, func(a, b *entry) int {
+		return cmp.Or(cmp.Compare(a.Phase, b.Phase), cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.seq, b.seq))
+	})
+	var errs []error
+	for start := 0; start < len(hooks); {
+		end := start
+		for end < len(hooks) && hooks[end].Phase == hooks[start].Phase {
+			end++
+		}
+		errs = append(errs, stopPhase(ctx, hooks[start].Phase, hooks[start:end]))
+		start = end
+	}
+	return errors.Join(errs...)
+}
+
+// stopPhase 停止已按优先级排序的钩子，相同优先级的钩子并发执行
+func stopPhase(ctx context.Context, phase Phase, hooks []*entry) error {
+	began := time.Now()
+	zap.L().Info("关闭阶段开始", zap.Stringer("phase", phase), zap.Int("hooks", len(hooks)))
+
+	var errs []error
+	for start := 0; start < len(hooks); {
+		end := start
+		for end < len(hooks) && hooks[end].Priority == hooks[start].Priority {
+			end++
+		}
+		tasks := make([]taskgroup.Task, 0, end-start)
+		for _, e := range hooks[start:end] {
+			// 钩子使用 ctx 而不是 group 的 context：某个钩子出错或超时不能取消同优先级的其他钩子
+			tasks = append(tasks, taskgroup.ContinueOnError(e.Name, func(context.Context) error {
+				return e.stop(ctx)
+			}))
+		}
+		for i, err := range taskgroup.Run(ctx, tasks...) {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", hooks[start+i].Name, err))
+			}
+		}
+		start = end
+	}
+
+	err := errors.Join(errs...)
+	fields := []zap.Field{zap.Stringer("phase", phase), zap.Duration("elapsed", time.Since(began))}
+	if err != nil {
+		zap.L().Warn("关闭阶段结束，部分钩子出错", append(fields, zap.Error(err))...)
+	} else {
+		zap.L().Info("关闭阶段结束", fields...)
+	}
+	return err
+}
+
+// stop 在关闭 context 之外再套用钩子自身的超时
+func (e *entry) stop(ctx context.Context) error {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	return e.OnStop(ctx)
+}
@@ -0,0 +1,127 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string) func(context.Context) error {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, name)
+		return nil
+	}
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+func TestStartAndStopFollowPhaseAndPriority(t *testing.T) {
+	var starts, stops recorder
+	m := New()
+	m.Append(Hook{Name: "redis", Phase: PhaseStores, Priority: 10, OnStop: stops.hook("redis")})
+	m.Append(Hook{Name: "id-lease", Phase: PhaseStores, OnStop: stops.hook("id-lease")})
+	m.Append(Hook{Name: "http", Phase: PhaseDrain, OnStart: starts.hook("http"), OnStop: stops.hook("http")})
+	m.Append(Hook{Name: "queue", Phase: PhaseWorkers, OnStart: starts.hook("queue"), OnStop: stops.hook("queue")})
+	m.Append(Hook{Name: "relay", Phase: PhaseWorkers, Priority: 10, OnStart: starts.hook("relay"), OnStop: stops.hook("relay")})
+	m.Append(Hook{Name: "readiness", Phase: PhaseReadiness, OnStop: stops.hook("readiness")})
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got, want := starts.list(), []string{"relay", "queue", "http"}; !slices.Equal(got, want) {
+		t.Fatalf("start order = %v, want %v", got, want)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if got, want := stops.list(), []string{"readiness", "http", "queue", "relay", "id-lease", "redis"}; !slices.Equal(got, want) {
+		t.Fatalf("stop order = %v, want %v", got, want)
+	}
+	if err := m.Stop(context.Background()); err != nil || len(stops.list()) != 6 {
+		t.Fatalf("second Stop() = %v, calls = %v; want no-op", err, stops.list())
+	}
+}
+
+func TestStopSkipsHooksThatFailedToStart(t *testing.T) {
+	var stops recorder
+	startErr := errors.New("relay unavailable")
+	m := New()
+	m.Append(Hook{Name: "mysql", Phase: PhaseStores, OnStop: stops.hook("mysql")})
+	m.Append(Hook{Name: "relay", Phase: PhaseWorkers, OnStart: func(context.Context) error { return startErr }, OnStop: stops.hook("relay")})
+	m.Append(Hook{Name: "http", Phase: PhaseDrain, OnStart: func(context.Context) error { return nil }, OnStop: stops.hook("http")})
+
+	if err := m.Start(context.Background()); !errors.Is(err, startErr) {
+		t.Fatalf("Start() error = %v, want %v", err, startErr)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if got, want := stops.list(), []string{"mysql"}; !slices.Equal(got, want) {
+		t.Fatalf("stopped = %v, want %v", got, want)
+	}
+}
+
+func TestStopAggregatesErrorsAndClosesStoresAfterDeadline(t *testing.T) {
+	var stops recorder
+	workerErr := errors.New("worker failed")
+	m := New()
+	m.Append(Hook{Name: "http", Phase: PhaseDrain, OnStop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	m.Append(Hook{Name: "queue", Phase: PhaseWorkers, OnStop: func(context.Context) error { return workerErr }})
+	// a failing hook must not cancel the others sharing its priority
+	m.Append(Hook{Name: "relay", Phase: PhaseWorkers, OnStop: func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return stops.hook("relay")(ctx)
+	}})
+	m.Append(Hook{Name: "mysql", Phase: PhaseStores, OnStop: stops.hook("mysql")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := m.Stop(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, workerErr) {
+		t.Fatalf("Stop() error = %v, want deadline and worker errors", err)
+	}
+	if got, want := stops.list(), []string{"relay", "mysql"}; !slices.Equal(got, want) {
+		t.Fatalf("stopped = %v, want %v", got, want)
+	}
+}
+
+func TestHookTimeoutBoundsOnlyThatHook(t *testing.T) {
+	m := New()
+	m.Append(Hook{Name: "slow", Phase: PhaseWorkers, Timeout: 20 * time.Millisecond, OnStop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	var storeCtxErr error
+	m.Append(Hook{Name: "store", Phase: PhaseStores, OnStop: func(ctx context.Context) error {
+		storeCtxErr = ctx.Err()
+		return nil
+	}})
+
+	begin := time.Now()
+	if err := m.Stop(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("Stop() took %v, want the hook timeout to apply", elapsed)
+	}
+	if storeCtxErr != nil {
+		t.Fatalf("store hook ctx error = %v, want live context", storeCtxErr)
+	}
+}
@@ -0,0 +1,175 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAsyncBufferSize = 8192
+	asyncSyncTimeout       = 5 * time.Second
+	asyncReportInterval    = 30 * time.Second
+)
+
+// asyncEntry 是一条已编码的日志及其入队时间（loki 推送需要时间戳）
+type asyncEntry struct {
+	at   time.Time
+	data []byte
+}
+
+// asyncWriter 是带界缓冲的异步 WriteSyncer：Write 只入队，缓冲区满时直接丢弃并计数，
+// 后台 goroutine 按批次交给 handle 输出，慢输出（远端、磁盘抖动）不会阻塞业务请求。
+type asyncWriter struct {
+	name      string
+	entries   chan asyncEntry
+	flushReq  chan chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+
+	batchSize int
+	interval  time.Duration
+	handle    func(batch []asyncEntry) error
+
+	dropped atomic.Uint64 // 缓冲区满被丢弃的条数
+	failed  atomic.Uint64 // handle 返回错误的批次内条数
+}
+
+func newAsyncWriter(name string, bufferSize, batchSize int, interval time.Duration, handle func([]asyncEntry) error) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &asyncWriter{
+		name:      name,
+		entries:   make(chan asyncEntry, bufferSize),
+		flushReq:  make(chan chan struct{}),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		batchSize: batchSize,
+		interval:  interval,
+		handle:    handle,
+	}
+	go w.run()
+	return w
+}
+
+// Write 复制 p 后入队；zap 会复用 p 的底层缓冲，不能直接持有
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.done:
+		w.dropped.Add(1)
+		return len(p), nil
+	default:
+	}
+	entry := asyncEntry{at: time.Now(), data: append([]byte(nil), p...)}
+	select {
+	case w.entries <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 等待当前已入队的日志输出完成，最长等待 asyncSyncTimeout
+func (w *asyncWriter) Sync() error {
+	req := make(chan struct{})
+	select {
+	case w.flushReq <- req:
+	case <-w.stopped:
+		return nil
+	}
+	timer := time.NewTimer(asyncSyncTimeout)
+	defer timer.Stop()
+	select {
+	case <-req:
+	case <-timer.C:
+	}
+	return nil
+}
+
+// Close 输出剩余日志后停止后台 goroutine
+func (w *asyncWriter) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	<-w.stopped
+	return nil
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+
+	flushTicker := time.NewTicker(w.interval)
+	defer flushTicker.Stop()
+	reportTicker := time.NewTicker(asyncReportInterval)
+	defer reportTicker.Stop()
+
+	batch := make([]asyncEntry, 0, w.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.handle(batch); err != nil {
+			w.failed.Add(uint64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+	drain := func() {
+		for {
+			select {
+			case entry := <-w.entries:
+				batch = append(batch, entry)
+				if len(batch) >= w.batchSize {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	var reportedDropped, reportedFailed uint64
+	for {
+		select {
+		case entry := <-w.entries:
+			batch = append(batch, entry)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		case req := <-w.flushReq:
+			drain()
+			close(req)
+		case <-reportTicker.C:
+			reportedDropped, reportedFailed = w.report(reportedDropped, reportedFailed)
+		case <-w.done:
+			drain()
+			w.report(reportedDropped, reportedFailed)
+			return
+		}
+	}
+}
+
+// report 在丢弃或失败计数增长时记录告警；告警本身也可能因缓冲区满被丢弃，累计值可通过 SinkStats 查询
+func (w *asyncWriter) report(lastDropped, lastFailed uint64) (uint64, uint64) {
+	dropped, failed := w.dropped.Load(), w.failed.Load()
+	if dropped > lastDropped || failed > lastFailed {
+		zap.L().Warn("log sink lost entries",
+			zap.String("sink", w.name),
+			zap.Uint64("dropped", dropped-lastDropped),
+			zap.Uint64("failed", failed-lastFailed),
+			zap.Uint64("dropped_total", dropped),
+			zap.Uint64("failed_total", failed),
+		)
+	}
+	return dropped, failed
+}
@@ -0,0 +1,272 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 可以单独调整日志级别的模块
+const (
+	ModuleApp   = "app"   // GetLogger / zap.L() 返回的业务日志
+	ModuleGin   = "gin"   // gin 访问日志与错误日志
+	ModuleGorm  = "gorm"  // SQL 追踪、慢查询与 gorm 错误
+	ModuleRedis = "redis" // go-redis 客户端日志与命令追踪
+	ModuleCron  = "cron"  // 定时任务
+)
+
+// ErrUnknownModule 调整级别时指定的模块不存在
+var ErrUnknownModule = errors.New("log: unknown module")
+
+// moduleLevel 保存单个模块当前生效的级别与配置中的级别
+type moduleLevel struct {
+	atomic     zap.AtomicLevel
+	configured zapcore.Level
+	override   bool
+	revertAt   time.Time
+	revert     *time.Timer
+}
+
+var (
+	levelsMu     sync.Mutex
+	moduleLevels = map[string]*moduleLevel{}
+)
+
+func init() {
+	for _, module := range Modules() {
+		moduleLevels[module] = &moduleLevel{atomic: zap.NewAtomicLevel(), configured: zapcore.InfoLevel}
+	}
+}
+
+// Modules 返回所有可调整级别的模块名
+func Modules() []string {
+	return []string{ModuleApp, ModuleGin, ModuleGorm, ModuleRedis, ModuleCron}
+}
+
+// ModuleLevel 描述模块当前的级别状态
+type ModuleLevel struct {
+	Module     string     `json:"module"`
+	Level      string     `json:"level"`
+	Configured string     `json:"configured"`
+	Override   bool       `json:"override"`
+	RevertAt   *time.Time `json:"revert_at,omitempty"`
+}
+
+// Levels 返回每个模块的级别状态
+func Levels() []ModuleLevel {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	result := make([]ModuleLevel, 0, len(moduleLevels))
+	for _, module := range Modules() {
+		state := moduleLevels[module]
+		item := ModuleLevel{
+			Module:     module,
+			Level:      state.atomic.Level().String(),
+			Configured: state.configured.String(),
+			Override:   state.override,
+		}
+		if state.override && !state.revertAt.IsZero() {
+			revertAt := state.revertAt
+			item.RevertAt = &revertAt
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// SetLevel 运行时覆盖模块的日志级别，revertAfter > 0 时到期后恢复为配置中的级别
+func SetLevel(module string, level zapcore.Level, revertAfter time.Duration) error {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	state, ok := moduleLevels[module]
+	if !ok {
+		return ErrUnknownModule
+	}
+	setOverrideLocked(module, state, level, revertAfter)
+	return nil
+}
+
+// ShiftLevels 把所有模块的级别移动 delta 档（负数更详细），限制在 debug 到 error 之间，
+// revertAfter > 0 时到期后恢复
+func ShiftLevels(delta int, revertAfter time.Duration) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	for module, state := range moduleLevels {
+		next := state.atomic.Level() + zapcore.Level(delta)
+		next = min(max(next, zapcore.DebugLevel), zapcore.ErrorLevel)
+		setOverrideLocked(module, state, next, revertAfter)
+	}
+}
+
+// ResetLevel 撤销模块的运行时覆盖，恢复为配置中的级别
+func ResetLevel(module string) error {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	state, ok := moduleLevels[module]
+	if !ok {
+		return ErrUnknownModule
+	}
+	resetLocked(state)
+	return nil
+}
+
+// ResetLevels 把所有模块恢复为配置中的级别
+func ResetLevels() {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	for _, state := range moduleLevels {
+		resetLocked(state)
+	}
+}
+
+// ErrInvalidLevel ParseLevel 遇到 debug、info、warn、error 以外的级别名
+var ErrInvalidLevel = errors.New("log: level must be one of debug, info, warn, error")
+
+// ParseLevel 严格解析级别名，与配置解析不同，无法识别时不会回退为 info
+func ParseLevel(text string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn", "warning":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, ErrInvalidLevel
+	}
+}
+
+func setOverrideLocked(module string, state *moduleLevel, level zapcore.Level, revertAfter time.Duration) {
+	if state.revert != nil {
+		state.revert.Stop()
+		state.revert = nil
+	}
+	state.override = true
+	state.revertAt = time.Time{}
+	state.atomic.SetLevel(level)
+	if revertAfter > 0 {
+		state.revertAt = time.Now().Add(revertAfter)
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			levelsMu.Lock()
+			defer levelsMu.Unlock()
+			// 计时期间可能已被新的覆盖替换（Stop 与回调触发存在竞争），只撤销自己设置的那一次
+			if state.revert != timer {
+				return
+			}
+			resetLocked(state)
+			zap.L().Info("log level override expired", zap.String("module", module), zap.Stringer("level", state.configured))
+		})
+		state.revert = timer
+	}
+}
+
+func resetLocked(state *moduleLevel) {
+	if state.revert != nil {
+		state.revert.Stop()
+		state.revert = nil
+	}
+	state.override = false
+	state.revertAt = time.Time{}
+	state.atomic.SetLevel(state.configured)
+}
+
+// applyConfiguredLevels 从配置读取各模块的级别，存在运行时覆盖的模块保持覆盖，
+// 直到被重置或到期
+func applyConfiguredLevels() {
+	logConfig := config.Current().Log
+	appLevel := parseLogLevel(logConfig.Level)
+	configured := map[string]zapcore.Level{ModuleApp: appLevel}
+	for module, text := range map[string]string{
+		ModuleGin:   logConfig.GinLevel,
+		ModuleGorm:  logConfig.GormLevel,
+		ModuleRedis: logConfig.RedisLevel,
+		ModuleCron:  logConfig.CronLevel,
+	} {
+		configured[module] = appLevel
+		if strings.TrimSpace(text) != "" {
+			configured[module] = parseLogLevel(text)
+		}
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	for module, level := range configured {
+		state := moduleLevels[module]
+		state.configured = level
+		if !state.override {
+			state.atomic.SetLevel(level)
+		}
+	}
+}
+
+// levelOf 返回模块的 AtomicLevel，未知模块使用 app 的级别
+func levelOf(module string) zap.AtomicLevel {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	if state, ok := moduleLevels[module]; ok {
+		return state.atomic
+	}
+	return moduleLevels[ModuleApp].atomic
+}
+
+// leveledCore 先按模块级别过滤日志，再交给以 debug 级别构建的底层 core
+type leveledCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func withModuleLevel(l *zap.Logger, module string) *zap.Logger {
+	level := levelOf(module)
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &leveledCore{Core: core, level: level}
+	}))
+}
+
+func (c *leveledCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &leveledCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *leveledCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// Named 返回写入业务日志、使用模块自身级别的 logger
+func Named(module string) *zap.Logger {
+	mu.RLock()
+	l, ok := namedLoggers[module]
+	mu.RUnlock()
+	if ok {
+		return l
+	}
+
+	GetLogger()
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := namedLoggers[module]; ok {
+		return l
+	}
+	l = withModuleLevel(baseLogger, module).Named(module)
+	namedLoggers[module] = l
+	return l
+}
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func useLevelConfig(t *testing.T, appLevel, gormLevel string) {
+	t.Helper()
+	oldRunModel := config.RunModel
+	oldConfig := config.Current()
+	t.Cleanup(func() {
+		config.RunModel = oldRunModel
+		config.Replace(oldConfig)
+		ResetLevels()
+		SetLogger()
+	})
+
+	config.RunModel = config.RunModelDevValue
+	config.Update(func(c *config.Config) {
+		c.Log.Level = appLevel
+		c.Log.GinLevel = ""
+		c.Log.GormLevel = gormLevel
+		c.Log.RedisLevel = ""
+		c.Log.CronLevel = ""
+	})
+	ResetLevels()
+	SetLogger()
+}
+
+func levelState(t *testing.T, module string) ModuleLevel {
+	t.Helper()
+	for _, item := range Levels() {
+		if item.Module == module {
+			return item
+		}
+	}
+	t.Fatalf("module %q missing from Levels()", module)
+	return ModuleLevel{}
+}
+
+func TestNamed_UsesConfiguredModuleLevel(t *testing.T) {
+	useLevelConfig(t, "warn", "debug")
+
+	if ce := Named(ModuleGorm).Check(zap.DebugLevel, "sql"); ce == nil {
+		t.Fatal("expected gorm logger to allow debug level")
+	}
+	if ce := Named(ModuleRedis).Check(zap.InfoLevel, "redis"); ce != nil {
+		t.Fatal("expected redis logger to follow app level when its level is empty")
+	}
+	if ce := GetLogger().Check(zap.InfoLevel, "business info"); ce != nil {
+		t.Fatal("expected business logger to suppress info level")
+	}
+}
+
+func TestSetLevel_AppliesLiveAndSurvivesReload(t *testing.T) {
+	useLevelConfig(t, "info", "")
+	appLogger := GetLogger()
+
+	if err := SetLevel(ModuleApp, zapcore.DebugLevel, 0); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if ce := appLogger.Check(zap.DebugLevel, "debug"); ce == nil {
+		t.Fatal("expected existing logger to pick up the new level without rebuilding")
+	}
+	if ce := Named(ModuleGorm).Check(zap.DebugLevel, "sql"); ce != nil {
+		t.Fatal("expected app override to leave gorm level untouched")
+	}
+
+	SetLogger()
+	if state := levelState(t, ModuleApp); state.Level != "debug" || !state.Override || state.Configured != "info" {
+		t.Fatalf("app level after reload = %+v, want debug override of info", state)
+	}
+
+	if err := ResetLevel(ModuleApp); err != nil {
+		t.Fatalf("ResetLevel() error = %v", err)
+	}
+	if ce := GetLogger().Check(zap.DebugLevel, "debug"); ce != nil {
+		t.Fatal("expected reset to restore configured info level")
+	}
+}
+
+func TestSetLevel_RevertsAfterDuration(t *testing.T) {
+	useLevelConfig(t, "info", "")
+
+	if err := SetLevel(ModuleGorm, zapcore.DebugLevel, 20*time.Millisecond); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if state := levelState(t, ModuleGorm); state.RevertAt == nil {
+		t.Fatal("expected revert_at to be reported")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for levelState(t, ModuleGorm).Override {
+		if time.Now().After(deadline) {
+			t.Fatal("override was not reverted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if state := levelState(t, ModuleGorm); state.Level != "info" {
+		t.Fatalf("gorm level after revert = %s, want info", state.Level)
+	}
+}
+
+func TestSetLevel_NewOverrideCancelsPendingRevert(t *testing.T) {
+	useLevelConfig(t, "info", "")
+
+	if err := SetLevel(ModuleRedis, zapcore.DebugLevel, 10*time.Millisecond); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if err := SetLevel(ModuleRedis, zapcore.WarnLevel, 0); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if state := levelState(t, ModuleRedis); state.Level != "warn" || !state.Override {
+		t.Fatalf("redis level = %+v, want permanent warn override", state)
+	}
+}
+
+func TestShiftLevels_ClampsToSupportedRange(t *testing.T) {
+	useLevelConfig(t, "debug", "error")
+
+	ShiftLevels(-1, 0)
+	if state := levelState(t, ModuleApp); state.Level != "debug" {
+		t.Fatalf("app level = %s, want debug clamp", state.Level)
+	}
+	if state := levelState(t, ModuleGorm); state.Level != "warn" {
+		t.Fatalf("gorm level = %s, want warn", state.Level)
+	}
+
+	ShiftLevels(2, 0)
+	if state := levelState(t, ModuleGorm); state.Level != "error" {
+		t.Fatalf("gorm level = %s, want error clamp", state.Level)
+	}
+}
+
+func TestSetLevel_RejectsUnknownModule(t *testing.T) {
+	if err := SetLevel("kafka", zapcore.DebugLevel, 0); !errors.Is(err, ErrUnknownModule) {
+		t.Fatalf("SetLevel() error = %v, want %v", err, ErrUnknownModule)
+	}
+	if err := ResetLevel("kafka"); !errors.Is(err, ErrUnknownModule) {
+		t.Fatalf("ResetLevel() error = %v, want %v", err, ErrUnknownModule)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{"debug", zapcore.DebugLevel, false},
+		{" WARNING ", zapcore.WarnLevel, false},
+		{"error", zapcore.ErrorLevel, false},
+		{"fatal", zapcore.InfoLevel, true},
+		{"", zapcore.InfoLevel, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.input, got, err)
+		}
+	}
+}
@@ -14,90 +14,51 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
 	mu sync.RWMutex
 
-	logger   *zap.Logger
-	loggerLJ *lumberjack.Logger
+	logger       *zap.Logger
+	currentSinks *sinkSet
+
+	baseLogger   *zap.Logger            // 不带模块级别过滤的业务 logger，Named 基于它派生
+	namedLoggers map[string]*zap.Logger // Named 的缓存，SetLogger 重建时清空
 
 	ginLogger      *zap.Logger
 	ginErrorLogger *zap.Logger
-	ginLoggerLJ    *lumberjack.Logger
 
 	monitorDone chan struct{} // 用于停止监控 goroutine
-	rotateDone  chan struct{} // 用于停止按天 Rotate goroutine
+	rotateDone  chan struct{} // 用于停止定时 Rotate goroutine
 )
 
-// Creating Dev logger
-// DEV mode outputs logs to the terminal and is more readable
-func createDevLogger(level zapcore.Level) *zap.Logger {
-	encoder := zap.NewDevelopmentEncoderConfig()
-	core := zapcore.NewTee(
-		zapcore.NewSamplerWithOptions(
-			zapcore.NewCore(zapcore.NewConsoleEncoder(encoder), os.Stdout, level), time.Second, 4, 1),
-	)
-	return zap.New(core, zap.AddCaller())
-}
-
-// Creating product logger
-// The product pattern outputs logs to a file and is architecturally structured, in json format.
-func createProductLogger(fileName string, level zapcore.Level) (*zap.Logger, *lumberjack.Logger) {
-	fileEncoder := zap.NewProductionEncoderConfig()
-	fileEncoder.EncodeTime = zapcore.ISO8601TimeEncoder
-	lj := &lumberjack.Logger{
-		Filename: fileName,
-		MaxSize:  config.LogMaxSize,
-		// 不限制备份文件数量：只按 max_age 做清理（并保留 max_size 兜底轮转）。
-		MaxBackups: 0,
-		MaxAge:     config.LogMaxAge,
-		LocalTime:  true,
-	}
-	fileWriter := zapcore.AddSync(lj)
-	core := zapcore.NewTee(
-		zapcore.NewSamplerWithOptions(
-			zapcore.NewCore(zapcore.NewJSONEncoder(fileEncoder), fileWriter, level), time.Second, 4, 1),
-	)
-	return zap.New(core, zap.AddCaller()), lj
-}
-
 // SetLogger to prevent zap persistence problems after files are deleted
+// 输出由 log.sinks 决定（未配置时 dev 输出控制台、release 写文件）；底层 core 不设模块级别，
+// 实际级别由各模块的 AtomicLevel 过滤，运行期调整无需重建 logger。
 func SetLogger() {
-	mu.Lock()
-	defer mu.Unlock()
-	businessLevel := parseLogLevel(config.LogLevel)
-	ginLevel := businessLevel
-	if strings.TrimSpace(config.GinLogLevel) != "" {
-		ginLevel = parseLogLevel(config.GinLogLevel)
-	}
+	applyConfiguredLevels()
+	sinks, errs := buildSinks(config.Current().Log.Sinks, !runmodel.IsRelease())
 
-	// Get log mode
-	switch {
-	case runmodel.IsDev():
-		logger = createDevLogger(businessLevel)
-		loggerLJ = nil
-
-		ginLogger = createDevLogger(ginLevel).With(zap.String("logger", "gin"))
-		ginErrorLogger = ginLogger.With(zap.String("stream", "stderr"))
-		ginLoggerLJ = nil
-	case runmodel.IsRelease():
-		logger, loggerLJ = createProductLogger(config.LogPath, businessLevel)
-
-		ginLogger, ginLoggerLJ = createProductLogger(ginLogPath(), ginLevel)
-		ginLogger = ginLogger.With(zap.String("logger", "gin"))
-		ginErrorLogger = ginLogger.With(zap.String("stream", "stderr"))
-	default:
-		// 默认视作开发模式，避免测试/包初始化阶段创建文件与目录
-		logger = createDevLogger(businessLevel)
-		loggerLJ = nil
+	mu.Lock()
+	previous := currentSinks
+	currentSinks = sinks
+
+	baseLogger = zap.New(sinks.core(streamApp), zap.AddCaller())
+	logger = withModuleLevel(baseLogger, ModuleApp)
+	ginLogger = withModuleLevel(zap.New(sinks.core(streamGin), zap.AddCaller()), ModuleGin).
+		With(zap.String("logger", "gin"))
+	ginErrorLogger = ginLogger.With(zap.String("stream", "stderr"))
+	namedLoggers = make(map[string]*zap.Logger)
+	zap.ReplaceGlobals(logger)
+	mu.Unlock()
 
-		ginLogger = createDevLogger(ginLevel).With(zap.String("logger", "gin"))
-		ginErrorLogger = ginLogger.With(zap.String("stream", "stderr"))
-		ginLoggerLJ = nil
+	for _, err := range errs {
+		logger.Error("log sink unavailable", zap.Error(err))
+	}
+	if previous != nil {
+		// 旧输出可能仍被缓存的 logger 引用，后台刷新后关闭，之后的写入会被丢弃
+		go previous.close()
 	}
-	zap.ReplaceGlobals(logger)
 }
 
 // Listen to log files
@@ -266,7 +227,7 @@ func StartMonitor() {
 		mu.Unlock()
 
 		go monitorFile(monitorDone)
-		go rotateDaily(rotateDone)
+		go rotateScheduled(rotateDone)
 	}
 }
 
@@ -298,49 +259,68 @@ func StopMonitor() {
 	}
 }
 
-func rotateDaily(done <-chan struct{}) {
+// rotateScheduled 按 log.rotation 定时轮转（daily 每天 00:00、hourly 每个整点、none 只按大小轮转），
+// 同时定期检查日志目录的磁盘预算；每轮重新读取配置，热重载后下一次等待即生效。
+func rotateScheduled(done <-chan struct{}) {
 	if done == nil {
 		return
 	}
 
+	budgetTicker := time.NewTicker(diskBudgetInterval)
+	defer budgetTicker.Stop()
 	for {
-		now := time.Now()
-		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-		wait := time.Until(next)
-		if wait <= 0 {
-			wait = time.Second
+		var rotateC <-chan time.Time
+		var timer *time.Timer
+		if next := nextRotation(time.Now(), config.Current().Log.Rotation); !next.IsZero() {
+			wait := time.Until(next)
+			if wait <= 0 {
+				wait = time.Second
+			}
+			timer = time.NewTimer(wait)
+			rotateC = timer.C
 		}
 
-		t := time.NewTimer(wait)
 		select {
-		case <-t.C:
+		case <-rotateC:
 			rotateAll()
+			enforceDiskBudget()
+		case <-budgetTicker.C:
+			enforceDiskBudget()
 		case <-done:
-			if !t.Stop() {
-				select {
-				case <-t.C:
-				default:
-				}
+			if timer != nil {
+				timer.Stop()
 			}
 			return
 		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// nextRotation 返回 now 之后的下一个轮转时间点（本地时间）；schedule 为 none 时返回零值
+func nextRotation(now time.Time, schedule string) time.Time {
+	switch schedule {
+	case "none":
+		return time.Time{}
+	case "hourly":
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+	default:
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
 	}
 }
 
 func rotateAll() {
 	mu.RLock()
-	blj := loggerLJ
-	glj := ginLoggerLJ
+	sinks := currentSinks
 	mu.RUnlock()
-
-	if blj != nil {
-		if err := blj.Rotate(); err != nil {
-			zap.L().Warn("rotate business log failed", zap.Error(err))
-		}
+	if sinks == nil {
+		return
 	}
-	if glj != nil {
-		if err := glj.Rotate(); err != nil {
-			zap.L().Warn("rotate gin log failed", zap.Error(err))
+
+	for _, lj := range sinks.lumberjacks() {
+		if err := lj.Rotate(); err != nil {
+			zap.L().Warn("rotate log file failed", zap.String("path", lj.Filename), zap.Error(err))
 		}
 	}
 }
@@ -18,19 +18,13 @@ func TestSetLogger_UsesIndependentGinLogLevel(t *testing.T) {
 	oldLogDir := config.LogDir
 	oldLogPath := config.LogPath
 	oldSelfName := config.SelfName
-	oldLogMaxSize := config.LogMaxSize
-	oldLogMaxAge := config.LogMaxAge
-	oldLogLevel := config.LogLevel
-	oldGinLogLevel := config.GinLogLevel
+	oldConfig := config.Current()
 	t.Cleanup(func() {
 		config.RunModel = oldRunModel
 		config.LogDir = oldLogDir
 		config.LogPath = oldLogPath
 		config.SelfName = oldSelfName
-		config.LogMaxSize = oldLogMaxSize
-		config.LogMaxAge = oldLogMaxAge
-		config.LogLevel = oldLogLevel
-		config.GinLogLevel = oldGinLogLevel
+		config.Replace(oldConfig)
 		SetLogger()
 	})
 
@@ -38,10 +32,12 @@ func TestSetLogger_UsesIndependentGinLogLevel(t *testing.T) {
 	config.LogDir = tempDir
 	config.SelfName = "http-services-test"
 	config.LogPath = filepath.Join(tempDir, "app.log")
-	config.LogMaxSize = 1
-	config.LogMaxAge = 1
-	config.LogLevel = "warn"
-	config.GinLogLevel = "info"
+	config.Update(func(c *config.Config) {
+		c.Log.MaxSize = 1
+		c.Log.MaxAge = 1
+		c.Log.Level = "warn"
+		c.Log.GinLevel = "info"
+	})
 
 	SetLogger()
 
@@ -65,19 +61,13 @@ func TestSetLogger_EmptyGinLogLevelFallsBackToBusinessLevel(t *testing.T) {
 	oldLogDir := config.LogDir
 	oldLogPath := config.LogPath
 	oldSelfName := config.SelfName
-	oldLogMaxSize := config.LogMaxSize
-	oldLogMaxAge := config.LogMaxAge
-	oldLogLevel := config.LogLevel
-	oldGinLogLevel := config.GinLogLevel
+	oldConfig := config.Current()
 	t.Cleanup(func() {
 		config.RunModel = oldRunModel
 		config.LogDir = oldLogDir
 		config.LogPath = oldLogPath
 		config.SelfName = oldSelfName
-		config.LogMaxSize = oldLogMaxSize
-		config.LogMaxAge = oldLogMaxAge
-		config.LogLevel = oldLogLevel
-		config.GinLogLevel = oldGinLogLevel
+		config.Replace(oldConfig)
 		SetLogger()
 	})
 
@@ -85,10 +75,12 @@ func TestSetLogger_EmptyGinLogLevelFallsBackToBusinessLevel(t *testing.T) {
 	config.LogDir = tempDir
 	config.SelfName = "http-services-test"
 	config.LogPath = filepath.Join(tempDir, "app.log")
-	config.LogMaxSize = 1
-	config.LogMaxAge = 1
-	config.LogLevel = "error"
-	config.GinLogLevel = ""
+	config.Update(func(c *config.Config) {
+		c.Log.MaxSize = 1
+		c.Log.MaxAge = 1
+		c.Log.Level = "error"
+		c.Log.GinLevel = ""
+	})
 
 	SetLogger()
 
@@ -106,19 +98,13 @@ func TestSetLogger_ReleaseWritesSeparateBusinessAndGinFiles(t *testing.T) {
 	oldLogDir := config.LogDir
 	oldLogPath := config.LogPath
 	oldSelfName := config.SelfName
-	oldLogMaxSize := config.LogMaxSize
-	oldLogMaxAge := config.LogMaxAge
-	oldLogLevel := config.LogLevel
-	oldGinLogLevel := config.GinLogLevel
+	oldConfig := config.Current()
 	t.Cleanup(func() {
 		config.RunModel = oldRunModel
 		config.LogDir = oldLogDir
 		config.LogPath = oldLogPath
 		config.SelfName = oldSelfName
-		config.LogMaxSize = oldLogMaxSize
-		config.LogMaxAge = oldLogMaxAge
-		config.LogLevel = oldLogLevel
-		config.GinLogLevel = oldGinLogLevel
+		config.Replace(oldConfig)
 		SetLogger()
 	})
 
@@ -126,10 +112,12 @@ func TestSetLogger_ReleaseWritesSeparateBusinessAndGinFiles(t *testing.T) {
 	config.LogDir = tempDir
 	config.SelfName = "http-services-test"
 	config.LogPath = filepath.Join(tempDir, "http-services-test.log")
-	config.LogMaxSize = 1
-	config.LogMaxAge = 1
-	config.LogLevel = "info"
-	config.GinLogLevel = "info"
+	config.Update(func(c *config.Config) {
+		c.Log.MaxSize = 1
+		c.Log.MaxAge = 1
+		c.Log.Level = "info"
+		c.Log.GinLevel = "info"
+	})
 	SetLogger()
 
 	zap.L().Info("business event")
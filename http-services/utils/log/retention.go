@@ -0,0 +1,94 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+)
+
+// diskBudgetInterval 是检查日志目录总大小的间隔；lumberjack 按大小轮转和压缩都在后台进行，定时检查即可覆盖
+const diskBudgetInterval = time.Minute
+
+type logFileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// enforceDiskBudget 在 LogDir 下日志文件总大小超过 log.max_total_size 时，从最旧的文件开始删除，
+// 正在写入的文件计入总量但不会被删除。只处理 .log / .log.gz 文件，不触碰目录中的其他文件。
+func enforceDiskBudget() {
+	budget := config.Current().Log.MaxTotalSize
+	if budget <= 0 {
+		return
+	}
+
+	mu.RLock()
+	sinks := currentSinks
+	mu.RUnlock()
+	active := make(map[string]bool)
+	if sinks != nil {
+		for _, lj := range sinks.lumberjacks() {
+			active[filepath.Clean(lj.Filename)] = true
+		}
+	}
+
+	removed, err := trimLogDir(config.LogDir, budget, active)
+	for _, path := range removed {
+		zap.L().Info("removed log file over disk budget", zap.String("path", path), zap.Int64("budget", budget))
+	}
+	if err != nil {
+		zap.L().Warn("enforce log disk budget failed", zap.String("dir", config.LogDir), zap.Error(err))
+	}
+}
+
+// trimLogDir 删除 dir 中最旧的非活动日志文件直到总大小不超过 budget，返回已删除的路径
+func trimLogDir(dir string, budget int64, active map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		total     int64
+		deletable []logFileInfo
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		total += info.Size()
+		if !active[filepath.Clean(path)] {
+			deletable = append(deletable, logFileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		}
+	}
+	if total <= budget {
+		return nil, nil
+	}
+
+	slices.SortFunc(deletable, func(a, b logFileInfo) int { return a.modTime.Compare(b.modTime) })
+	var removed []string
+	for _, file := range deletable {
+		if total <= budget {
+			break
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		total -= file.size
+		removed = append(removed, file.path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeLogFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
+
+func TestTrimLogDir_RemovesOldestInactiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	active := filepath.Join(dir, "app.log")
+	writeLogFile(t, active, 100, now.Add(-3*time.Hour))
+	writeLogFile(t, filepath.Join(dir, "app-2026-01-01T00-00-00.000.log.gz"), 100, now.Add(-2*time.Hour))
+	writeLogFile(t, filepath.Join(dir, "app-2026-01-02T00-00-00.000.log.gz"), 100, now.Add(-time.Hour))
+	writeLogFile(t, filepath.Join(dir, "app.gin.log"), 100, now)
+	writeLogFile(t, filepath.Join(dir, "http-services.pid"), 1000, now.Add(-4*time.Hour))
+
+	removed, err := trimLogDir(dir, 250, map[string]bool{active: true})
+	if err != nil {
+		t.Fatalf("trimLogDir() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "app-2026-01-01T00-00-00.000.log.gz"),
+		filepath.Join(dir, "app-2026-01-02T00-00-00.000.log.gz"),
+	}
+	if !slices.Equal(removed, want) {
+		t.Fatalf("removed = %v, want %v", removed, want)
+	}
+	for _, kept := range []string{active, filepath.Join(dir, "app.gin.log"), filepath.Join(dir, "http-services.pid")} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s should be kept: %v", kept, err)
+		}
+	}
+}
+
+func TestTrimLogDir_UnderBudgetIsNoop(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, filepath.Join(dir, "old.log"), 10, time.Now().Add(-time.Hour))
+
+	removed, err := trimLogDir(dir, 100, nil)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("trimLogDir() = %v, %v, want nothing removed", removed, err)
+	}
+}
+
+func TestNextRotation(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 30, 15, 0, time.Local)
+	tests := []struct {
+		schedule string
+		want     time.Time
+	}{
+		{"daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)},
+		{"hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.Local)},
+		{"none", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := nextRotation(now, tt.schedule); !got.Equal(tt.want) {
+			t.Errorf("nextRotation(%s) = %v, want %v", tt.schedule, got, tt.want)
+		}
+	}
+}
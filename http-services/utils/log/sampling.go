@@ -0,0 +1,64 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// exemptSamplingCore 只对非豁免级别采样：豁免级别直接交给原 core，
+// 采样部分按级别 + 消息统计，超出 first 后每 thereafter 条保留 1 条。
+type exemptSamplingCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+	exempt  [zapcore.FatalLevel - zapcore.DebugLevel + 1]bool
+}
+
+// newSamplingCore 根据 log.sampling 配置包装 core；未启用时原样返回，dropped 记录被采样丢弃的条数
+func newSamplingCore(core zapcore.Core, dropped *atomic.Uint64) zapcore.Core {
+	sampling := config.Current().Log.Sampling
+	if !sampling.Enabled {
+		return core
+	}
+	c := &exemptSamplingCore{Core: core}
+	// error 及以上始终不采样，避免重复错误被静默吞掉
+	for level := zapcore.ErrorLevel; level <= zapcore.FatalLevel; level++ {
+		c.exempt[level-zapcore.DebugLevel] = true
+	}
+	for _, text := range sampling.ExemptLevels {
+		level, err := ParseLevel(text)
+		if err != nil {
+			zap.L().Warn("ignore invalid log.sampling.exempt_levels entry", zap.String("level", text))
+			continue
+		}
+		c.exempt[level-zapcore.DebugLevel] = true
+	}
+	c.sampled = zapcore.NewSamplerWithOptions(core,
+		sampling.Tick, sampling.First, sampling.Thereafter,
+		zapcore.SamplerHook(func(_ zapcore.Entry, decision zapcore.SamplingDecision) {
+			if decision&zapcore.LogDropped != 0 {
+				dropped.Add(1)
+			}
+		}),
+	)
+	return c
+}
+
+func (c *exemptSamplingCore) isExempt(level zapcore.Level) bool {
+	index := int(level - zapcore.DebugLevel)
+	return index < 0 || index >= len(c.exempt) || c.exempt[index]
+}
+
+func (c *exemptSamplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &exemptSamplingCore{Core: c.Core.With(fields), sampled: c.sampled.With(fields), exempt: c.exempt}
+}
+
+func (c *exemptSamplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.isExempt(entry.Level) {
+		return c.Core.Check(entry, checked)
+	}
+	return c.sampled.Check(entry, checked)
+}
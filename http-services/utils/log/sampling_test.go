@@ -0,0 +1,62 @@
+package log
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func useSampling(t *testing.T, exempt []string) {
+	t.Helper()
+	oldConfig := config.Update(func(c *config.Config) {
+		c.Log.Sampling = config.LogSamplingConfig{
+			Enabled:      true,
+			Tick:         time.Minute,
+			First:        2,
+			Thereafter:   5,
+			ExemptLevels: exempt,
+		}
+	})
+	t.Cleanup(func() { config.Replace(oldConfig) })
+}
+
+func TestSamplingCore_ExemptsErrorsAndConfiguredLevels(t *testing.T) {
+	useSampling(t, []string{"warn"})
+	observed, logs := observer.New(zap.DebugLevel)
+	var dropped atomic.Uint64
+	l := zap.New(newSamplingCore(observed, &dropped)).With(zap.String("component", "test"))
+
+	for range 10 {
+		l.Info("repeated info")
+		l.Warn("repeated warn")
+		l.Error("repeated error")
+	}
+
+	if got := logs.FilterMessage("repeated error").Len(); got != 10 {
+		t.Errorf("error entries = %d, want 10 (never sampled)", got)
+	}
+	if got := logs.FilterMessage("repeated warn").Len(); got != 10 {
+		t.Errorf("warn entries = %d, want 10 (exempt)", got)
+	}
+	// first 2 pass, then only every 5th after them (the 7th entry) passes
+	if got := logs.FilterMessage("repeated info").Len(); got != 3 {
+		t.Errorf("info entries = %d, want 3", got)
+	}
+	if dropped.Load() != 7 {
+		t.Errorf("dropped = %d, want 7", dropped.Load())
+	}
+}
+
+func TestSamplingCore_DisabledReturnsCore(t *testing.T) {
+	useSampling(t, nil)
+	config.Update(func(c *config.Config) { c.Log.Sampling.Enabled = false })
+	observed, _ := observer.New(zap.DebugLevel)
+	if core := newSamplingCore(observed, new(atomic.Uint64)); core != observed {
+		t.Fatal("newSamplingCore() wrapped the core while sampling is disabled")
+	}
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultShipperBatchSize     = 500
+	defaultShipperFlushInterval = time.Second
+	defaultShipperTimeout       = 5 * time.Second
+)
+
+// httpShipper 将一批 JSON 日志推送到远端：
+//   - ndjson：每行一条日志，适用于 Vector、Fluent Bit 等 HTTP 输入；
+//   - loki：Loki push API（/loki/api/v1/push），所有日志进入 labels 指定的同一个 stream；
+//   - elastic：Elasticsearch _bulk API，url 需指向 /<index>/_bulk。
+type httpShipper struct {
+	client  *http.Client
+	url     string
+	format  string
+	headers map[string]string
+	labels  map[string]string
+	timeout time.Duration
+}
+
+func (s *httpShipper) send(batch []asyncEntry) error {
+	body, contentType, err := s.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("log shipper: %s responded %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *httpShipper) encode(batch []asyncEntry) ([]byte, string, error) {
+	var body bytes.Buffer
+	switch s.format {
+	case "loki":
+		values := make([][2]string, 0, len(batch))
+		for _, entry := range batch {
+			values = append(values, [2]string{
+				strconv.FormatInt(entry.at.UnixNano(), 10),
+				string(bytes.TrimRight(entry.data, "\r\n")),
+			})
+		}
+		payload := map[string]any{
+			"streams": []map[string]any{{"stream": s.labels, "values": values}},
+		}
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return nil, "", err
+		}
+		return body.Bytes(), "application/json", nil
+	case "elastic":
+		for _, entry := range batch {
+			body.WriteString("{\"index\":{}}\n")
+			body.Write(bytes.TrimRight(entry.data, "\r\n"))
+			body.WriteByte('\n')
+		}
+		return body.Bytes(), "application/x-ndjson", nil
+	default:
+		for _, entry := range batch {
+			body.Write(bytes.TrimRight(entry.data, "\r\n"))
+			body.WriteByte('\n')
+		}
+		return body.Bytes(), "application/x-ndjson", nil
+	}
+}
@@ -0,0 +1,274 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 日志流：业务日志与 gin 日志共享非文件输出，默认文件输出各写各的文件
+const (
+	streamApp = iota
+	streamGin
+)
+
+// sinkSet 是一次 SetLogger 构建出的全部输出，重建时整体替换，旧的在后台刷新并关闭
+type sinkSet struct {
+	cores   [2][]zapcore.Core // 按 stream 索引
+	appLJ   *lumberjack.Logger
+	ginLJ   *lumberjack.Logger
+	files   []*lumberjack.Logger // 自定义路径的文件输出，同样参与定时轮转
+	async   []*asyncWriter
+	closers []io.Closer
+	sampled atomic.Uint64 // 被 log.sampling 丢弃的条数
+}
+
+// SinkStat 是异步输出（以及启用采样时的 sampler）的丢弃统计
+type SinkStat struct {
+	Sink     string `json:"sink"`
+	Buffered int    `json:"buffered"` // 当前缓冲中待输出的条数
+	Dropped  uint64 `json:"dropped"`  // 缓冲区满被丢弃的条数
+	Failed   uint64 `json:"failed"`   // 输出失败的条数（如远端不可达）
+}
+
+// SinkStats 返回当前异步输出的累计丢弃与失败计数
+func SinkStats() []SinkStat {
+	mu.RLock()
+	set := currentSinks
+	mu.RUnlock()
+	if set == nil {
+		return nil
+	}
+	stats := make([]SinkStat, 0, len(set.async)+1)
+	for _, w := range set.async {
+		stats = append(stats, SinkStat{
+			Sink:     w.name,
+			Buffered: len(w.entries),
+			Dropped:  w.dropped.Load(),
+			Failed:   w.failed.Load(),
+		})
+	}
+	if config.Current().Log.Sampling.Enabled {
+		stats = append(stats, SinkStat{Sink: "sampler", Dropped: set.sampled.Load()})
+	}
+	return stats
+}
+
+// defaultSinks 是未配置 log.sinks 时的输出：dev 模式控制台，release 模式按流写默认文件
+func defaultSinks(dev bool) []config.LogSink {
+	if dev {
+		return []config.LogSink{{Type: config.LogSinkStdout}}
+	}
+	return []config.LogSink{{Type: config.LogSinkFile}}
+}
+
+// buildSinks 构建全部输出；单个输出失败（如 syslog 不可达）时跳过它并返回错误，其余输出照常工作
+func buildSinks(sinks []config.LogSink, dev bool) (*sinkSet, []error) {
+	if len(sinks) == 0 {
+		sinks = defaultSinks(dev)
+	}
+	set := &sinkSet{}
+	var errs []error
+	for index, sink := range sinks {
+		if err := set.add(index, sink, dev); err != nil {
+			errs = append(errs, fmt.Errorf("log sink %d (%s): %w", index, sink.Type, err))
+		}
+	}
+	if len(set.cores[streamApp]) == 0 {
+		// 所有输出都不可用时退回 stderr，至少保证错误可见
+		set.addCore(config.LogSink{Type: config.LogSinkStderr}, dev, zapcore.Lock(os.Stderr), nil)
+	}
+	return set, errs
+}
+
+func (s *sinkSet) add(index int, sink config.LogSink, dev bool) error {
+	name := fmt.Sprintf("%s#%d", sink.Type, index)
+	switch sink.Type {
+	case config.LogSinkStdout:
+		s.addCore(sink, dev, s.maybeAsync(name, sink, zapcore.Lock(os.Stdout)), nil)
+	case config.LogSinkStderr:
+		s.addCore(sink, dev, s.maybeAsync(name, sink, zapcore.Lock(os.Stderr)), nil)
+	case config.LogSinkFile:
+		if strings.TrimSpace(sink.Path) == "" {
+			s.appLJ = newLumberjack(config.LogPath)
+			s.ginLJ = newLumberjack(ginLogPath())
+			s.closers = append(s.closers, s.appLJ, s.ginLJ)
+			s.addStreamCore(streamApp, sink, dev, s.maybeAsync(name+"/app", sink, zapcore.AddSync(s.appLJ)))
+			s.addStreamCore(streamGin, sink, dev, s.maybeAsync(name+"/gin", sink, zapcore.AddSync(s.ginLJ)))
+			return nil
+		}
+		lj := newLumberjack(sink.Path)
+		s.files = append(s.files, lj)
+		s.closers = append(s.closers, lj)
+		s.addCore(sink, dev, s.maybeAsync(name, sink, zapcore.AddSync(lj)), nil)
+	case config.LogSinkSyslog:
+		facility, err := parseSyslogFacility(sink.Facility)
+		if err != nil {
+			return err
+		}
+		conn, err := newSyslogConn(sink.Network, sink.Address)
+		if err != nil {
+			return err
+		}
+		s.closers = append(s.closers, conn)
+		tag := strings.TrimSpace(sink.Tag)
+		if tag == "" {
+			tag = config.SelfName
+		}
+		out := s.maybeAsync(name, sink, conn)
+		stream := isSyslogStream(conn.network)
+		s.addCore(sink, dev, out, func(enc zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+			return newSyslogCore(enc, out, enabler, facility, tag, stream)
+		})
+	case config.LogSinkHTTP:
+		s.addCore(sink, dev, s.newShipper(name, sink), nil)
+	default:
+		return fmt.Errorf("unknown sink type %q", sink.Type)
+	}
+	return nil
+}
+
+func (s *sinkSet) newShipper(name string, sink config.LogSink) zapcore.WriteSyncer {
+	timeout := sink.Timeout
+	if timeout <= 0 {
+		timeout = defaultShipperTimeout
+	}
+	labels := map[string]string{"service": config.SelfName}
+	if len(sink.Labels) > 0 {
+		labels = make(map[string]string, len(sink.Labels))
+		for _, label := range sink.Labels {
+			labels[label.Name] = label.Value
+		}
+	}
+	shipper := &httpShipper{
+		client:  &http.Client{Timeout: timeout},
+		url:     sink.URL,
+		format:  sink.Format,
+		headers: sink.Headers,
+		labels:  labels,
+		timeout: timeout,
+	}
+	batchSize := sink.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultShipperBatchSize
+	}
+	interval := sink.FlushInterval
+	if interval <= 0 {
+		interval = defaultShipperFlushInterval
+	}
+	w := newAsyncWriter(name, sink.BufferSize, batchSize, interval, shipper.send)
+	s.async = append(s.async, w)
+	s.closers = append(s.closers, w)
+	return w
+}
+
+// maybeAsync 在 sink.async 开启时用带界缓冲包装 out
+func (s *sinkSet) maybeAsync(name string, sink config.LogSink, out zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if !sink.Async {
+		return out
+	}
+	w := newAsyncWriter(name, sink.BufferSize, 0, time.Second, func(batch []asyncEntry) error {
+		var errs []error
+		for _, entry := range batch {
+			if _, err := out.Write(entry.data); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+	s.async = append(s.async, w)
+	// 先关闭异步层输出剩余日志，再关闭底层连接或文件
+	s.closers = append([]io.Closer{w}, s.closers...)
+	return w
+}
+
+// addCore 为两个日志流各建一个 core；build 为空时使用标准的编码 + 写入 core
+func (s *sinkSet) addCore(sink config.LogSink, dev bool, out zapcore.WriteSyncer, build func(zapcore.Encoder, zapcore.LevelEnabler) zapcore.Core) {
+	for _, stream := range []int{streamApp, streamGin} {
+		if build != nil {
+			s.cores[stream] = append(s.cores[stream], build(newSinkEncoder(sink, dev), sinkLevel(sink)))
+			continue
+		}
+		s.addStreamCore(stream, sink, dev, out)
+	}
+}
+
+func (s *sinkSet) addStreamCore(stream int, sink config.LogSink, dev bool, out zapcore.WriteSyncer) {
+	s.cores[stream] = append(s.cores[stream], zapcore.NewCore(newSinkEncoder(sink, dev), out, sinkLevel(sink)))
+}
+
+// core 返回某个日志流的合并 core
+func (s *sinkSet) core(stream int) zapcore.Core {
+	cores := s.cores[stream]
+	if len(cores) == 0 {
+		cores = s.cores[streamApp]
+	}
+	return newSamplingCore(zapcore.NewTee(cores...), &s.sampled)
+}
+
+func (s *sinkSet) lumberjacks() []*lumberjack.Logger {
+	result := make([]*lumberjack.Logger, 0, len(s.files)+2)
+	for _, lj := range append([]*lumberjack.Logger{s.appLJ, s.ginLJ}, s.files...) {
+		if lj != nil {
+			result = append(result, lj)
+		}
+	}
+	return result
+}
+
+func (s *sinkSet) close() {
+	for _, closer := range s.closers {
+		if err := closer.Close(); err != nil {
+			zap.L().Warn("close log sink failed", zap.Error(err))
+		}
+	}
+}
+
+func newLumberjack(fileName string) *lumberjack.Logger {
+	logConfig := config.Current().Log
+	return &lumberjack.Logger{
+		Filename: fileName,
+		MaxSize:  logConfig.MaxSize,
+		// 不限制备份文件数量：按 max_age 清理，目录总量由 log.max_total_size 兜底（见 enforceDiskBudget）。
+		MaxBackups: 0,
+		MaxAge:     logConfig.MaxAge,
+		LocalTime:  true,
+		Compress:   logConfig.Compress,
+	}
+}
+
+// newSinkEncoder dev 模式下的 stdout/stderr 默认输出彩色友好的 console 格式，其余默认 JSON
+func newSinkEncoder(sink config.LogSink, dev bool) zapcore.Encoder {
+	encoder := sink.Encoder
+	if encoder == "" {
+		encoder = "json"
+		if dev && (sink.Type == config.LogSinkStdout || sink.Type == config.LogSinkStderr) {
+			encoder = "console"
+		}
+	}
+	if encoder == "console" {
+		return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// sinkLevel 返回输出自身的最低级别；未配置时放行全部，由模块级别决定
+func sinkLevel(sink config.LogSink) zapcore.LevelEnabler {
+	if strings.TrimSpace(sink.Level) == "" {
+		return zapcore.DebugLevel
+	}
+	return parseLogLevel(sink.Level)
+}
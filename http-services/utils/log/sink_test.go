@@ -0,0 +1,219 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"http-services/config"
+
+	"go.uber.org/zap"
+)
+
+func useSinks(t *testing.T, sinks []config.LogSink) {
+	t.Helper()
+	oldRunModel := config.RunModel
+	oldConfig := config.Current()
+	t.Cleanup(func() {
+		config.RunModel = oldRunModel
+		config.Replace(oldConfig)
+		SetLogger()
+	})
+
+	config.RunModel = config.RunModelRelease
+	config.Update(func(c *config.Config) {
+		c.Log.Level = "debug"
+		c.Log.GinLevel = ""
+		c.Log.Sinks = sinks
+	})
+	SetLogger()
+}
+
+func TestSetLogger_StdoutJSONSinkInRelease(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = oldStdout })
+
+	useSinks(t, []config.LogSink{{Type: config.LogSinkStdout}})
+	os.Stdout = oldStdout
+
+	zap.L().Info("container event")
+	GetGinLogger().Info("gin event")
+	_ = writer.Close()
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read stdout: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stdout lines = %q, want business and gin entries", output)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("stdout is not JSON: %q", lines[1])
+	}
+	if entry["msg"] != "gin event" || entry["logger"] != "gin" {
+		t.Fatalf("gin entry = %v", entry)
+	}
+}
+
+func TestSetLogger_FileSinkHonorsSinkLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom", "errors.log")
+	useSinks(t, []config.LogSink{{Type: config.LogSinkFile, Path: path, Level: "warn", Async: true}})
+
+	zap.L().Info("ignored info")
+	zap.L().Warn("kept warn")
+	if err := zap.L().Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sink file: %v", err)
+	}
+	if strings.Contains(string(content), "ignored info") || !strings.Contains(string(content), "kept warn") {
+		t.Fatalf("sink file content = %q", content)
+	}
+}
+
+func TestSetLogger_SyslogSinkOverUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	useSinks(t, []config.LogSink{{
+		Type:     config.LogSinkSyslog,
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Tag:      "svc",
+		Facility: "local0",
+	}})
+	zap.L().Warn("syslog event")
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	frame := string(buf[:n])
+	// local0(16)*8 + warning(4) = 132
+	if !strings.HasPrefix(frame, "<132>") || !strings.Contains(frame, " svc[") || !strings.Contains(frame, "syslog event") {
+		t.Fatalf("syslog frame = %q", frame)
+	}
+}
+
+func TestSetLogger_UnavailableSinkFallsBackToStderr(t *testing.T) {
+	useSinks(t, []config.LogSink{{Type: config.LogSinkSyslog, Network: "unix", Address: filepath.Join(t.TempDir(), "missing.sock")}})
+
+	if ce := zap.L().Check(zap.ErrorLevel, "still logging"); ce == nil {
+		t.Fatal("expected fallback sink to accept entries")
+	}
+}
+
+func TestSetLogger_HTTPSinkShipsLokiBatches(t *testing.T) {
+	bodies := make(chan []byte, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Scope-OrgID") != "tenant" {
+			t.Errorf("missing tenant header: %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	useSinks(t, []config.LogSink{{
+		Type:    config.LogSinkHTTP,
+		URL:     server.URL,
+		Format:  "loki",
+		Headers: map[string]string{"X-Scope-OrgID": "tenant"},
+		Labels:  []config.LogLabel{{Name: "job", Value: "api"}, {Name: "serviceName", Value: "http-services"}},
+	}})
+	zap.L().Info("first")
+	zap.L().Info("second")
+	if err := zap.L().Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	select {
+	case body := <-bodies:
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("loki payload = %q: %v", body, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no batch shipped")
+	}
+	if len(payload.Streams) != 1 || payload.Streams[0].Stream["job"] != "api" || payload.Streams[0].Stream["serviceName"] != "http-services" || len(payload.Streams[0].Values) != 2 {
+		t.Fatalf("loki payload = %+v", payload)
+	}
+	if !strings.Contains(payload.Streams[0].Values[1][1], `"msg":"second"`) {
+		t.Fatalf("second line = %q", payload.Streams[0].Values[1][1])
+	}
+}
+
+func TestAsyncWriter_DropsUnderBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan int, 16)
+	w := newAsyncWriter("test", 1, 1, time.Hour, func(batch []asyncEntry) error {
+		<-release
+		handled <- len(batch)
+		return nil
+	})
+
+	for range 10 {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if w.dropped.Load() == 0 {
+		t.Fatal("expected writes beyond the buffer to be dropped")
+	}
+
+	close(release)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := w.Write([]byte("after close\n")); err != nil {
+		t.Fatalf("Write() after Close error = %v", err)
+	}
+	total := 0
+	for len(handled) > 0 {
+		total += <-handled
+	}
+	if dropped := w.dropped.Load(); uint64(total)+dropped != 11 {
+		t.Fatalf("handled %d + dropped %d, want 11", total, dropped)
+	}
+}
+
+func TestHTTPShipper_ElasticBulkFormat(t *testing.T) {
+	shipper := &httpShipper{format: "elastic"}
+	body, contentType, err := shipper.encode([]asyncEntry{{data: []byte("{\"msg\":\"a\"}\n")}, {data: []byte("{\"msg\":\"b\"}\n")}})
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+	want := "{\"index\":{}}\n{\"msg\":\"a\"}\n{\"index\":{}}\n{\"msg\":\"b\"}\n"
+	if string(body) != want || contentType != "application/x-ndjson" {
+		t.Fatalf("encode() = %q (%s), want %q", body, contentType, want)
+	}
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultSyslogSocket = "/dev/log"
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func parseSyslogFacility(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return syslogFacilities["user"], nil
+	}
+	facility, ok := syslogFacilities[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility %q", name)
+	}
+	return facility, nil
+}
+
+// syslogSeverity 将 zap 级别映射为 RFC 5424 severity
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 7
+	case level == zapcore.InfoLevel:
+		return 6
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// syslogCore 按 RFC 3164 格式（<PRI>时间 主机 tag[pid]: 消息）输出，PRI 随每条日志的级别变化，
+// 因此不能复用 zapcore.NewCore 的固定写入方式。
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc      zapcore.Encoder
+	out      zapcore.WriteSyncer
+	facility int
+	tag      string
+	hostname string
+	pid      int
+	stream   bool // tcp/unix 等流式连接需要换行分帧
+}
+
+func newSyslogCore(enc zapcore.Encoder, out zapcore.WriteSyncer, enabler zapcore.LevelEnabler, facility int, tag string, stream bool) *syslogCore {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &syslogCore{
+		LevelEnabler: enabler,
+		enc:          enc,
+		out:          out,
+		facility:     facility,
+		tag:          tag,
+		hostname:     hostname,
+		pid:          os.Getpid(),
+		stream:       stream,
+	}
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+
+	frame := fmt.Sprintf("<%d>%s %s %s[%d]: %s",
+		c.facility*8+syslogSeverity(entry.Level), entry.Time.Format(time.Stamp), c.hostname, c.tag, c.pid, msg)
+	if c.stream {
+		frame += "\n"
+	}
+	if _, err := c.out.Write([]byte(frame)); err != nil {
+		return err
+	}
+	if entry.Level > zapcore.ErrorLevel {
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
+
+// syslogConn 是自动重连的 syslog 连接；本机 syslog 重启或远端短暂不可达时在下一次写入时重新拨号
+type syslogConn struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+}
+
+func newSyslogConn(network, address string) (*syslogConn, error) {
+	if address == "" && (network == "" || network == "unixgram" || network == "unix") {
+		address = defaultSyslogSocket
+	}
+	if address == "" {
+		return nil, errors.New("syslog sink requires address")
+	}
+	c := &syslogConn{network: network, address: address}
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// isSyslogStream 判断网络类型是否需要换行分帧
+func isSyslogStream(network string) bool {
+	return network == "tcp" || network == "tcp4" || network == "tcp6" || network == "unix"
+}
+
+func (c *syslogConn) dial() error {
+	if c.network != "" {
+		conn, err := net.DialTimeout(c.network, c.address, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+		return nil
+	}
+	// 未指定 network 时按 /dev/log 的常见类型依次尝试
+	var errs []error
+	for _, network := range []string{"unixgram", "unix"} {
+		conn, err := net.DialTimeout(network, c.address, 5*time.Second)
+		if err == nil {
+			c.network = network
+			c.conn = conn
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *syslogConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		if n, err := c.conn.Write(p); err == nil {
+			return n, nil
+		}
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	if err := c.dial(); err != nil {
+		return 0, err
+	}
+	return c.conn.Write(p)
+}
+
+func (c *syslogConn) Sync() error {
+	return nil
+}
+
+func (c *syslogConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -123,6 +123,53 @@ func Remove(path string, pid int) error {
 	return nil
 }
 
+// Replace atomically hands the pid file from oldPID to newPID: the new content is
+// written to a temporary file in the same directory and renamed over path, so a
+// supervisor reading the file sees either the old or the new pid, never a partial
+// write. It fails with ErrOwnership unless path currently holds oldPID.
+func Replace(path string, oldPID, newPID int) (err error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return &OperationError{Op: "validate", Path: path, Err: ErrInvalidPath}
+	}
+	if oldPID <= 0 || newPID <= 0 {
+		return &OperationError{Op: "validate", Path: path, Err: ErrInvalidPID}
+	}
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return &OperationError{Op: "read", Path: path, Err: errors.Join(ErrWrite, readErr)}
+	}
+	if string(content) != strconv.Itoa(oldPID)+"\n" {
+		return &OperationError{Op: "verify", Path: path, Err: ErrOwnership}
+	}
+
+	file, createErr := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if createErr != nil {
+		return &OperationError{Op: "create", Path: path, Err: errors.Join(ErrWrite, createErr)}
+	}
+	tmpPath := file.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	if _, writeErr := file.Write([]byte(strconv.Itoa(newPID) + "\n")); writeErr != nil {
+		_ = file.Close()
+		return &OperationError{Op: "write", Path: path, Err: errors.Join(ErrWrite, writeErr)}
+	}
+	if syncErr := file.Sync(); syncErr != nil {
+		_ = file.Close()
+		return &OperationError{Op: "sync", Path: path, Err: errors.Join(ErrWrite, syncErr)}
+	}
+	if closeErr := file.Close(); closeErr != nil {
+		return wrapFilesystem("close", path, closeErr, ErrWrite)
+	}
+	if renameErr := os.Rename(tmpPath, path); renameErr != nil {
+		return wrapFilesystem("rename", path, renameErr, ErrWrite)
+	}
+	return nil
+}
+
 func wrapFilesystem(operation, path string, err, category error) error {
 	if err == nil {
 		return nil
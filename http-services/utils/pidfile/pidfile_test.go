@@ -49,3 +49,38 @@ func TestRemoveVerifiesPIDOwnershipAndIsIdempotent(t *testing.T) {
 		t.Fatalf("second Remove() error = %v", err)
 	}
 }
+
+func TestReplaceHandsPIDFileToNewOwner(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.pid")
+	if err := Write(path, 1234); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := Replace(path, 9999, 5678); !errors.Is(err, ErrOwnership) {
+		t.Fatalf("Replace() from wrong owner error = %v, want ErrOwnership", err)
+	}
+	if err := Replace(path, 1234, 5678); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != "5678\n" {
+		t.Fatalf("content = %q, want %q", content, "5678\\n")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries after Replace, want only the pid file", len(entries))
+	}
+	// The previous owner removing by its own pid must not delete the new owner's file.
+	if err := Remove(path, 1234); !errors.Is(err, ErrOwnership) {
+		t.Fatalf("Remove() by previous owner error = %v, want ErrOwnership", err)
+	}
+	if err := Replace(filepath.Join(dir, "missing.pid"), 1234, 5678); !errors.Is(err, ErrWrite) {
+		t.Fatalf("Replace() of missing file error = %v, want ErrWrite", err)
+	}
+}
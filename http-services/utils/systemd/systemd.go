@@ -0,0 +1,177 @@
+// Package systemd 实现服务用到的 systemd 服务协议：socket 激活（LISTEN_FDS）、
+// 就绪通知（NOTIFY_SOCKET）与 watchdog（WATCHDOG_USEC）。进程不是由 systemd 启动时所有函数都不做任何事
+package systemd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// listenFDsStart 即 SD_LISTEN_FDS_START，systemd 传入的第一个文件描述符
+const listenFDsStart = 3
+
+// 服务管理器能识别的通知状态，见 sd_notify(3)
+const (
+	Ready        = "READY=1"
+	Stopping     = "STOPPING=1"
+	WatchdogPing = "WATCHDOG=1"
+)
+
+// ErrUnsupportedSocket NOTIFY_SOCKET 既不是文件路径也不是以 @ 开头的抽象 socket
+var ErrUnsupportedSocket = errors.New("systemd: unsupported NOTIFY_SOCKET")
+
+// Status 返回 STATUS= 通知，内容显示在 systemctl status 中
+func Status(text string) string {
+	return "STATUS=" + text
+}
+
+// MainPID 返回 MAINPID= 通知，告知 systemd 服务已由另一个进程接管
+func MainPID(pid int) string {
+	return "MAINPID=" + strconv.Itoa(pid)
+}
+
+// Listener 是 socket 激活传入的 socket 及其 FileDescriptorName=
+type Listener struct {
+	net.Listener
+	Name string
+}
+
+// Listeners 按 socket unit 中的顺序返回 systemd socket 激活传入的 socket
+// LISTEN_PID 不是当前进程时返回 nil；LISTEN_* 环境变量会被清除，避免子进程把这些描述符误认为自己的
+func Listeners() ([]Listener, error) {
+	return listenersFrom(listenFDsStart)
+}
+
+func listenersFrom(start int) ([]Listener, error) {
+	pidValue, fdsValue, namesValue := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	if pidValue == "" || fdsValue == "" {
+		return nil, nil
+	}
+	pid, err := strconv.Atoi(pidValue)
+	if err != nil {
+		return nil, fmt.Errorf("systemd: invalid LISTEN_PID %q", pidValue)
+	}
+	if pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(fdsValue)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("systemd: invalid LISTEN_FDS %q", fdsValue)
+	}
+	var names []string
+	if namesValue != "" {
+		names = strings.Split(namesValue, ":")
+	}
+
+	listeners := make([]Listener, 0, count)
+	for i := range count {
+		name := "unknown"
+		if i < len(names) {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(start+i), name)
+		// FileListener 会复制出带 close-on-exec 的描述符，继承来的描述符不再需要
+		ln, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("systemd: fd %d (%s): %w", start+i, name, err)
+		}
+		listeners = append(listeners, Listener{Listener: ln, Name: name})
+	}
+	return listeners, nil
+}
+
+// Notify 把 states 作为一个数据报发送给服务管理器，见 sd_notify(3)
+// 未设置 NOTIFY_SOCKET 时返回 false 且不报错
+func Notify(states ...string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// 抽象 socket 以 @ 开头，net 包会将其映射到抽象命名空间
+	if !strings.HasPrefix(socket, "/") && !strings.HasPrefix(socket, "@") {
+		return false, fmt.Errorf("%w %q", ErrUnsupportedSocket, socket)
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("systemd: notify: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return false, fmt.Errorf("systemd: notify: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval 在当前进程启用了 watchdog 时返回 WATCHDOG_USEC，否则返回 0
+// 未设置 WATCHDOG_PID 表示 watchdog 对读取它的进程生效
+func WatchdogInterval() (time.Duration, error) {
+	usecValue := os.Getenv("WATCHDOG_USEC")
+	if usecValue == "" {
+		return 0, nil
+	}
+	if pidValue := os.Getenv("WATCHDOG_PID"); pidValue != "" {
+		pid, err := strconv.Atoi(pidValue)
+		if err != nil {
+			return 0, fmt.Errorf("systemd: invalid WATCHDOG_PID %q", pidValue)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+	usec, err := strconv.ParseInt(usecValue, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("systemd: invalid WATCHDOG_USEC %q", usecValue)
+	}
+	return time.Duration(usec) * time.Microsecond, nil
+}
+
+// Watchdog 每隔 interval/2 向服务管理器发送一次心跳，直到 ctx 结束
+// 只有 check 返回 nil 时才发送心跳，整个 interval 内持续不健康的进程会被 systemd 重启；
+// 检查失败与恢复通过 STATUS= 报告
+func Watchdog(ctx context.Context, interval time.Duration, check func() error) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	healthy := true
+	for {
+		var states []string
+		err := check()
+		switch {
+		case err != nil:
+			if healthy {
+				states = append(states, Status("health check failed: "+err.Error()))
+			}
+		case !healthy:
+			states = append(states, WatchdogPing, Status("health check recovered"))
+		default:
+			states = append(states, WatchdogPing)
+		}
+		healthy = err == nil
+		if len(states) > 0 {
+			if _, notifyErr := Notify(states...); notifyErr != nil {
+				zap.L().Warn("systemd watchdog 通知失败", zap.Error(notifyErr))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,190 @@
+//go:build linux
+
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeNotifySocket listens on a unix datagram socket and points NOTIFY_SOCKET at it, as systemd does.
+func fakeNotifySocket(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("ListenUnixgram() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read notification: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotifySendsStatesAsOneDatagram(t *testing.T) {
+	conn := fakeNotifySocket(t)
+
+	sent, err := Notify(MainPID(42), Ready, Status("serving"))
+	if err != nil || !sent {
+		t.Fatalf("Notify() = %v, %v, want true, nil", sent, err)
+	}
+	if got, want := readNotification(t, conn), "MAINPID=42\nREADY=1\nSTATUS=serving"; got != want {
+		t.Fatalf("notification = %q, want %q", got, want)
+	}
+}
+
+func TestNotifyWithoutSocketIsNoop(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(Ready); err != nil || sent {
+		t.Fatalf("Notify() = %v, %v, want false, nil", sent, err)
+	}
+	t.Setenv("NOTIFY_SOCKET", "vsock:2:1234")
+	if _, err := Notify(Ready); err == nil {
+		t.Fatal("Notify() to vsock succeeded, want ErrUnsupportedSocket")
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"disabled", "", "", 0, false},
+		{"this process", "30000000", pid, 30 * time.Second, false},
+		{"no pid", "500000", "", 500 * time.Millisecond, false},
+		{"other process", "30000000", "1", 0, false},
+		{"invalid usec", "soon", pid, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			got, err := WatchdogInterval()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("WatchdogInterval() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWatchdogPingsOnlyWhileHealthy(t *testing.T) {
+	conn := fakeNotifySocket(t)
+	var failing atomic.Bool
+	check := func() error {
+		if failing.Load() {
+			return os.ErrDeadlineExceeded
+		}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Watchdog(ctx, 20*time.Millisecond, check)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	if got := readNotification(t, conn); got != WatchdogPing {
+		t.Fatalf("first notification = %q, want %q", got, WatchdogPing)
+	}
+	failing.Store(true)
+	for {
+		got := readNotification(t, conn)
+		if strings.HasPrefix(got, "STATUS=health check failed") {
+			break
+		}
+		if got != WatchdogPing {
+			t.Fatalf("notification = %q, want pings until the failure status", got)
+		}
+	}
+	// While unhealthy nothing is sent, so the service manager's timer runs out.
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, err := conn.Read(make([]byte, 64)); err == nil {
+		t.Fatalf("received %d bytes while unhealthy, want no ping", n)
+	}
+	failing.Store(false)
+	if got, want := readNotification(t, conn), WatchdogPing+"\nSTATUS=health check recovered"; got != want {
+		t.Fatalf("notification after recovery = %q, want %q", got, want)
+	}
+}
+
+func TestListenersTakesSocketsPassedBySystemd(t *testing.T) {
+	const start = 200
+	for i, name := range []string{"http", "admin"} {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := ln.(*net.TCPListener).File()
+		ln.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Place the descriptors where systemd would: consecutive numbers from the start fd.
+		if err := syscall.Dup3(int(file.Fd()), start+i, 0); err != nil {
+			t.Fatalf("dup %s listener: %v", name, err)
+		}
+		file.Close()
+	}
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	t.Setenv("LISTEN_FDNAMES", "http:admin")
+
+	listeners, err := listenersFrom(start)
+	if err != nil {
+		t.Fatalf("listenersFrom() error = %v", err)
+	}
+	if len(listeners) != 2 || listeners[0].Name != "http" || listeners[1].Name != "admin" {
+		t.Fatalf("listeners = %+v, want http and admin", listeners)
+	}
+	for _, ln := range listeners {
+		conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+		if err != nil {
+			t.Fatalf("dial %s: %v", ln.Name, err)
+		}
+		conn.Close()
+		accepted, err := ln.Accept()
+		if err != nil {
+			t.Fatalf("accept on %s: %v", ln.Name, err)
+		}
+		accepted.Close()
+		ln.Close()
+	}
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Errorf("%s still set after Listeners", key)
+		}
+	}
+
+	// Descriptors addressed to another process are ignored.
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+	if listeners, err := listenersFrom(start); err != nil || listeners != nil {
+		t.Fatalf("listenersFrom() for another pid = %v, %v, want nil, nil", listeners, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package tlscert 加载 TLS 服务端证书与校验客户端证书用的 CA bundle，并在文件变化时热更新。
+//
+// 文件所在目录通过 fsnotify 监听：证书通常以“写临时文件再 rename”或 Kubernetes Secret 的
+// ..data 符号链接切换方式更新，只监听文件本身会在第一次替换后失效。重新加载失败（例如证书已写入、
+// 私钥还没写入）时继续使用上一份可用的证书，等待下一次文件变化。
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay 合并同一次更新产生的多个文件事件，证书与私钥分两次写入时只在写完后加载一次
+const reloadDelay = 200 * time.Millisecond
+
+// Files 是需要加载的 PEM 文件；ClientCAFile 为空表示不校验客户端证书
+type Files struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Reloader 持有当前生效的证书与客户端 CA
+type Reloader struct {
+	files     Files
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+}
+
+// New 加载证书与客户端 CA，任一文件无法加载时返回错误
+func New(files Files) (*Reloader, error) {
+	r := &Reloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取全部文件；失败时保留原来的证书与 CA 并返回错误
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tlscert: load key pair: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.files.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.files.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("tlscert: read client ca: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tlscert: no certificate found in %s", r.files.ClientCAFile)
+		}
+	}
+	r.cert.Store(&cert)
+	r.clientCAs.Store(pool)
+	return nil
+}
+
+// Certificate 返回当前生效的证书
+func (r *Reloader) Certificate() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// ClientCAs 返回当前生效的客户端 CA；没有配置 ClientCAFile 时为 nil
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	return r.clientCAs.Load()
+}
+
+// Apply 返回使用热更新证书的 tls.Config：证书通过 GetCertificate 读取，客户端 CA 在每次握手时
+// 通过 GetConfigForClient 换成最新的一份。base 中的版本、密码套件与 ClientAuth 保持不变
+func (r *Reloader) Apply(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.cert.Load(), nil
+	}
+	if r.files.ClientCAFile != "" {
+		perHandshake := cfg.Clone()
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			handshake := perHandshake.Clone()
+			handshake.ClientCAs = r.clientCAs.Load()
+			return handshake, nil
+		}
+	}
+	return cfg
+}
+
+// Watch 监听文件所在目录，文件变化时重新加载，直到 ctx 结束
+func (r *Reloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("tlscert: %w", err)
+	}
+	defer watcher.Close()
+	for _, dir := range r.dirs() {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("tlscert: watch %s: %w", dir, err)
+		}
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			zap.L().Warn("证书文件监听出错", zap.Error(watchErr))
+		case <-timer.C:
+			r.reloadChanged()
+		}
+	}
+}
+
+// reloadChanged 在文件事件后重新加载，证书内容没有变化时不记录日志（目录中其他文件的变化也会触发）
+func (r *Reloader) reloadChanged() {
+	previous, previousCAs := r.cert.Load(), r.clientCAs.Load()
+	if err := r.Reload(); err != nil {
+		zap.L().Warn("重新加载 TLS 证书失败，继续使用原证书", zap.Error(err))
+		return
+	}
+	current := r.cert.Load()
+	if sameCertificate(previous, current) && previousCAs.Equal(r.clientCAs.Load()) {
+		return
+	}
+	fields := []zap.Field{zap.String("cert_file", r.files.CertFile)}
+	if leaf := current.Leaf; leaf != nil {
+		fields = append(fields, zap.String("subject", leaf.Subject.String()), zap.Time("not_after", leaf.NotAfter))
+	}
+	zap.L().Info("TLS 证书已重新加载", fields...)
+}
+
+func (r *Reloader) dirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, path := range []string{r.files.CertFile, r.files.KeyFile, r.files.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		if dir := filepath.Dir(path); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func sameCertificate(a, b *tls.Certificate) bool {
+	if a == nil || b == nil || len(a.Certificate) != len(b.Certificate) {
+		return false
+	}
+	for i := range a.Certificate {
+		if string(a.Certificate[i]) != string(b.Certificate[i]) {
+			return false
+		}
+	}
+	return true
+}
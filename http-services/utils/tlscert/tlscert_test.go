@@ -0,0 +1,205 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 用 CA 签发 CommonName 为 cn 的证书，返回证书与私钥 PEM
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// replaceFile 与证书签发工具一样先写临时文件再 rename 到目标路径
+func replaceFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func subject(r *Reloader) string {
+	return r.Certificate().Leaf.Subject.CommonName
+}
+
+func TestWatchReloadsReplacedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+	files := Files{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	certPEM, keyPEM := ca.issue(t, "first", x509.ExtKeyUsageServerAuth)
+	replaceFile(t, files.CertFile, certPEM)
+	replaceFile(t, files.KeyFile, keyPEM)
+
+	r, err := New(files)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Watch(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// 等待 watcher 开始监听
+	time.Sleep(50 * time.Millisecond)
+
+	// 证书无法解析时保留原证书
+	replaceFile(t, files.CertFile, []byte("not a certificate"))
+	time.Sleep(3 * reloadDelay)
+	if got := subject(r); got != "first" {
+		t.Fatalf("certificate after broken update = %s, want first", got)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "second", x509.ExtKeyUsageServerAuth)
+	replaceFile(t, files.KeyFile, keyPEM)
+	replaceFile(t, files.CertFile, certPEM)
+	deadline := time.Now().Add(5 * time.Second)
+	for subject(r) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatalf("certificate = %s, want second after replacement", subject(r))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestApplyVerifiesClientsAgainstReloadedCA(t *testing.T) {
+	dir := t.TempDir()
+	serverCA, firstClientCA, secondClientCA := newTestCA(t, "server-ca"), newTestCA(t, "client-ca-1"), newTestCA(t, "client-ca-2")
+	files := Files{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "client-ca.crt"),
+	}
+	certPEM, keyPEM := serverCA.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	replaceFile(t, files.CertFile, certPEM)
+	replaceFile(t, files.KeyFile, keyPEM)
+	replaceFile(t, files.ClientCAFile, firstClientCA.pem)
+
+	r, err := New(files)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	serverConfig := r.Apply(&tls.Config{MinVersion: tls.VersionTLS12, ClientAuth: tls.RequireAndVerifyClientCert})
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCA.cert)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("当前环境无法分配本地监听端口: %v", err)
+	}
+	defer ln.Close()
+
+	handshake := func(ca *testCA) error {
+		clientCertPEM, clientKeyPEM := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+		clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverErr := make(chan error, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				serverErr <- err
+				return
+			}
+			defer conn.Close()
+			serverErr <- tls.Server(conn, serverConfig).Handshake()
+		}()
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		client := tls.Client(conn, &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCert}})
+		_ = client.Handshake()
+		return <-serverErr
+	}
+
+	if err := handshake(firstClientCA); err != nil {
+		t.Fatalf("handshake with trusted client = %v, want success", err)
+	}
+	if err := handshake(secondClientCA); err == nil {
+		t.Fatal("handshake with untrusted client succeeded")
+	}
+
+	// 更新 CA bundle 后，已经创建的 tls.Config 立即使用新的 CA
+	replaceFile(t, files.ClientCAFile, secondClientCA.pem)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if err := handshake(secondClientCA); err != nil {
+		t.Fatalf("handshake after CA rotation = %v, want success", err)
+	}
+	if err := handshake(firstClientCA); err == nil {
+		t.Fatal("handshake with rotated-out CA succeeded")
+	}
+}